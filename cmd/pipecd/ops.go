@@ -24,7 +24,13 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/pipe-cd/pipe/pkg/admin"
+	"github.com/pipe-cd/pipe/pkg/app/api/applicationlivestatestore"
+	"github.com/pipe-cd/pipe/pkg/app/api/commandoutputstore"
+	"github.com/pipe-cd/pipe/pkg/app/api/deploymentartifactstore"
+	"github.com/pipe-cd/pipe/pkg/app/api/stagelogstore"
 	"github.com/pipe-cd/pipe/pkg/app/ops/handler"
+	"github.com/pipe-cd/pipe/pkg/app/ops/modelcleaner"
+	"github.com/pipe-cd/pipe/pkg/cache/memorycache"
 	"github.com/pipe-cd/pipe/pkg/cli"
 	"github.com/pipe-cd/pipe/pkg/datastore"
 	"github.com/pipe-cd/pipe/pkg/version"
@@ -82,6 +88,31 @@ func (s *ops) run(ctx context.Context, t cli.Telemetry) error {
 		}
 	}()
 
+	// Connect to the file store.
+	fs, err := createFilestore(ctx, cfg, t.Logger)
+	if err != nil {
+		t.Logger.Error("failed to create filestore", zap.Error(err))
+		return err
+	}
+	defer func() {
+		if err := fs.Close(); err != nil {
+			t.Logger.Error("failed to close filestore client", zap.Error(err))
+		}
+	}()
+
+	// Start running model cleaner.
+	{
+		cache := memorycache.NewTTLCache(ctx, cfg.Cache.TTLDuration(), time.Minute)
+		sls := stagelogstore.NewStore(fs, cache, t.Logger)
+		alss := applicationlivestatestore.NewStore(fs, cache, t.Logger)
+		cos := commandoutputstore.NewStore(fs, t.Logger)
+		das := deploymentartifactstore.NewStore(fs, t.Logger)
+		cleaner := modelcleaner.NewCleaner(ds, sls, alss, cos, das, t.Logger)
+		group.Go(func() error {
+			return cleaner.Run(ctx)
+		})
+	}
+
 	// Start running HTTP server.
 	{
 		handler := handler.NewHandler(s.httpPort, datastore.NewProjectStore(ds), cfg.SharedSSOConfigs, s.gracePeriod, t.Logger)
@@ -32,10 +32,13 @@ import (
 	"github.com/pipe-cd/pipe/pkg/app/api/apikeyverifier"
 	"github.com/pipe-cd/pipe/pkg/app/api/applicationlivestatestore"
 	"github.com/pipe-cd/pipe/pkg/app/api/authhandler"
+	"github.com/pipe-cd/pipe/pkg/app/api/commandoutputstore"
 	"github.com/pipe-cd/pipe/pkg/app/api/commandstore"
+	"github.com/pipe-cd/pipe/pkg/app/api/deploymentartifactstore"
 	"github.com/pipe-cd/pipe/pkg/app/api/grpcapi"
 	"github.com/pipe-cd/pipe/pkg/app/api/pipedverifier"
 	"github.com/pipe-cd/pipe/pkg/app/api/service/webservice"
+	"github.com/pipe-cd/pipe/pkg/app/api/slackhandler"
 	"github.com/pipe-cd/pipe/pkg/app/api/stagelogstore"
 	"github.com/pipe-cd/pipe/pkg/cache/rediscache"
 	"github.com/pipe-cd/pipe/pkg/cli"
@@ -173,6 +176,8 @@ func (s *server) run(ctx context.Context, t cli.Telemetry) error {
 	}()
 	cache := rediscache.NewTTLCache(rd, cfg.Cache.TTLDuration())
 	sls := stagelogstore.NewStore(fs, cache, t.Logger)
+	das := deploymentartifactstore.NewStore(fs, t.Logger)
+	cos := commandoutputstore.NewStore(fs, t.Logger)
 	alss := applicationlivestatestore.NewStore(fs, cache, t.Logger)
 	cmds := commandstore.NewStore(ds, cache, t.Logger)
 
@@ -186,7 +191,7 @@ func (s *server) run(ctx context.Context, t cli.Telemetry) error {
 				datastore.NewPipedStore(ds),
 				t.Logger,
 			)
-			service = grpcapi.NewPipedAPI(ctx, ds, sls, alss, cmds, t.Logger)
+			service = grpcapi.NewPipedAPI(ctx, ds, sls, alss, cmds, das, cos, t.Logger)
 			opts    = []rpc.Option{
 				rpc.WithPort(s.pipedAPIPort),
 				rpc.WithGracePeriod(s.gracePeriod),
@@ -194,6 +199,7 @@ func (s *server) run(ctx context.Context, t cli.Telemetry) error {
 				rpc.WithLogUnaryInterceptor(t.Logger),
 				rpc.WithPipedTokenAuthUnaryInterceptor(verifier, t.Logger),
 				rpc.WithRequestValidationUnaryInterceptor(),
+				rpc.WithRequestValidationStreamInterceptor(),
 			}
 		)
 		if s.tls {
@@ -211,20 +217,29 @@ func (s *server) run(ctx context.Context, t cli.Telemetry) error {
 
 	// Start a gRPC server for handling external API requests.
 	{
+		trustedProxies, err := cfg.TrustedProxyNetworks()
+		if err != nil {
+			t.Logger.Error("failed to parse trusted proxies", zap.Error(err))
+			return err
+		}
 		var (
 			verifier = apikeyverifier.NewVerifier(
 				ctx,
 				datastore.NewAPIKeyStore(ds),
+				trustedProxies,
 				t.Logger,
 			)
-			service = grpcapi.NewAPI(ds, cmds, t.Logger)
+			service = grpcapi.NewAPI(ds, sls, cmds, t.Logger)
 			opts    = []rpc.Option{
 				rpc.WithPort(s.apiPort),
 				rpc.WithGracePeriod(s.gracePeriod),
 				rpc.WithLogger(t.Logger),
 				rpc.WithLogUnaryInterceptor(t.Logger),
 				rpc.WithAPIKeyAuthUnaryInterceptor(verifier, t.Logger),
+				rpc.WithAPIKeyAuthStreamInterceptor(verifier, t.Logger),
+				rpc.WithAuditLogUnaryInterceptor(datastore.NewAuditLogStore(ds), t.Logger),
 				rpc.WithRequestValidationUnaryInterceptor(),
+				rpc.WithRequestValidationStreamInterceptor(),
 			}
 		)
 		if s.tls {
@@ -251,13 +266,16 @@ func (s *server) run(ctx context.Context, t cli.Telemetry) error {
 			return err
 		}
 
-		service := grpcapi.NewWebAPI(ctx, ds, sls, alss, cmds, cfg.ProjectMap(), encryptDecrypter, t.Logger)
+		service := grpcapi.NewWebAPI(ctx, ds, sls, alss, cmds, das, cos, cfg.ProjectMap(), encryptDecrypter, t.Logger)
 		opts := []rpc.Option{
 			rpc.WithPort(s.webAPIPort),
 			rpc.WithGracePeriod(s.gracePeriod),
 			rpc.WithLogger(t.Logger),
 			rpc.WithJWTAuthUnaryInterceptor(verifier, webservice.NewRBACAuthorizer(), t.Logger),
+			rpc.WithJWTAuthStreamInterceptor(verifier, webservice.NewRBACAuthorizer(), t.Logger),
+			rpc.WithAuditLogUnaryInterceptor(datastore.NewAuditLogStore(ds), t.Logger),
 			rpc.WithRequestValidationUnaryInterceptor(),
+			rpc.WithRequestValidationStreamInterceptor(),
 		}
 		if s.tls {
 			opts = append(opts, rpc.WithTLS(s.certFile, s.keyFile))
@@ -301,6 +319,16 @@ func (s *server) run(ctx context.Context, t cli.Telemetry) error {
 				t.Logger,
 			),
 		}
+		if cfg.Slack != nil {
+			handlers = append(handlers, slackhandler.NewHandler(
+				cfg.Slack,
+				datastore.NewDeploymentStore(ds),
+				datastore.NewApplicationStore(ds),
+				datastore.NewProjectStore(ds),
+				cmds,
+				t.Logger,
+			))
+		}
 
 		for _, h := range handlers {
 			h.Register(mux.HandleFunc)
@@ -20,6 +20,8 @@ import (
 
 	"github.com/pipe-cd/pipe/pkg/app/pipectl/cmd/application"
 	"github.com/pipe-cd/pipe/pkg/app/pipectl/cmd/deployment"
+	"github.com/pipe-cd/pipe/pkg/app/pipectl/cmd/env"
+	"github.com/pipe-cd/pipe/pkg/app/pipectl/cmd/piped"
 	"github.com/pipe-cd/pipe/pkg/cli"
 )
 
@@ -32,6 +34,8 @@ func main() {
 	app.AddCommands(
 		application.NewCommand(),
 		deployment.NewCommand(),
+		piped.NewCommand(),
+		env.NewCommand(),
 	)
 
 	if err := app.Run(); err != nil {
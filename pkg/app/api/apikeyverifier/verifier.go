@@ -17,31 +17,61 @@ package apikeyverifier
 import (
 	"context"
 	"fmt"
+	"net"
+	"strings"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 
 	"github.com/pipe-cd/pipe/pkg/cache"
 	"github.com/pipe-cd/pipe/pkg/cache/memorycache"
 	"github.com/pipe-cd/pipe/pkg/model"
 )
 
-type apiKeyGetter interface {
+const (
+	// The minimum interval between two updates of the last used time of a key.
+	lastUsedUpdateInterval = time.Minute
+	forwardedForHeader     = "x-forwarded-for"
+)
+
+type apiKeyStore interface {
 	GetAPIKey(ctx context.Context, id string) (*model.APIKey, error)
+	UpdateAPIKeyLastUsedAt(ctx context.Context, id string, usedAt int64) error
+}
+
+type usage struct {
+	id     string
+	usedAt int64
 }
 
 type Verifier struct {
 	apiKeyCache cache.Cache
-	apiKeyStore apiKeyGetter
-	logger      *zap.Logger
+	apiKeyStore apiKeyStore
+	usageCh     chan usage
+	// The last time when the usage of each key was written to the datastore.
+	lastRecorded map[string]int64
+	mu           sync.Mutex
+	// The proxies trusted to set the x-forwarded-for header.
+	trustedProxies []*net.IPNet
+	nowFunc        func() time.Time
+	logger         *zap.Logger
 }
 
-func NewVerifier(ctx context.Context, getter apiKeyGetter, logger *zap.Logger) *Verifier {
-	return &Verifier{
-		apiKeyCache: memorycache.NewTTLCache(ctx, 5*time.Minute, time.Minute),
-		apiKeyStore: getter,
-		logger:      logger,
+func NewVerifier(ctx context.Context, store apiKeyStore, trustedProxies []*net.IPNet, logger *zap.Logger) *Verifier {
+	v := &Verifier{
+		apiKeyCache:    memorycache.NewTTLCache(ctx, 5*time.Minute, time.Minute),
+		apiKeyStore:    store,
+		usageCh:        make(chan usage, 100),
+		lastRecorded:   make(map[string]int64),
+		trustedProxies: trustedProxies,
+		nowFunc:        time.Now,
+		logger:         logger,
 	}
+	go v.recordUsages(ctx)
+	return v
 }
 
 func (v *Verifier) Verify(ctx context.Context, key string) (*model.APIKey, error) {
@@ -54,9 +84,10 @@ func (v *Verifier) Verify(ctx context.Context, key string) (*model.APIKey, error
 	item, err := v.apiKeyCache.Get(keyID)
 	if err == nil {
 		apiKey = item.(*model.APIKey)
-		if err := checkAPIKey(apiKey, keyID, key); err != nil {
+		if err := v.checkAPIKey(ctx, apiKey, keyID, key); err != nil {
 			return nil, err
 		}
+		v.markUsed(keyID)
 		return apiKey, nil
 	}
 
@@ -70,21 +101,142 @@ func (v *Verifier) Verify(ctx context.Context, key string) (*model.APIKey, error
 	if err := v.apiKeyCache.Put(keyID, apiKey); err != nil {
 		v.logger.Warn("unable to store API key in memory cache", zap.Error(err))
 	}
-	if err := checkAPIKey(apiKey, keyID, key); err != nil {
+	if err := v.checkAPIKey(ctx, apiKey, keyID, key); err != nil {
 		return nil, err
 	}
+	v.markUsed(keyID)
 
 	return apiKey, nil
 }
 
-func checkAPIKey(apiKey *model.APIKey, id, key string) error {
+func (v *Verifier) checkAPIKey(ctx context.Context, apiKey *model.APIKey, id, key string) error {
 	if apiKey.Disabled {
 		return fmt.Errorf("the api key %s was already disabled", id)
 	}
 
+	if apiKey.IsExpired(v.nowFunc()) {
+		return fmt.Errorf("the api key %s was already expired", id)
+	}
+
 	if err := apiKey.CompareKey(key); err != nil {
 		return fmt.Errorf("invalid api key %s: %w", id, err)
 	}
 
+	if len(apiKey.AllowedIpRanges) > 0 {
+		ip := clientIP(ctx, v.trustedProxies)
+		if !apiKey.AllowsIP(ip) {
+			return fmt.Errorf("the api key %s is not allowed to be used from %s", id, ip)
+		}
+	}
+
 	return nil
 }
+
+// markUsed queues an update of the last used time of the given key
+// without blocking the request. The updates are throttled per key.
+func (v *Verifier) markUsed(id string) {
+	now := v.nowFunc().Unix()
+
+	v.mu.Lock()
+	last := v.lastRecorded[id]
+	if now-last < int64(lastUsedUpdateInterval.Seconds()) {
+		v.mu.Unlock()
+		return
+	}
+	v.lastRecorded[id] = now
+	v.mu.Unlock()
+
+	select {
+	case v.usageCh <- usage{id: id, usedAt: now}:
+	default:
+		v.logger.Warn("dropped an usage of api key because the queue is full", zap.String("key", id))
+	}
+}
+
+func (v *Verifier) recordUsages(ctx context.Context) {
+	ticker := time.NewTicker(lastUsedUpdateInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			v.evictLastRecorded()
+		case u := <-v.usageCh:
+			if err := v.apiKeyStore.UpdateAPIKeyLastUsedAt(ctx, u.id, u.usedAt); err != nil {
+				v.logger.Warn("unable to update the last used time of api key",
+					zap.String("key", u.id),
+					zap.Error(err),
+				)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// evictLastRecorded removes the keys whose usage was recorded longer than
+// the update interval ago since they are no longer throttled.
+func (v *Verifier) evictLastRecorded() {
+	threshold := v.nowFunc().Unix() - int64(lastUsedUpdateInterval.Seconds())
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for id, last := range v.lastRecorded {
+		if last <= threshold {
+			delete(v.lastRecorded, id)
+		}
+	}
+}
+
+// clientIP returns the IP address of the client sending the request.
+// The address of the connection peer is used by default since the x-forwarded-for header
+// can be set by anyone. Only when the peer is one of the trusted proxies,
+// the header is walked from the last entry and the first address
+// not belonging to the trusted proxies is used.
+func clientIP(ctx context.Context, trustedProxies []*net.IPNet) net.IP {
+	ip := peerIP(ctx)
+	if ip == nil || !isTrustedProxy(ip, trustedProxies) {
+		return ip
+	}
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ip
+	}
+	var addrs []string
+	for _, v := range md.Get(forwardedForHeader) {
+		addrs = append(addrs, strings.Split(v, ",")...)
+	}
+	for i := len(addrs) - 1; i >= 0; i-- {
+		forwarded := net.ParseIP(strings.TrimSpace(addrs[i]))
+		if forwarded == nil {
+			// The entries before a malformed one cannot be trusted.
+			return ip
+		}
+		ip = forwarded
+		if !isTrustedProxy(ip, trustedProxies) {
+			return ip
+		}
+	}
+	return ip
+}
+
+func peerIP(ctx context.Context) net.IP {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return net.ParseIP(p.Addr.String())
+	}
+	return net.ParseIP(host)
+}
+
+func isTrustedProxy(ip net.IP, trustedProxies []*net.IPNet) bool {
+	for _, n := range trustedProxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
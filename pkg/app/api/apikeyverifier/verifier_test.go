@@ -17,11 +17,16 @@ package apikeyverifier
 import (
 	"context"
 	"fmt"
+	"net"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/protobuf/proto"
 
 	"github.com/pipe-cd/pipe/pkg/model"
@@ -30,6 +35,25 @@ import (
 type fakeAPIKeyGetter struct {
 	calls   int
 	apiKeys map[string]*model.APIKey
+
+	mu       sync.Mutex
+	lastUsed map[string]int64
+}
+
+func (g *fakeAPIKeyGetter) UpdateAPIKeyLastUsedAt(_ context.Context, id string, usedAt int64) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.lastUsed == nil {
+		g.lastUsed = make(map[string]int64)
+	}
+	g.lastUsed[id] = usedAt
+	return nil
+}
+
+func (g *fakeAPIKeyGetter) getLastUsed(id string) int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.lastUsed[id]
 }
 
 func (g *fakeAPIKeyGetter) GetAPIKey(_ context.Context, id string) (*model.APIKey, error) {
@@ -54,6 +78,14 @@ func TestVerify(t *testing.T) {
 	key2, hash2, err := model.GenerateAPIKey(id2)
 	require.NoError(t, err)
 
+	var id3 = "expired-api-key"
+	key3, hash3, err := model.GenerateAPIKey(id3)
+	require.NoError(t, err)
+
+	var id4 = "ip-restricted-api-key"
+	key4, hash4, err := model.GenerateAPIKey(id4)
+	require.NoError(t, err)
+
 	apiKeyGetter := &fakeAPIKeyGetter{
 		apiKeys: map[string]*model.APIKey{
 			id1: {
@@ -69,9 +101,23 @@ func TestVerify(t *testing.T) {
 				ProjectId: "test-project",
 				Disabled:  true,
 			},
+			id3: {
+				Id:        id3,
+				Name:      id3,
+				KeyHash:   hash3,
+				ProjectId: "test-project",
+				ExpiresAt: time.Now().Add(-time.Hour).Unix(),
+			},
+			id4: {
+				Id:              id4,
+				Name:            id4,
+				KeyHash:         hash4,
+				ProjectId:       "test-project",
+				AllowedIpRanges: []string{"10.0.0.0/8"},
+			},
 		},
 	}
-	v := NewVerifier(ctx, apiKeyGetter, zap.NewNop())
+	v := NewVerifier(ctx, apiKeyGetter, nil, zap.NewNop())
 
 	// Not found key.
 	notFoundKey, _, err := model.GenerateAPIKey("not-found-api-key")
@@ -102,4 +148,117 @@ func TestVerify(t *testing.T) {
 	assert.Equal(t, id1, apiKey.Name)
 	assert.Nil(t, err)
 	require.Equal(t, 3, apiKeyGetter.calls)
+
+	// The last used time is recorded asynchronously.
+	require.Eventually(t, func() bool {
+		return apiKeyGetter.getLastUsed(id1) > 0
+	}, time.Second, 10*time.Millisecond)
+
+	// Found key but it was expired.
+	apiKey, err = v.Verify(ctx, key3)
+	require.Nil(t, apiKey)
+	require.NotNil(t, err)
+	assert.Equal(t, "the api key expired-api-key was already expired", err.Error())
+
+	// Found key but used from a not allowed address.
+	outsideCtx := peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("192.168.0.1"), Port: 1234}})
+	apiKey, err = v.Verify(outsideCtx, key4)
+	require.Nil(t, apiKey)
+	require.NotNil(t, err)
+	assert.Equal(t, "the api key ip-restricted-api-key is not allowed to be used from 192.168.0.1", err.Error())
+
+	insideCtx := peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.1.2.3"), Port: 1234}})
+	apiKey, err = v.Verify(insideCtx, key4)
+	require.NoError(t, err)
+	assert.Equal(t, id4, apiKey.Name)
+
+	// The forwarded address is ignored when no proxy is trusted.
+	spoofedCtx := metadata.NewIncomingContext(outsideCtx, metadata.Pairs("x-forwarded-for", "10.1.2.3"))
+	apiKey, err = v.Verify(spoofedCtx, key4)
+	require.Nil(t, apiKey)
+	require.NotNil(t, err)
+}
+
+func TestEvictLastRecorded(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	v := &Verifier{
+		lastRecorded: map[string]int64{
+			"fresh-key":    now.Add(-30 * time.Second).Unix(),
+			"boundary-key": now.Add(-lastUsedUpdateInterval).Unix(),
+			"stale-key":    now.Add(-time.Hour).Unix(),
+		},
+		nowFunc: func() time.Time { return now },
+	}
+
+	v.evictLastRecorded()
+	assert.Equal(t, map[string]int64{
+		"fresh-key": now.Add(-30 * time.Second).Unix(),
+	}, v.lastRecorded)
+}
+
+func TestClientIP(t *testing.T) {
+	_, proxies, err := net.ParseCIDR("172.16.0.0/12")
+	require.NoError(t, err)
+	trusted := []*net.IPNet{proxies}
+
+	testcases := []struct {
+		name         string
+		peer         string
+		forwardedFor []string
+		trusted      []*net.IPNet
+		expected     string
+	}{
+		{
+			name:     "no forwarded header",
+			peer:     "192.168.0.1",
+			trusted:  trusted,
+			expected: "192.168.0.1",
+		},
+		{
+			name:         "forwarded header is ignored when no proxy is trusted",
+			peer:         "172.16.0.1",
+			forwardedFor: []string{"10.0.0.1"},
+			expected:     "172.16.0.1",
+		},
+		{
+			name:         "forwarded header is ignored when sent from an untrusted peer",
+			peer:         "192.168.0.1",
+			forwardedFor: []string{"10.0.0.1"},
+			trusted:      trusted,
+			expected:     "192.168.0.1",
+		},
+		{
+			name:         "the address seen by the trusted proxy",
+			peer:         "172.16.0.1",
+			forwardedFor: []string{"10.0.0.1, 192.168.0.1"},
+			trusted:      trusted,
+			expected:     "192.168.0.1",
+		},
+		{
+			name:         "multiple trusted proxies are skipped",
+			peer:         "172.16.0.1",
+			forwardedFor: []string{"10.0.0.1, 192.168.0.1", "172.16.0.2"},
+			trusted:      trusted,
+			expected:     "192.168.0.1",
+		},
+		{
+			name:         "malformed entry stops walking",
+			peer:         "172.16.0.1",
+			forwardedFor: []string{"10.0.0.1, unknown, 172.16.0.2"},
+			trusted:      trusted,
+			expected:     "172.16.0.2",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(tc.peer), Port: 1234}})
+			if len(tc.forwardedFor) > 0 {
+				md := metadata.MD{}
+				md.Append("x-forwarded-for", tc.forwardedFor...)
+				ctx = metadata.NewIncomingContext(ctx, md)
+			}
+			got := clientIP(ctx, tc.trusted)
+			assert.Equal(t, tc.expected, got.String())
+		})
+	}
 }
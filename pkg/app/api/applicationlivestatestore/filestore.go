@@ -50,6 +50,11 @@ func (f *applicationLiveStateFileStore) Put(ctx context.Context, applicationID s
 	return f.backend.PutObject(ctx, path, data)
 }
 
+func (f *applicationLiveStateFileStore) Delete(ctx context.Context, applicationID string) error {
+	path := applicationLiveStatePath(applicationID)
+	return f.backend.DeleteObject(ctx, path)
+}
+
 func applicationLiveStatePath(applicationID string) string {
 	return fmt.Sprintf("application-live-state/%s.json", applicationID)
 }
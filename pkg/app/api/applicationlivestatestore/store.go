@@ -32,6 +32,8 @@ type Store interface {
 	PutStateSnapshot(ctx context.Context, snapshot *model.ApplicationLiveStateSnapshot) error
 	// PatchKubernetesApplicationLiveState updates the kubernetes resource state in the application live state snapshot.
 	PatchKubernetesApplicationLiveState(ctx context.Context, events []*model.KubernetesResourceStateEvent)
+	// DeleteStateSnapshot deletes the specified application live state snapshot.
+	DeleteStateSnapshot(ctx context.Context, applicationID string) error
 }
 
 type store struct {
@@ -85,6 +87,18 @@ func (s *store) PutStateSnapshot(ctx context.Context, snapshot *model.Applicatio
 	return nil
 }
 
+func (s *store) DeleteStateSnapshot(ctx context.Context, applicationID string) error {
+	if err := s.backend.Delete(ctx, applicationID); err != nil {
+		s.logger.Error("failed to delete application live state snapshot from filestore", zap.Error(err))
+		return err
+	}
+
+	if err := s.cache.Delete(applicationID); err != nil {
+		s.logger.Error("failed to delete application live state snapshot from cache", zap.Error(err))
+	}
+	return nil
+}
+
 func (s *store) PatchKubernetesApplicationLiveState(ctx context.Context, events []*model.KubernetesResourceStateEvent) {
 	snapshots := make(map[string]*model.ApplicationLiveStateSnapshot)
 	for _, ev := range events {
@@ -28,6 +28,7 @@ import (
 	"github.com/pipe-cd/pipe/pkg/jwt"
 	"github.com/pipe-cd/pipe/pkg/model"
 	"github.com/pipe-cd/pipe/pkg/oauth/github"
+	"github.com/pipe-cd/pipe/pkg/oauth/oidc"
 )
 
 func (h *Handler) handleCallback(w http.ResponseWriter, r *http.Request) {
@@ -77,7 +78,7 @@ func (h *Handler) handleCallback(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	user, err := getUser(ctx, sso, proj.Rbac, proj.Id, authCode)
+	user, err := getUser(ctx, sso, proj.Rbac, proj.Id, h.callbackURL, authCode)
 	if err != nil {
 		h.handleError(w, r, "Unable to find user", err)
 		return
@@ -131,7 +132,7 @@ func checkState(r *http.Request, key string) error {
 	return nil
 }
 
-func getUser(ctx context.Context, sso *model.ProjectSSOConfig, rbac *model.ProjectRBACConfig, projectID, code string) (*model.User, error) {
+func getUser(ctx context.Context, sso *model.ProjectSSOConfig, rbac *model.ProjectRBACConfig, projectID, callbackURL, code string) (*model.User, error) {
 	switch sso.Provider {
 	case model.ProjectSSOConfig_GITHUB, model.ProjectSSOConfig_GITHUB_ENTERPRISE:
 		if sso.Github == nil {
@@ -143,6 +144,16 @@ func getUser(ctx context.Context, sso *model.ProjectSSOConfig, rbac *model.Proje
 		}
 		return cli.GetUser(ctx)
 
+	case model.ProjectSSOConfig_OIDC:
+		if sso.Oidc == nil {
+			return nil, fmt.Errorf("missing OIDC oauth in the SSO configuration")
+		}
+		cli, err := oidc.NewOAuthClient(ctx, sso.Oidc, rbac, projectID, callbackURL, code)
+		if err != nil {
+			return nil, err
+		}
+		return cli.GetUser(ctx)
+
 	default:
 		return nil, fmt.Errorf("not implemented")
 	}
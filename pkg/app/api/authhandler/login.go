@@ -26,6 +26,7 @@ import (
 
 	"github.com/pipe-cd/pipe/pkg/jwt"
 	"github.com/pipe-cd/pipe/pkg/model"
+	"github.com/pipe-cd/pipe/pkg/oauth/oidc"
 )
 
 // handleSSOLogin is called when an user requested to login via SSO.
@@ -69,7 +70,7 @@ func (h *Handler) handleSSOLogin(w http.ResponseWriter, r *http.Request) {
 		stateToken = xsrftoken.Generate(h.stateKey, "", "")
 		state      = hex.EncodeToString([]byte(stateToken))
 	)
-	authURL, err := sso.GenerateAuthCodeURL(proj.Id, h.callbackURL, state)
+	authURL, err := generateAuthCodeURL(ctx, sso, proj.Id, h.callbackURL, state)
 	if err != nil {
 		h.handleError(w, r, "Internal error", err)
 		return
@@ -79,6 +80,19 @@ func (h *Handler) handleSSOLogin(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, authURL, http.StatusFound)
 }
 
+func generateAuthCodeURL(ctx context.Context, sso *model.ProjectSSOConfig, projectID, callbackURL, state string) (string, error) {
+	switch sso.Provider {
+	case model.ProjectSSOConfig_OIDC:
+		if sso.Oidc == nil {
+			return "", fmt.Errorf("missing OIDC oauth in the SSO configuration")
+		}
+		return oidc.GenerateAuthCodeURL(ctx, sso.Oidc, projectID, callbackURL, state)
+
+	default:
+		return sso.GenerateAuthCodeURL(projectID, callbackURL, state)
+	}
+}
+
 // handleStaticAdminLogin is called when an user requested to login as a static admin.
 func (h *Handler) handleStaticAdminLogin(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/html")
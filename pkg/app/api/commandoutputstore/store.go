@@ -0,0 +1,117 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package commandoutputstore provides a way to save and load
+// the output data of the handled commands, such as the read container logs.
+package commandoutputstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/pipe-cd/pipe/pkg/filestore"
+)
+
+// OutputTTL is the period during which the output of a handled command can be read.
+// The output may contain the sensitive data such as container logs
+// so it should be removed shortly.
+const OutputTTL = 10 * time.Minute
+
+const outputPathPrefix = "command-outputs/"
+
+var (
+	ErrNotFound = errors.New("command output was not found")
+)
+
+type Store interface {
+	// GetOutput returns the output data of the given command.
+	GetOutput(ctx context.Context, commandID string) ([]byte, error)
+	// PutOutput saves the output data of the given command.
+	PutOutput(ctx context.Context, commandID string, output []byte) error
+	// DeleteOutput deletes the output data of the given command.
+	DeleteOutput(ctx context.Context, commandID string) error
+	// ListCommandIDs returns the IDs of all commands having the saved output.
+	ListCommandIDs(ctx context.Context) ([]string, error)
+}
+
+type store struct {
+	backend filestore.Store
+	logger  *zap.Logger
+}
+
+func NewStore(fs filestore.Store, logger *zap.Logger) Store {
+	return &store{
+		backend: fs,
+		logger:  logger.Named("command-output-store"),
+	}
+}
+
+func (s *store) GetOutput(ctx context.Context, commandID string) ([]byte, error) {
+	obj, err := s.backend.GetObject(ctx, outputPath(commandID))
+	if errors.Is(err, filestore.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		s.logger.Error("failed to get command output from filestore",
+			zap.String("command-id", commandID),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	return obj.Content, nil
+}
+
+func (s *store) PutOutput(ctx context.Context, commandID string, output []byte) error {
+	if err := s.backend.PutObject(ctx, outputPath(commandID), output); err != nil {
+		s.logger.Error("failed to put command output to filestore",
+			zap.String("command-id", commandID),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+func (s *store) DeleteOutput(ctx context.Context, commandID string) error {
+	if err := s.backend.DeleteObject(ctx, outputPath(commandID)); err != nil {
+		s.logger.Error("failed to delete command output from filestore",
+			zap.String("command-id", commandID),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+func (s *store) ListCommandIDs(ctx context.Context) ([]string, error) {
+	objs, err := s.backend.ListObjects(ctx, outputPathPrefix)
+	if err != nil {
+		s.logger.Error("failed to list command outputs from filestore", zap.Error(err))
+		return nil, err
+	}
+	ids := make([]string, 0, len(objs))
+	for _, obj := range objs {
+		ids = append(ids, strings.TrimPrefix(obj.Path, outputPathPrefix))
+	}
+	return ids, nil
+}
+
+func outputPath(commandID string) string {
+	return fmt.Sprintf("%s%s", outputPathPrefix, commandID)
+}
@@ -0,0 +1,104 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commandoutputstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/pipe-cd/pipe/pkg/filestore"
+	"github.com/pipe-cd/pipe/pkg/filestore/filestoretest"
+)
+
+func TestGetOutput(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testcases := []struct {
+		name      string
+		commandID string
+		object    filestore.Object
+		getErr    error
+
+		expected    []byte
+		expectedErr error
+	}{
+		{
+			name:        "output not found in filestore",
+			commandID:   "command-id",
+			getErr:      filestore.ErrNotFound,
+			expectedErr: ErrNotFound,
+		},
+		{
+			name:      "found output in filestore",
+			commandID: "command-id",
+			object: filestore.Object{
+				Path:    "command-outputs/command-id",
+				Content: []byte("logs"),
+			},
+			expected: []byte("logs"),
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			fs := filestoretest.NewMockStore(ctrl)
+			fs.EXPECT().
+				GetObject(context.TODO(), "command-outputs/"+tc.commandID).
+				Return(tc.object, tc.getErr)
+
+			s := NewStore(fs, zap.NewNop())
+			got, err := s.GetOutput(context.TODO(), tc.commandID)
+			assert.Equal(t, tc.expected, got)
+			assert.True(t, errors.Is(err, tc.expectedErr))
+		})
+	}
+}
+
+func TestPutOutput(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fs := filestoretest.NewMockStore(ctrl)
+	fs.EXPECT().
+		PutObject(context.TODO(), "command-outputs/command-id", []byte("logs")).
+		Return(nil)
+
+	s := NewStore(fs, zap.NewNop())
+	err := s.PutOutput(context.TODO(), "command-id", []byte("logs"))
+	assert.NoError(t, err)
+}
+
+func TestListCommandIDs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fs := filestoretest.NewMockStore(ctrl)
+	fs.EXPECT().
+		ListObjects(context.TODO(), "command-outputs/").
+		Return([]filestore.Object{
+			{Path: "command-outputs/command-1"},
+			{Path: "command-outputs/command-2"},
+		}, nil)
+
+	s := NewStore(fs, zap.NewNop())
+	got, err := s.ListCommandIDs(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"command-1", "command-2"}, got)
+}
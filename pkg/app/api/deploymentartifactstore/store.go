@@ -0,0 +1,138 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package deploymentartifactstore provides a way to save and load
+// the files generated while handling deployments, such as the rendered diff of manifests.
+package deploymentartifactstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/pipe-cd/pipe/pkg/filestore"
+)
+
+// ArtifactTTL is the period during which the artifacts of a completed deployment are kept.
+// The artifacts such as the terraform plan may contain the sensitive data
+// so they should not be kept longer than needed.
+const ArtifactTTL = 7 * 24 * time.Hour
+
+const artifactPathPrefix = "deployment-artifacts/"
+
+var (
+	ErrNotFound = errors.New("deployment artifact was not found")
+)
+
+type Store interface {
+	// GetArtifact returns the content of the specified artifact of a deployment.
+	GetArtifact(ctx context.Context, deploymentID, name string) ([]byte, error)
+	// PutArtifact saves the content as the specified artifact of a deployment.
+	PutArtifact(ctx context.Context, deploymentID, name string, content []byte) error
+	// DeleteArtifacts deletes all artifacts of a deployment.
+	DeleteArtifacts(ctx context.Context, deploymentID string) error
+	// ListDeploymentIDs returns the IDs of all deployments having the saved artifacts.
+	ListDeploymentIDs(ctx context.Context) ([]string, error)
+}
+
+type store struct {
+	backend filestore.Store
+	logger  *zap.Logger
+}
+
+func NewStore(fs filestore.Store, logger *zap.Logger) Store {
+	return &store{
+		backend: fs,
+		logger:  logger.Named("deployment-artifact-store"),
+	}
+}
+
+func (s *store) GetArtifact(ctx context.Context, deploymentID, name string) ([]byte, error) {
+	obj, err := s.backend.GetObject(ctx, artifactPath(deploymentID, name))
+	if errors.Is(err, filestore.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		s.logger.Error("failed to get deployment artifact from filestore",
+			zap.String("deployment-id", deploymentID),
+			zap.String("name", name),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	return obj.Content, nil
+}
+
+func (s *store) PutArtifact(ctx context.Context, deploymentID, name string, content []byte) error {
+	if err := s.backend.PutObject(ctx, artifactPath(deploymentID, name), content); err != nil {
+		s.logger.Error("failed to put deployment artifact to filestore",
+			zap.String("deployment-id", deploymentID),
+			zap.String("name", name),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+func (s *store) DeleteArtifacts(ctx context.Context, deploymentID string) error {
+	objs, err := s.backend.ListObjects(ctx, artifactPath(deploymentID, ""))
+	if err != nil {
+		s.logger.Error("failed to list deployment artifacts from filestore",
+			zap.String("deployment-id", deploymentID),
+			zap.Error(err),
+		)
+		return err
+	}
+	for _, obj := range objs {
+		if err := s.backend.DeleteObject(ctx, obj.Path); err != nil {
+			s.logger.Error("failed to delete deployment artifact from filestore",
+				zap.String("deployment-id", deploymentID),
+				zap.String("path", obj.Path),
+				zap.Error(err),
+			)
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *store) ListDeploymentIDs(ctx context.Context) ([]string, error) {
+	objs, err := s.backend.ListObjects(ctx, artifactPathPrefix)
+	if err != nil {
+		s.logger.Error("failed to list deployment artifacts from filestore", zap.Error(err))
+		return nil, err
+	}
+	var (
+		ids  = make([]string, 0, len(objs))
+		seen = make(map[string]struct{}, len(objs))
+	)
+	for _, obj := range objs {
+		id := strings.SplitN(strings.TrimPrefix(obj.Path, artifactPathPrefix), "/", 2)[0]
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func artifactPath(deploymentID, name string) string {
+	return fmt.Sprintf("%s%s/%s", artifactPathPrefix, deploymentID, name)
+}
@@ -0,0 +1,131 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploymentartifactstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/pipe-cd/pipe/pkg/filestore"
+	"github.com/pipe-cd/pipe/pkg/filestore/filestoretest"
+)
+
+func TestGetArtifact(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testcases := []struct {
+		name         string
+		deploymentID string
+		artifactName string
+		object       filestore.Object
+		getErr       error
+
+		expected    []byte
+		expectedErr error
+	}{
+		{
+			name:         "artifact not found in filestore",
+			deploymentID: "deployment-id",
+			artifactName: "manifests.diff",
+			getErr:       filestore.ErrNotFound,
+			expectedErr:  ErrNotFound,
+		},
+		{
+			name:         "found artifact in filestore",
+			deploymentID: "deployment-id",
+			artifactName: "manifests.diff",
+			object: filestore.Object{
+				Path:    "deployment-artifacts/deployment-id/manifests.diff",
+				Content: []byte("diff"),
+			},
+			expected: []byte("diff"),
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			fs := filestoretest.NewMockStore(ctrl)
+			fs.EXPECT().
+				GetObject(context.TODO(), "deployment-artifacts/"+tc.deploymentID+"/"+tc.artifactName).
+				Return(tc.object, tc.getErr)
+
+			s := NewStore(fs, zap.NewNop())
+			got, err := s.GetArtifact(context.TODO(), tc.deploymentID, tc.artifactName)
+			assert.Equal(t, tc.expected, got)
+			assert.True(t, errors.Is(err, tc.expectedErr))
+		})
+	}
+}
+
+func TestPutArtifact(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fs := filestoretest.NewMockStore(ctrl)
+	fs.EXPECT().
+		PutObject(context.TODO(), "deployment-artifacts/deployment-id/manifests.diff", []byte("diff")).
+		Return(nil)
+
+	s := NewStore(fs, zap.NewNop())
+	err := s.PutArtifact(context.TODO(), "deployment-id", "manifests.diff", []byte("diff"))
+	assert.NoError(t, err)
+}
+
+func TestDeleteArtifacts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fs := filestoretest.NewMockStore(ctrl)
+	fs.EXPECT().
+		ListObjects(context.TODO(), "deployment-artifacts/deployment-id/").
+		Return([]filestore.Object{
+			{Path: "deployment-artifacts/deployment-id/manifests.diff"},
+			{Path: "deployment-artifacts/deployment-id/terraform-plan-stage-1"},
+		}, nil)
+	fs.EXPECT().
+		DeleteObject(context.TODO(), "deployment-artifacts/deployment-id/manifests.diff").
+		Return(nil)
+	fs.EXPECT().
+		DeleteObject(context.TODO(), "deployment-artifacts/deployment-id/terraform-plan-stage-1").
+		Return(nil)
+
+	s := NewStore(fs, zap.NewNop())
+	err := s.DeleteArtifacts(context.TODO(), "deployment-id")
+	assert.NoError(t, err)
+}
+
+func TestListDeploymentIDs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fs := filestoretest.NewMockStore(ctrl)
+	fs.EXPECT().
+		ListObjects(context.TODO(), "deployment-artifacts/").
+		Return([]filestore.Object{
+			{Path: "deployment-artifacts/deployment-1/manifests.diff"},
+			{Path: "deployment-artifacts/deployment-1/terraform-plan-stage-1"},
+			{Path: "deployment-artifacts/deployment-2/manifests.diff"},
+		}, nil)
+
+	s := NewStore(fs, zap.NewNop())
+	ids, err := s.ListDeploymentIDs(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"deployment-1", "deployment-2"}, ids)
+}
@@ -26,6 +26,7 @@ import (
 
 	"github.com/pipe-cd/pipe/pkg/app/api/commandstore"
 	"github.com/pipe-cd/pipe/pkg/app/api/service/apiservice"
+	"github.com/pipe-cd/pipe/pkg/app/api/stagelogstore"
 	"github.com/pipe-cd/pipe/pkg/datastore"
 	"github.com/pipe-cd/pipe/pkg/model"
 	"github.com/pipe-cd/pipe/pkg/rpc/rpcauth"
@@ -34,8 +35,10 @@ import (
 // API implements the behaviors for the gRPC definitions of API.
 type API struct {
 	applicationStore datastore.ApplicationStore
+	environmentStore datastore.EnvironmentStore
 	deploymentStore  datastore.DeploymentStore
 	pipedStore       datastore.PipedStore
+	stageLogStore    stagelogstore.Store
 	commandStore     commandstore.Store
 
 	logger *zap.Logger
@@ -44,13 +47,16 @@ type API struct {
 // NewAPI creates a new API instance.
 func NewAPI(
 	ds datastore.DataStore,
+	sls stagelogstore.Store,
 	cmds commandstore.Store,
 	logger *zap.Logger,
 ) *API {
 	a := &API{
 		applicationStore: datastore.NewApplicationStore(ds),
+		environmentStore: datastore.NewEnvironmentStore(ds),
 		deploymentStore:  datastore.NewDeploymentStore(ds),
 		pipedStore:       datastore.NewPipedStore(ds),
+		stageLogStore:    sls,
 		commandStore:     cmds,
 		logger:           logger.Named("api"),
 	}
@@ -76,6 +82,16 @@ func (a *API) AddApplication(ctx context.Context, req *apiservice.AddApplication
 	if key.ProjectId != piped.ProjectId {
 		return nil, status.Error(codes.InvalidArgument, "Requested piped does not belong to your project")
 	}
+	// A key restricted to some applications can not be used to add a new one.
+	if len(key.ApplicationIds) > 0 {
+		return nil, status.Error(codes.PermissionDenied, "The API key is restricted to some applications")
+	}
+	if err := checkAPIKeyScope(key, req.EnvId, "", a.logger); err != nil {
+		return nil, err
+	}
+	if err := model.ValidateLabels(req.Labels); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
 
 	gitpath, err := makeGitPath(
 		req.GitPath.Repo.Id,
@@ -97,6 +113,7 @@ func (a *API) AddApplication(ctx context.Context, req *apiservice.AddApplication
 		GitPath:       gitpath,
 		Kind:          req.Kind,
 		CloudProvider: req.CloudProvider,
+		Labels:        req.Labels,
 	}
 	err = a.applicationStore.AddApplication(ctx, &app)
 	if errors.Is(err, datastore.ErrAlreadyExists) {
@@ -126,6 +143,9 @@ func (a *API) SyncApplication(ctx context.Context, req *apiservice.SyncApplicati
 	if key.ProjectId != app.ProjectId {
 		return nil, status.Error(codes.InvalidArgument, "Requested application does not belong to your project")
 	}
+	if err := checkAPIKeyScope(key, app.EnvId, app.Id, a.logger); err != nil {
+		return nil, err
+	}
 
 	cmd := model.Command{
 		Id:            uuid.New().String(),
@@ -161,34 +181,395 @@ func (a *API) GetApplication(ctx context.Context, req *apiservice.GetApplication
 	if app.ProjectId != key.ProjectId {
 		return nil, status.Error(codes.InvalidArgument, "Requested application does not belong to your project")
 	}
+	if err := checkAPIKeyScope(key, app.EnvId, app.Id, a.logger); err != nil {
+		return nil, err
+	}
 
 	return &apiservice.GetApplicationResponse{
 		Application: app,
 	}, nil
 }
 
+func (a *API) ListApplications(ctx context.Context, req *apiservice.ListApplicationsRequest) (*apiservice.ListApplicationsResponse, error) {
+	key, err := requireAPIKey(ctx, model.APIKey_READ_ONLY, a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	o := req.Options
+	filters := makeApplicationListFilters(key.ProjectId, o.GetEnabled(), o.GetKinds(), o.GetSyncStatuses(), o.GetEnvIds())
+	selector, labelFilters, err := parseLabelSelector(o.GetLabelSelector())
+	if err != nil {
+		return nil, err
+	}
+	filters = append(filters, labelFilters...)
+	apps, err := a.applicationStore.ListApplications(ctx, datastore.ListOptions{
+		Filters: filters,
+		Orders: []datastore.Order{
+			{
+				Field:     "UpdatedAt",
+				Direction: datastore.Desc,
+			},
+		},
+	})
+	if err != nil {
+		a.logger.Error("failed to get applications", zap.Error(err))
+		return nil, status.Error(codes.Internal, "Failed to get applications")
+	}
+
+	// Leaving out the applications the key is not allowed to access.
+	apps = filterApplications(apps, selector)
+	allowed := apps[:0]
+	for _, app := range apps {
+		if key.AllowsApplication(app.EnvId, app.Id) {
+			allowed = append(allowed, app)
+		}
+	}
+
+	return &apiservice.ListApplicationsResponse{
+		Applications: allowed,
+	}, nil
+}
+
+func (a *API) UpdateApplication(ctx context.Context, req *apiservice.UpdateApplicationRequest) (*apiservice.UpdateApplicationResponse, error) {
+	key, err := requireAPIKey(ctx, model.APIKey_READ_WRITE, a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	app, err := getApplication(ctx, a.applicationStore, req.ApplicationId, a.logger)
+	if err != nil {
+		return nil, err
+	}
+	if app.ProjectId != key.ProjectId {
+		return nil, status.Error(codes.InvalidArgument, "Requested application does not belong to your project")
+	}
+	// The key must be allowed to access the application in both the current and the new environment.
+	if err := checkAPIKeyScope(key, app.EnvId, app.Id, a.logger); err != nil {
+		return nil, err
+	}
+	if err := checkAPIKeyScope(key, req.EnvId, app.Id, a.logger); err != nil {
+		return nil, err
+	}
+	if err := model.ValidateLabels(req.Labels); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	piped, err := getPiped(ctx, a.pipedStore, req.PipedId, a.logger)
+	if err != nil {
+		return nil, err
+	}
+	if piped.ProjectId != key.ProjectId {
+		return nil, status.Error(codes.InvalidArgument, "Requested piped does not belong to your project")
+	}
+
+	gitpath, err := makeGitPath(
+		req.GitPath.Repo.Id,
+		req.GitPath.Path,
+		req.GitPath.ConfigFilename,
+		piped,
+		a.logger,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.applicationStore.UpdateApplication(ctx, req.ApplicationId, func(app *model.Application) error {
+		app.Name = req.Name
+		app.EnvId = req.EnvId
+		app.PipedId = req.PipedId
+		app.GitPath = gitpath
+		app.Kind = req.Kind
+		app.CloudProvider = req.CloudProvider
+		// The current labels are kept unless the new ones are specified or they are cleared.
+		if len(req.Labels) > 0 || req.ClearLabels {
+			app.Labels = req.Labels
+		}
+		return nil
+	})
+	if err != nil {
+		a.logger.Error("failed to update application", zap.Error(err))
+		return nil, status.Error(codes.Internal, "Failed to update application")
+	}
+
+	return &apiservice.UpdateApplicationResponse{}, nil
+}
+
+func (a *API) EnableApplication(ctx context.Context, req *apiservice.EnableApplicationRequest) (*apiservice.EnableApplicationResponse, error) {
+	if err := a.updateApplicationEnable(ctx, req.ApplicationId, true); err != nil {
+		return nil, err
+	}
+	return &apiservice.EnableApplicationResponse{}, nil
+}
+
+func (a *API) DisableApplication(ctx context.Context, req *apiservice.DisableApplicationRequest) (*apiservice.DisableApplicationResponse, error) {
+	if err := a.updateApplicationEnable(ctx, req.ApplicationId, false); err != nil {
+		return nil, err
+	}
+	return &apiservice.DisableApplicationResponse{}, nil
+}
+
+func (a *API) updateApplicationEnable(ctx context.Context, appID string, enable bool) error {
+	key, err := requireAPIKey(ctx, model.APIKey_READ_WRITE, a.logger)
+	if err != nil {
+		return err
+	}
+
+	app, err := getApplication(ctx, a.applicationStore, appID, a.logger)
+	if err != nil {
+		return err
+	}
+	if app.ProjectId != key.ProjectId {
+		return status.Error(codes.InvalidArgument, "Requested application does not belong to your project")
+	}
+	if err := checkAPIKeyScope(key, app.EnvId, app.Id, a.logger); err != nil {
+		return err
+	}
+
+	return setApplicationEnable(ctx, a.applicationStore, appID, enable, a.logger)
+}
+
 func (a *API) GetDeployment(ctx context.Context, req *apiservice.GetDeploymentRequest) (*apiservice.GetDeploymentResponse, error) {
 	key, err := requireAPIKey(ctx, model.APIKey_READ_ONLY, a.logger)
 	if err != nil {
 		return nil, err
 	}
 
-	deployment, err := getDeployment(ctx, a.deploymentStore, req.DeploymentId, a.logger)
+	deployment, err := a.getDeployment(ctx, key, req.DeploymentId)
 	if err != nil {
 		return nil, err
 	}
 
+	return &apiservice.GetDeploymentResponse{
+		Deployment: deployment,
+	}, nil
+}
+
+func (a *API) ListDeployments(ctx context.Context, req *apiservice.ListDeploymentsRequest) (*apiservice.ListDeploymentsResponse, error) {
+	key, err := requireAPIKey(ctx, model.APIKey_READ_ONLY, a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	o := req.Options
+	filters := makeDeploymentListFilters(key.ProjectId, o.GetStatuses(), o.GetKinds(), o.GetApplicationIds(), o.GetEnvIds(), o.GetMaxUpdatedAt())
+	selector, labelFilters, err := parseLabelSelector(o.GetLabelSelector())
+	if err != nil {
+		return nil, err
+	}
+	filters = append(filters, labelFilters...)
+
+	// The label selector and the scope of the key cannot be expressed in the query,
+	// so the deployments are fetched batch by batch until the page is filled.
+	allowed := func(d *model.Deployment) bool {
+		return key.AllowsApplication(d.EnvId, d.ApplicationId) && matchesDeploymentLabels(d, selector)
+	}
+	deployments, err := listFilteredDeployments(ctx, a.deploymentStore, filters, int(req.PageSize), allowed)
+	if err != nil {
+		a.logger.Error("failed to get deployments", zap.Error(err))
+		return nil, status.Error(codes.Internal, "Failed to get deployments")
+	}
+
+	return &apiservice.ListDeploymentsResponse{
+		Deployments: deployments,
+	}, nil
+}
+
+func (a *API) CancelDeployment(ctx context.Context, req *apiservice.CancelDeploymentRequest) (*apiservice.CancelDeploymentResponse, error) {
+	key, err := requireAPIKey(ctx, model.APIKey_READ_WRITE, a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	deployment, err := a.getDeployment(ctx, key, req.DeploymentId)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd, err := makeCancelDeploymentCommand(deployment, key.Id, req.ForceRollback, req.ForceNoRollback)
+	if err != nil {
+		return nil, err
+	}
+	if err := addCommand(ctx, a.commandStore, cmd, a.logger); err != nil {
+		return nil, err
+	}
+
+	return &apiservice.CancelDeploymentResponse{
+		CommandId: cmd.Id,
+	}, nil
+}
+
+// ApproveStage approves the given WAIT_APPROVAL stage on behalf of the API key.
+// Since the key is not a user nor a member of any team, only the stages
+// that do not restrict their approvers can be approved.
+func (a *API) ApproveStage(ctx context.Context, req *apiservice.ApproveStageRequest) (*apiservice.ApproveStageResponse, error) {
+	key, err := requireAPIKey(ctx, model.APIKey_READ_WRITE, a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	deployment, err := a.getDeployment(ctx, key, req.DeploymentId)
+	if err != nil {
+		return nil, err
+	}
+
+	stage, err := findPendingStage(deployment, req.StageId, "approve")
+	if err != nil {
+		return nil, err
+	}
+	if len(model.StageApprovers(stage)) > 0 {
+		return nil, status.Error(codes.PermissionDenied, "The stage can be approved only by its approvers")
+	}
+
+	cmd := model.Command{
+		Id:            uuid.New().String(),
+		PipedId:       deployment.PipedId,
+		ApplicationId: deployment.ApplicationId,
+		DeploymentId:  req.DeploymentId,
+		StageId:       req.StageId,
+		Type:          model.Command_APPROVE_STAGE,
+		Commander:     key.Id,
+		ApproveStage: &model.Command_ApproveStage{
+			DeploymentId: req.DeploymentId,
+			StageId:      req.StageId,
+		},
+	}
+	if err := addCommand(ctx, a.commandStore, &cmd, a.logger); err != nil {
+		return nil, err
+	}
+
+	return &apiservice.ApproveStageResponse{
+		CommandId: cmd.Id,
+	}, nil
+}
+
+func (a *API) GetStageLog(ctx context.Context, req *apiservice.GetStageLogRequest) (*apiservice.GetStageLogResponse, error) {
+	key, err := requireAPIKey(ctx, model.APIKey_READ_ONLY, a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := a.getDeployment(ctx, key, req.DeploymentId); err != nil {
+		return nil, err
+	}
+
+	blocks, completed, err := fetchStageLogs(ctx, a.stageLogStore, req.DeploymentId, req.StageId, req.RetriedCount, req.OffsetIndex, a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return &apiservice.GetStageLogResponse{
+		Blocks:    blocks,
+		Completed: completed,
+	}, nil
+}
+
+func (a *API) StreamStageLog(req *apiservice.StreamStageLogRequest, stream apiservice.APIService_StreamStageLogServer) error {
+	ctx := stream.Context()
+	key, err := requireAPIKey(ctx, model.APIKey_READ_ONLY, a.logger)
+	if err != nil {
+		return err
+	}
+
+	if _, err := a.getDeployment(ctx, key, req.DeploymentId); err != nil {
+		return err
+	}
+
+	return streamStageLogs(ctx, a.stageLogStore, req.DeploymentId, req.StageId, req.RetriedCount, req.OffsetIndex, func(blocks []*model.LogBlock, completed bool) error {
+		return stream.Send(&apiservice.StreamStageLogResponse{
+			Blocks:    blocks,
+			Completed: completed,
+		})
+	}, a.logger)
+}
+
+// getDeployment returns the specified deployment
+// after ensuring that the given key is allowed to access it.
+func (a *API) getDeployment(ctx context.Context, key *model.APIKey, id string) (*model.Deployment, error) {
+	deployment, err := getDeployment(ctx, a.deploymentStore, id, a.logger)
+	if err != nil {
+		return nil, err
+	}
 	if key.ProjectId != deployment.ProjectId {
 		return nil, status.Error(codes.InvalidArgument, "Requested deployment does not belong to your project")
 	}
+	if err := checkAPIKeyScope(key, deployment.EnvId, deployment.ApplicationId, a.logger); err != nil {
+		return nil, err
+	}
+	return deployment, nil
+}
 
-	return &apiservice.GetDeploymentResponse{
-		Deployment: deployment,
+func (a *API) ListPipeds(ctx context.Context, req *apiservice.ListPipedsRequest) (*apiservice.ListPipedsResponse, error) {
+	key, err := requireAPIKey(ctx, model.APIKey_READ_ONLY, a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	pipeds, err := listPipeds(ctx, a.pipedStore, key.ProjectId, req.Options.GetEnabled(), a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return &apiservice.ListPipedsResponse{
+		Pipeds: pipeds,
+	}, nil
+}
+
+func (a *API) GetPiped(ctx context.Context, req *apiservice.GetPipedRequest) (*apiservice.GetPipedResponse, error) {
+	key, err := requireAPIKey(ctx, model.APIKey_READ_ONLY, a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	piped, err := getPiped(ctx, a.pipedStore, req.PipedId, a.logger)
+	if err != nil {
+		return nil, err
+	}
+	if piped.ProjectId != key.ProjectId {
+		return nil, status.Error(codes.InvalidArgument, "Requested piped does not belong to your project")
+	}
+
+	// Redact all sensitive data inside piped message before sending to the client.
+	piped.RedactSensitiveData()
+
+	return &apiservice.GetPipedResponse{
+		Piped: piped,
+	}, nil
+}
+
+func (a *API) ListEnvironments(ctx context.Context, req *apiservice.ListEnvironmentsRequest) (*apiservice.ListEnvironmentsResponse, error) {
+	key, err := requireAPIKey(ctx, model.APIKey_READ_ONLY, a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	envs, err := listEnvironments(ctx, a.environmentStore, key.ProjectId, a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	// Leaving out the environments the key is not allowed to access.
+	if len(key.EnvIds) > 0 {
+		allowed := make(map[string]struct{}, len(key.EnvIds))
+		for _, id := range key.EnvIds {
+			allowed[id] = struct{}{}
+		}
+		filtered := envs[:0]
+		for _, env := range envs {
+			if _, ok := allowed[env.Id]; ok {
+				filtered = append(filtered, env)
+			}
+		}
+		envs = filtered
+	}
+
+	return &apiservice.ListEnvironmentsResponse{
+		Environments: envs,
 	}, nil
 }
 
 func (a *API) GetCommand(ctx context.Context, req *apiservice.GetCommandRequest) (*apiservice.GetCommandResponse, error) {
-	_, err := requireAPIKey(ctx, model.APIKey_READ_ONLY, a.logger)
+	key, err := requireAPIKey(ctx, model.APIKey_READ_ONLY, a.logger)
 	if err != nil {
 		return nil, err
 	}
@@ -198,6 +579,20 @@ func (a *API) GetCommand(ctx context.Context, req *apiservice.GetCommandRequest)
 		return nil, err
 	}
 
+	// The environment of the command is known only through its application.
+	if key.IsScoped() {
+		app, err := getApplication(ctx, a.applicationStore, cmd.ApplicationId, a.logger)
+		if err != nil {
+			return nil, err
+		}
+		if app.ProjectId != key.ProjectId {
+			return nil, status.Error(codes.InvalidArgument, "Requested command does not belong to your project")
+		}
+		if err := checkAPIKeyScope(key, app.EnvId, app.Id, a.logger); err != nil {
+			return nil, err
+		}
+	}
+
 	return &apiservice.GetCommandResponse{
 		Command: cmd,
 	}, nil
@@ -227,3 +622,17 @@ func requireAPIKey(ctx context.Context, role model.APIKey_Role, logger *zap.Logg
 		return nil, status.Error(codes.PermissionDenied, "Invalid role")
 	}
 }
+
+// checkAPIKeyScope ensures that the given API key can be used for the application
+// of the given ID in the given environment.
+func checkAPIKeyScope(key *model.APIKey, envID, appID string, logger *zap.Logger) error {
+	if key.AllowsApplication(envID, appID) {
+		return nil
+	}
+	logger.Warn("detected an API key used out of its scope",
+		zap.String("key", key.Id),
+		zap.String("env-id", envID),
+		zap.String("application-id", appID),
+	)
+	return status.Error(codes.PermissionDenied, "The API key is not allowed to access this application")
+}
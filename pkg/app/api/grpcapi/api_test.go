@@ -19,8 +19,10 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 
+	"github.com/pipe-cd/pipe/pkg/datastore"
 	"github.com/pipe-cd/pipe/pkg/model"
 	"github.com/pipe-cd/pipe/pkg/rpc/rpcauth"
 )
@@ -102,3 +104,77 @@ func TestRequireAPIKey(t *testing.T) {
 		})
 	}
 }
+
+type fakeDeploymentLister struct {
+	datastore.DeploymentStore
+	// Sorted by UpdatedAt in descending order.
+	deployments []*model.Deployment
+	calls       int
+}
+
+func (l *fakeDeploymentLister) ListDeployments(_ context.Context, opts datastore.ListOptions) ([]*model.Deployment, error) {
+	l.calls++
+	var list []*model.Deployment
+	for _, d := range l.deployments {
+		matched := true
+		for _, f := range opts.Filters {
+			if f.Field == "UpdatedAt" && d.UpdatedAt > f.Value.(int64) {
+				matched = false
+			}
+		}
+		if !matched {
+			continue
+		}
+		list = append(list, d)
+		if opts.PageSize > 0 && len(list) == opts.PageSize {
+			break
+		}
+	}
+	return list, nil
+}
+
+func TestListFilteredDeployments(t *testing.T) {
+	lister := &fakeDeploymentLister{
+		deployments: []*model.Deployment{
+			{Id: "d-1", EnvId: "env-1", UpdatedAt: 9},
+			{Id: "d-2", EnvId: "env-2", UpdatedAt: 8},
+			{Id: "d-3", EnvId: "env-2", UpdatedAt: 7},
+			{Id: "d-4", EnvId: "env-2", UpdatedAt: 7},
+			{Id: "d-5", EnvId: "env-1", UpdatedAt: 7},
+			{Id: "d-6", EnvId: "env-2", UpdatedAt: 5},
+			{Id: "d-7", EnvId: "env-1", UpdatedAt: 4},
+			{Id: "d-8", EnvId: "env-1", UpdatedAt: 3},
+		},
+	}
+	inEnv1 := func(d *model.Deployment) bool {
+		return d.EnvId == "env-1"
+	}
+	ids := func(ds []*model.Deployment) []string {
+		out := make([]string, 0, len(ds))
+		for _, d := range ds {
+			out = append(out, d.Id)
+		}
+		return out
+	}
+
+	got, err := listFilteredDeployments(context.Background(), lister, nil, 3, inEnv1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"d-1", "d-5", "d-7"}, ids(got))
+
+	got, err = listFilteredDeployments(context.Background(), lister, nil, 10, inEnv1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"d-1", "d-5", "d-7", "d-8"}, ids(got))
+
+	got, err = listFilteredDeployments(context.Background(), lister, nil, 0, inEnv1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"d-1", "d-5", "d-7", "d-8"}, ids(got))
+
+	// A whole batch updated at the same time does not stop the listing.
+	lister.calls = 0
+	got, err = listFilteredDeployments(context.Background(), lister, nil, 1, func(d *model.Deployment) bool {
+		return d.Id == "d-6"
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"d-6"}, ids(got))
+	assert.LessOrEqual(t, lister.calls, maxDeploymentListBatches)
+}
@@ -17,15 +17,20 @@ package grpcapi
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
 	"time"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	"github.com/pipe-cd/pipe/pkg/app/api/applicationlivestatestore"
+	"github.com/pipe-cd/pipe/pkg/app/api/commandoutputstore"
 	"github.com/pipe-cd/pipe/pkg/app/api/commandstore"
+	"github.com/pipe-cd/pipe/pkg/app/api/deploymentartifactstore"
 	"github.com/pipe-cd/pipe/pkg/app/api/service/pipedservice"
 	"github.com/pipe-cd/pipe/pkg/app/api/stagelogstore"
 	"github.com/pipe-cd/pipe/pkg/cache"
@@ -39,13 +44,17 @@ import (
 type PipedAPI struct {
 	applicationStore          datastore.ApplicationStore
 	deploymentStore           datastore.DeploymentStore
+	deploymentChainStore      datastore.DeploymentChainStore
 	environmentStore          datastore.EnvironmentStore
 	pipedStatsStore           datastore.PipedStatsStore
 	pipedStore                datastore.PipedStore
+	pipedConfigRevisionStore  datastore.PipedConfigRevisionStore
 	projectStore              datastore.ProjectStore
 	stageLogStore             stagelogstore.Store
 	applicationLiveStateStore applicationlivestatestore.Store
 	commandStore              commandstore.Store
+	deploymentArtifactStore   deploymentartifactstore.Store
+	commandOutputStore        commandoutputstore.Store
 
 	appPipedCache        cache.Cache
 	deploymentPipedCache cache.Cache
@@ -55,17 +64,21 @@ type PipedAPI struct {
 }
 
 // NewPipedAPI creates a new PipedAPI instance.
-func NewPipedAPI(ctx context.Context, ds datastore.DataStore, sls stagelogstore.Store, alss applicationlivestatestore.Store, cs commandstore.Store, logger *zap.Logger) *PipedAPI {
+func NewPipedAPI(ctx context.Context, ds datastore.DataStore, sls stagelogstore.Store, alss applicationlivestatestore.Store, cs commandstore.Store, das deploymentartifactstore.Store, cos commandoutputstore.Store, logger *zap.Logger) *PipedAPI {
 	a := &PipedAPI{
 		applicationStore:          datastore.NewApplicationStore(ds),
 		deploymentStore:           datastore.NewDeploymentStore(ds),
+		deploymentChainStore:      datastore.NewDeploymentChainStore(ds),
 		environmentStore:          datastore.NewEnvironmentStore(ds),
 		pipedStatsStore:           datastore.NewPipedStatsStore(ds),
 		pipedStore:                datastore.NewPipedStore(ds),
+		pipedConfigRevisionStore:  datastore.NewPipedConfigRevisionStore(ds),
 		projectStore:              datastore.NewProjectStore(ds),
 		stageLogStore:             sls,
 		applicationLiveStateStore: alss,
 		commandStore:              cs,
+		deploymentArtifactStore:   das,
+		commandOutputStore:        cos,
 		appPipedCache:             memorycache.NewTTLCache(ctx, 24*time.Hour, 3*time.Hour),
 		deploymentPipedCache:      memorycache.NewTTLCache(ctx, 24*time.Hour, 3*time.Hour),
 		envProjectCache:           memorycache.NewTTLCache(ctx, 24*time.Hour, 3*time.Hour),
@@ -97,7 +110,7 @@ func (a *PipedAPI) ReportPipedMeta(ctx context.Context, req *pipedservice.Report
 	now := time.Now().Unix()
 	connStatus := model.Piped_ONLINE
 
-	if err = a.pipedStore.UpdatePiped(ctx, pipedID, datastore.PipedMetadataUpdater(req.CloudProviders, req.Repositories, connStatus, req.SealedSecretEncryption, req.Version, now)); err != nil {
+	if err = a.pipedStore.UpdatePiped(ctx, pipedID, datastore.PipedMetadataUpdater(req.CloudProviders, req.Repositories, connStatus, req.SealedSecretEncryption, req.Version, req.ConfigVersion, now)); err != nil {
 		switch err {
 		case datastore.ErrNotFound:
 			return nil, status.Error(codes.InvalidArgument, "piped is not found")
@@ -114,6 +127,54 @@ func (a *PipedAPI) ReportPipedMeta(ctx context.Context, req *pipedservice.Report
 	return &pipedservice.ReportPipedMetaResponse{}, nil
 }
 
+// GetDesiredPipedConfig returns the centrally managed configuration
+// the requested piped should run with.
+// NotFound is returned when the piped is using its local configuration file.
+func (a *PipedAPI) GetDesiredPipedConfig(ctx context.Context, req *pipedservice.GetDesiredPipedConfigRequest) (*pipedservice.GetDesiredPipedConfigResponse, error) {
+	_, pipedID, _, err := rpcauth.ExtractPipedToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	piped, err := getPiped(ctx, a.pipedStore, pipedID, a.logger)
+	if err != nil {
+		return nil, err
+	}
+	if piped.DesiredConfigVersion == 0 {
+		return nil, status.Error(codes.NotFound, "piped is using its local configuration file")
+	}
+
+	revision, err := getPipedConfigRevision(ctx, a.pipedConfigRevisionStore, pipedID, piped.DesiredConfigVersion, a.logger)
+	if err != nil {
+		return nil, err
+	}
+	return &pipedservice.GetDesiredPipedConfigResponse{
+		Revision: revision,
+	}, nil
+}
+
+// GetDriftDetectionConfig returns the default drift detection settings
+// of the project the requested piped belongs to.
+func (a *PipedAPI) GetDriftDetectionConfig(ctx context.Context, req *pipedservice.GetDriftDetectionConfigRequest) (*pipedservice.GetDriftDetectionConfigResponse, error) {
+	projectID, _, _, err := rpcauth.ExtractPipedToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := a.projectStore.GetProject(ctx, projectID)
+	if errors.Is(err, datastore.ErrNotFound) {
+		// The projects specified in the control-plane configuration are not stored.
+		return &pipedservice.GetDriftDetectionConfigResponse{}, nil
+	}
+	if err != nil {
+		a.logger.Error("failed to get project", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to get project")
+	}
+	return &pipedservice.GetDriftDetectionConfigResponse{
+		DriftDetection: project.DriftDetection,
+	}, nil
+}
+
 // GetEnvironment finds and returns the environment for the specified ID.
 func (a *PipedAPI) GetEnvironment(ctx context.Context, req *pipedservice.GetEnvironmentRequest) (*pipedservice.GetEnvironmentResponse, error) {
 	projectID, _, _, err := rpcauth.ExtractPipedToken(ctx)
@@ -204,6 +265,81 @@ func (a *PipedAPI) ReportApplicationSyncState(ctx context.Context, req *pipedser
 	return &pipedservice.ReportApplicationSyncStateResponse{}, nil
 }
 
+// ReportApplicationLabels is used to update the labels of an application
+// with the ones specified in its deployment configuration.
+func (a *PipedAPI) ReportApplicationLabels(ctx context.Context, req *pipedservice.ReportApplicationLabelsRequest) (*pipedservice.ReportApplicationLabelsResponse, error) {
+	projectID, pipedID, _, err := rpcauth.ExtractPipedToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.validateAppBelongsToPiped(ctx, req.ApplicationId, pipedID); err != nil {
+		return nil, err
+	}
+	if err := model.ValidateLabels(req.Labels); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// The labels scoping the RBAC role bindings can be changed only by the users
+	// allowed to edit the application, not by anyone able to push to its repository.
+	project, err := a.projectStore.GetProject(ctx, projectID)
+	if err != nil && !errors.Is(err, datastore.ErrNotFound) {
+		a.logger.Error("failed to get project", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to get project")
+	}
+	boundKeys := project.GetRbac().BoundLabelKeys()
+
+	var (
+		labels      map[string]string
+		ignoredKeys []string
+	)
+	err = a.applicationStore.UpdateApplication(ctx, req.ApplicationId, func(app *model.Application) error {
+		labels, ignoredKeys = mergeReportedLabels(app.Labels, req.Labels, boundKeys)
+		app.Labels = labels
+		return nil
+	})
+	if err != nil {
+		switch err {
+		case datastore.ErrNotFound:
+			return nil, status.Error(codes.InvalidArgument, "application is not found")
+		default:
+			a.logger.Error("failed to update application labels",
+				zap.String("application-id", req.ApplicationId),
+				zap.Error(err),
+			)
+			return nil, status.Error(codes.Internal, "failed to update the application labels")
+		}
+	}
+	return &pipedservice.ReportApplicationLabelsResponse{
+		Labels:      labels,
+		IgnoredKeys: ignoredKeys,
+	}, nil
+}
+
+// mergeReportedLabels returns the labels replacing the current ones with the reported ones
+// while keeping the current values of the given bound keys.
+// The bound keys whose reported values were not applied are also returned.
+func mergeReportedLabels(current, reported map[string]string, boundKeys map[string]struct{}) (map[string]string, []string) {
+	labels := make(map[string]string, len(reported))
+	for k, v := range reported {
+		if _, ok := boundKeys[k]; !ok {
+			labels[k] = v
+		}
+	}
+
+	var ignored []string
+	for k := range boundKeys {
+		cv, cok := current[k]
+		if cok {
+			labels[k] = cv
+		}
+		if rv, rok := reported[k]; rok != cok || rv != cv {
+			ignored = append(ignored, k)
+		}
+	}
+	sort.Strings(ignored)
+	return labels, ignored
+}
+
 // ReportApplicationMostRecentDeployment is used to update the basic information about
 // the most recent deployment of a specific application.
 func (a *PipedAPI) ReportApplicationMostRecentDeployment(ctx context.Context, req *pipedservice.ReportApplicationMostRecentDeploymentRequest) (*pipedservice.ReportApplicationMostRecentDeploymentResponse, error) {
@@ -321,6 +457,24 @@ func (a *PipedAPI) CreateDeployment(ctx context.Context, req *pipedservice.Creat
 		a.logger.Error("failed to create deployment", zap.Error(err))
 		return nil, status.Error(codes.Internal, "failed to create deployment")
 	}
+
+	if req.Deployment.DeploymentChainId != "" {
+		chain, _, err := a.updateDeploymentChainNode(ctx, req.Deployment)
+		if err != nil {
+			a.logger.Error("failed to update deployment chain",
+				zap.String("deployment-chain-id", req.Deployment.DeploymentChainId),
+				zap.String("deployment-id", req.Deployment.Id),
+				zap.Error(err),
+			)
+		}
+		// The chain was cancelled while its sync command was waiting to be handled
+		// so this deployment should be cancelled as well.
+		if chain != nil && (chain.CancelledBy != "" || model.IsCompletedChain(chain.Status)) {
+			if err := a.cancelChainDeployment(ctx, req.Deployment, chain.CancelledBy); err != nil {
+				return nil, err
+			}
+		}
+	}
 	return &pipedservice.CreateDeploymentResponse{}, nil
 }
 
@@ -355,7 +509,7 @@ func (a *PipedAPI) ReportDeploymentPlanned(ctx context.Context, req *pipedservic
 }
 
 // ReportDeploymentStatusChanged is used to update the status
-// of a specific deployment to RUNNING or ROLLING_BACK.
+// of a specific deployment to PLANNED, RUNNING or ROLLING_BACK.
 func (a *PipedAPI) ReportDeploymentStatusChanged(ctx context.Context, req *pipedservice.ReportDeploymentStatusChangedRequest) (*pipedservice.ReportDeploymentStatusChangedResponse, error) {
 	_, pipedID, _, err := rpcauth.ExtractPipedToken(ctx)
 	if err != nil {
@@ -395,8 +549,15 @@ func (a *PipedAPI) ReportDeploymentCompleted(ctx context.Context, req *pipedserv
 		return nil, err
 	}
 
+	var completed *model.Deployment
 	updater := datastore.DeploymentToCompletedUpdater(req.Status, req.StageStatuses, req.StatusReason, req.CompletedAt)
-	err = a.deploymentStore.UpdateDeployment(ctx, req.DeploymentId, updater)
+	err = a.deploymentStore.UpdateDeployment(ctx, req.DeploymentId, func(d *model.Deployment) error {
+		if err := updater(d); err != nil {
+			return err
+		}
+		completed = d
+		return nil
+	})
 	if err != nil {
 		switch err {
 		case datastore.ErrNotFound:
@@ -411,7 +572,297 @@ func (a *PipedAPI) ReportDeploymentCompleted(ctx context.Context, req *pipedserv
 			return nil, status.Error(codes.Internal, "failed to update deployment to be completed")
 		}
 	}
-	return &pipedservice.ReportDeploymentCompletedResponse{}, nil
+
+	resp := &pipedservice.ReportDeploymentCompletedResponse{}
+	if completed.DeploymentChainId == "" {
+		return resp, nil
+	}
+	chain, chainCompleted, err := a.updateDeploymentChainNode(ctx, completed)
+	if err != nil {
+		a.logger.Error("failed to update deployment chain",
+			zap.String("deployment-chain-id", completed.DeploymentChainId),
+			zap.String("deployment-id", req.DeploymentId),
+			zap.Error(err),
+		)
+		return nil, status.Error(codes.Internal, "failed to update deployment chain")
+	}
+	if chainCompleted {
+		resp.CompletedDeploymentChain = chain
+	}
+	return resp, nil
+}
+
+// TriggerDeploymentChain is used to register the downstream applications
+// of a successfully completed deployment.
+// A new chain is created when the deployment does not belong to any chain yet,
+// otherwise the applications are added as the next block of its chain.
+// The applications are actually triggered by updateDeploymentChainNode
+// once all deployments of the current block have been completed successfully.
+func (a *PipedAPI) TriggerDeploymentChain(ctx context.Context, req *pipedservice.TriggerDeploymentChainRequest) (*pipedservice.TriggerDeploymentChainResponse, error) {
+	_, pipedID, _, err := rpcauth.ExtractPipedToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.validateDeploymentBelongsToPiped(ctx, req.DeploymentId, pipedID); err != nil {
+		return nil, err
+	}
+
+	deployment, err := getDeployment(ctx, a.deploymentStore, req.DeploymentId, a.logger)
+	if err != nil {
+		return nil, err
+	}
+	if model.IsCompletedDeployment(deployment.Status) {
+		return nil, status.Error(codes.FailedPrecondition, "the downstream applications must be triggered before completing the deployment")
+	}
+
+	nodes := make([]*model.ChainNode, 0, len(req.Applications))
+	for _, ca := range req.Applications {
+		node, err := a.findChainNode(ctx, deployment, ca)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+
+	// This deployment is the head of a new chain.
+	// The chain ID is saved into the deployment first
+	// so that the same chain will be used when this request is retried.
+	if deployment.DeploymentChainId == "" {
+		chainID := uuid.New().String()
+		err := a.deploymentStore.UpdateDeployment(ctx, deployment.Id, func(d *model.Deployment) error {
+			d.DeploymentChainId = chainID
+			d.DeploymentChainBlockIndex = 0
+			return nil
+		})
+		if err != nil {
+			a.logger.Error("failed to update deployment chain of deployment",
+				zap.String("deployment-id", deployment.Id),
+				zap.Error(err),
+			)
+			return nil, status.Error(codes.Internal, "failed to update deployment")
+		}
+		deployment.DeploymentChainId = chainID
+		deployment.DeploymentChainBlockIndex = 0
+	}
+
+	var (
+		now       = time.Now().Unix()
+		created   bool
+		chain     *model.DeploymentChain
+		nextBlock = deployment.DeploymentChainBlockIndex + 1
+	)
+	if deployment.DeploymentChainBlockIndex == 0 {
+		chain = &model.DeploymentChain{
+			Id:                  deployment.DeploymentChainId,
+			ProjectId:           deployment.ProjectId,
+			TriggerDeploymentId: deployment.Id,
+			Blocks: []*model.ChainBlock{
+				{
+					Nodes: []*model.ChainNode{
+						{
+							ApplicationId:    deployment.ApplicationId,
+							ApplicationName:  deployment.ApplicationName,
+							EnvId:            deployment.EnvId,
+							PipedId:          deployment.PipedId,
+							DeploymentId:     deployment.Id,
+							DeploymentStatus: deployment.Status,
+						},
+					},
+					Status:    model.ChainStatus_DEPLOYMENT_CHAIN_RUNNING,
+					StartedAt: deployment.CreatedAt,
+				},
+			},
+		}
+		chain.AddNodes(nextBlock, nodes, now)
+		err = a.deploymentChainStore.AddDeploymentChain(ctx, chain)
+		switch {
+		case err == nil:
+			created = true
+		case errors.Is(err, datastore.ErrAlreadyExists):
+			// The chain was already created by a previous request.
+			chain = nil
+		default:
+			a.logger.Error("failed to create deployment chain",
+				zap.String("deployment-chain-id", deployment.DeploymentChainId),
+				zap.Error(err),
+			)
+			return nil, status.Error(codes.Internal, "failed to create deployment chain")
+		}
+	}
+
+	if !created {
+		err = a.deploymentChainStore.UpdateDeploymentChain(ctx, deployment.DeploymentChainId, func(c *model.DeploymentChain) error {
+			if c.CancelledBy != "" || model.IsCompletedChain(c.Status) {
+				return fmt.Errorf("deployment chain %s was already completed: %w", c.Id, datastore.ErrInvalidArgument)
+			}
+			c.AddNodes(nextBlock, nodes, now)
+			chain = c
+			return nil
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, datastore.ErrNotFound):
+				return nil, status.Error(codes.NotFound, "deployment chain is not found")
+			case errors.Is(err, datastore.ErrInvalidArgument):
+				return nil, status.Error(codes.FailedPrecondition, "deployment chain was already completed or cancelled")
+			default:
+				a.logger.Error("failed to update deployment chain",
+					zap.String("deployment-chain-id", deployment.DeploymentChainId),
+					zap.Error(err),
+				)
+				return nil, status.Error(codes.Internal, "failed to update deployment chain")
+			}
+		}
+	}
+
+	return &pipedservice.TriggerDeploymentChainResponse{
+		DeploymentChain: chain,
+		Created:         created,
+	}, nil
+}
+
+// findChainNode finds the application specified by the given chain application
+// and builds a chain node for it.
+func (a *PipedAPI) findChainNode(ctx context.Context, upstream *model.Deployment, ca *pipedservice.TriggerDeploymentChainRequest_Application) (*model.ChainNode, error) {
+	envID := upstream.EnvId
+	if ca.EnvName != "" {
+		envs, err := a.environmentStore.ListEnvironments(ctx, datastore.ListOptions{
+			Filters: []datastore.ListFilter{
+				{
+					Field:    "ProjectId",
+					Operator: "==",
+					Value:    upstream.ProjectId,
+				},
+				{
+					Field:    "Name",
+					Operator: "==",
+					Value:    ca.EnvName,
+				},
+			},
+		})
+		if err != nil {
+			a.logger.Error("failed to list environments", zap.Error(err))
+			return nil, status.Error(codes.Internal, "failed to list environments")
+		}
+		if len(envs) == 0 {
+			return nil, status.Errorf(codes.NotFound, "environment %s is not found", ca.EnvName)
+		}
+		envID = envs[0].Id
+	}
+
+	apps, err := a.applicationStore.ListApplications(ctx, datastore.ListOptions{
+		Filters: []datastore.ListFilter{
+			{
+				Field:    "ProjectId",
+				Operator: "==",
+				Value:    upstream.ProjectId,
+			},
+			{
+				Field:    "EnvId",
+				Operator: "==",
+				Value:    envID,
+			},
+			{
+				Field:    "Name",
+				Operator: "==",
+				Value:    ca.Name,
+			},
+			{
+				Field:    "Disabled",
+				Operator: "==",
+				Value:    false,
+			},
+		},
+	})
+	if err != nil {
+		a.logger.Error("failed to list applications", zap.Error(err))
+		return nil, status.Error(codes.Internal, "failed to list applications")
+	}
+	if len(apps) == 0 {
+		return nil, status.Errorf(codes.NotFound, "application %s is not found in the environment", ca.Name)
+	}
+
+	app := apps[0]
+	return &model.ChainNode{
+		ApplicationId:    app.Id,
+		ApplicationName:  app.Name,
+		EnvId:            app.EnvId,
+		PipedId:          app.PipedId,
+		DeploymentStatus: model.DeploymentStatus_DEPLOYMENT_PENDING,
+	}, nil
+}
+
+// updateDeploymentChainNode updates the node of the given deployment in its chain.
+// The returned boolean reports whether the chain was completed by this update.
+func (a *PipedAPI) updateDeploymentChainNode(ctx context.Context, d *model.Deployment) (*model.DeploymentChain, bool, error) {
+	var (
+		chain     *model.DeploymentChain
+		completed bool
+		now       = time.Now().Unix()
+		updater   = datastore.DeploymentChainNodeUpdater(d.DeploymentChainBlockIndex, d.ApplicationId, d.Id, d.Status, now)
+	)
+	var (
+		triggerBlock uint32
+		triggerNodes []*model.ChainNode
+	)
+	err := a.deploymentChainStore.UpdateDeploymentChain(ctx, d.DeploymentChainId, func(c *model.DeploymentChain) error {
+		wasCompleted := model.IsCompletedChain(c.Status)
+		if err := updater(c); err != nil {
+			return err
+		}
+		triggerBlock, triggerNodes = c.NodesToTrigger(now)
+		chain = c
+		completed = !wasCompleted && model.IsCompletedChain(c.Status)
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if err := a.triggerChainNodes(ctx, chain.Id, triggerBlock, triggerNodes); err != nil {
+		return nil, false, err
+	}
+	return chain, completed, nil
+}
+
+// triggerChainNodes sends a command to the piped of each given node to sync its application.
+// The command ID is derived from the node so that the same node is never triggered twice
+// even when this is called again for a retried request.
+func (a *PipedAPI) triggerChainNodes(ctx context.Context, chainID string, blockIndex uint32, nodes []*model.ChainNode) error {
+	for _, n := range nodes {
+		name := fmt.Sprintf("%s/%d/%s", chainID, blockIndex, n.ApplicationId)
+		cmd := model.Command{
+			Id:            uuid.NewSHA1(uuid.NameSpaceOID, []byte(name)).String(),
+			PipedId:       n.PipedId,
+			ApplicationId: n.ApplicationId,
+			Type:          model.Command_SYNC_APPLICATION,
+			SyncApplication: &model.Command_SyncApplication{
+				ApplicationId:             n.ApplicationId,
+				DeploymentChainId:         chainID,
+				DeploymentChainBlockIndex: blockIndex,
+			},
+		}
+		err := a.commandStore.AddCommand(ctx, &cmd)
+		if err != nil && !errors.Is(err, datastore.ErrAlreadyExists) {
+			return fmt.Errorf("failed to trigger application %s: %w", n.ApplicationId, err)
+		}
+	}
+	return nil
+}
+
+// cancelChainDeployment creates a command to cancel the given deployment of a cancelled chain.
+func (a *PipedAPI) cancelChainDeployment(ctx context.Context, d *model.Deployment, commander string) error {
+	cmd := model.Command{
+		Id:            uuid.New().String(),
+		PipedId:       d.PipedId,
+		ApplicationId: d.ApplicationId,
+		DeploymentId:  d.Id,
+		Type:          model.Command_CANCEL_DEPLOYMENT,
+		Commander:     commander,
+		CancelDeployment: &model.Command_CancelDeployment{
+			DeploymentId: d.Id,
+		},
+	}
+	return addCommand(ctx, a.commandStore, &cmd, a.logger)
 }
 
 // SaveDeploymentMetadata used by piped to persist the metadata of a specific deployment.
@@ -438,6 +889,45 @@ func (a *PipedAPI) SaveDeploymentMetadata(ctx context.Context, req *pipedservice
 	return &pipedservice.SaveDeploymentMetadataResponse{}, nil
 }
 
+// PutDeploymentArtifact is used to save a file generated while handling a deployment
+// into the filestore. The existing artifact with the same name is overwritten.
+func (a *PipedAPI) PutDeploymentArtifact(ctx context.Context, req *pipedservice.PutDeploymentArtifactRequest) (*pipedservice.PutDeploymentArtifactResponse, error) {
+	_, pipedID, _, err := rpcauth.ExtractPipedToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.validateDeploymentBelongsToPiped(ctx, req.DeploymentId, pipedID); err != nil {
+		return nil, err
+	}
+
+	if err := a.deploymentArtifactStore.PutArtifact(ctx, req.DeploymentId, req.Name, req.Content); err != nil {
+		return nil, status.Error(codes.Internal, "failed to save deployment artifact")
+	}
+	return &pipedservice.PutDeploymentArtifactResponse{}, nil
+}
+
+// GetDeploymentArtifact is used to load a file saved by PutDeploymentArtifact.
+func (a *PipedAPI) GetDeploymentArtifact(ctx context.Context, req *pipedservice.GetDeploymentArtifactRequest) (*pipedservice.GetDeploymentArtifactResponse, error) {
+	_, pipedID, _, err := rpcauth.ExtractPipedToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.validateDeploymentBelongsToPiped(ctx, req.DeploymentId, pipedID); err != nil {
+		return nil, err
+	}
+
+	content, err := a.deploymentArtifactStore.GetArtifact(ctx, req.DeploymentId, req.Name)
+	if errors.Is(err, deploymentartifactstore.ErrNotFound) {
+		return nil, status.Error(codes.NotFound, "deployment artifact is not found")
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to get deployment artifact")
+	}
+	return &pipedservice.GetDeploymentArtifactResponse{
+		Content: content,
+	}, nil
+}
+
 // SaveStageMetadata used by piped to persist the metadata
 // of a specific stage of a deployment.
 func (a *PipedAPI) SaveStageMetadata(ctx context.Context, req *pipedservice.SaveStageMetadataRequest) (*pipedservice.SaveStageMetadataResponse, error) {
@@ -582,6 +1072,14 @@ func (a *PipedAPI) ReportCommandHandled(ctx context.Context, req *pipedservice.R
 		return nil, status.Error(codes.PermissionDenied, "The current piped does not have requested command")
 	}
 
+	// The output must be saved before marking the command as handled
+	// because the web reads it right after seeing the command was handled.
+	if len(req.Output) > 0 {
+		if err := a.commandOutputStore.PutOutput(ctx, req.CommandId, req.Output); err != nil {
+			return nil, status.Error(codes.Internal, "failed to save command output")
+		}
+	}
+
 	err = a.commandStore.UpdateCommandHandled(ctx, req.CommandId, req.Status, req.Metadata, req.HandledAt)
 	if err != nil {
 		switch err {
@@ -304,3 +304,47 @@ func TestValidateEnvBelongsToProject(t *testing.T) {
 		})
 	}
 }
+
+func TestMergeReportedLabels(t *testing.T) {
+	boundKeys := map[string]struct{}{"team": {}, "tier": {}}
+	tests := []struct {
+		name            string
+		current         map[string]string
+		reported        map[string]string
+		expected        map[string]string
+		expectedIgnored []string
+	}{
+		{
+			name:     "bound key is removed",
+			current:  map[string]string{"team": "a", "app": "old"},
+			reported: map[string]string{"app": "new"},
+			expected: map[string]string{"team": "a", "app": "new"},
+			expectedIgnored: []string{
+				"team",
+			},
+		},
+		{
+			name:     "same values for the bound keys",
+			current:  map[string]string{"team": "a", "app": "old"},
+			reported: map[string]string{"team": "a", "app": "new"},
+			expected: map[string]string{"team": "a", "app": "new"},
+		},
+		{
+			name:     "bound keys are changed or added",
+			current:  map[string]string{"team": "a"},
+			reported: map[string]string{"team": "b", "tier": "backend", "app": "new"},
+			expected: map[string]string{"team": "a", "app": "new"},
+			expectedIgnored: []string{
+				"team",
+				"tier",
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			labels, ignored := mergeReportedLabels(tc.current, tc.reported, boundKeys)
+			assert.Equal(t, tc.expected, labels)
+			assert.Equal(t, tc.expectedIgnored, ignored)
+		})
+	}
+}
@@ -17,12 +17,16 @@ package grpcapi
 import (
 	"context"
 	"errors"
+	"sort"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 
 	"github.com/pipe-cd/pipe/pkg/app/api/commandstore"
+	"github.com/pipe-cd/pipe/pkg/app/api/stagelogstore"
 	"github.com/pipe-cd/pipe/pkg/datastore"
 	"github.com/pipe-cd/pipe/pkg/git"
 	"github.com/pipe-cd/pipe/pkg/model"
@@ -41,6 +45,19 @@ func getPiped(ctx context.Context, store datastore.PipedStore, id string, logger
 	return piped, nil
 }
 
+func getPipedConfigRevision(ctx context.Context, store datastore.PipedConfigRevisionStore, pipedID string, version int64, logger *zap.Logger) (*model.PipedConfigRevision, error) {
+	revision, err := store.GetPipedConfigRevision(ctx, model.MakePipedConfigRevisionID(pipedID, version))
+	if errors.Is(err, datastore.ErrNotFound) {
+		return nil, status.Error(codes.NotFound, "Piped config revision is not found")
+	}
+	if err != nil {
+		logger.Error("failed to get piped config revision", zap.Error(err))
+		return nil, status.Error(codes.Internal, "Failed to get piped config revision")
+	}
+
+	return revision, nil
+}
+
 func getApplication(ctx context.Context, store datastore.ApplicationStore, id string, logger *zap.Logger) (*model.Application, error) {
 	app, err := store.GetApplication(ctx, id)
 	if errors.Is(err, datastore.ErrNotFound) {
@@ -67,6 +84,19 @@ func getDeployment(ctx context.Context, store datastore.DeploymentStore, id stri
 	return deployment, nil
 }
 
+func getDeploymentChain(ctx context.Context, store datastore.DeploymentChainStore, id string, logger *zap.Logger) (*model.DeploymentChain, error) {
+	chain, err := store.GetDeploymentChain(ctx, id)
+	if errors.Is(err, datastore.ErrNotFound) {
+		return nil, status.Error(codes.NotFound, "Deployment chain is not found")
+	}
+	if err != nil {
+		logger.Error("failed to get deployment chain", zap.Error(err))
+		return nil, status.Error(codes.Internal, "Failed to get deployment chain")
+	}
+
+	return chain, nil
+}
+
 func getCommand(ctx context.Context, store commandstore.Store, id string, logger *zap.Logger) (*model.Command, error) {
 	cmd, err := store.GetCommand(ctx, id)
 	if errors.Is(err, datastore.ErrNotFound) {
@@ -114,3 +144,449 @@ func makeGitPath(repoID, path, cfgFilename string, piped *model.Piped, logger *z
 		Url:            u,
 	}, nil
 }
+
+// listEnvironments returns all environments of the given project.
+func listEnvironments(ctx context.Context, store datastore.EnvironmentStore, projectID string, logger *zap.Logger) ([]*model.Environment, error) {
+	opts := datastore.ListOptions{
+		Filters: []datastore.ListFilter{
+			{
+				Field:    "ProjectId",
+				Operator: "==",
+				Value:    projectID,
+			},
+		},
+	}
+	envs, err := store.ListEnvironments(ctx, opts)
+	if err != nil {
+		logger.Error("failed to get environments", zap.Error(err))
+		return nil, status.Error(codes.Internal, "Failed to get environments")
+	}
+
+	// Deleted environments are kept in the datastore but hidden from the users.
+	filtered := envs[:0]
+	for _, env := range envs {
+		if !env.Deleted {
+			filtered = append(filtered, env)
+		}
+	}
+	return filtered, nil
+}
+
+// listPipeds returns the pipeds of the given project after redacting their sensitive data.
+func listPipeds(ctx context.Context, store datastore.PipedStore, projectID string, enabled *wrapperspb.BoolValue, logger *zap.Logger) ([]*model.Piped, error) {
+	opts := datastore.ListOptions{
+		Filters: []datastore.ListFilter{
+			{
+				Field:    "ProjectId",
+				Operator: "==",
+				Value:    projectID,
+			},
+		},
+	}
+	if enabled != nil {
+		opts.Filters = append(opts.Filters, datastore.ListFilter{
+			Field:    "Disabled",
+			Operator: "==",
+			Value:    !enabled.GetValue(),
+		})
+	}
+
+	pipeds, err := store.ListPipeds(ctx, opts)
+	if err != nil {
+		logger.Error("failed to get pipeds", zap.Error(err))
+		return nil, status.Error(codes.Internal, "Failed to get pipeds")
+	}
+
+	// Deleted pipeds are kept in the datastore but hidden from the users.
+	filtered := pipeds[:0]
+	for _, p := range pipeds {
+		if p.Deleted {
+			continue
+		}
+		// Redact all sensitive data inside piped message before sending to the client.
+		p.RedactSensitiveData()
+		filtered = append(filtered, p)
+	}
+	return filtered, nil
+}
+
+// filterApplications returns the given applications matching the given selector except the deleted ones.
+func filterApplications(apps []*model.Application, sel model.LabelSelector) []*model.Application {
+	filtered := apps[:0]
+	for _, app := range apps {
+		if !app.Deleted && sel.Matches(app.Labels) {
+			filtered = append(filtered, app)
+		}
+	}
+	return filtered
+}
+
+// parseLabelSelector parses the given label selector and gives back the datastore filters
+// for its equality requirements. Since the other requirements can not be served
+// by all datastores, they must be checked with the returned selector after listing.
+func parseLabelSelector(selector string) (model.LabelSelector, []datastore.ListFilter, error) {
+	sel, err := model.ParseLabelSelector(selector)
+	if err != nil {
+		return model.LabelSelector{}, nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	eqs := sel.EqualityRequirements()
+	keys := make([]string, 0, len(eqs))
+	for k := range eqs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	filters := make([]datastore.ListFilter, 0, len(keys))
+	for _, k := range keys {
+		filters = append(filters, datastore.ListFilter{
+			Field:    "Labels." + k,
+			Operator: "==",
+			Value:    eqs[k],
+		})
+	}
+	return sel, filters, nil
+}
+
+// The maximum number of batches fetched to fill a page of the filtered deployments.
+const maxDeploymentListBatches = 10
+
+// listFilteredDeployments lists the deployments matching the given filters from the latest updated one
+// and returns up to pageSize of them accepted by the given function.
+// Since some conditions cannot be expressed in the query, the deployments are fetched batch by batch
+// by moving the upper bound of UpdatedAt until the page is filled or no deployment remains.
+func listFilteredDeployments(ctx context.Context, store datastore.DeploymentStore, filters []datastore.ListFilter, pageSize int, accept func(*model.Deployment) bool) ([]*model.Deployment, error) {
+	orders := []datastore.Order{
+		{
+			Field:     "UpdatedAt",
+			Direction: datastore.Desc,
+		},
+	}
+	if pageSize <= 0 {
+		deployments, err := store.ListDeployments(ctx, datastore.ListOptions{
+			Filters: filters,
+			Orders:  orders,
+		})
+		if err != nil {
+			return nil, err
+		}
+		filtered := deployments[:0]
+		for _, d := range deployments {
+			if accept(d) {
+				filtered = append(filtered, d)
+			}
+		}
+		return filtered, nil
+	}
+
+	var (
+		filtered     = make([]*model.Deployment, 0, pageSize)
+		seen         = make(map[string]struct{}, pageSize)
+		batchFilters = filters
+	)
+	for i := 0; i < maxDeploymentListBatches; i++ {
+		batch, err := store.ListDeployments(ctx, datastore.ListOptions{
+			Filters:  batchFilters,
+			Orders:   orders,
+			PageSize: pageSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		progressed := false
+		for _, d := range batch {
+			// The deployments updated at the boundary are fetched again by the next batch.
+			if _, ok := seen[d.Id]; ok {
+				continue
+			}
+			seen[d.Id] = struct{}{}
+			progressed = true
+			if !accept(d) {
+				continue
+			}
+			filtered = append(filtered, d)
+			if len(filtered) == pageSize {
+				return filtered, nil
+			}
+		}
+		if len(batch) < pageSize {
+			break
+		}
+		// Step over the boundary when a whole batch was updated at the same time.
+		next := batch[len(batch)-1].UpdatedAt
+		if !progressed {
+			next--
+		}
+		batchFilters = append(filters[:len(filters):len(filters)], datastore.ListFilter{
+			Field:    "UpdatedAt",
+			Operator: "<=",
+			Value:    next,
+		})
+	}
+	return filtered, nil
+}
+
+// matchesDeploymentLabels reports whether the given deployment matches the given selector.
+func matchesDeploymentLabels(d *model.Deployment, sel model.LabelSelector) bool {
+	return sel.Empty() || sel.Matches(d.Labels)
+}
+
+// makeApplicationListFilters returns the filters for listing the applications of the given project.
+func makeApplicationListFilters(projectID string, enabled *wrapperspb.BoolValue, kinds []model.ApplicationKind, syncStatuses []model.ApplicationSyncStatus, envIDs []string) []datastore.ListFilter {
+	filters := []datastore.ListFilter{
+		{
+			Field:    "ProjectId",
+			Operator: "==",
+			Value:    projectID,
+		},
+	}
+	if enabled != nil {
+		filters = append(filters, datastore.ListFilter{
+			Field:    "Disabled",
+			Operator: "==",
+			Value:    !enabled.GetValue(),
+		})
+	}
+	// Allowing multiple so that it can do In Query later.
+	// Currently only the first value is used.
+	if len(kinds) > 0 {
+		filters = append(filters, datastore.ListFilter{
+			Field:    "Kind",
+			Operator: "==",
+			Value:    kinds[0],
+		})
+	}
+	if len(syncStatuses) > 0 {
+		filters = append(filters, datastore.ListFilter{
+			Field:    "SyncState.Status",
+			Operator: "==",
+			Value:    syncStatuses[0],
+		})
+	}
+	if len(envIDs) > 0 {
+		filters = append(filters, datastore.ListFilter{
+			Field:    "EnvId",
+			Operator: "==",
+			Value:    envIDs[0],
+		})
+	}
+	return filters
+}
+
+// makeDeploymentListFilters returns the filters for listing the deployments of the given project.
+func makeDeploymentListFilters(projectID string, statuses []model.DeploymentStatus, kinds []model.ApplicationKind, appIDs, envIDs []string, maxUpdatedAt int64) []datastore.ListFilter {
+	filters := []datastore.ListFilter{
+		{
+			Field:    "ProjectId",
+			Operator: "==",
+			Value:    projectID,
+		},
+	}
+	// Allowing multiple so that it can do In Query later.
+	// Currently only the first value is used.
+	if len(statuses) > 0 {
+		filters = append(filters, datastore.ListFilter{
+			Field:    "Status",
+			Operator: "==",
+			Value:    statuses[0],
+		})
+	}
+	if len(kinds) > 0 {
+		filters = append(filters, datastore.ListFilter{
+			Field:    "Kind",
+			Operator: "==",
+			Value:    kinds[0],
+		})
+	}
+	if len(appIDs) > 0 {
+		filters = append(filters, datastore.ListFilter{
+			Field:    "ApplicationId",
+			Operator: "==",
+			Value:    appIDs[0],
+		})
+	}
+	if len(envIDs) > 0 {
+		filters = append(filters, datastore.ListFilter{
+			Field:    "EnvId",
+			Operator: "==",
+			Value:    envIDs[0],
+		})
+	}
+	if maxUpdatedAt != 0 {
+		filters = append(filters, datastore.ListFilter{
+			Field:    "UpdatedAt",
+			Operator: "<=",
+			Value:    maxUpdatedAt,
+		})
+	}
+	return filters
+}
+
+// setApplicationEnable enables or disables the given application.
+func setApplicationEnable(ctx context.Context, store datastore.ApplicationStore, appID string, enable bool, logger *zap.Logger) error {
+	var updater func(context.Context, string) error
+	if enable {
+		updater = store.EnableApplication
+	} else {
+		updater = store.DisableApplication
+	}
+
+	if err := updater(ctx, appID); err != nil {
+		switch err {
+		case datastore.ErrNotFound:
+			return status.Error(codes.InvalidArgument, "The application is not found")
+		case datastore.ErrInvalidArgument:
+			return status.Error(codes.InvalidArgument, "Invalid value for update")
+		default:
+			logger.Error("failed to update the application",
+				zap.String("application-id", appID),
+				zap.Error(err),
+			)
+			return status.Error(codes.Internal, "Failed to update the application")
+		}
+	}
+	return nil
+}
+
+// ensureNoRunningDeployments gives back a FailedPrecondition error
+// if there is any not completed deployment whose given field has the given value.
+func ensureNoRunningDeployments(ctx context.Context, store datastore.DeploymentStore, projectID, field, value string, logger *zap.Logger) error {
+	opts := datastore.ListOptions{
+		PageSize: 1,
+		Filters: []datastore.ListFilter{
+			{
+				Field:    "ProjectId",
+				Operator: "==",
+				Value:    projectID,
+			},
+			{
+				Field:    field,
+				Operator: "==",
+				Value:    value,
+			},
+			{
+				Field:    "Status",
+				Operator: "in",
+				Value:    model.GetNotCompletedDeploymentStatuses(),
+			},
+		},
+	}
+	deployments, err := store.ListDeployments(ctx, opts)
+	if err != nil {
+		logger.Error("failed to list running deployments", zap.Error(err))
+		return status.Error(codes.Internal, "Failed to list running deployments")
+	}
+	if len(deployments) > 0 {
+		return status.Errorf(codes.FailedPrecondition, "Unable to delete while deployment %s is running", deployments[0].Id)
+	}
+	return nil
+}
+
+// listApplicationsByField returns all applications of the given project whose given field has the given value.
+func listApplicationsByField(ctx context.Context, store datastore.ApplicationStore, projectID, field, value string, logger *zap.Logger) ([]*model.Application, error) {
+	apps, err := store.ListApplications(ctx, datastore.ListOptions{
+		Filters: []datastore.ListFilter{
+			{
+				Field:    "ProjectId",
+				Operator: "==",
+				Value:    projectID,
+			},
+			{
+				Field:    field,
+				Operator: "==",
+				Value:    value,
+			},
+		},
+	})
+	if err != nil {
+		logger.Error("failed to get applications", zap.Error(err))
+		return nil, status.Error(codes.Internal, "Failed to get applications")
+	}
+	return apps, nil
+}
+
+// deleteApplications marks all given applications as deleted.
+func deleteApplications(ctx context.Context, store datastore.ApplicationStore, apps []*model.Application, logger *zap.Logger) error {
+	for _, app := range apps {
+		if app.Deleted {
+			continue
+		}
+		if err := store.DeleteApplication(ctx, app.Id); err != nil {
+			logger.Error("failed to delete application",
+				zap.String("application-id", app.Id),
+				zap.Error(err),
+			)
+			return status.Error(codes.Internal, "Failed to delete application")
+		}
+	}
+	return nil
+}
+
+// makeCancelDeploymentCommand returns a command to cancel the given deployment.
+// It gives back error if the deployment was already completed.
+func makeCancelDeploymentCommand(deployment *model.Deployment, commander string, forceRollback, forceNoRollback bool) (*model.Command, error) {
+	if model.IsCompletedDeployment(deployment.Status) {
+		return nil, status.Errorf(codes.FailedPrecondition, "could not cancel the deployment because it was already completed")
+	}
+
+	return &model.Command{
+		Id:            uuid.New().String(),
+		PipedId:       deployment.PipedId,
+		ApplicationId: deployment.ApplicationId,
+		DeploymentId:  deployment.Id,
+		Type:          model.Command_CANCEL_DEPLOYMENT,
+		Commander:     commander,
+		CancelDeployment: &model.Command_CancelDeployment{
+			DeploymentId:    deployment.Id,
+			ForceRollback:   forceRollback,
+			ForceNoRollback: forceNoRollback,
+		},
+	}, nil
+}
+
+// findPendingStage returns the stage of the given ID to perform the given action on it.
+// It gives back error unless the stage exists and is not completed yet.
+func findPendingStage(deployment *model.Deployment, stageID, action string) (*model.PipelineStage, error) {
+	var stage *model.PipelineStage
+	for _, s := range deployment.Stages {
+		if s.Id == stageID {
+			stage = s
+			break
+		}
+	}
+	if stage == nil {
+		return nil, status.Error(codes.FailedPrecondition, "The stage was not found in the deployment")
+	}
+	if model.IsCompletedStage(stage.Status) {
+		return nil, status.Errorf(codes.FailedPrecondition, "Could not %s the stage because it was already completed", action)
+	}
+	return stage, nil
+}
+
+func fetchStageLogs(ctx context.Context, store stagelogstore.Store, deploymentID, stageID string, retriedCount int32, offsetIndex int64, logger *zap.Logger) ([]*model.LogBlock, bool, error) {
+	blocks, completed, err := store.FetchLogs(ctx, deploymentID, stageID, retriedCount, offsetIndex)
+	if errors.Is(err, stagelogstore.ErrNotFound) {
+		return nil, false, status.Error(codes.NotFound, "The stage log not found")
+	}
+	if err != nil {
+		logger.Error("failed to get stage logs", zap.Error(err))
+		return nil, false, status.Error(codes.Internal, "Failed to get stage logs")
+	}
+	return blocks, completed, nil
+}
+
+// streamStageLogs keeps sending the stage logs from offsetIndex until the stage log has been completed.
+func streamStageLogs(ctx context.Context, store stagelogstore.Store, deploymentID, stageID string, retriedCount int32, offsetIndex int64, handler stagelogstore.StreamHandler, logger *zap.Logger) error {
+	err := store.StreamLogs(ctx, deploymentID, stageID, retriedCount, offsetIndex, handler)
+	if err == nil {
+		return nil
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return status.FromContextError(ctxErr).Err()
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	logger.Error("failed to stream stage logs", zap.Error(err))
+	return status.Error(codes.Internal, "Failed to stream stage logs")
+}
@@ -15,10 +15,15 @@
 package grpcapi
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
+	"encoding/csv"
 	"errors"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -28,7 +33,9 @@ import (
 	"google.golang.org/grpc/status"
 
 	"github.com/pipe-cd/pipe/pkg/app/api/applicationlivestatestore"
+	"github.com/pipe-cd/pipe/pkg/app/api/commandoutputstore"
 	"github.com/pipe-cd/pipe/pkg/app/api/commandstore"
+	"github.com/pipe-cd/pipe/pkg/app/api/deploymentartifactstore"
 	"github.com/pipe-cd/pipe/pkg/app/api/service/webservice"
 	"github.com/pipe-cd/pipe/pkg/app/api/stagelogstore"
 	"github.com/pipe-cd/pipe/pkg/cache"
@@ -50,12 +57,17 @@ type WebAPI struct {
 	applicationStore          datastore.ApplicationStore
 	environmentStore          datastore.EnvironmentStore
 	deploymentStore           datastore.DeploymentStore
+	deploymentChainStore      datastore.DeploymentChainStore
 	pipedStore                datastore.PipedStore
+	pipedConfigRevisionStore  datastore.PipedConfigRevisionStore
 	projectStore              datastore.ProjectStore
 	apiKeyStore               datastore.APIKeyStore
+	auditLogStore             datastore.AuditLogStore
 	stageLogStore             stagelogstore.Store
 	applicationLiveStateStore applicationlivestatestore.Store
 	commandStore              commandstore.Store
+	deploymentArtifactStore   deploymentartifactstore.Store
+	commandOutputStore        commandoutputstore.Store
 	encrypter                 encrypter
 
 	appProjectCache        cache.Cache
@@ -73,6 +85,8 @@ func NewWebAPI(
 	sls stagelogstore.Store,
 	alss applicationlivestatestore.Store,
 	cmds commandstore.Store,
+	das deploymentartifactstore.Store,
+	cos commandoutputstore.Store,
 	projs map[string]config.ControlPlaneProject,
 	encrypter encrypter,
 	logger *zap.Logger) *WebAPI {
@@ -80,12 +94,17 @@ func NewWebAPI(
 		applicationStore:          datastore.NewApplicationStore(ds),
 		environmentStore:          datastore.NewEnvironmentStore(ds),
 		deploymentStore:           datastore.NewDeploymentStore(ds),
+		deploymentChainStore:      datastore.NewDeploymentChainStore(ds),
 		pipedStore:                datastore.NewPipedStore(ds),
+		pipedConfigRevisionStore:  datastore.NewPipedConfigRevisionStore(ds),
 		projectStore:              datastore.NewProjectStore(ds),
 		apiKeyStore:               datastore.NewAPIKeyStore(ds),
+		auditLogStore:             datastore.NewAuditLogStore(ds),
 		stageLogStore:             sls,
 		applicationLiveStateStore: alss,
 		commandStore:              cmds,
+		deploymentArtifactStore:   das,
+		commandOutputStore:        cos,
 		projectsInConfig:          projs,
 		encrypter:                 encrypter,
 		appProjectCache:           memorycache.NewTTLCache(ctx, 24*time.Hour, 3*time.Hour),
@@ -137,19 +156,9 @@ func (a *WebAPI) ListEnvironments(ctx context.Context, req *webservice.ListEnvir
 		return nil, err
 	}
 
-	opts := datastore.ListOptions{
-		Filters: []datastore.ListFilter{
-			{
-				Field:    "ProjectId",
-				Operator: "==",
-				Value:    claims.Role.ProjectId,
-			},
-		},
-	}
-	envs, err := a.environmentStore.ListEnvironments(ctx, opts)
+	envs, err := listEnvironments(ctx, a.environmentStore, claims.Role.ProjectId, a.logger)
 	if err != nil {
-		a.logger.Error("failed to get environments", zap.Error(err))
-		return nil, status.Error(codes.Internal, "Failed to get environments")
+		return nil, err
 	}
 
 	return &webservice.ListEnvironmentsResponse{
@@ -157,6 +166,46 @@ func (a *WebAPI) ListEnvironments(ctx context.Context, req *webservice.ListEnvir
 	}, nil
 }
 
+// DeleteEnvironment marks the given environment and all of its applications as deleted.
+// The related data of the applications are removed by the ops component in background.
+func (a *WebAPI) DeleteEnvironment(ctx context.Context, req *webservice.DeleteEnvironmentRequest) (*webservice.DeleteEnvironmentResponse, error) {
+	claims, err := rpcauth.ExtractClaims(ctx)
+	if err != nil {
+		a.logger.Error("failed to authenticate the current user", zap.Error(err))
+		return nil, err
+	}
+
+	env, err := a.environmentStore.GetEnvironment(ctx, req.EnvironmentId)
+	if errors.Is(err, datastore.ErrNotFound) {
+		return nil, status.Error(codes.NotFound, "The environment is not found")
+	}
+	if err != nil {
+		a.logger.Error("failed to get environment", zap.Error(err))
+		return nil, status.Error(codes.Internal, "Failed to get environment")
+	}
+	if env.ProjectId != claims.Role.ProjectId {
+		return nil, status.Error(codes.InvalidArgument, "Requested environment does not belong to your project")
+	}
+
+	if err := ensureNoRunningDeployments(ctx, a.deploymentStore, env.ProjectId, "EnvId", env.Id, a.logger); err != nil {
+		return nil, err
+	}
+
+	apps, err := listApplicationsByField(ctx, a.applicationStore, env.ProjectId, "EnvId", env.Id, a.logger)
+	if err != nil {
+		return nil, err
+	}
+	if err := deleteApplications(ctx, a.applicationStore, apps, a.logger); err != nil {
+		return nil, err
+	}
+
+	if err := a.environmentStore.DeleteEnvironment(ctx, env.Id); err != nil {
+		a.logger.Error("failed to delete environment", zap.Error(err))
+		return nil, status.Error(codes.Internal, "Failed to delete environment")
+	}
+	return &webservice.DeleteEnvironmentResponse{}, nil
+}
+
 func (a *WebAPI) RegisterPiped(ctx context.Context, req *webservice.RegisterPipedRequest) (*webservice.RegisterPipedResponse, error) {
 	claims, err := rpcauth.ExtractClaims(ctx)
 	if err != nil {
@@ -249,6 +298,36 @@ func (a *WebAPI) DisablePiped(ctx context.Context, req *webservice.DisablePipedR
 	return &webservice.DisablePipedResponse{}, nil
 }
 
+// DeletePiped marks the given piped and all of its applications as deleted.
+// The related data are removed by the ops component in background.
+func (a *WebAPI) DeletePiped(ctx context.Context, req *webservice.DeletePipedRequest) (*webservice.DeletePipedResponse, error) {
+	claims, err := rpcauth.ExtractClaims(ctx)
+	if err != nil {
+		a.logger.Error("failed to authenticate the current user", zap.Error(err))
+		return nil, err
+	}
+
+	if err := a.validatePipedBelongsToProject(ctx, req.PipedId, claims.Role.ProjectId); err != nil {
+		return nil, err
+	}
+	if err := ensureNoRunningDeployments(ctx, a.deploymentStore, claims.Role.ProjectId, "PipedId", req.PipedId, a.logger); err != nil {
+		return nil, err
+	}
+
+	apps, err := listApplicationsByField(ctx, a.applicationStore, claims.Role.ProjectId, "PipedId", req.PipedId, a.logger)
+	if err != nil {
+		return nil, err
+	}
+	if err := deleteApplications(ctx, a.applicationStore, apps, a.logger); err != nil {
+		return nil, err
+	}
+
+	if err := a.updatePiped(ctx, req.PipedId, a.pipedStore.DeletePiped); err != nil {
+		return nil, err
+	}
+	return &webservice.DeletePipedResponse{}, nil
+}
+
 func (a *WebAPI) updatePiped(ctx context.Context, pipedID string, updater func(context.Context, string) error) error {
 	claims, err := rpcauth.ExtractClaims(ctx)
 	if err != nil {
@@ -285,35 +364,9 @@ func (a *WebAPI) ListPipeds(ctx context.Context, req *webservice.ListPipedsReque
 		return nil, err
 	}
 
-	opts := datastore.ListOptions{
-		Filters: []datastore.ListFilter{
-			{
-				Field:    "ProjectId",
-				Operator: "==",
-				Value:    claims.Role.ProjectId,
-			},
-		},
-	}
-
-	if req.Options != nil {
-		if req.Options.Enabled != nil {
-			opts.Filters = append(opts.Filters, datastore.ListFilter{
-				Field:    "Disabled",
-				Operator: "==",
-				Value:    !req.Options.Enabled.GetValue(),
-			})
-		}
-	}
-
-	pipeds, err := a.pipedStore.ListPipeds(ctx, opts)
+	pipeds, err := listPipeds(ctx, a.pipedStore, claims.Role.ProjectId, req.Options.GetEnabled(), a.logger)
 	if err != nil {
-		a.logger.Error("failed to get pipeds", zap.Error(err))
-		return nil, status.Error(codes.Internal, "Failed to get pipeds")
-	}
-
-	// Redact all sensitive data inside piped message before sending to the client.
-	for i := range pipeds {
-		pipeds[i].RedactSensitiveData()
+		return nil, err
 	}
 
 	return &webservice.ListPipedsResponse{
@@ -344,6 +397,187 @@ func (a *WebAPI) GetPiped(ctx context.Context, req *webservice.GetPipedRequest)
 	}, nil
 }
 
+// GetPipedConfig returns the specified version of the centrally managed configuration of the piped.
+func (a *WebAPI) GetPipedConfig(ctx context.Context, req *webservice.GetPipedConfigRequest) (*webservice.GetPipedConfigResponse, error) {
+	claims, err := rpcauth.ExtractClaims(ctx)
+	if err != nil {
+		a.logger.Error("failed to authenticate the current user", zap.Error(err))
+		return nil, err
+	}
+
+	if err := a.validatePipedBelongsToProject(ctx, req.PipedId, claims.Role.ProjectId); err != nil {
+		return nil, err
+	}
+	piped, err := getPiped(ctx, a.pipedStore, req.PipedId, a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	version := req.Version
+	if version == 0 {
+		version = piped.DesiredConfigVersion
+	}
+	if version == 0 {
+		return nil, status.Error(codes.NotFound, "The piped is using its local configuration file")
+	}
+
+	revision, err := getPipedConfigRevision(ctx, a.pipedConfigRevisionStore, req.PipedId, version, a.logger)
+	if err != nil {
+		return nil, err
+	}
+	return &webservice.GetPipedConfigResponse{
+		Revision:       revision,
+		AppliedVersion: piped.AppliedConfigVersion,
+	}, nil
+}
+
+// UpdatePipedConfig stores the given configuration as a new version
+// and makes the piped use it.
+func (a *WebAPI) UpdatePipedConfig(ctx context.Context, req *webservice.UpdatePipedConfigRequest) (*webservice.UpdatePipedConfigResponse, error) {
+	if err := config.ValidateCentralPipedConfig([]byte(req.Data)); err != nil {
+		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("Invalid piped configuration: %v", err))
+	}
+
+	version, err := a.addPipedConfigRevision(ctx, req.PipedId, req.BaseVersion, &model.PipedConfigRevision{
+		Data: req.Data,
+		Desc: req.Desc,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &webservice.UpdatePipedConfigResponse{
+		Version: version,
+	}, nil
+}
+
+// RollbackPipedConfig stores the data of the specified version as a new version
+// and makes the piped use it.
+func (a *WebAPI) RollbackPipedConfig(ctx context.Context, req *webservice.RollbackPipedConfigRequest) (*webservice.RollbackPipedConfigResponse, error) {
+	claims, err := rpcauth.ExtractClaims(ctx)
+	if err != nil {
+		a.logger.Error("failed to authenticate the current user", zap.Error(err))
+		return nil, err
+	}
+
+	if err := a.validatePipedBelongsToProject(ctx, req.PipedId, claims.Role.ProjectId); err != nil {
+		return nil, err
+	}
+	restored, err := getPipedConfigRevision(ctx, a.pipedConfigRevisionStore, req.PipedId, req.Version, a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := a.addPipedConfigRevision(ctx, req.PipedId, req.BaseVersion, &model.PipedConfigRevision{
+		Data:            restored.Data,
+		Desc:            req.Desc,
+		RestoredVersion: restored.Version,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &webservice.RollbackPipedConfigResponse{
+		Version: version,
+	}, nil
+}
+
+// addPipedConfigRevision adds the given revision as the next version of the piped configuration
+// and then updates the desired version of the piped.
+// The update is rejected when the current desired version is not the given base version.
+func (a *WebAPI) addPipedConfigRevision(ctx context.Context, pipedID string, baseVersion int64, revision *model.PipedConfigRevision) (int64, error) {
+	claims, err := rpcauth.ExtractClaims(ctx)
+	if err != nil {
+		a.logger.Error("failed to authenticate the current user", zap.Error(err))
+		return 0, err
+	}
+
+	if err := a.validatePipedBelongsToProject(ctx, pipedID, claims.Role.ProjectId); err != nil {
+		return 0, err
+	}
+	piped, err := getPiped(ctx, a.pipedStore, pipedID, a.logger)
+	if err != nil {
+		return 0, err
+	}
+	if piped.Deleted {
+		return 0, status.Error(codes.FailedPrecondition, "The piped was already deleted")
+	}
+	if piped.DesiredConfigVersion != baseVersion {
+		return 0, status.Error(codes.FailedPrecondition, fmt.Sprintf("The piped configuration was updated to version %d by someone else", piped.DesiredConfigVersion))
+	}
+
+	version := baseVersion + 1
+	revision.Id = model.MakePipedConfigRevisionID(pipedID, version)
+	revision.PipedId = pipedID
+	revision.ProjectId = claims.Role.ProjectId
+	revision.Version = version
+	revision.Updater = claims.Subject
+
+	if err := a.pipedConfigRevisionStore.AddPipedConfigRevision(ctx, revision); err != nil {
+		if errors.Is(err, datastore.ErrAlreadyExists) {
+			return 0, status.Error(codes.FailedPrecondition, "The piped configuration was updated by someone else")
+		}
+		a.logger.Error("failed to add piped config revision",
+			zap.String("piped-id", pipedID),
+			zap.Int64("version", version),
+			zap.Error(err),
+		)
+		return 0, status.Error(codes.Internal, "Failed to add piped config revision")
+	}
+
+	updater := func(p *model.Piped) error {
+		if p.DesiredConfigVersion < version {
+			p.DesiredConfigVersion = version
+		}
+		return nil
+	}
+	if err := a.pipedStore.UpdatePiped(ctx, pipedID, updater); err != nil {
+		a.logger.Error("failed to update the desired config version of piped",
+			zap.String("piped-id", pipedID),
+			zap.Int64("version", version),
+			zap.Error(err),
+		)
+		return 0, status.Error(codes.Internal, "Failed to update the piped")
+	}
+	return version, nil
+}
+
+// ListPipedConfigRevisions returns the history of the centrally managed configuration of the piped.
+func (a *WebAPI) ListPipedConfigRevisions(ctx context.Context, req *webservice.ListPipedConfigRevisionsRequest) (*webservice.ListPipedConfigRevisionsResponse, error) {
+	claims, err := rpcauth.ExtractClaims(ctx)
+	if err != nil {
+		a.logger.Error("failed to authenticate the current user", zap.Error(err))
+		return nil, err
+	}
+
+	if err := a.validatePipedBelongsToProject(ctx, req.PipedId, claims.Role.ProjectId); err != nil {
+		return nil, err
+	}
+
+	revisions, err := a.pipedConfigRevisionStore.ListPipedConfigRevisions(ctx, datastore.ListOptions{
+		Filters: []datastore.ListFilter{
+			{
+				Field:    "PipedId",
+				Operator: "==",
+				Value:    req.PipedId,
+			},
+		},
+		Orders: []datastore.Order{
+			{
+				Field:     "Version",
+				Direction: datastore.Desc,
+			},
+		},
+		PageSize: int(req.PageSize),
+	})
+	if err != nil {
+		a.logger.Error("failed to list piped config revisions", zap.Error(err))
+		return nil, status.Error(codes.Internal, "Failed to list piped config revisions")
+	}
+
+	return &webservice.ListPipedConfigRevisionsResponse{
+		Revisions: revisions,
+	}, nil
+}
+
 // validatePipedBelongsToProject checks if the given piped belongs to the given project.
 // It gives back error unless the piped belongs to the project.
 func (a *WebAPI) validatePipedBelongsToProject(ctx context.Context, pipedID, projectID string) error {
@@ -383,6 +617,9 @@ func (a *WebAPI) AddApplication(ctx context.Context, req *webservice.AddApplicat
 	if piped.ProjectId != claims.Role.ProjectId {
 		return nil, status.Error(codes.InvalidArgument, "Requested piped does not belong to your project")
 	}
+	if err := model.ValidateLabels(req.Labels); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
 
 	gitpath, err := makeGitPath(
 		req.GitPath.Repo.Id,
@@ -404,6 +641,7 @@ func (a *WebAPI) AddApplication(ctx context.Context, req *webservice.AddApplicat
 		GitPath:       gitpath,
 		Kind:          req.Kind,
 		CloudProvider: req.CloudProvider,
+		Labels:        req.Labels,
 	}
 	err = a.applicationStore.AddApplication(ctx, &app)
 	if errors.Is(err, datastore.ErrAlreadyExists) {
@@ -435,6 +673,29 @@ func (a *WebAPI) UpdateApplication(ctx context.Context, req *webservice.UpdateAp
 		return nil, status.Error(codes.InvalidArgument, "Requested piped does not belong to your project")
 	}
 
+	app, err := getApplication(ctx, a.applicationStore, req.ApplicationId, a.logger)
+	if err != nil {
+		return nil, err
+	}
+	if app.ProjectId != claims.Role.ProjectId {
+		return nil, status.Error(codes.InvalidArgument, "Requested application does not belong to your project")
+	}
+	if err := model.ValidateLabels(req.Labels); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	// The current labels are kept unless the new ones are specified or they are cleared.
+	labels := app.Labels
+	if len(req.Labels) > 0 || req.ClearLabels {
+		labels = req.Labels
+	}
+	// The user must be allowed to edit the application in both the current and the new environment.
+	if err := a.authorizeOnApplication(ctx, &claims.Role, app.EnvId, app.Labels, model.ProjectRBACRole_EDIT_CONFIG); err != nil {
+		return nil, err
+	}
+	if err := a.authorizeOnApplication(ctx, &claims.Role, req.EnvId, labels, model.ProjectRBACRole_EDIT_CONFIG); err != nil {
+		return nil, err
+	}
+
 	gitpath, err := makeGitPath(
 		req.GitPath.Repo.Id,
 		req.GitPath.Path,
@@ -453,6 +714,7 @@ func (a *WebAPI) UpdateApplication(ctx context.Context, req *webservice.UpdateAp
 		app.GitPath = gitpath
 		app.Kind = req.Kind
 		app.CloudProvider = req.CloudProvider
+		app.Labels = labels
 		return nil
 	})
 	if err != nil {
@@ -477,6 +739,54 @@ func (a *WebAPI) DisableApplication(ctx context.Context, req *webservice.Disable
 	return &webservice.DisableApplicationResponse{}, nil
 }
 
+// DeleteApplication marks the given application as deleted.
+// Its related data are removed by the ops component in background,
+// and its deployed resources are removed by its piped when prune_resources was specified.
+func (a *WebAPI) DeleteApplication(ctx context.Context, req *webservice.DeleteApplicationRequest) (*webservice.DeleteApplicationResponse, error) {
+	claims, err := rpcauth.ExtractClaims(ctx)
+	if err != nil {
+		a.logger.Error("failed to authenticate the current user", zap.Error(err))
+		return nil, err
+	}
+
+	app, err := getApplication(ctx, a.applicationStore, req.ApplicationId, a.logger)
+	if err != nil {
+		return nil, err
+	}
+	if app.ProjectId != claims.Role.ProjectId {
+		return nil, status.Error(codes.InvalidArgument, "Requested application does not belong to your project")
+	}
+	if req.PruneResources && app.Kind != model.ApplicationKind_KUBERNETES {
+		return nil, status.Errorf(codes.InvalidArgument, "Pruning resources is not supported for %s application", app.Kind.String())
+	}
+
+	if err := ensureNoRunningDeployments(ctx, a.deploymentStore, app.ProjectId, "ApplicationId", app.Id, a.logger); err != nil {
+		return nil, err
+	}
+	if err := deleteApplications(ctx, a.applicationStore, []*model.Application{app}, a.logger); err != nil {
+		return nil, err
+	}
+
+	if !req.PruneResources {
+		return &webservice.DeleteApplicationResponse{}, nil
+	}
+	cmd := model.Command{
+		Id:            uuid.New().String(),
+		PipedId:       app.PipedId,
+		ApplicationId: app.Id,
+		Type:          model.Command_PRUNE_APPLICATION_RESOURCES,
+		Commander:     claims.Subject,
+		PruneApplicationResources: &model.Command_PruneApplicationResources{
+			ApplicationId: app.Id,
+			CloudProvider: app.CloudProvider,
+		},
+	}
+	if err := addCommand(ctx, a.commandStore, &cmd, a.logger); err != nil {
+		return nil, err
+	}
+	return &webservice.DeleteApplicationResponse{}, nil
+}
+
 func (a *WebAPI) updateApplicationEnable(ctx context.Context, appID string, enable bool) error {
 	claims, err := rpcauth.ExtractClaims(ctx)
 	if err != nil {
@@ -488,28 +798,7 @@ func (a *WebAPI) updateApplicationEnable(ctx context.Context, appID string, enab
 		return err
 	}
 
-	var updater func(context.Context, string) error
-	if enable {
-		updater = a.applicationStore.EnableApplication
-	} else {
-		updater = a.applicationStore.DisableApplication
-	}
-
-	if err := updater(ctx, appID); err != nil {
-		switch err {
-		case datastore.ErrNotFound:
-			return status.Error(codes.InvalidArgument, "The application is not found")
-		case datastore.ErrInvalidArgument:
-			return status.Error(codes.InvalidArgument, "Invalid value for update")
-		default:
-			a.logger.Error("failed to update the application",
-				zap.String("application-id", appID),
-				zap.Error(err),
-			)
-			return status.Error(codes.Internal, "Failed to update the application")
-		}
-	}
-	return nil
+	return setApplicationEnable(ctx, a.applicationStore, appID, enable, a.logger)
 }
 
 func (a *WebAPI) ListApplications(ctx context.Context, req *webservice.ListApplicationsRequest) (*webservice.ListApplicationsResponse, error) {
@@ -525,45 +814,13 @@ func (a *WebAPI) ListApplications(ctx context.Context, req *webservice.ListAppli
 			Direction: datastore.Desc,
 		},
 	}
-	filters := []datastore.ListFilter{
-		{
-			Field:    "ProjectId",
-			Operator: "==",
-			Value:    claims.Role.ProjectId,
-		},
-	}
-	if o := req.Options; o != nil {
-		if o.Enabled != nil {
-			filters = append(filters, datastore.ListFilter{
-				Field:    "Disabled",
-				Operator: "==",
-				Value:    !o.Enabled.GetValue(),
-			})
-		}
-		// Allowing multiple so that it can do In Query later.
-		// Currently only the first value is used.
-		if len(o.Kinds) > 0 {
-			filters = append(filters, datastore.ListFilter{
-				Field:    "Kind",
-				Operator: "==",
-				Value:    o.Kinds[0],
-			})
-		}
-		if len(o.SyncStatuses) > 0 {
-			filters = append(filters, datastore.ListFilter{
-				Field:    "SyncState.Status",
-				Operator: "==",
-				Value:    o.SyncStatuses[0],
-			})
-		}
-		if len(o.EnvIds) > 0 {
-			filters = append(filters, datastore.ListFilter{
-				Field:    "EnvId",
-				Operator: "==",
-				Value:    o.EnvIds[0],
-			})
-		}
+	o := req.Options
+	filters := makeApplicationListFilters(claims.Role.ProjectId, o.GetEnabled(), o.GetKinds(), o.GetSyncStatuses(), o.GetEnvIds())
+	selector, labelFilters, err := parseLabelSelector(o.GetLabelSelector())
+	if err != nil {
+		return nil, err
 	}
+	filters = append(filters, labelFilters...)
 
 	apps, err := a.applicationStore.ListApplications(ctx, datastore.ListOptions{
 		Filters: filters,
@@ -575,7 +832,7 @@ func (a *WebAPI) ListApplications(ctx context.Context, req *webservice.ListAppli
 	}
 
 	return &webservice.ListApplicationsResponse{
-		Applications: apps,
+		Applications: filterApplications(apps, selector),
 	}, nil
 }
 
@@ -594,6 +851,9 @@ func (a *WebAPI) SyncApplication(ctx context.Context, req *webservice.SyncApplic
 	if claims.Role.ProjectId != app.ProjectId {
 		return nil, status.Error(codes.InvalidArgument, "Requested application does not belong to your project")
 	}
+	if err := a.authorizeOnApplication(ctx, &claims.Role, app.EnvId, app.Labels, model.ProjectRBACRole_SYNC); err != nil {
+		return nil, err
+	}
 
 	cmd := model.Command{
 		Id:            uuid.New().String(),
@@ -699,19 +959,334 @@ func (a *WebAPI) validateAppBelongsToProject(ctx context.Context, appID, project
 		return nil
 	}
 
-	app, err := getApplication(ctx, a.applicationStore, appID, a.logger)
+	app, err := getApplication(ctx, a.applicationStore, appID, a.logger)
+	if err != nil {
+		return err
+	}
+	a.appProjectCache.Put(appID, app.ProjectId)
+
+	if app.ProjectId != projectID {
+		return status.Error(codes.PermissionDenied, "Requested application doesn't belong to the project you logged in")
+	}
+	return nil
+}
+
+func (a *WebAPI) ListDeployments(ctx context.Context, req *webservice.ListDeploymentsRequest) (*webservice.ListDeploymentsResponse, error) {
+	claims, err := rpcauth.ExtractClaims(ctx)
+	if err != nil {
+		a.logger.Error("failed to authenticate the current user", zap.Error(err))
+		return nil, err
+	}
+
+	o := req.Options
+	filters := makeDeploymentListFilters(claims.Role.ProjectId, o.GetStatuses(), o.GetKinds(), o.GetApplicationIds(), o.GetEnvIds(), o.GetMaxUpdatedAt())
+	selector, labelFilters, err := parseLabelSelector(o.GetLabelSelector())
+	if err != nil {
+		return nil, err
+	}
+	filters = append(filters, labelFilters...)
+
+	deployments, err := listFilteredDeployments(ctx, a.deploymentStore, filters, int(req.PageSize), func(d *model.Deployment) bool {
+		return matchesDeploymentLabels(d, selector)
+	})
+	if err != nil {
+		a.logger.Error("failed to get deployments", zap.Error(err))
+		return nil, status.Error(codes.Internal, "Failed to get deployments")
+	}
+	return &webservice.ListDeploymentsResponse{
+		Deployments: deployments,
+	}, nil
+}
+
+func (a *WebAPI) GetDeployment(ctx context.Context, req *webservice.GetDeploymentRequest) (*webservice.GetDeploymentResponse, error) {
+	claims, err := rpcauth.ExtractClaims(ctx)
+	if err != nil {
+		a.logger.Error("failed to authenticate the current user", zap.Error(err))
+		return nil, err
+	}
+
+	deployment, err := getDeployment(ctx, a.deploymentStore, req.DeploymentId, a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Role.ProjectId != deployment.ProjectId {
+		return nil, status.Error(codes.InvalidArgument, "Requested deployment does not belong to your project")
+	}
+
+	return &webservice.GetDeploymentResponse{
+		Deployment: deployment,
+	}, nil
+}
+
+// validateDeploymentBelongsToProject checks if the given deployment belongs to the given project.
+// It gives back error unless the deployment belongs to the project.
+func (a *WebAPI) validateDeploymentBelongsToProject(ctx context.Context, deploymentID, projectID string) error {
+	pid, err := a.deploymentProjectCache.Get(deploymentID)
+	if err == nil {
+		if pid != projectID {
+			return status.Error(codes.PermissionDenied, "Requested deployment doesn't belong to the project you logged in")
+		}
+		return nil
+	}
+
+	deployment, err := getDeployment(ctx, a.deploymentStore, deploymentID, a.logger)
+	if err != nil {
+		return err
+	}
+	a.deploymentProjectCache.Put(deploymentID, deployment.ProjectId)
+
+	if deployment.ProjectId != projectID {
+		return status.Error(codes.PermissionDenied, "Requested deployment doesn't belong to the project you logged in")
+	}
+	return nil
+}
+
+// GetDeploymentDiff returns the diff between the running and target manifests
+// rendered by piped while planning the deployment.
+func (a *WebAPI) GetDeploymentDiff(ctx context.Context, req *webservice.GetDeploymentDiffRequest) (*webservice.GetDeploymentDiffResponse, error) {
+	claims, err := rpcauth.ExtractClaims(ctx)
+	if err != nil {
+		a.logger.Error("failed to authenticate the current user", zap.Error(err))
+		return nil, err
+	}
+
+	if err := a.validateDeploymentBelongsToProject(ctx, req.DeploymentId, claims.Role.ProjectId); err != nil {
+		return nil, err
+	}
+
+	content, err := a.deploymentArtifactStore.GetArtifact(ctx, req.DeploymentId, model.DeploymentManifestsDiffArtifactName)
+	if errors.Is(err, deploymentartifactstore.ErrNotFound) {
+		return nil, status.Error(codes.NotFound, "The diff of the deployment is not found")
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, "Failed to get the diff of the deployment")
+	}
+
+	return &webservice.GetDeploymentDiffResponse{
+		Diff: string(content),
+	}, nil
+}
+
+func (a *WebAPI) GetStageLog(ctx context.Context, req *webservice.GetStageLogRequest) (*webservice.GetStageLogResponse, error) {
+	claims, err := rpcauth.ExtractClaims(ctx)
+	if err != nil {
+		a.logger.Error("failed to authenticate the current user", zap.Error(err))
+		return nil, err
+	}
+
+	if err := a.validateDeploymentBelongsToProject(ctx, req.DeploymentId, claims.Role.ProjectId); err != nil {
+		return nil, err
+	}
+
+	blocks, completed, err := fetchStageLogs(ctx, a.stageLogStore, req.DeploymentId, req.StageId, req.RetriedCount, req.OffsetIndex, a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return &webservice.GetStageLogResponse{
+		Blocks:    blocks,
+		Completed: completed,
+	}, nil
+}
+
+func (a *WebAPI) StreamStageLog(req *webservice.StreamStageLogRequest, stream webservice.WebService_StreamStageLogServer) error {
+	ctx := stream.Context()
+	claims, err := rpcauth.ExtractClaims(ctx)
+	if err != nil {
+		a.logger.Error("failed to authenticate the current user", zap.Error(err))
+		return err
+	}
+
+	if err := a.validateDeploymentBelongsToProject(ctx, req.DeploymentId, claims.Role.ProjectId); err != nil {
+		return err
+	}
+
+	return streamStageLogs(ctx, a.stageLogStore, req.DeploymentId, req.StageId, req.RetriedCount, req.OffsetIndex, func(blocks []*model.LogBlock, completed bool) error {
+		return stream.Send(&webservice.StreamStageLogResponse{
+			Blocks:    blocks,
+			Completed: completed,
+		})
+	}, a.logger)
+}
+
+func (a *WebAPI) CancelDeployment(ctx context.Context, req *webservice.CancelDeploymentRequest) (*webservice.CancelDeploymentResponse, error) {
+	claims, err := rpcauth.ExtractClaims(ctx)
+	if err != nil {
+		a.logger.Error("failed to authenticate the current user", zap.Error(err))
+		return nil, err
+	}
+
+	deployment, err := getDeployment(ctx, a.deploymentStore, req.DeploymentId, a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Role.ProjectId != deployment.ProjectId {
+		return nil, status.Error(codes.InvalidArgument, "Requested deployment does not belong to your project")
+	}
+
+	if err := a.authorizeOnDeployment(ctx, &claims.Role, deployment, model.ProjectRBACRole_CANCEL); err != nil {
+		return nil, err
+	}
+
+	cmd, err := makeCancelDeploymentCommand(deployment, claims.Subject, req.ForceRollback, req.ForceNoRollback)
+	if err != nil {
+		return nil, err
+	}
+	if err := addCommand(ctx, a.commandStore, cmd, a.logger); err != nil {
+		return nil, err
+	}
+
+	return &webservice.CancelDeploymentResponse{
+		CommandId: cmd.Id,
+	}, nil
+}
+
+func (a *WebAPI) ApproveStage(ctx context.Context, req *webservice.ApproveStageRequest) (*webservice.ApproveStageResponse, error) {
+	claims, err := rpcauth.ExtractClaims(ctx)
+	if err != nil {
+		a.logger.Error("failed to authenticate the current user", zap.Error(err))
+		return nil, err
+	}
+
+	deployment, team, err := a.validateStageApprover(ctx, req.DeploymentId, req.StageId, "approve", claims.Subject, &claims.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	commandID := uuid.New().String()
+	cmd := model.Command{
+		Id:            commandID,
+		PipedId:       deployment.PipedId,
+		ApplicationId: deployment.ApplicationId,
+		DeploymentId:  req.DeploymentId,
+		StageId:       req.StageId,
+		Type:          model.Command_APPROVE_STAGE,
+		Commander:     claims.Subject,
+		ApproveStage: &model.Command_ApproveStage{
+			DeploymentId:  req.DeploymentId,
+			StageId:       req.StageId,
+			CommanderTeam: team,
+		},
+	}
+	if err := addCommand(ctx, a.commandStore, &cmd, a.logger); err != nil {
+		return nil, err
+	}
+
+	return &webservice.ApproveStageResponse{
+		CommandId: commandID,
+	}, nil
+}
+
+func (a *WebAPI) RejectStage(ctx context.Context, req *webservice.RejectStageRequest) (*webservice.RejectStageResponse, error) {
+	claims, err := rpcauth.ExtractClaims(ctx)
+	if err != nil {
+		a.logger.Error("failed to authenticate the current user", zap.Error(err))
+		return nil, err
+	}
+
+	deployment, team, err := a.validateStageApprover(ctx, req.DeploymentId, req.StageId, "reject", claims.Subject, &claims.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	commandID := uuid.New().String()
+	cmd := model.Command{
+		Id:            commandID,
+		PipedId:       deployment.PipedId,
+		ApplicationId: deployment.ApplicationId,
+		DeploymentId:  req.DeploymentId,
+		StageId:       req.StageId,
+		Type:          model.Command_REJECT_STAGE,
+		Commander:     claims.Subject,
+		RejectStage: &model.Command_RejectStage{
+			DeploymentId:  req.DeploymentId,
+			StageId:       req.StageId,
+			CommanderTeam: team,
+			Reason:        req.Reason,
+		},
+	}
+	if err := addCommand(ctx, a.commandStore, &cmd, a.logger); err != nil {
+		return nil, err
+	}
+
+	return &webservice.RejectStageResponse{
+		CommandId: commandID,
+	}, nil
+}
+
+// validateStageApprover checks whether the current user is allowed to approve or reject the given stage.
+// It returns the deployment of the stage and the SSO team through which the user was granted the project role.
+func (a *WebAPI) validateStageApprover(ctx context.Context, deploymentID, stageID, action, user string, role *model.Role) (*model.Deployment, string, error) {
+	deployment, err := getDeployment(ctx, a.deploymentStore, deploymentID, a.logger)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := a.validateDeploymentBelongsToProject(ctx, deploymentID, role.ProjectId); err != nil {
+		return nil, "", err
+	}
+	if err := a.authorizeOnDeployment(ctx, role, deployment, model.ProjectRBACRole_APPROVE); err != nil {
+		return nil, "", err
+	}
+
+	stage, err := findPendingStage(deployment, stageID, action)
+	if err != nil {
+		return nil, "", err
+	}
+
+	project, err := a.projectStore.GetProject(ctx, role.ProjectId)
+	if err != nil {
+		a.logger.Error("failed to get project", zap.Error(err))
+		return nil, "", status.Error(codes.Internal, "Failed to get project")
+	}
+	// The static admin is not a member of any SSO team.
+	var team string
+	if project.Rbac != nil && (project.StaticAdmin == nil || project.StaticAdmin.Username != user) {
+		team = project.Rbac.TeamForRole(role.ProjectRole)
+	}
+
+	if !model.IsStageApprover(model.StageApprovers(stage), user, team) {
+		return nil, "", status.Error(codes.PermissionDenied, "You are not in the list of approvers of this stage")
+	}
+	return deployment, team, nil
+}
+
+// authorizeOnApplication checks whether the given role is allowed to perform the action
+// on an application in the given environment and with the given labels.
+// The users having the required project role are always allowed,
+// the others must be granted the permission through a scoped role binding.
+func (a *WebAPI) authorizeOnApplication(ctx context.Context, role *model.Role, envID string, appLabels map[string]string, perm model.ProjectRBACRole_Permission) error {
+	if model.HasProjectRolePermission(role.ProjectRole, perm) {
+		return nil
+	}
+	if len(role.Teams) > 0 {
+		project, err := a.projectStore.GetProject(ctx, role.ProjectId)
+		if err != nil {
+			a.logger.Error("failed to get project", zap.Error(err))
+			return status.Error(codes.Internal, "Failed to get project")
+		}
+		if project.Authorize(role, envID, appLabels, perm) {
+			return nil
+		}
+	}
+	return status.Errorf(codes.PermissionDenied, "You don't have the %s permission on this application", perm.String())
+}
+
+// authorizeOnDeployment checks whether the given role is allowed to perform the action
+// on the application of the given deployment.
+func (a *WebAPI) authorizeOnDeployment(ctx context.Context, role *model.Role, deployment *model.Deployment, perm model.ProjectRBACRole_Permission) error {
+	if model.HasProjectRolePermission(role.ProjectRole, perm) {
+		return nil
+	}
+	app, err := getApplication(ctx, a.applicationStore, deployment.ApplicationId, a.logger)
 	if err != nil {
 		return err
 	}
-	a.appProjectCache.Put(appID, app.ProjectId)
-
-	if app.ProjectId != projectID {
-		return status.Error(codes.PermissionDenied, "Requested application doesn't belong to the project you logged in")
-	}
-	return nil
+	return a.authorizeOnApplication(ctx, role, app.EnvId, app.Labels, perm)
 }
 
-func (a *WebAPI) ListDeployments(ctx context.Context, req *webservice.ListDeploymentsRequest) (*webservice.ListDeploymentsResponse, error) {
+func (a *WebAPI) ListDeploymentChains(ctx context.Context, req *webservice.ListDeploymentChainsRequest) (*webservice.ListDeploymentChainsResponse, error) {
 	claims, err := rpcauth.ExtractClaims(ctx)
 	if err != nil {
 		a.logger.Error("failed to authenticate the current user", zap.Error(err))
@@ -741,27 +1316,6 @@ func (a *WebAPI) ListDeployments(ctx context.Context, req *webservice.ListDeploy
 				Value:    o.Statuses[0],
 			})
 		}
-		if len(o.Kinds) > 0 {
-			filters = append(filters, datastore.ListFilter{
-				Field:    "Kind",
-				Operator: "==",
-				Value:    o.Kinds[0],
-			})
-		}
-		if len(o.ApplicationIds) > 0 {
-			filters = append(filters, datastore.ListFilter{
-				Field:    "ApplicationId",
-				Operator: "==",
-				Value:    o.ApplicationIds[0],
-			})
-		}
-		if len(o.EnvIds) > 0 {
-			filters = append(filters, datastore.ListFilter{
-				Field:    "EnvId",
-				Operator: "==",
-				Value:    o.EnvIds[0],
-			})
-		}
 		if o.MaxUpdatedAt != 0 {
 			filters = append(filters, datastore.ListFilter{
 				Field:    "UpdatedAt",
@@ -771,196 +1325,250 @@ func (a *WebAPI) ListDeployments(ctx context.Context, req *webservice.ListDeploy
 		}
 	}
 
-	deployments, err := a.deploymentStore.ListDeployments(ctx, datastore.ListOptions{
+	chains, err := a.deploymentChainStore.ListDeploymentChains(ctx, datastore.ListOptions{
 		Filters:  filters,
 		Orders:   orders,
 		PageSize: int(req.PageSize),
 	})
 	if err != nil {
-		a.logger.Error("failed to get deployments", zap.Error(err))
-		return nil, status.Error(codes.Internal, "Failed to get deployments")
+		a.logger.Error("failed to get deployment chains", zap.Error(err))
+		return nil, status.Error(codes.Internal, "Failed to get deployment chains")
 	}
-	return &webservice.ListDeploymentsResponse{
-		Deployments: deployments,
+	return &webservice.ListDeploymentChainsResponse{
+		DeploymentChains: chains,
 	}, nil
 }
 
-func (a *WebAPI) GetDeployment(ctx context.Context, req *webservice.GetDeploymentRequest) (*webservice.GetDeploymentResponse, error) {
+func (a *WebAPI) GetDeploymentChain(ctx context.Context, req *webservice.GetDeploymentChainRequest) (*webservice.GetDeploymentChainResponse, error) {
 	claims, err := rpcauth.ExtractClaims(ctx)
 	if err != nil {
 		a.logger.Error("failed to authenticate the current user", zap.Error(err))
 		return nil, err
 	}
 
-	deployment, err := getDeployment(ctx, a.deploymentStore, req.DeploymentId, a.logger)
+	chain, err := getDeploymentChain(ctx, a.deploymentChainStore, req.DeploymentChainId, a.logger)
 	if err != nil {
 		return nil, err
 	}
 
-	if claims.Role.ProjectId != deployment.ProjectId {
-		return nil, status.Error(codes.InvalidArgument, "Requested deployment does not belong to your project")
+	if claims.Role.ProjectId != chain.ProjectId {
+		return nil, status.Error(codes.InvalidArgument, "Requested deployment chain does not belong to your project")
 	}
 
-	return &webservice.GetDeploymentResponse{
-		Deployment: deployment,
+	return &webservice.GetDeploymentChainResponse{
+		DeploymentChain: chain,
 	}, nil
 }
 
-// validateDeploymentBelongsToProject checks if the given deployment belongs to the given project.
-// It gives back error unless the deployment belongs to the project.
-func (a *WebAPI) validateDeploymentBelongsToProject(ctx context.Context, deploymentID, projectID string) error {
-	pid, err := a.deploymentProjectCache.Get(deploymentID)
-	if err == nil {
-		if pid != projectID {
-			return status.Error(codes.PermissionDenied, "Requested deployment doesn't belong to the project you logged in")
-		}
-		return nil
+// CancelDeploymentChain marks the chain as cancelled and sends a command
+// to cancel each running deployment of the chain.
+// The cancellation is terminal, the chain stays CANCELLED
+// even if some of those deployments are completed successfully later.
+func (a *WebAPI) CancelDeploymentChain(ctx context.Context, req *webservice.CancelDeploymentChainRequest) (*webservice.CancelDeploymentChainResponse, error) {
+	claims, err := rpcauth.ExtractClaims(ctx)
+	if err != nil {
+		a.logger.Error("failed to authenticate the current user", zap.Error(err))
+		return nil, err
 	}
 
-	deployment, err := getDeployment(ctx, a.deploymentStore, deploymentID, a.logger)
+	chain, err := getDeploymentChain(ctx, a.deploymentChainStore, req.DeploymentChainId, a.logger)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	a.deploymentProjectCache.Put(deploymentID, deployment.ProjectId)
 
-	if deployment.ProjectId != projectID {
-		return status.Error(codes.PermissionDenied, "Requested deployment doesn't belong to the project you logged in")
+	if claims.Role.ProjectId != chain.ProjectId {
+		return nil, status.Error(codes.InvalidArgument, "Requested deployment chain does not belong to your project")
 	}
-	return nil
+
+	var running []*model.ChainNode
+	err = a.deploymentChainStore.UpdateDeploymentChain(ctx, chain.Id, func(c *model.DeploymentChain) error {
+		if model.IsCompletedChain(c.Status) {
+			return fmt.Errorf("deployment chain %s was already completed: %w", c.Id, datastore.ErrInvalidArgument)
+		}
+		running = running[:0]
+		for _, b := range c.Blocks {
+			for _, n := range b.Nodes {
+				if n.DeploymentId != "" && !model.IsCompletedDeployment(n.DeploymentStatus) {
+					running = append(running, n)
+				}
+			}
+		}
+		c.Cancel(claims.Subject, time.Now().Unix())
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, datastore.ErrInvalidArgument) {
+			return nil, status.Error(codes.FailedPrecondition, "could not cancel the deployment chain because it was already completed")
+		}
+		a.logger.Error("failed to cancel deployment chain", zap.Error(err))
+		return nil, status.Error(codes.Internal, "Failed to cancel deployment chain")
+	}
+
+	commandIDs := make([]string, 0, len(running))
+	for _, n := range running {
+		cmd := model.Command{
+			Id:            uuid.New().String(),
+			PipedId:       n.PipedId,
+			ApplicationId: n.ApplicationId,
+			DeploymentId:  n.DeploymentId,
+			Type:          model.Command_CANCEL_DEPLOYMENT,
+			Commander:     claims.Subject,
+			CancelDeployment: &model.Command_CancelDeployment{
+				DeploymentId: n.DeploymentId,
+			},
+		}
+		if err := addCommand(ctx, a.commandStore, &cmd, a.logger); err != nil {
+			return nil, err
+		}
+		commandIDs = append(commandIDs, cmd.Id)
+	}
+
+	return &webservice.CancelDeploymentChainResponse{
+		CommandIds: commandIDs,
+	}, nil
 }
 
-func (a *WebAPI) GetStageLog(ctx context.Context, req *webservice.GetStageLogRequest) (*webservice.GetStageLogResponse, error) {
+func (a *WebAPI) GetApplicationLiveState(ctx context.Context, req *webservice.GetApplicationLiveStateRequest) (*webservice.GetApplicationLiveStateResponse, error) {
 	claims, err := rpcauth.ExtractClaims(ctx)
 	if err != nil {
 		a.logger.Error("failed to authenticate the current user", zap.Error(err))
 		return nil, err
 	}
 
-	if err := a.validateDeploymentBelongsToProject(ctx, req.DeploymentId, claims.Role.ProjectId); err != nil {
+	if err := a.validateAppBelongsToProject(ctx, req.ApplicationId, claims.Role.ProjectId); err != nil {
 		return nil, err
 	}
 
-	blocks, completed, err := a.stageLogStore.FetchLogs(ctx, req.DeploymentId, req.StageId, req.RetriedCount, req.OffsetIndex)
-	if errors.Is(err, stagelogstore.ErrNotFound) {
-		return nil, status.Error(codes.NotFound, "The stage log not found")
-	}
+	snapshot, err := a.applicationLiveStateStore.GetStateSnapshot(ctx, req.ApplicationId)
 	if err != nil {
-		a.logger.Error("failed to get stage logs", zap.Error(err))
-		return nil, status.Error(codes.Internal, "Failed to get stage logs")
+		a.logger.Error("failed to get application live state", zap.Error(err))
+		return nil, status.Error(codes.Internal, "Failed to get application live state")
 	}
-
-	return &webservice.GetStageLogResponse{
-		Blocks:    blocks,
-		Completed: completed,
+	return &webservice.GetApplicationLiveStateResponse{
+		Snapshot: snapshot,
 	}, nil
 }
 
-func (a *WebAPI) CancelDeployment(ctx context.Context, req *webservice.CancelDeploymentRequest) (*webservice.CancelDeploymentResponse, error) {
+// RequestKubernetesPodLogs adds a command to let the piped read the logs of a container of the application.
+// The read logs can be got through GetCommandOutput once the command was handled.
+func (a *WebAPI) RequestKubernetesPodLogs(ctx context.Context, req *webservice.RequestKubernetesPodLogsRequest) (*webservice.RequestKubernetesPodLogsResponse, error) {
 	claims, err := rpcauth.ExtractClaims(ctx)
 	if err != nil {
 		a.logger.Error("failed to authenticate the current user", zap.Error(err))
 		return nil, err
 	}
 
-	deployment, err := getDeployment(ctx, a.deploymentStore, req.DeploymentId, a.logger)
+	app, err := a.getKubernetesApplication(ctx, req.ApplicationId, claims.Role.ProjectId)
 	if err != nil {
 		return nil, err
 	}
-
-	if claims.Role.ProjectId != deployment.ProjectId {
-		return nil, status.Error(codes.InvalidArgument, "Requested deployment does not belong to your project")
+	if err := a.authorizeOnApplication(ctx, &claims.Role, app.EnvId, app.Labels, model.ProjectRBACRole_READ_LOGS); err != nil {
+		return nil, err
 	}
-
-	if model.IsCompletedDeployment(deployment.Status) {
-		return nil, status.Errorf(codes.FailedPrecondition, "could not cancel the deployment because it was already completed")
+	cloudProvider, err := a.validateApplicationCloudProvider(ctx, app, req.CloudProvider)
+	if err != nil {
+		return nil, err
 	}
 
 	cmd := model.Command{
 		Id:            uuid.New().String(),
-		PipedId:       deployment.PipedId,
-		ApplicationId: deployment.ApplicationId,
-		DeploymentId:  req.DeploymentId,
-		Type:          model.Command_CANCEL_DEPLOYMENT,
+		PipedId:       app.PipedId,
+		ApplicationId: app.Id,
+		Type:          model.Command_GET_KUBERNETES_POD_LOGS,
 		Commander:     claims.Subject,
-		CancelDeployment: &model.Command_CancelDeployment{
-			DeploymentId:    req.DeploymentId,
-			ForceRollback:   req.ForceRollback,
-			ForceNoRollback: req.ForceNoRollback,
+		GetKubernetesPodLogs: &model.Command_GetKubernetesPodLogs{
+			ApplicationId: app.Id,
+			CloudProvider: cloudProvider,
+			Namespace:     req.Namespace,
+			PodName:       req.PodName,
+			ContainerName: req.ContainerName,
+			TailLines:     req.TailLines,
+			SinceSeconds:  req.SinceSeconds,
+			Previous:      req.Previous,
 		},
 	}
 	if err := addCommand(ctx, a.commandStore, &cmd, a.logger); err != nil {
 		return nil, err
 	}
-
-	return &webservice.CancelDeploymentResponse{
+	return &webservice.RequestKubernetesPodLogsResponse{
 		CommandId: cmd.Id,
 	}, nil
 }
 
-func (a *WebAPI) ApproveStage(ctx context.Context, req *webservice.ApproveStageRequest) (*webservice.ApproveStageResponse, error) {
+// RequestKubernetesResourceEvents adds a command to let the piped read the events of a resource of the application.
+// The read events can be got through GetCommandOutput once the command was handled.
+func (a *WebAPI) RequestKubernetesResourceEvents(ctx context.Context, req *webservice.RequestKubernetesResourceEventsRequest) (*webservice.RequestKubernetesResourceEventsResponse, error) {
 	claims, err := rpcauth.ExtractClaims(ctx)
 	if err != nil {
 		a.logger.Error("failed to authenticate the current user", zap.Error(err))
 		return nil, err
 	}
 
-	deployment, err := getDeployment(ctx, a.deploymentStore, req.DeploymentId, a.logger)
+	app, err := a.getKubernetesApplication(ctx, req.ApplicationId, claims.Role.ProjectId)
 	if err != nil {
 		return nil, err
 	}
-	if err := a.validateDeploymentBelongsToProject(ctx, req.DeploymentId, claims.Role.ProjectId); err != nil {
+	cloudProvider, err := a.validateApplicationCloudProvider(ctx, app, req.CloudProvider)
+	if err != nil {
 		return nil, err
 	}
-	stage, ok := deployment.StageStatusMap()[req.StageId]
-	if !ok {
-		return nil, status.Error(codes.FailedPrecondition, "The stage was not found in the deployment")
-	}
-	if model.IsCompletedStage(stage) {
-		return nil, status.Errorf(codes.FailedPrecondition, "Could not approve the stage because it was already completed")
-	}
 
-	commandID := uuid.New().String()
 	cmd := model.Command{
-		Id:            commandID,
-		PipedId:       deployment.PipedId,
-		ApplicationId: deployment.ApplicationId,
-		DeploymentId:  req.DeploymentId,
-		StageId:       req.StageId,
-		Type:          model.Command_APPROVE_STAGE,
+		Id:            uuid.New().String(),
+		PipedId:       app.PipedId,
+		ApplicationId: app.Id,
+		Type:          model.Command_GET_KUBERNETES_RESOURCE_EVENTS,
 		Commander:     claims.Subject,
-		ApproveStage: &model.Command_ApproveStage{
-			DeploymentId: req.DeploymentId,
-			StageId:      req.StageId,
+		GetKubernetesResourceEvents: &model.Command_GetKubernetesResourceEvents{
+			ApplicationId: app.Id,
+			CloudProvider: cloudProvider,
+			ApiVersion:    req.ApiVersion,
+			Kind:          req.Kind,
+			Namespace:     req.Namespace,
+			Name:          req.Name,
 		},
 	}
 	if err := addCommand(ctx, a.commandStore, &cmd, a.logger); err != nil {
 		return nil, err
 	}
-
-	return &webservice.ApproveStageResponse{
-		CommandId: commandID,
+	return &webservice.RequestKubernetesResourceEventsResponse{
+		CommandId: cmd.Id,
 	}, nil
 }
 
-func (a *WebAPI) GetApplicationLiveState(ctx context.Context, req *webservice.GetApplicationLiveStateRequest) (*webservice.GetApplicationLiveStateResponse, error) {
-	claims, err := rpcauth.ExtractClaims(ctx)
+// getKubernetesApplication returns the specified application
+// after validating that it is a Kubernetes application of the given project.
+func (a *WebAPI) getKubernetesApplication(ctx context.Context, appID, projectID string) (*model.Application, error) {
+	app, err := getApplication(ctx, a.applicationStore, appID, a.logger)
 	if err != nil {
-		a.logger.Error("failed to authenticate the current user", zap.Error(err))
 		return nil, err
 	}
-
-	if err := a.validateAppBelongsToProject(ctx, req.ApplicationId, claims.Role.ProjectId); err != nil {
-		return nil, err
+	if app.ProjectId != projectID {
+		return nil, status.Error(codes.InvalidArgument, "Requested application does not belong to your project")
+	}
+	if app.Kind != model.ApplicationKind_KUBERNETES {
+		return nil, status.Errorf(codes.InvalidArgument, "Requested application is not a Kubernetes application but %s", app.Kind.String())
 	}
+	return app, nil
+}
 
-	snapshot, err := a.applicationLiveStateStore.GetStateSnapshot(ctx, req.ApplicationId)
+// validateApplicationCloudProvider returns the cloud provider where the resources of the given application should be inspected.
+// Empty means the cloud provider of the application, otherwise it must be one of the clusters the application is running on.
+func (a *WebAPI) validateApplicationCloudProvider(ctx context.Context, app *model.Application, cloudProvider string) (string, error) {
+	if cloudProvider == "" || cloudProvider == app.CloudProvider {
+		return app.CloudProvider, nil
+	}
+	snapshot, err := a.applicationLiveStateStore.GetStateSnapshot(ctx, app.Id)
 	if err != nil {
 		a.logger.Error("failed to get application live state", zap.Error(err))
-		return nil, status.Error(codes.Internal, "Failed to get application live state")
+		return "", status.Error(codes.Internal, "Failed to get application live state")
 	}
-	return &webservice.GetApplicationLiveStateResponse{
-		Snapshot: snapshot,
-	}, nil
+	for _, c := range snapshot.GetKubernetes().GetClusters() {
+		if c.CloudProvider == cloudProvider {
+			return cloudProvider, nil
+		}
+	}
+	return "", status.Errorf(codes.InvalidArgument, "Cloud provider %s is not one of the cloud providers of the application", cloudProvider)
 }
 
 // GetProject gets the specified porject without sensitive data.
@@ -1100,6 +1708,10 @@ func (a *WebAPI) UpdateProjectRBACConfig(ctx context.Context, req *webservice.Up
 		return nil, status.Error(codes.FailedPrecondition, "Failed to update a debug project specified in the control-plane configuration")
 	}
 
+	if err := req.Rbac.ValidateRoleBindings(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
 	if err := a.projectStore.UpdateProjectRBACConfig(ctx, claims.Role.ProjectId, req.Rbac); err != nil {
 		a.logger.Error("failed to update project single sign on settings", zap.Error(err))
 		return nil, status.Error(codes.Internal, "Failed to update project single sign on settings")
@@ -1107,6 +1719,29 @@ func (a *WebAPI) UpdateProjectRBACConfig(ctx context.Context, req *webservice.Up
 	return &webservice.UpdateProjectRBACConfigResponse{}, nil
 }
 
+// UpdateProjectDriftDetectionConfig updates the default drift detection settings of the project.
+func (a *WebAPI) UpdateProjectDriftDetectionConfig(ctx context.Context, req *webservice.UpdateProjectDriftDetectionConfigRequest) (*webservice.UpdateProjectDriftDetectionConfigResponse, error) {
+	claims, err := rpcauth.ExtractClaims(ctx)
+	if err != nil {
+		a.logger.Error("failed to authenticate the current user", zap.Error(err))
+		return nil, err
+	}
+
+	if _, ok := a.projectsInConfig[claims.Role.ProjectId]; ok {
+		return nil, status.Error(codes.FailedPrecondition, "Failed to update a debug project specified in the control-plane configuration")
+	}
+
+	if err := config.ValidateProjectDriftDetection(req.DriftDetection); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := a.projectStore.UpdateProjectDriftDetectionConfig(ctx, claims.Role.ProjectId, req.DriftDetection); err != nil {
+		a.logger.Error("failed to update project drift detection settings", zap.Error(err))
+		return nil, status.Error(codes.Internal, "Failed to update project drift detection settings")
+	}
+	return &webservice.UpdateProjectDriftDetectionConfigResponse{}, nil
+}
+
 // GetMe gets information about the current user.
 func (a *WebAPI) GetMe(ctx context.Context, req *webservice.GetMeRequest) (*webservice.GetMeResponse, error) {
 	claims, err := rpcauth.ExtractClaims(ctx)
@@ -1135,6 +1770,49 @@ func (a *WebAPI) GetCommand(ctx context.Context, req *webservice.GetCommandReque
 	}, nil
 }
 
+// GetCommandOutput returns the output of a handled command.
+// Since the output may contain the sensitive data such as container logs,
+// it can be read only by the commander in the project of the command and only for a short period after it was handled.
+func (a *WebAPI) GetCommandOutput(ctx context.Context, req *webservice.GetCommandOutputRequest) (*webservice.GetCommandOutputResponse, error) {
+	claims, err := rpcauth.ExtractClaims(ctx)
+	if err != nil {
+		a.logger.Error("failed to authenticate the current user", zap.Error(err))
+		return nil, err
+	}
+
+	cmd, err := getCommand(ctx, a.commandStore, req.CommandId, a.logger)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.validatePipedBelongsToProject(ctx, cmd.PipedId, claims.Role.ProjectId); err != nil {
+		return nil, err
+	}
+	if cmd.Commander != claims.Subject {
+		return nil, status.Error(codes.PermissionDenied, "Requested command was not issued by you")
+	}
+	if !cmd.IsHandled() {
+		return nil, status.Error(codes.FailedPrecondition, "Requested command has not been handled yet")
+	}
+
+	if time.Since(time.Unix(cmd.HandledAt, 0)) > commandoutputstore.OutputTTL {
+		if err := a.commandOutputStore.DeleteOutput(ctx, cmd.Id); err != nil {
+			a.logger.Error("failed to delete expired command output", zap.Error(err))
+		}
+		return nil, status.Error(codes.NotFound, "The output of requested command has been expired")
+	}
+
+	output, err := a.commandOutputStore.GetOutput(ctx, cmd.Id)
+	if errors.Is(err, commandoutputstore.ErrNotFound) {
+		return nil, status.Error(codes.NotFound, "Requested command has no output")
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, "Failed to get command output")
+	}
+	return &webservice.GetCommandOutputResponse{
+		Output: string(output),
+	}, nil
+}
+
 func (a *WebAPI) ListDeploymentConfigTemplates(ctx context.Context, req *webservice.ListDeploymentConfigTemplatesRequest) (*webservice.ListDeploymentConfigTemplatesResponse, error) {
 	claims, err := rpcauth.ExtractClaims(ctx)
 	if err != nil {
@@ -1208,6 +1886,10 @@ func (a *WebAPI) GenerateAPIKey(ctx context.Context, req *webservice.GenerateAPI
 		return nil, err
 	}
 
+	if req.ExpiresAt != 0 && req.ExpiresAt <= time.Now().Unix() {
+		return nil, status.Error(codes.InvalidArgument, "The expiration time must be in the future")
+	}
+
 	id := uuid.New().String()
 	key, hash, err := model.GenerateAPIKey(id)
 	if err != nil {
@@ -1216,12 +1898,19 @@ func (a *WebAPI) GenerateAPIKey(ctx context.Context, req *webservice.GenerateAPI
 	}
 
 	apiKey := model.APIKey{
-		Id:        id,
-		Name:      req.Name,
-		KeyHash:   hash,
-		ProjectId: claims.Role.ProjectId,
-		Role:      req.Role,
-		Creator:   claims.Subject,
+		Id:              id,
+		Name:            req.Name,
+		KeyHash:         hash,
+		ProjectId:       claims.Role.ProjectId,
+		Role:            req.Role,
+		Creator:         claims.Subject,
+		EnvIds:          req.EnvIds,
+		ApplicationIds:  req.ApplicationIds,
+		ExpiresAt:       req.ExpiresAt,
+		AllowedIpRanges: req.AllowedIpRanges,
+	}
+	if err := apiKey.ValidateAllowedIPRanges(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
 	err = a.apiKeyStore.AddAPIKey(ctx, &apiKey)
@@ -1296,6 +1985,18 @@ func (a *WebAPI) ListAPIKeys(ctx context.Context, req *webservice.ListAPIKeysReq
 		return nil, status.Error(codes.Internal, "Failed to list API keys")
 	}
 
+	// Filtering the unused keys here since it depends on both LastUsedAt and CreatedAt.
+	if d := req.Options.GetUnusedSeconds(); d > 0 {
+		now := time.Now()
+		filtered := apiKeys[:0]
+		for _, k := range apiKeys {
+			if k.IsUnused(now, time.Duration(d)*time.Second) {
+				filtered = append(filtered, k)
+			}
+		}
+		apiKeys = filtered
+	}
+
 	// Redact all sensitive data inside API key before sending to the client.
 	for i := range apiKeys {
 		apiKeys[i].RedactSensitiveData()
@@ -1306,6 +2007,128 @@ func (a *WebAPI) ListAPIKeys(ctx context.Context, req *webservice.ListAPIKeysReq
 	}, nil
 }
 
+func (a *WebAPI) ListAuditLogs(ctx context.Context, req *webservice.ListAuditLogsRequest) (*webservice.ListAuditLogsResponse, error) {
+	claims, err := rpcauth.ExtractClaims(ctx)
+	if err != nil {
+		a.logger.Error("failed to authenticate the current user", zap.Error(err))
+		return nil, err
+	}
+
+	orders := []datastore.Order{
+		{
+			Field:     "CreatedAt",
+			Direction: datastore.Desc,
+		},
+	}
+	filters := []datastore.ListFilter{
+		{
+			Field:    "ProjectId",
+			Operator: "==",
+			Value:    claims.Role.ProjectId,
+		},
+	}
+	if o := req.Options; o != nil {
+		if o.Actor != "" {
+			filters = append(filters, datastore.ListFilter{
+				Field:    "Actor",
+				Operator: "==",
+				Value:    o.Actor,
+			})
+		}
+		if o.Method != "" {
+			filters = append(filters, datastore.ListFilter{
+				Field:    "Method",
+				Operator: "==",
+				Value:    o.Method,
+			})
+		}
+		if o.Succeeded != nil {
+			filters = append(filters, datastore.ListFilter{
+				Field:    "Succeeded",
+				Operator: "==",
+				Value:    o.Succeeded.GetValue(),
+			})
+		}
+		if o.MinCreatedAt != 0 {
+			filters = append(filters, datastore.ListFilter{
+				Field:    "CreatedAt",
+				Operator: ">=",
+				Value:    o.MinCreatedAt,
+			})
+		}
+		if o.MaxCreatedAt != 0 {
+			filters = append(filters, datastore.ListFilter{
+				Field:    "CreatedAt",
+				Operator: "<=",
+				Value:    o.MaxCreatedAt,
+			})
+		}
+	}
+
+	logs, err := a.auditLogStore.ListAuditLogs(ctx, datastore.ListOptions{
+		Filters:  filters,
+		Orders:   orders,
+		PageSize: int(req.PageSize),
+	})
+	if err != nil {
+		a.logger.Error("failed to list audit logs", zap.Error(err))
+		return nil, status.Error(codes.Internal, "Failed to list audit logs")
+	}
+
+	resp := &webservice.ListAuditLogsResponse{
+		AuditLogs: logs,
+	}
+	if req.ExportFormat == webservice.ListAuditLogsRequest_CSV {
+		data, err := makeAuditLogsCSV(logs)
+		if err != nil {
+			a.logger.Error("failed to export audit logs", zap.Error(err))
+			return nil, status.Error(codes.Internal, "Failed to export audit logs")
+		}
+		resp.ExportedData = data
+	}
+	return resp, nil
+}
+
+// makeAuditLogsCSV returns a CSV file containing the given audit logs.
+func makeAuditLogsCSV(logs []*model.AuditLog) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	header := []string{"time", "actor", "actor_type", "role", "method", "targets", "succeeded", "status_code", "status_message"}
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+	for _, l := range logs {
+		keys := make([]string, 0, len(l.Targets))
+		for k := range l.Targets {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		targets := make([]string, 0, len(keys))
+		for _, k := range keys {
+			targets = append(targets, k+"="+l.Targets[k])
+		}
+		record := []string{
+			time.Unix(l.CreatedAt, 0).UTC().Format(time.RFC3339),
+			l.Actor,
+			l.ActorType.String(),
+			l.Role,
+			l.Method,
+			strings.Join(targets, ";"),
+			strconv.FormatBool(l.Succeeded),
+			l.StatusCode,
+			l.StatusMessage,
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 // GetInsightData returns the accumulated insight data.
 func (a *WebAPI) GetInsightData(ctx context.Context, req *webservice.GetInsightDataRequest) (*webservice.GetInsightDataResponse, error) {
 	claims, err := rpcauth.ExtractClaims(ctx)
@@ -26,6 +26,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
 
+	"github.com/pipe-cd/pipe/pkg/app/api/applicationlivestatestore"
 	"github.com/pipe-cd/pipe/pkg/app/api/service/webservice"
 	"github.com/pipe-cd/pipe/pkg/cache"
 	"github.com/pipe-cd/pipe/pkg/cache/cachetest"
@@ -207,6 +208,171 @@ func TestValidateAppBelongsToProject(t *testing.T) {
 	}
 }
 
+func TestAuthorizeOnApplication(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	project := &model.Project{
+		Id: "projectID",
+		Rbac: &model.ProjectRBACConfig{
+			Admin: "org/admin",
+			Roles: []*model.ProjectRBACRole{
+				{
+					Name:        "deployer",
+					Permissions: []model.ProjectRBACRole_Permission{model.ProjectRBACRole_SYNC},
+				},
+			},
+			Bindings: []*model.ProjectRBACBinding{
+				{
+					Team:   "org/team-x",
+					Role:   "deployer",
+					EnvIds: []string{"staging"},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		role         *model.Role
+		envID        string
+		perm         model.ProjectRBACRole_Permission
+		projectStore datastore.ProjectStore
+		wantErr      bool
+	}{
+		{
+			name:    "editor can sync",
+			role:    &model.Role{ProjectId: "projectID", ProjectRole: model.Role_EDITOR},
+			envID:   "production",
+			perm:    model.ProjectRBACRole_SYNC,
+			wantErr: false,
+		},
+		{
+			name:    "editor can not edit config",
+			role:    &model.Role{ProjectId: "projectID", ProjectRole: model.Role_EDITOR},
+			envID:   "production",
+			perm:    model.ProjectRBACRole_EDIT_CONFIG,
+			wantErr: true,
+		},
+		{
+			name:    "editor can read logs",
+			role:    &model.Role{ProjectId: "projectID", ProjectRole: model.Role_EDITOR},
+			envID:   "production",
+			perm:    model.ProjectRBACRole_READ_LOGS,
+			wantErr: false,
+		},
+		{
+			name:    "viewer can not read logs",
+			role:    &model.Role{ProjectId: "projectID", ProjectRole: model.Role_VIEWER},
+			envID:   "production",
+			perm:    model.ProjectRBACRole_READ_LOGS,
+			wantErr: true,
+		},
+		{
+			name:  "scoped team can sync in the bound environment",
+			role:  &model.Role{ProjectId: "projectID", ProjectRole: model.Role_VIEWER, Teams: []string{"org/team-x"}},
+			envID: "staging",
+			perm:  model.ProjectRBACRole_SYNC,
+			projectStore: func() datastore.ProjectStore {
+				s := datastoretest.NewMockProjectStore(ctrl)
+				s.EXPECT().
+					GetProject(gomock.Any(), "projectID").Return(project, nil)
+				return s
+			}(),
+			wantErr: false,
+		},
+		{
+			name:  "scoped team can not sync in another environment",
+			role:  &model.Role{ProjectId: "projectID", ProjectRole: model.Role_VIEWER, Teams: []string{"org/team-x"}},
+			envID: "production",
+			perm:  model.ProjectRBACRole_SYNC,
+			projectStore: func() datastore.ProjectStore {
+				s := datastoretest.NewMockProjectStore(ctrl)
+				s.EXPECT().
+					GetProject(gomock.Any(), "projectID").Return(project, nil)
+				return s
+			}(),
+			wantErr: true,
+		},
+		{
+			name:    "viewer without scoped teams",
+			role:    &model.Role{ProjectId: "projectID", ProjectRole: model.Role_VIEWER},
+			envID:   "staging",
+			perm:    model.ProjectRBACRole_SYNC,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &WebAPI{
+				projectStore: tt.projectStore,
+				logger:       zap.NewNop(),
+			}
+			err := api.authorizeOnApplication(ctx, tt.role, tt.envID, nil, tt.perm)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+type fakeApplicationLiveStateStore struct {
+	applicationlivestatestore.Store
+	snapshot *model.ApplicationLiveStateSnapshot
+}
+
+func (s *fakeApplicationLiveStateStore) GetStateSnapshot(_ context.Context, _ string) (*model.ApplicationLiveStateSnapshot, error) {
+	return s.snapshot, nil
+}
+
+func TestValidateApplicationCloudProvider(t *testing.T) {
+	app := &model.Application{Id: "app-1", CloudProvider: "cluster-1"}
+	api := &WebAPI{
+		applicationLiveStateStore: &fakeApplicationLiveStateStore{
+			snapshot: &model.ApplicationLiveStateSnapshot{
+				ApplicationId: "app-1",
+				Kubernetes: &model.KubernetesApplicationLiveState{
+					Clusters: []*model.KubernetesClusterLiveState{
+						{CloudProvider: "cluster-1"},
+						{CloudProvider: "cluster-2"},
+					},
+				},
+			},
+		},
+		logger: zap.NewNop(),
+	}
+	tests := []struct {
+		name          string
+		cloudProvider string
+		want          string
+		wantErr       bool
+	}{
+		{
+			name:          "empty means the cloud provider of the application",
+			cloudProvider: "",
+			want:          "cluster-1",
+		},
+		{
+			name:          "another cluster of the application",
+			cloudProvider: "cluster-2",
+			want:          "cluster-2",
+		},
+		{
+			name:          "cluster not running the application",
+			cloudProvider: "cluster-3",
+			wantErr:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := api.validateApplicationCloudProvider(context.Background(), app, tt.cloudProvider)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func TestValidateDeploymentBelongsToProject(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -826,3 +992,36 @@ func TestGetInsightDataForChangeFailureRate(t *testing.T) {
 		})
 	}
 }
+
+func TestMakeAuditLogsCSV(t *testing.T) {
+	logs := []*model.AuditLog{
+		{
+			Actor:      "user-1",
+			ActorType:  model.AuditActorType_AUDIT_ACTOR_USER,
+			Role:       "ADMIN",
+			Method:     "/pipe.api.service.webservice.WebService/DisablePiped",
+			Targets:    map[string]string{"piped_id": "piped-1"},
+			Succeeded:  true,
+			StatusCode: "OK",
+			CreatedAt:  time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).Unix(),
+		},
+		{
+			Actor:         "key-1",
+			ActorType:     model.AuditActorType_AUDIT_ACTOR_API_KEY,
+			Role:          "READ_WRITE",
+			Method:        "/pipe.api.service.apiservice.APIService/SyncApplication",
+			Targets:       map[string]string{"application_id": "app-1", "command_id": "cmd-1"},
+			StatusCode:    "NotFound",
+			StatusMessage: "Application is not found, \"app-1\"",
+			CreatedAt:     time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC).Unix(),
+		},
+	}
+	expected := `time,actor,actor_type,role,method,targets,succeeded,status_code,status_message
+2020-01-01T00:00:00Z,user-1,AUDIT_ACTOR_USER,ADMIN,/pipe.api.service.webservice.WebService/DisablePiped,piped_id=piped-1,true,OK,
+2020-01-02T00:00:00Z,key-1,AUDIT_ACTOR_API_KEY,READ_WRITE,/pipe.api.service.apiservice.APIService/SyncApplication,application_id=app-1;command_id=cmd-1,false,NotFound,"Application is not found, ""app-1"""
+`
+
+	data, err := makeAuditLogsCSV(logs)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(data))
+}
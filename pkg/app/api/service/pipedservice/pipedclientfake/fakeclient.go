@@ -31,6 +31,7 @@ import (
 type fakeClient struct {
 	applications map[string]*model.Application
 	deployments  map[string]*model.Deployment
+	artifacts    map[string][]byte
 	mu           sync.RWMutex
 	logger       *zap.Logger
 }
@@ -90,6 +91,7 @@ func NewClient(logger *zap.Logger) *fakeClient {
 	return &fakeClient{
 		applications: apps,
 		deployments:  map[string]*model.Deployment{},
+		artifacts:    map[string][]byte{},
 		logger:       logger.Named("fake-piped-client"),
 	}
 }
@@ -114,6 +116,20 @@ func (c *fakeClient) ReportPipedMeta(ctx context.Context, req *pipedservice.Repo
 	return &pipedservice.ReportPipedMetaResponse{}, nil
 }
 
+// GetDesiredPipedConfig returns the centrally managed configuration
+// the requested piped should run with.
+func (c *fakeClient) GetDesiredPipedConfig(ctx context.Context, req *pipedservice.GetDesiredPipedConfigRequest, opts ...grpc.CallOption) (*pipedservice.GetDesiredPipedConfigResponse, error) {
+	c.logger.Info("fake client received GetDesiredPipedConfig rpc", zap.Any("request", req))
+	return nil, status.Error(codes.NotFound, "piped is using its local configuration file")
+}
+
+// GetDriftDetectionConfig returns the default drift detection settings
+// of the project the requested piped belongs to.
+func (c *fakeClient) GetDriftDetectionConfig(ctx context.Context, req *pipedservice.GetDriftDetectionConfigRequest, opts ...grpc.CallOption) (*pipedservice.GetDriftDetectionConfigResponse, error) {
+	c.logger.Info("fake client received GetDriftDetectionConfig rpc", zap.Any("request", req))
+	return &pipedservice.GetDriftDetectionConfigResponse{}, nil
+}
+
 // GetEnvironment finds and returns the environment for the specified ID.
 func (c *fakeClient) GetEnvironment(ctx context.Context, req *pipedservice.GetEnvironmentRequest, opts ...grpc.CallOption) (*pipedservice.GetEnvironmentResponse, error) {
 	c.logger.Info("fake client received GetEnvironment rpc", zap.Any("request", req))
@@ -160,6 +176,22 @@ func (c *fakeClient) ReportApplicationSyncState(ctx context.Context, req *pipeds
 
 // ReportApplicationMostRecentDeployment is used to update the basic information about
 // the most recent deployment of a specific application.
+func (c *fakeClient) ReportApplicationLabels(ctx context.Context, req *pipedservice.ReportApplicationLabelsRequest, opts ...grpc.CallOption) (*pipedservice.ReportApplicationLabelsResponse, error) {
+	c.logger.Info("fake client received ReportApplicationLabels rpc", zap.Any("request", req))
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	app, ok := c.applications[req.ApplicationId]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "application was not found")
+	}
+	app.Labels = req.Labels
+
+	return &pipedservice.ReportApplicationLabelsResponse{
+		Labels: req.Labels,
+	}, nil
+}
+
 func (c *fakeClient) ReportApplicationMostRecentDeployment(ctx context.Context, req *pipedservice.ReportApplicationMostRecentDeploymentRequest, opts ...grpc.CallOption) (*pipedservice.ReportApplicationMostRecentDeploymentResponse, error) {
 	c.logger.Info("fake client received ReportApplicationMostRecentDeployment rpc", zap.Any("request", req))
 
@@ -273,7 +305,7 @@ func (c *fakeClient) ReportDeploymentPlanned(ctx context.Context, req *pipedserv
 }
 
 // ReportDeploymentStatusChanged is used to update the status
-// of a specific deployment to RUNNING or ROLLING_BACK.
+// of a specific deployment to PLANNED, RUNNING or ROLLING_BACK.
 func (c *fakeClient) ReportDeploymentStatusChanged(ctx context.Context, req *pipedservice.ReportDeploymentStatusChangedRequest, opts ...grpc.CallOption) (*pipedservice.ReportDeploymentStatusChangedResponse, error) {
 	c.logger.Info("fake client received ReportDeploymentStatusChanged rpc", zap.Any("request", req))
 	c.mu.Lock()
@@ -328,6 +360,13 @@ func (c *fakeClient) ReportDeploymentCompleted(ctx context.Context, req *pipedse
 	return &pipedservice.ReportDeploymentCompletedResponse{}, nil
 }
 
+// TriggerDeploymentChain is used to trigger the downstream applications
+// of a successfully completed deployment.
+func (c *fakeClient) TriggerDeploymentChain(ctx context.Context, req *pipedservice.TriggerDeploymentChainRequest, opts ...grpc.CallOption) (*pipedservice.TriggerDeploymentChainResponse, error) {
+	c.logger.Info("fake client received TriggerDeploymentChain rpc", zap.Any("request", req))
+	return &pipedservice.TriggerDeploymentChainResponse{}, nil
+}
+
 // SaveDeploymentMetadata used by piped to persist the metadata of a specific deployment.
 func (c *fakeClient) SaveDeploymentMetadata(ctx context.Context, req *pipedservice.SaveDeploymentMetadataRequest, opts ...grpc.CallOption) (*pipedservice.SaveDeploymentMetadataResponse, error) {
 	c.logger.Info("fake client received SaveDeploymentMetadata rpc", zap.Any("request", req))
@@ -343,6 +382,38 @@ func (c *fakeClient) SaveDeploymentMetadata(ctx context.Context, req *pipedservi
 	return &pipedservice.SaveDeploymentMetadataResponse{}, nil
 }
 
+// PutDeploymentArtifact is used to save a file generated while handling a deployment.
+func (c *fakeClient) PutDeploymentArtifact(ctx context.Context, req *pipedservice.PutDeploymentArtifactRequest, opts ...grpc.CallOption) (*pipedservice.PutDeploymentArtifactResponse, error) {
+	c.logger.Info("fake client received PutDeploymentArtifact rpc",
+		zap.String("deployment-id", req.DeploymentId),
+		zap.String("name", req.Name),
+		zap.Int("size", len(req.Content)),
+	)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.artifacts[req.DeploymentId+"/"+req.Name] = req.Content
+	return &pipedservice.PutDeploymentArtifactResponse{}, nil
+}
+
+// GetDeploymentArtifact is used to load a file saved by PutDeploymentArtifact.
+func (c *fakeClient) GetDeploymentArtifact(ctx context.Context, req *pipedservice.GetDeploymentArtifactRequest, opts ...grpc.CallOption) (*pipedservice.GetDeploymentArtifactResponse, error) {
+	c.logger.Info("fake client received GetDeploymentArtifact rpc",
+		zap.String("deployment-id", req.DeploymentId),
+		zap.String("name", req.Name),
+	)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	content, ok := c.artifacts[req.DeploymentId+"/"+req.Name]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "deployment artifact was not found")
+	}
+	return &pipedservice.GetDeploymentArtifactResponse{
+		Content: content,
+	}, nil
+}
+
 // SaveStageMetadata used by piped to persist the metadata
 // of a specific stage of a deployment.
 func (c *fakeClient) SaveStageMetadata(ctx context.Context, req *pipedservice.SaveStageMetadataRequest, opts ...grpc.CallOption) (*pipedservice.SaveStageMetadataResponse, error) {
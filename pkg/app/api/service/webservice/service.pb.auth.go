@@ -38,6 +38,12 @@ func isViewer(r model.Role) bool {
 	return r.ProjectRole == model.Role_VIEWER
 }
 
+// hasScopedTeams reports whether the user is in any team having scoped role bindings.
+// The scoped permissions are checked by the handler after resolving the target application.
+func hasScopedTeams(r model.Role) bool {
+	return len(r.Teams) > 0
+}
+
 // Authorize checks whether a role is enough for given gRPC method or not.
 // Todo: Auto generate this file from protobuf.
 func (a *authorizer) Authorize(method string, r model.Role) bool {
@@ -46,6 +52,8 @@ func (a *authorizer) Authorize(method string, r model.Role) bool {
 		return isAdmin(r)
 	case "/pipe.api.service.webservice.WebService/UpdateEnvironmentDesc":
 		return isAdmin(r)
+	case "/pipe.api.service.webservice.WebService/DeleteEnvironment":
+		return isAdmin(r)
 	case "/pipe.api.service.webservice.WebService/RegisterPiped":
 		return isAdmin(r)
 	case "/pipe.api.service.webservice.WebService/UpdatePiped":
@@ -56,14 +64,26 @@ func (a *authorizer) Authorize(method string, r model.Role) bool {
 		return isAdmin(r)
 	case "/pipe.api.service.webservice.WebService/DisablePiped":
 		return isAdmin(r)
+	case "/pipe.api.service.webservice.WebService/DeletePiped":
+		return isAdmin(r)
+	case "/pipe.api.service.webservice.WebService/GetPipedConfig":
+		return isAdmin(r)
+	case "/pipe.api.service.webservice.WebService/UpdatePipedConfig":
+		return isAdmin(r)
+	case "/pipe.api.service.webservice.WebService/RollbackPipedConfig":
+		return isAdmin(r)
+	case "/pipe.api.service.webservice.WebService/ListPipedConfigRevisions":
+		return isAdmin(r)
 	case "/pipe.api.service.webservice.WebService/AddApplication":
 		return isAdmin(r)
 	case "/pipe.api.service.webservice.WebService/UpdateApplication":
-		return isAdmin(r)
+		return isAdmin(r) || hasScopedTeams(r)
 	case "/pipe.api.service.webservice.WebService/EnableApplication":
 		return isAdmin(r)
 	case "/pipe.api.service.webservice.WebService/DisableApplication":
 		return isAdmin(r)
+	case "/pipe.api.service.webservice.WebService/DeleteApplication":
+		return isAdmin(r)
 	case "/pipe.api.service.webservice.WebService/UpdateProjectStaticAdmin":
 		return isAdmin(r)
 	case "/pipe.api.service.webservice.WebService/EnableStaticAdmin":
@@ -74,18 +94,28 @@ func (a *authorizer) Authorize(method string, r model.Role) bool {
 		return isAdmin(r)
 	case "/pipe.api.service.webservice.WebService/UpdateProjectRBACConfig":
 		return isAdmin(r)
+	case "/pipe.api.service.webservice.WebService/UpdateProjectDriftDetectionConfig":
+		return isAdmin(r)
 	case "/pipe.api.service.webservice.WebService/GenerateAPIKey":
 		return isAdmin(r)
 	case "/pipe.api.service.webservice.WebService/DisableAPIKey":
 		return isAdmin(r)
+	case "/pipe.api.service.webservice.WebService/ListAuditLogs":
+		return isAdmin(r)
 	case "/pipe.api.service.webservice.WebService/ListAPIKeys":
 		return isAdmin(r)
 
 	case "/pipe.api.service.webservice.WebService/SyncApplication":
-		return isAdmin(r) || isEditor(r)
+		return isAdmin(r) || isEditor(r) || hasScopedTeams(r)
+	case "/pipe.api.service.webservice.WebService/RequestKubernetesPodLogs":
+		return isAdmin(r) || isEditor(r) || hasScopedTeams(r)
 	case "/pipe.api.service.webservice.WebService/CancelDeployment":
-		return isAdmin(r) || isEditor(r)
+		return isAdmin(r) || isEditor(r) || hasScopedTeams(r)
 	case "/pipe.api.service.webservice.WebService/ApproveStage":
+		return isAdmin(r) || isEditor(r) || hasScopedTeams(r)
+	case "/pipe.api.service.webservice.WebService/RejectStage":
+		return isAdmin(r) || isEditor(r) || hasScopedTeams(r)
+	case "/pipe.api.service.webservice.WebService/CancelDeploymentChain":
 		return isAdmin(r) || isEditor(r)
 	case "/pipe.api.service.webservice.WebService/GenerateApplicationSealedSecret":
 		return isAdmin(r) || isEditor(r)
@@ -96,6 +126,10 @@ func (a *authorizer) Authorize(method string, r model.Role) bool {
 		return isAdmin(r) || isEditor(r) || isViewer(r)
 	case "/pipe.api.service.webservice.WebService/GetCommand":
 		return isAdmin(r) || isEditor(r) || isViewer(r)
+	case "/pipe.api.service.webservice.WebService/GetCommandOutput":
+		return isAdmin(r) || isEditor(r) || isViewer(r)
+	case "/pipe.api.service.webservice.WebService/RequestKubernetesResourceEvents":
+		return isAdmin(r) || isEditor(r) || isViewer(r)
 	case "/pipe.api.service.webservice.WebService/ListDeploymentConfigTemplates":
 		return isAdmin(r) || isEditor(r) || isViewer(r)
 	case "/pipe.api.service.webservice.WebService/ListEnvironments":
@@ -112,8 +146,16 @@ func (a *authorizer) Authorize(method string, r model.Role) bool {
 		return isAdmin(r) || isEditor(r) || isViewer(r)
 	case "/pipe.api.service.webservice.WebService/GetDeployment":
 		return isAdmin(r) || isEditor(r) || isViewer(r)
+	case "/pipe.api.service.webservice.WebService/GetDeploymentDiff":
+		return isAdmin(r) || isEditor(r) || isViewer(r)
 	case "/pipe.api.service.webservice.WebService/GetStageLog":
 		return isAdmin(r) || isEditor(r) || isViewer(r)
+	case "/pipe.api.service.webservice.WebService/StreamStageLog":
+		return isAdmin(r) || isEditor(r) || isViewer(r)
+	case "/pipe.api.service.webservice.WebService/ListDeploymentChains":
+		return isAdmin(r) || isEditor(r) || isViewer(r)
+	case "/pipe.api.service.webservice.WebService/GetDeploymentChain":
+		return isAdmin(r) || isEditor(r) || isViewer(r)
 	case "/pipe.api.service.webservice.WebService/GetMe":
 		return isAdmin(r) || isEditor(r) || isViewer(r)
 	case "/pipe.api.service.webservice.WebService/GetInsightData":
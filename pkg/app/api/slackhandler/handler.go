@@ -0,0 +1,492 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package slackhandler provides a handler for the interactive messages sent from Slack
+// to approve or reject the WAIT_APPROVAL stages.
+package slackhandler
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/datastore"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+const (
+	// interactionsPath is the path configured as the interactivity request URL of the Slack app.
+	interactionsPath = "/slack/interactions"
+
+	timestampHeader = "X-Slack-Request-Timestamp"
+	signatureHeader = "X-Slack-Signature"
+	signatureVer    = "v0"
+
+	interactiveMessageType = "interactive_message"
+
+	maxRequestAge  = 5 * time.Minute
+	maxRequestSize = 1024 * 1024
+
+	// How long to wait for the piped to handle the created command
+	// before giving up updating the message with its result.
+	commandWaitTimeout   = 5 * time.Minute
+	commandCheckInterval = 2 * time.Second
+)
+
+type deploymentGetter interface {
+	GetDeployment(ctx context.Context, id string) (*model.Deployment, error)
+}
+
+type applicationGetter interface {
+	GetApplication(ctx context.Context, id string) (*model.Application, error)
+}
+
+type projectGetter interface {
+	GetProject(ctx context.Context, id string) (*model.Project, error)
+}
+
+type commandStore interface {
+	AddCommand(ctx context.Context, command *model.Command) error
+	GetCommand(ctx context.Context, id string) (*model.Command, error)
+}
+
+// Handler handles all incoming interaction requests from Slack.
+type Handler struct {
+	config            *config.ControlPlaneSlack
+	deploymentGetter  deploymentGetter
+	applicationGetter applicationGetter
+	projectGetter     projectGetter
+	commandStore      commandStore
+	httpClient        *http.Client
+	checkInterval     time.Duration
+	nowFunc           func() time.Time
+	logger            *zap.Logger
+}
+
+// NewHandler returns a handler that will be used for handling Slack interactions.
+func NewHandler(
+	cfg *config.ControlPlaneSlack,
+	deploymentGetter deploymentGetter,
+	applicationGetter applicationGetter,
+	projectGetter projectGetter,
+	commandStore commandStore,
+	logger *zap.Logger,
+) *Handler {
+	return &Handler{
+		config:            cfg,
+		deploymentGetter:  deploymentGetter,
+		applicationGetter: applicationGetter,
+		projectGetter:     projectGetter,
+		commandStore:      commandStore,
+		httpClient:        &http.Client{Timeout: 10 * time.Second},
+		checkInterval:     commandCheckInterval,
+		nowFunc:           time.Now,
+		logger:            logger.Named("slack-handler"),
+	}
+}
+
+// Register registers all handler into the specified registry.
+func (h *Handler) Register(r func(string, func(http.ResponseWriter, *http.Request))) {
+	r(interactionsPath, h.handleInteraction)
+}
+
+type interactionPayload struct {
+	Type       string `json:"type"`
+	CallbackID string `json:"callback_id"`
+	User       struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"user"`
+	Actions []struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"actions"`
+	OriginalMessage map[string]interface{} `json:"original_message"`
+	ResponseURL     string                 `json:"response_url"`
+}
+
+// handleInteraction verifies the request sent from Slack and creates
+// the command to approve or reject the stage specified in the clicked action.
+// The response is used by Slack to show that the request is being processed,
+// the original message is updated again once the command was handled by piped.
+func (h *Handler) handleInteraction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxRequestSize))
+	if err != nil {
+		http.Error(w, "Unable to read request body", http.StatusBadRequest)
+		return
+	}
+	if err := verifySignature(h.config.SigningSecret, r.Header, body, h.nowFunc()); err != nil {
+		h.logger.Warn("received an unverified request", zap.Error(err))
+		http.Error(w, "Invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	var p interactionPayload
+	if err := json.Unmarshal([]byte(form.Get("payload")), &p); err != nil {
+		http.Error(w, "Invalid payload", http.StatusBadRequest)
+		return
+	}
+	if p.Type != interactiveMessageType || p.CallbackID != model.SlackApprovalCallbackID || len(p.Actions) == 0 {
+		http.Error(w, "Unsupported interaction", http.StatusBadRequest)
+		return
+	}
+
+	msg, err := h.handleApprovalAction(r.Context(), p)
+	if err != nil {
+		// Only the user who clicked the button can see this message.
+		msg = map[string]interface{}{
+			"response_type":    "ephemeral",
+			"replace_original": false,
+			"text":             err.Error(),
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(msg); err != nil {
+		h.logger.Error("failed to write response", zap.Error(err))
+	}
+}
+
+// handleApprovalAction creates the command for the clicked action and returns the message
+// to be shown while the command is being handled.
+// The returned error is a message that can be shown to the Slack user.
+func (h *Handler) handleApprovalAction(ctx context.Context, p interactionPayload) (map[string]interface{}, error) {
+	action := p.Actions[0]
+	if action.Name != model.SlackApproveActionName && action.Name != model.SlackRejectActionName {
+		return nil, fmt.Errorf("Unsupported action %q", action.Name)
+	}
+	deploymentID, stageID, ok := model.ParseSlackApprovalValue(action.Value)
+	if !ok {
+		return nil, errors.New("Malformed action value")
+	}
+
+	deployment, err := h.deploymentGetter.GetDeployment(ctx, deploymentID)
+	if errors.Is(err, datastore.ErrNotFound) {
+		return nil, errors.New("The deployment was not found")
+	}
+	if err != nil {
+		h.logger.Error("failed to get deployment", zap.Error(err))
+		return nil, errors.New("Failed to get the deployment")
+	}
+
+	user, ok := h.config.FindUser(p.User.ID, deployment.ProjectId)
+	if !ok {
+		return nil, errors.New("Your Slack account is not linked to any PipeCD user of the project")
+	}
+
+	// Check the permission of the linked user in the same way as approving from the web.
+	project, err := h.projectGetter.GetProject(ctx, deployment.ProjectId)
+	if err != nil {
+		h.logger.Error("failed to get project", zap.Error(err))
+		return nil, errors.New("Failed to get the project")
+	}
+	role, ok := resolveRole(project, user)
+	if !ok {
+		return nil, errors.New("Your PipeCD user does not have any role in the project")
+	}
+	if !model.HasProjectRolePermission(role.ProjectRole, model.ProjectRBACRole_APPROVE) {
+		app, err := h.applicationGetter.GetApplication(ctx, deployment.ApplicationId)
+		if err != nil {
+			h.logger.Error("failed to get application", zap.Error(err))
+			return nil, errors.New("Failed to get the application")
+		}
+		if !project.Authorize(role, app.EnvId, app.Labels, model.ProjectRBACRole_APPROVE) {
+			return nil, errors.New("You don't have the APPROVE permission on this application")
+		}
+	}
+
+	var stage *model.PipelineStage
+	for _, s := range deployment.Stages {
+		if s.Id == stageID {
+			stage = s
+			break
+		}
+	}
+	if stage == nil {
+		return nil, errors.New("The stage was not found in the deployment")
+	}
+	if model.IsCompletedStage(stage.Status) {
+		return nil, errors.New("Could not handle the stage because it was already completed")
+	}
+	if !model.IsStageApprover(model.StageApprovers(stage), user.Username, user.Team) {
+		return nil, errors.New("You are not in the list of approvers of this stage")
+	}
+
+	cmd := model.Command{
+		Id:            uuid.New().String(),
+		PipedId:       deployment.PipedId,
+		ApplicationId: deployment.ApplicationId,
+		DeploymentId:  deploymentID,
+		StageId:       stageID,
+		Commander:     user.Username,
+	}
+	if action.Name == model.SlackApproveActionName {
+		cmd.Type = model.Command_APPROVE_STAGE
+		cmd.ApproveStage = &model.Command_ApproveStage{
+			DeploymentId:  deploymentID,
+			StageId:       stageID,
+			CommanderTeam: user.Team,
+		}
+	} else {
+		cmd.Type = model.Command_REJECT_STAGE
+		cmd.RejectStage = &model.Command_RejectStage{
+			DeploymentId:  deploymentID,
+			StageId:       stageID,
+			CommanderTeam: user.Team,
+			Reason:        "Rejected from Slack",
+		}
+	}
+	if err := h.commandStore.AddCommand(ctx, &cmd); err != nil {
+		h.logger.Error("failed to create command", zap.Error(err))
+		return nil, errors.New("Failed to create the command")
+	}
+
+	// Keep the buttons while more approvals are required.
+	minApproverNum, _ := strconv.Atoi(stage.Metadata[model.MinApproverNumStageMetadataKey])
+	keepActions := cmd.Type == model.Command_APPROVE_STAGE && minApproverNum > 1
+
+	result := commandResult{
+		action:      action.Name,
+		slackUserID: p.User.ID,
+		username:    user.Username,
+		keepActions: keepActions,
+	}
+	if p.ResponseURL != "" {
+		// The original message is copied since it is modified by the pending message below.
+		original := copyMessage(p.OriginalMessage)
+		go h.reportCommandResult(cmd.Id, p.ResponseURL, original, result)
+	}
+	return pendingMessage(p.OriginalMessage, result), nil
+}
+
+// resolveRole returns the role of the PipeCD user linked to a Slack user
+// in the same way as the role given at the login through SSO.
+func resolveRole(project *model.Project, user config.SlackUserMapping) (*model.Role, bool) {
+	if project.StaticAdmin != nil && project.StaticAdmin.Username == user.Username {
+		return &model.Role{
+			ProjectId:   project.Id,
+			ProjectRole: model.Role_ADMIN,
+		}, true
+	}
+	if project.Rbac == nil {
+		return nil, false
+	}
+	// Members of only the teams having scoped role bindings
+	// can act on the bound applications as same as logging in through SSO.
+	boundTeams := project.Rbac.BoundTeams([]string{user.Team})
+	projectRole, ok := project.Rbac.RoleForTeam(user.Team)
+	if !ok && len(boundTeams) == 0 {
+		return nil, false
+	}
+	return &model.Role{
+		ProjectId:   project.Id,
+		ProjectRole: projectRole,
+		Teams:       boundTeams,
+	}, true
+}
+
+type commandResult struct {
+	action      string
+	slackUserID string
+	username    string
+	keepActions bool
+}
+
+// reportCommandResult waits until the given command is handled by piped
+// and then updates the original message through the response URL given by Slack.
+func (h *Handler) reportCommandResult(commandID, responseURL string, original map[string]interface{}, result commandResult) {
+	ctx, cancel := context.WithTimeout(context.Background(), commandWaitTimeout)
+	defer cancel()
+
+	cmd, err := h.waitCommandHandled(ctx, commandID)
+	if err != nil {
+		h.logger.Warn("failed to wait for the command to be handled",
+			zap.String("command-id", commandID),
+			zap.Error(err),
+		)
+		return
+	}
+
+	var msg map[string]interface{}
+	if cmd.Status == model.CommandStatus_COMMAND_SUCCEEDED {
+		msg = updateMessage(original, result.action, result.slackUserID, result.username, result.keepActions)
+	} else {
+		msg = failedMessage(original, result, cmd.Status)
+	}
+	if err := h.respond(ctx, responseURL, msg); err != nil {
+		h.logger.Error("failed to update slack message", zap.Error(err))
+	}
+}
+
+func (h *Handler) waitCommandHandled(ctx context.Context, commandID string) (*model.Command, error) {
+	ticker := time.NewTicker(h.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		cmd, err := h.commandStore.GetCommand(ctx, commandID)
+		if err != nil {
+			return nil, err
+		}
+		if cmd.IsHandled() {
+			return cmd, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *Handler) respond(ctx context.Context, responseURL string, msg map[string]interface{}) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, responseURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// pendingMessage adds the requested action into the original message
+// and removes the buttons until the command is handled.
+func pendingMessage(msg map[string]interface{}, result commandResult) map[string]interface{} {
+	title := "Approval requested by"
+	if result.action == model.SlackRejectActionName {
+		title = "Rejection requested by"
+	}
+	return addField(msg, title, fmt.Sprintf("<@%s> (%s)", result.slackUserID, result.username), false)
+}
+
+// failedMessage adds the failure of the action into the original message.
+// The buttons are kept so that the action can be retried.
+func failedMessage(msg map[string]interface{}, result commandResult, status model.CommandStatus) map[string]interface{} {
+	title := "Failed to approve"
+	if result.action == model.SlackRejectActionName {
+		title = "Failed to reject"
+	}
+	value := fmt.Sprintf("<@%s> (%s): %s", result.slackUserID, result.username, status.String())
+	return addField(msg, title, value, true)
+}
+
+func copyMessage(msg map[string]interface{}) map[string]interface{} {
+	if msg == nil {
+		return nil
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil
+	}
+	var copied map[string]interface{}
+	if err := json.Unmarshal(data, &copied); err != nil {
+		return nil
+	}
+	return copied
+}
+
+// updateMessage adds the result of the action into the original message.
+func updateMessage(msg map[string]interface{}, action, slackUserID, username string, keepActions bool) map[string]interface{} {
+	title := "Approved by"
+	if action == model.SlackRejectActionName {
+		title = "Rejected by"
+	}
+	return addField(msg, title, fmt.Sprintf("<@%s> (%s)", slackUserID, username), keepActions)
+}
+
+// addField adds a field into the approval attachment of the given message.
+func addField(msg map[string]interface{}, title, value string, keepActions bool) map[string]interface{} {
+	field := map[string]interface{}{
+		"title": title,
+		"value": value,
+		"short": true,
+	}
+
+	if msg == nil {
+		msg = make(map[string]interface{})
+	}
+	attachments, _ := msg["attachments"].([]interface{})
+	for _, a := range attachments {
+		attachment, ok := a.(map[string]interface{})
+		if !ok || attachment["callback_id"] != model.SlackApprovalCallbackID {
+			continue
+		}
+		fields, _ := attachment["fields"].([]interface{})
+		attachment["fields"] = append(fields, field)
+		if !keepActions {
+			delete(attachment, "actions")
+		}
+	}
+	msg["replace_original"] = true
+	return msg
+}
+
+// verifySignature verifies the request signed by Slack with the signing secret.
+// https://api.slack.com/authentication/verifying-requests-from-slack
+func verifySignature(secret string, header http.Header, body []byte, now time.Time) error {
+	ts := header.Get(timestampHeader)
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp %q", ts)
+	}
+	if d := now.Sub(time.Unix(unix, 0)); d > maxRequestAge || d < -maxRequestAge {
+		return fmt.Errorf("request timestamp %q is too old", ts)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s:%s:", signatureVer, ts)
+	mac.Write(body)
+	expected := signatureVer + "=" + hex.EncodeToString(mac.Sum(nil))
+
+	if !hmac.Equal([]byte(expected), []byte(header.Get(signatureHeader))) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
@@ -0,0 +1,373 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slackhandler
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+const testSecret = "signing-secret"
+
+type fakeDeploymentGetter struct {
+	deployment *model.Deployment
+}
+
+func (g *fakeDeploymentGetter) GetDeployment(_ context.Context, id string) (*model.Deployment, error) {
+	if g.deployment == nil || g.deployment.Id != id {
+		return nil, fmt.Errorf("not found")
+	}
+	return g.deployment, nil
+}
+
+type fakeApplicationGetter struct {
+	application *model.Application
+}
+
+func (g *fakeApplicationGetter) GetApplication(_ context.Context, id string) (*model.Application, error) {
+	if g.application == nil || g.application.Id != id {
+		return nil, fmt.Errorf("not found")
+	}
+	return g.application, nil
+}
+
+type fakeProjectGetter struct {
+	project *model.Project
+}
+
+func (g *fakeProjectGetter) GetProject(_ context.Context, id string) (*model.Project, error) {
+	if g.project == nil || g.project.Id != id {
+		return nil, fmt.Errorf("not found")
+	}
+	return g.project, nil
+}
+
+type fakeCommandStore struct {
+	mu       sync.Mutex
+	commands []*model.Command
+	status   model.CommandStatus
+}
+
+func (s *fakeCommandStore) AddCommand(_ context.Context, cmd *model.Command) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.commands = append(s.commands, cmd)
+	return nil
+}
+
+func (s *fakeCommandStore) GetCommand(_ context.Context, id string) (*model.Command, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, cmd := range s.commands {
+		if cmd.Id == id {
+			return &model.Command{Id: cmd.Id, Status: s.status}, nil
+		}
+	}
+	return nil, fmt.Errorf("not found")
+}
+
+func signedRequest(t *testing.T, payload string, now time.Time) *http.Request {
+	body := url.Values{"payload": {payload}}.Encode()
+	ts := strconv.FormatInt(now.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	fmt.Fprintf(mac, "v0:%s:%s", ts, body)
+
+	req := httptest.NewRequest(http.MethodPost, interactionsPath, strings.NewReader(body))
+	req.Header.Set(timestampHeader, ts)
+	req.Header.Set(signatureHeader, "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+func TestVerifySignature(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	req := signedRequest(t, "{}", now)
+	body := []byte(url.Values{"payload": {"{}"}}.Encode())
+
+	assert.NoError(t, verifySignature(testSecret, req.Header, body, now))
+	assert.Error(t, verifySignature("wrong-secret", req.Header, body, now))
+	assert.Error(t, verifySignature(testSecret, req.Header, []byte("modified"), now))
+	assert.Error(t, verifySignature(testSecret, req.Header, body, now.Add(10*time.Minute)))
+}
+
+func TestHandleInteraction(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	newDeployment := func(minApproverNum string) *model.Deployment {
+		return &model.Deployment{
+			Id:            "deployment-1",
+			ProjectId:     "project-1",
+			PipedId:       "piped-1",
+			ApplicationId: "app-1",
+			Stages: []*model.PipelineStage{
+				{
+					Id:     "stage-1",
+					Name:   model.StageWaitApproval.String(),
+					Status: model.StageStatus_STAGE_RUNNING,
+					Metadata: map[string]string{
+						model.ApproversStageMetadataKey:      "foo,pipe-cd/sre,qux,pipe-cd/payment,pipe-cd/infra",
+						model.MinApproverNumStageMetadataKey: minApproverNum,
+					},
+				},
+			},
+		}
+	}
+	cfg := &config.ControlPlaneSlack{
+		SigningSecret: testSecret,
+		Users: []config.SlackUserMapping{
+			{SlackUserID: "U1", ProjectID: "project-1", Username: "foo"},
+			{SlackUserID: "U2", ProjectID: "project-1", Username: "bar", Team: "pipe-cd/sre"},
+			{SlackUserID: "U3", ProjectID: "project-1", Username: "baz", Team: "pipe-cd/admin"},
+			{SlackUserID: "U5", ProjectID: "project-1", Username: "qux", Team: "pipe-cd/dev"},
+			{SlackUserID: "U6", ProjectID: "project-1", Username: "quux", Team: "pipe-cd/payment"},
+			{SlackUserID: "U7", ProjectID: "project-1", Username: "corge", Team: "pipe-cd/infra"},
+		},
+	}
+	project := &model.Project{
+		Id:          "project-1",
+		StaticAdmin: &model.ProjectStaticUser{Username: "foo"},
+		Rbac: &model.ProjectRBACConfig{
+			Admin:  "pipe-cd/admin",
+			Editor: "pipe-cd/sre",
+			Viewer: "pipe-cd/dev",
+			Roles: []*model.ProjectRBACRole{
+				{
+					Name:        "approver",
+					Permissions: []model.ProjectRBACRole_Permission{model.ProjectRBACRole_APPROVE},
+				},
+			},
+			Bindings: []*model.ProjectRBACBinding{
+				{
+					Team:              "pipe-cd/payment",
+					Role:              "approver",
+					ApplicationLabels: map[string]string{"team": "payment"},
+				},
+				{
+					Team:              "pipe-cd/infra",
+					Role:              "approver",
+					ApplicationLabels: map[string]string{"team": "infra"},
+				},
+			},
+		},
+	}
+	app := &model.Application{
+		Id:     "app-1",
+		EnvId:  "env-1",
+		Labels: map[string]string{"team": "payment"},
+	}
+	makePayload := func(userID, action, responseURL string) string {
+		return fmt.Sprintf(`{
+"type": "interactive_message",
+"callback_id": "wait_approval",
+"user": {"id": %q},
+"actions": [{"name": %q, "value": "deployment-1/stage-1"}],
+"original_message": {"attachments": [{"callback_id": "wait_approval", "actions": [{"name": "approve"}]}]},
+"response_url": %q
+}`, userID, action, responseURL)
+	}
+
+	testcases := []struct {
+		name           string
+		minApproverNum string
+		userID         string
+		action         string
+		commandStatus  model.CommandStatus
+		expectedType   model.Command_Type
+		expectedTeam   string
+		expectedField  string
+		expectedResult string
+		keepActions    bool
+		expectedError  bool
+	}{
+		{
+			name:           "approved by a listed user",
+			minApproverNum: "1",
+			userID:         "U1",
+			action:         model.SlackApproveActionName,
+			commandStatus:  model.CommandStatus_COMMAND_SUCCEEDED,
+			expectedType:   model.Command_APPROVE_STAGE,
+			expectedField:  "Approval requested by",
+			expectedResult: "Approved by",
+		},
+		{
+			name:           "approved by a member of a listed team",
+			minApproverNum: "2",
+			userID:         "U2",
+			action:         model.SlackApproveActionName,
+			commandStatus:  model.CommandStatus_COMMAND_SUCCEEDED,
+			expectedType:   model.Command_APPROVE_STAGE,
+			expectedTeam:   "pipe-cd/sre",
+			expectedField:  "Approval requested by",
+			expectedResult: "Approved by",
+			keepActions:    true,
+		},
+		{
+			name:           "approved through a scoped role binding",
+			minApproverNum: "1",
+			userID:         "U6",
+			action:         model.SlackApproveActionName,
+			commandStatus:  model.CommandStatus_COMMAND_SUCCEEDED,
+			expectedType:   model.Command_APPROVE_STAGE,
+			expectedTeam:   "pipe-cd/payment",
+			expectedField:  "Approval requested by",
+			expectedResult: "Approved by",
+		},
+		{
+			name:           "rejected",
+			minApproverNum: "2",
+			userID:         "U1",
+			action:         model.SlackRejectActionName,
+			commandStatus:  model.CommandStatus_COMMAND_SUCCEEDED,
+			expectedType:   model.Command_REJECT_STAGE,
+			expectedField:  "Rejection requested by",
+			expectedResult: "Rejected by",
+		},
+		{
+			name:           "failed to be handled by piped",
+			minApproverNum: "1",
+			userID:         "U1",
+			action:         model.SlackApproveActionName,
+			commandStatus:  model.CommandStatus_COMMAND_FAILED,
+			expectedType:   model.Command_APPROVE_STAGE,
+			expectedField:  "Approval requested by",
+			expectedResult: "Failed to approve",
+			keepActions:    true,
+		},
+		{
+			name:           "not an approver",
+			minApproverNum: "1",
+			userID:         "U3",
+			action:         model.SlackApproveActionName,
+			expectedError:  true,
+		},
+		{
+			name:           "viewer is not allowed",
+			minApproverNum: "1",
+			userID:         "U5",
+			action:         model.SlackApproveActionName,
+			expectedError:  true,
+		},
+		{
+			name:           "scoped role binding not matching the application",
+			minApproverNum: "1",
+			userID:         "U7",
+			action:         model.SlackApproveActionName,
+			expectedError:  true,
+		},
+		{
+			name:           "unknown slack user",
+			minApproverNum: "1",
+			userID:         "U4",
+			action:         model.SlackApproveActionName,
+			expectedError:  true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			responses := make(chan map[string]interface{}, 1)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var msg map[string]interface{}
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+				responses <- msg
+			}))
+			defer server.Close()
+
+			store := &fakeCommandStore{status: tc.commandStatus}
+			h := NewHandler(
+				cfg,
+				&fakeDeploymentGetter{deployment: newDeployment(tc.minApproverNum)},
+				&fakeApplicationGetter{application: app},
+				&fakeProjectGetter{project: project},
+				store,
+				zap.NewNop(),
+			)
+			h.nowFunc = func() time.Time { return now }
+			h.checkInterval = time.Millisecond
+
+			rec := httptest.NewRecorder()
+			h.handleInteraction(rec, signedRequest(t, makePayload(tc.userID, tc.action, server.URL), now))
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			var resp map[string]interface{}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+
+			if tc.expectedError {
+				assert.Empty(t, store.commands)
+				assert.Equal(t, "ephemeral", resp["response_type"])
+				return
+			}
+			require.Len(t, store.commands, 1)
+			cmd := store.commands[0]
+			assert.Equal(t, tc.expectedType, cmd.Type)
+			assert.Equal(t, "deployment-1", cmd.DeploymentId)
+			assert.Equal(t, "stage-1", cmd.StageId)
+			assert.Equal(t, "piped-1", cmd.PipedId)
+			if tc.expectedType == model.Command_APPROVE_STAGE {
+				assert.Equal(t, tc.expectedTeam, cmd.ApproveStage.CommanderTeam)
+			}
+
+			// The buttons are removed until the command is handled.
+			assert.Equal(t, true, resp["replace_original"])
+			attachment := resp["attachments"].([]interface{})[0].(map[string]interface{})
+			fields := attachment["fields"].([]interface{})
+			require.Len(t, fields, 1)
+			assert.Equal(t, tc.expectedField, fields[0].(map[string]interface{})["title"])
+			_, hasActions := attachment["actions"]
+			assert.False(t, hasActions)
+
+			// The message is updated again with the result of the command.
+			var result map[string]interface{}
+			select {
+			case result = <-responses:
+			case <-time.After(5 * time.Second):
+				require.Fail(t, "the result of the command was not reported")
+			}
+			attachment = result["attachments"].([]interface{})[0].(map[string]interface{})
+			fields = attachment["fields"].([]interface{})
+			require.Len(t, fields, 1)
+			assert.Equal(t, tc.expectedResult, fields[0].(map[string]interface{})["title"])
+			_, hasActions = attachment["actions"]
+			assert.Equal(t, tc.keepActions, hasActions)
+		})
+	}
+}
+
+func TestHandleInteractionInvalidSignature(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	cfg := &config.ControlPlaneSlack{SigningSecret: "another-secret"}
+	h := NewHandler(cfg, &fakeDeploymentGetter{}, &fakeApplicationGetter{}, &fakeProjectGetter{}, &fakeCommandStore{}, zap.NewNop())
+	h.nowFunc = func() time.Time { return now }
+
+	rec := httptest.NewRecorder()
+	h.handleInteraction(rec, signedRequest(t, "{}", now))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
@@ -84,6 +84,23 @@ func (f *stageLogFileStore) Put(ctx context.Context, deploymentID, stageID strin
 	return f.filestore.PutObject(ctx, path, buf.Bytes())
 }
 
+func (f *stageLogFileStore) DeleteAll(ctx context.Context, deploymentID string) error {
+	objects, err := f.filestore.ListObjects(ctx, deploymentLogPrefix(deploymentID))
+	if err != nil {
+		return err
+	}
+	for _, obj := range objects {
+		if err := f.filestore.DeleteObject(ctx, obj.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func deploymentLogPrefix(deploymentID string) string {
+	return fmt.Sprintf("log/%s/", deploymentID)
+}
+
 func stageLogPath(deploymentID, stageID string, retriedCount int32) string {
 	return fmt.Sprintf("log/%s/%s/%d.txt", deploymentID, stageID, retriedCount)
 }
@@ -17,6 +17,7 @@ package stagelogstore
 import (
 	"context"
 	"errors"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -43,12 +44,26 @@ type Store interface {
 	// AppendLogsFromLastCheckpoint appends the stage logs. The stage logs are deduplicated with index value.
 	// If completed is true, flush all the logs to that point and cannot append it after this.
 	AppendLogsFromLastCheckpoint(ctx context.Context, deploymentID, stageID string, retriedCount int32, newBlocks []*model.LogBlock, completed bool) error
+	// StreamLogs calls the handler with the stage logs from offsetIndex and then with every newly appended block
+	// until the stage log has been completed or the context is done.
+	// The logs appended through any replica are delivered since they are shared via the cache.
+	StreamLogs(ctx context.Context, deploymentID, stageID string, retriedCount int32, offsetIndex int64, handler StreamHandler) error
+	// DeleteDeploymentLogs deletes the logs of all stages of the specified deployment.
+	DeleteDeploymentLogs(ctx context.Context, deploymentID string) error
 }
 
+const (
+	defaultStreamCheckInterval = 2 * time.Second
+	// The maximum interval to check the filestore for a stage log missing in the cache.
+	maxStreamCheckInterval = 30 * time.Second
+)
+
 type store struct {
-	backend *stageLogFileStore
-	cache   *stageLogCache
-	logger  *zap.Logger
+	backend             *stageLogFileStore
+	cache               *stageLogCache
+	subscriptions       subscriptions
+	streamCheckInterval time.Duration
+	logger              *zap.Logger
 }
 
 func NewStore(fs filestore.Store, c cache.Cache, logger *zap.Logger) Store {
@@ -59,7 +74,8 @@ func NewStore(fs filestore.Store, c cache.Cache, logger *zap.Logger) Store {
 		cache: &stageLogCache{
 			cache: c,
 		},
-		logger: logger.Named("stage-log-store"),
+		streamCheckInterval: defaultStreamCheckInterval,
+		logger:              logger.Named("stage-log-store"),
 	}
 }
 
@@ -108,6 +124,7 @@ func (s *store) AppendLogs(ctx context.Context, deploymentID, stageID string, re
 	if err := s.cache.Put(deploymentID, stageID, retriedCount, &lf); err != nil {
 		s.logger.Error("failed to put stage log to cache", zap.Error(err))
 	}
+	s.subscriptions.notify(cacheKey(deploymentID, stageID, retriedCount))
 	return nil
 }
 
@@ -133,6 +150,16 @@ func (s *store) AppendLogsFromLastCheckpoint(ctx context.Context, deploymentID,
 	if err := s.cache.Put(deploymentID, stageID, retriedCount, &lf); err != nil {
 		s.logger.Error("failed to put stage log to cache", zap.Error(err))
 	}
+	s.subscriptions.notify(cacheKey(deploymentID, stageID, retriedCount))
+	return nil
+}
+
+func (s *store) DeleteDeploymentLogs(ctx context.Context, deploymentID string) error {
+	// The cached logs are removed by their TTL.
+	if err := s.backend.DeleteAll(ctx, deploymentID); err != nil {
+		s.logger.Error("failed to delete stage logs from filestore", zap.Error(err))
+		return err
+	}
 	return nil
 }
 
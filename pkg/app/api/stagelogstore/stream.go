@@ -0,0 +1,177 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stagelogstore
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+// StreamHandler is called with every new part of a stage log.
+type StreamHandler func(blocks []*model.LogBlock, completed bool) error
+
+// subscriptions notifies the local streams about the stage logs appended by this replica.
+// Since the appends handled by the other replicas can be seen only through the shared cache,
+// a single poller per stage log is run while it has any local stream.
+type subscriptions struct {
+	mu   sync.Mutex
+	subs map[string]*subscription
+}
+
+type subscription struct {
+	chs        map[chan struct{}]struct{}
+	stopPoller func()
+}
+
+// subscribe registers a new local stream of the given key.
+// The poll function is started with the first stream and stopped after the last one unsubscribed.
+func (s *subscriptions) subscribe(key string, poll func(ctx context.Context, notify func())) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.subs == nil {
+		s.subs = make(map[string]*subscription)
+	}
+	sub, ok := s.subs[key]
+	if !ok {
+		ctx, cancel := context.WithCancel(context.Background())
+		sub = &subscription{
+			chs:        make(map[chan struct{}]struct{}),
+			stopPoller: cancel,
+		}
+		s.subs[key] = sub
+		go poll(ctx, func() { s.notify(key) })
+	}
+	sub.chs[ch] = struct{}{}
+
+	unsubscribe := func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(sub.chs, ch)
+		if len(sub.chs) == 0 {
+			sub.stopPoller()
+			delete(s.subs, key)
+		}
+	}
+	return ch, unsubscribe
+}
+
+func (s *subscriptions) notify(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sub, ok := s.subs[key]
+	if !ok {
+		return
+	}
+	for ch := range sub.chs {
+		// The subscriber always fetches all logs from its offset,
+		// so there is no need to queue more than one notification.
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (s *store) StreamLogs(ctx context.Context, deploymentID, stageID string, retriedCount int32, offsetIndex int64, handler StreamHandler) error {
+	ch, unsubscribe := s.subscriptions.subscribe(cacheKey(deploymentID, stageID, retriedCount), func(ctx context.Context, notify func()) {
+		s.pollLogs(ctx, deploymentID, stageID, retriedCount, notify)
+	})
+	defer unsubscribe()
+
+	for {
+		blocks, completed, err := s.FetchLogs(ctx, deploymentID, stageID, retriedCount, offsetIndex)
+		// The stage may not have reported any log yet.
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+		if len(blocks) > 0 || completed {
+			if err := handler(blocks, completed); err != nil {
+				return err
+			}
+		}
+		if completed {
+			return nil
+		}
+		for _, b := range blocks {
+			if b.Index >= offsetIndex {
+				offsetIndex = b.Index + 1
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ch:
+		}
+	}
+}
+
+// pollLogs periodically checks the stage log shared through the cache
+// and notifies the local streams once it was changed.
+// The filestore, which receives the log only at the checkpoints and the completion,
+// is checked only while the log is missing in the cache and with an increasing interval.
+func (s *store) pollLogs(ctx context.Context, deploymentID, stageID string, retriedCount int32, notify func()) {
+	var (
+		lastBlocks      = -1
+		lastCompleted   bool
+		missingInterval = s.streamCheckInterval
+	)
+	timer := time.NewTimer(s.streamCheckInterval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		interval := s.streamCheckInterval
+		lf, err := s.cache.Get(deploymentID, stageID, retriedCount)
+		if err != nil {
+			// The log has not been reported yet or was already evicted from the cache.
+			if lf, err = s.backend.Get(ctx, deploymentID, stageID, retriedCount); err == nil {
+				if e := s.cache.Put(deploymentID, stageID, retriedCount, &lf); e != nil {
+					s.logger.Error("failed to put stage log to cache", zap.Error(e))
+				}
+			} else {
+				missingInterval *= 2
+				if missingInterval > maxStreamCheckInterval {
+					missingInterval = maxStreamCheckInterval
+				}
+				interval = missingInterval
+			}
+		}
+		if err == nil {
+			missingInterval = s.streamCheckInterval
+			if len(lf.Blocks) != lastBlocks || lf.Completed != lastCompleted {
+				lastBlocks, lastCompleted = len(lf.Blocks), lf.Completed
+				notify()
+			}
+			if lf.Completed {
+				return
+			}
+		}
+		timer.Reset(interval)
+	}
+}
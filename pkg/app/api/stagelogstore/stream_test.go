@@ -0,0 +1,155 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stagelogstore
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/pipe-cd/pipe/pkg/cache/memorycache"
+	"github.com/pipe-cd/pipe/pkg/filestore"
+	"github.com/pipe-cd/pipe/pkg/filestore/filestoretest"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+func TestStreamLogs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fs := filestoretest.NewMockStore(ctrl)
+	fs.EXPECT().NewReader(gomock.Any(), gomock.Any()).Return(nil, filestore.ErrNotFound).AnyTimes()
+
+	s := NewStore(fs, memorycache.NewCache(), zap.NewNop()).(*store)
+	s.streamCheckInterval = 10 * time.Millisecond
+
+	var (
+		mu        sync.Mutex
+		received  []int64
+		completed bool
+		doneCh    = make(chan error, 1)
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	go func() {
+		doneCh <- s.StreamLogs(ctx, "deployment", "stage", 0, 2, func(blocks []*model.LogBlock, c bool) error {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, b := range blocks {
+				received = append(received, b.Index)
+			}
+			completed = c
+			return nil
+		})
+	}()
+
+	// Appended through this replica.
+	err := s.AppendLogs(ctx, "deployment", "stage", 0, []*model.LogBlock{
+		{Index: 1, Log: "log-1"},
+		{Index: 2, Log: "log-2"},
+	})
+	require.NoError(t, err)
+	err = s.AppendLogs(ctx, "deployment", "stage", 0, []*model.LogBlock{
+		{Index: 3, Log: "log-3"},
+	})
+	require.NoError(t, err)
+
+	// Appended through another replica sharing the same cache.
+	err = s.cache.Put("deployment", "stage", 0, &logFragment{
+		Blocks: []*model.LogBlock{
+			{Index: 1, Log: "log-1"},
+			{Index: 2, Log: "log-2"},
+			{Index: 3, Log: "log-3"},
+			{Index: 4, Log: "log-4"},
+		},
+		Completed: true,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, <-doneCh)
+	assert.Equal(t, []int64{2, 3, 4}, received)
+	assert.True(t, completed)
+}
+
+func TestStreamLogsCancelled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fs := filestoretest.NewMockStore(ctrl)
+	fs.EXPECT().NewReader(gomock.Any(), gomock.Any()).Return(nil, filestore.ErrNotFound).AnyTimes()
+
+	s := NewStore(fs, memorycache.NewCache(), zap.NewNop()).(*store)
+	s.streamCheckInterval = 10 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := s.StreamLogs(ctx, "deployment", "stage", 0, 0, func([]*model.LogBlock, bool) error {
+		t.Fatal("handler must not be called without any log")
+		return nil
+	})
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Empty(t, s.subscriptions.subs)
+}
+
+func TestStreamLogsSharedPoller(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		mu    sync.Mutex
+		reads int
+	)
+	fs := filestoretest.NewMockStore(ctrl)
+	fs.EXPECT().NewReader(gomock.Any(), gomock.Any()).DoAndReturn(func(context.Context, string) (io.ReadCloser, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		reads++
+		return nil, filestore.ErrNotFound
+	}).AnyTimes()
+
+	s := NewStore(fs, memorycache.NewCache(), zap.NewNop()).(*store)
+	s.streamCheckInterval = 10 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.StreamLogs(ctx, "deployment", "stage", 0, 0, func([]*model.LogBlock, bool) error {
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+
+	// Each stream reads the filestore once at the beginning,
+	// then only the shared poller reads it with an increasing interval.
+	mu.Lock()
+	defer mu.Unlock()
+	assert.LessOrEqual(t, reads, 5+6)
+	assert.Empty(t, s.subscriptions.subs)
+}
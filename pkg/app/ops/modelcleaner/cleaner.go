@@ -12,4 +12,310 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
+// Package modelcleaner provides a component that periodically removes
+// the data related to the deleted applications and pipeds,
+// as well as the expired outputs of the handled commands and artifacts of the completed deployments.
+// It also fails the deployment chains whose triggered applications were never deployed.
 package modelcleaner
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/pipe-cd/pipe/pkg/app/api/applicationlivestatestore"
+	"github.com/pipe-cd/pipe/pkg/app/api/commandoutputstore"
+	"github.com/pipe-cd/pipe/pkg/app/api/deploymentartifactstore"
+	"github.com/pipe-cd/pipe/pkg/app/api/stagelogstore"
+	"github.com/pipe-cd/pipe/pkg/datastore"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+const (
+	interval = 30 * time.Minute
+	// The related data of a deleted model are kept for a while
+	// to give the pipeds a chance to handle the remaining commands
+	// such as pruning the application resources.
+	cleanupDelay = 24 * time.Hour
+)
+
+type Cleaner struct {
+	applicationStore          datastore.ApplicationStore
+	pipedStore                datastore.PipedStore
+	deploymentStore           datastore.DeploymentStore
+	commandStore              datastore.CommandStore
+	deploymentChainStore      datastore.DeploymentChainStore
+	stageLogStore             stagelogstore.Store
+	applicationLiveStateStore applicationlivestatestore.Store
+	commandOutputStore        commandoutputstore.Store
+	deploymentArtifactStore   deploymentartifactstore.Store
+
+	// The deletion time of the last cleaned models.
+	// Zero means all deleted models will be checked.
+	lastAppDeletedAt   int64
+	lastPipedDeletedAt int64
+
+	nowFunc func() time.Time
+	logger  *zap.Logger
+}
+
+func NewCleaner(
+	ds datastore.DataStore,
+	sls stagelogstore.Store,
+	alss applicationlivestatestore.Store,
+	cos commandoutputstore.Store,
+	das deploymentartifactstore.Store,
+	logger *zap.Logger,
+) *Cleaner {
+	return &Cleaner{
+		applicationStore:          datastore.NewApplicationStore(ds),
+		pipedStore:                datastore.NewPipedStore(ds),
+		deploymentStore:           datastore.NewDeploymentStore(ds),
+		commandStore:              datastore.NewCommandStore(ds),
+		deploymentChainStore:      datastore.NewDeploymentChainStore(ds),
+		stageLogStore:             sls,
+		applicationLiveStateStore: alss,
+		commandOutputStore:        cos,
+		deploymentArtifactStore:   das,
+		nowFunc:                   time.Now,
+		logger:                    logger.Named("model-cleaner"),
+	}
+}
+
+func (c *Cleaner) Run(ctx context.Context) error {
+	c.logger.Info("start running model cleaner")
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			c.logger.Info("model cleaner has been stopped")
+			return nil
+		case <-ticker.C:
+			c.clean(ctx)
+		}
+	}
+}
+
+func (c *Cleaner) clean(ctx context.Context) {
+	maxDeletedAt := c.nowFunc().Add(-cleanupDelay).Unix()
+
+	if err := c.cleanApplications(ctx, maxDeletedAt); err != nil {
+		c.logger.Error("failed to clean deleted applications", zap.Error(err))
+	}
+	if err := c.cleanPipeds(ctx, maxDeletedAt); err != nil {
+		c.logger.Error("failed to clean deleted pipeds", zap.Error(err))
+	}
+	if err := c.cleanCommandOutputs(ctx); err != nil {
+		c.logger.Error("failed to clean expired command outputs", zap.Error(err))
+	}
+	if err := c.cleanDeploymentArtifacts(ctx); err != nil {
+		c.logger.Error("failed to clean expired deployment artifacts", zap.Error(err))
+	}
+	if err := c.timeoutDeploymentChains(ctx); err != nil {
+		c.logger.Error("failed to time out deployment chains", zap.Error(err))
+	}
+}
+
+// timeoutDeploymentChains updates the status of the running chains
+// having a node whose deployment was not created within model.ChainNodeStartTimeout.
+func (c *Cleaner) timeoutDeploymentChains(ctx context.Context) error {
+	chains, err := c.deploymentChainStore.ListDeploymentChains(ctx, datastore.ListOptions{
+		Filters: []datastore.ListFilter{
+			{
+				Field:    "CompletedAt",
+				Operator: "==",
+				Value:    0,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	now := c.nowFunc()
+	minTriggeredAt := now.Add(-model.ChainNodeStartTimeout).Unix()
+	for _, chain := range chains {
+		if !hasNodeTriggeredBefore(chain, minTriggeredAt) {
+			continue
+		}
+		err := c.deploymentChainStore.UpdateDeploymentChain(ctx, chain.Id, func(dc *model.DeploymentChain) error {
+			dc.UpdateStatus(now.Unix())
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		c.logger.Info("updated the status of timed out deployment chain", zap.String("deployment-chain-id", chain.Id))
+	}
+	return nil
+}
+
+func hasNodeTriggeredBefore(chain *model.DeploymentChain, t int64) bool {
+	for _, b := range chain.Blocks {
+		for _, n := range b.Nodes {
+			if n.DeploymentId == "" && n.TriggeredAt > 0 && n.TriggeredAt <= t {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// cleanCommandOutputs deletes the outputs of the commands
+// that were handled before the output TTL or have already been deleted.
+func (c *Cleaner) cleanCommandOutputs(ctx context.Context) error {
+	ids, err := c.commandOutputStore.ListCommandIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	minHandledAt := c.nowFunc().Add(-commandoutputstore.OutputTTL).Unix()
+	for _, id := range ids {
+		cmd, err := c.commandStore.GetCommand(ctx, id)
+		if err != nil && !errors.Is(err, datastore.ErrNotFound) {
+			return err
+		}
+		// The output is saved right before marking the command as handled.
+		if cmd != nil && (!cmd.IsHandled() || cmd.HandledAt >= minHandledAt) {
+			continue
+		}
+		if err := c.commandOutputStore.DeleteOutput(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cleanDeploymentArtifacts deletes the artifacts of the deployments
+// that were completed before the artifact TTL or have already been deleted.
+func (c *Cleaner) cleanDeploymentArtifacts(ctx context.Context) error {
+	ids, err := c.deploymentArtifactStore.ListDeploymentIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	minCompletedAt := c.nowFunc().Add(-deploymentartifactstore.ArtifactTTL).Unix()
+	for _, id := range ids {
+		d, err := c.deploymentStore.GetDeployment(ctx, id)
+		if err != nil && !errors.Is(err, datastore.ErrNotFound) {
+			return err
+		}
+		if d != nil && (!model.IsCompletedDeployment(d.Status) || d.CompletedAt >= minCompletedAt) {
+			continue
+		}
+		if err := c.deploymentArtifactStore.DeleteArtifacts(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Cleaner) cleanApplications(ctx context.Context, maxDeletedAt int64) error {
+	apps, err := c.applicationStore.ListApplications(ctx, datastore.ListOptions{
+		Filters: deletedModelFilters(c.lastAppDeletedAt, maxDeletedAt),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, app := range apps {
+		if err := c.cleanApplication(ctx, app); err != nil {
+			return err
+		}
+	}
+	c.lastAppDeletedAt = maxDeletedAt
+	return nil
+}
+
+func (c *Cleaner) cleanApplication(ctx context.Context, app *model.Application) error {
+	deployments, err := c.deploymentStore.ListDeployments(ctx, datastore.ListOptions{
+		Filters: []datastore.ListFilter{
+			{
+				Field:    "ApplicationId",
+				Operator: "==",
+				Value:    app.Id,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	for _, d := range deployments {
+		if err := c.stageLogStore.DeleteDeploymentLogs(ctx, d.Id); err != nil {
+			return err
+		}
+	}
+
+	if err := c.applicationLiveStateStore.DeleteStateSnapshot(ctx, app.Id); err != nil {
+		return err
+	}
+
+	if err := c.deleteCommands(ctx, "ApplicationId", app.Id); err != nil {
+		return err
+	}
+	c.logger.Info("cleaned up the data of deleted application", zap.String("application-id", app.Id))
+	return nil
+}
+
+func (c *Cleaner) cleanPipeds(ctx context.Context, maxDeletedAt int64) error {
+	pipeds, err := c.pipedStore.ListPipeds(ctx, datastore.ListOptions{
+		Filters: deletedModelFilters(c.lastPipedDeletedAt, maxDeletedAt),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, p := range pipeds {
+		if err := c.deleteCommands(ctx, "PipedId", p.Id); err != nil {
+			return err
+		}
+		c.logger.Info("cleaned up the data of deleted piped", zap.String("piped-id", p.Id))
+	}
+	c.lastPipedDeletedAt = maxDeletedAt
+	return nil
+}
+
+func (c *Cleaner) deleteCommands(ctx context.Context, field, value string) error {
+	cmds, err := c.commandStore.ListCommands(ctx, datastore.ListOptions{
+		Filters: []datastore.ListFilter{
+			{
+				Field:    field,
+				Operator: "==",
+				Value:    value,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	for _, cmd := range cmds {
+		if err := c.commandStore.DeleteCommand(ctx, cmd.Id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func deletedModelFilters(minDeletedAt, maxDeletedAt int64) []datastore.ListFilter {
+	return []datastore.ListFilter{
+		{
+			Field:    "Deleted",
+			Operator: "==",
+			Value:    true,
+		},
+		{
+			Field:    "DeletedAt",
+			Operator: ">",
+			Value:    minDeletedAt,
+		},
+		{
+			Field:    "DeletedAt",
+			Operator: "<=",
+			Value:    maxDeletedAt,
+		},
+	}
+}
@@ -0,0 +1,357 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modelcleaner
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/pipe-cd/pipe/pkg/app/api/applicationlivestatestore"
+	"github.com/pipe-cd/pipe/pkg/app/api/commandoutputstore"
+	"github.com/pipe-cd/pipe/pkg/app/api/deploymentartifactstore"
+	"github.com/pipe-cd/pipe/pkg/app/api/stagelogstore"
+	"github.com/pipe-cd/pipe/pkg/datastore"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+type fakeApplicationStore struct {
+	datastore.ApplicationStore
+	apps []*model.Application
+}
+
+func (s *fakeApplicationStore) ListApplications(_ context.Context, _ datastore.ListOptions) ([]*model.Application, error) {
+	return s.apps, nil
+}
+
+type fakePipedStore struct {
+	datastore.PipedStore
+	pipeds []*model.Piped
+}
+
+func (s *fakePipedStore) ListPipeds(_ context.Context, _ datastore.ListOptions) ([]*model.Piped, error) {
+	return s.pipeds, nil
+}
+
+type fakeDeploymentStore struct {
+	datastore.DeploymentStore
+	deployments []*model.Deployment
+}
+
+func (s *fakeDeploymentStore) ListDeployments(_ context.Context, opts datastore.ListOptions) ([]*model.Deployment, error) {
+	var list []*model.Deployment
+	for _, d := range s.deployments {
+		if matchFilters(opts.Filters, map[string]string{"ApplicationId": d.ApplicationId}) {
+			list = append(list, d)
+		}
+	}
+	return list, nil
+}
+
+func (s *fakeDeploymentStore) GetDeployment(_ context.Context, id string) (*model.Deployment, error) {
+	for _, d := range s.deployments {
+		if d.Id == id {
+			return d, nil
+		}
+	}
+	return nil, datastore.ErrNotFound
+}
+
+type fakeCommandStore struct {
+	datastore.CommandStore
+	commands map[string]*model.Command
+}
+
+func (s *fakeCommandStore) ListCommands(_ context.Context, opts datastore.ListOptions) ([]*model.Command, error) {
+	var list []*model.Command
+	for _, c := range s.commands {
+		if matchFilters(opts.Filters, map[string]string{"ApplicationId": c.ApplicationId, "PipedId": c.PipedId}) {
+			list = append(list, c)
+		}
+	}
+	return list, nil
+}
+
+func (s *fakeCommandStore) GetCommand(_ context.Context, id string) (*model.Command, error) {
+	c, ok := s.commands[id]
+	if !ok {
+		return nil, datastore.ErrNotFound
+	}
+	return c, nil
+}
+
+func (s *fakeCommandStore) DeleteCommand(_ context.Context, id string) error {
+	delete(s.commands, id)
+	return nil
+}
+
+type fakeDeploymentChainStore struct {
+	datastore.DeploymentChainStore
+	chains  map[string]*model.DeploymentChain
+	updated []string
+}
+
+func (s *fakeDeploymentChainStore) ListDeploymentChains(_ context.Context, _ datastore.ListOptions) ([]*model.DeploymentChain, error) {
+	var list []*model.DeploymentChain
+	for _, c := range s.chains {
+		if c.CompletedAt == 0 {
+			list = append(list, c)
+		}
+	}
+	return list, nil
+}
+
+func (s *fakeDeploymentChainStore) UpdateDeploymentChain(_ context.Context, id string, updater func(*model.DeploymentChain) error) error {
+	s.updated = append(s.updated, id)
+	return updater(s.chains[id])
+}
+
+type fakeStageLogStore struct {
+	stagelogstore.Store
+	deleted []string
+}
+
+func (s *fakeStageLogStore) DeleteDeploymentLogs(_ context.Context, deploymentID string) error {
+	s.deleted = append(s.deleted, deploymentID)
+	return nil
+}
+
+type fakeApplicationLiveStateStore struct {
+	applicationlivestatestore.Store
+	deleted []string
+}
+
+func (s *fakeApplicationLiveStateStore) DeleteStateSnapshot(_ context.Context, appID string) error {
+	s.deleted = append(s.deleted, appID)
+	return nil
+}
+
+type fakeCommandOutputStore struct {
+	commandoutputstore.Store
+	ids []string
+}
+
+func (s *fakeCommandOutputStore) ListCommandIDs(_ context.Context) ([]string, error) {
+	return s.ids, nil
+}
+
+func (s *fakeCommandOutputStore) DeleteOutput(_ context.Context, id string) error {
+	for i := range s.ids {
+		if s.ids[i] == id {
+			s.ids = append(s.ids[:i], s.ids[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+type fakeDeploymentArtifactStore struct {
+	deploymentartifactstore.Store
+	ids []string
+}
+
+func (s *fakeDeploymentArtifactStore) ListDeploymentIDs(_ context.Context) ([]string, error) {
+	return s.ids, nil
+}
+
+func (s *fakeDeploymentArtifactStore) DeleteArtifacts(_ context.Context, id string) error {
+	for i := range s.ids {
+		if s.ids[i] == id {
+			s.ids = append(s.ids[:i], s.ids[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func matchFilters(filters []datastore.ListFilter, fields map[string]string) bool {
+	for _, f := range filters {
+		v, ok := fields[f.Field]
+		if ok && f.Operator == "==" && v != f.Value {
+			return false
+		}
+	}
+	return true
+}
+
+func commandIDs(s *fakeCommandStore) []string {
+	ids := make([]string, 0, len(s.commands))
+	for id := range s.commands {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestCleanApplications(t *testing.T) {
+	var (
+		deploymentStore = &fakeDeploymentStore{
+			deployments: []*model.Deployment{
+				{Id: "deployment-1", ApplicationId: "app-1"},
+				{Id: "deployment-2", ApplicationId: "app-1"},
+				{Id: "deployment-3", ApplicationId: "app-2"},
+			},
+		}
+		commandStore = &fakeCommandStore{
+			commands: map[string]*model.Command{
+				"command-1": {Id: "command-1", ApplicationId: "app-1", PipedId: "piped-1"},
+				"command-2": {Id: "command-2", ApplicationId: "app-2", PipedId: "piped-1"},
+				"command-3": {Id: "command-3", PipedId: "piped-2"},
+			},
+		}
+		stageLogStore  = &fakeStageLogStore{}
+		liveStateStore = &fakeApplicationLiveStateStore{}
+		now            = time.Unix(1600000000, 0)
+	)
+	c := &Cleaner{
+		applicationStore: &fakeApplicationStore{
+			apps: []*model.Application{{Id: "app-1", Deleted: true}},
+		},
+		pipedStore: &fakePipedStore{
+			pipeds: []*model.Piped{{Id: "piped-2", Deleted: true}},
+		},
+		deploymentStore:           deploymentStore,
+		commandStore:              commandStore,
+		deploymentChainStore:      &fakeDeploymentChainStore{},
+		stageLogStore:             stageLogStore,
+		applicationLiveStateStore: liveStateStore,
+		commandOutputStore:        &fakeCommandOutputStore{},
+		deploymentArtifactStore:   &fakeDeploymentArtifactStore{},
+		nowFunc:                   func() time.Time { return now },
+		logger:                    zap.NewNop(),
+	}
+
+	c.clean(context.Background())
+
+	assert.Equal(t, []string{"deployment-1", "deployment-2"}, stageLogStore.deleted)
+	assert.Equal(t, []string{"app-1"}, liveStateStore.deleted)
+	assert.Equal(t, []string{"command-2"}, commandIDs(commandStore))
+
+	maxDeletedAt := now.Add(-cleanupDelay).Unix()
+	assert.Equal(t, maxDeletedAt, c.lastAppDeletedAt)
+	assert.Equal(t, maxDeletedAt, c.lastPipedDeletedAt)
+}
+
+func TestCleanCommandOutputs(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	expired := now.Add(-commandoutputstore.OutputTTL - time.Minute).Unix()
+	recent := now.Add(-time.Minute).Unix()
+
+	outputStore := &fakeCommandOutputStore{
+		ids: []string{"expired", "recent", "unhandled", "deleted"},
+	}
+	c := &Cleaner{
+		commandStore: &fakeCommandStore{
+			commands: map[string]*model.Command{
+				"expired":   {Id: "expired", Status: model.CommandStatus_COMMAND_SUCCEEDED, HandledAt: expired},
+				"recent":    {Id: "recent", Status: model.CommandStatus_COMMAND_FAILED, HandledAt: recent},
+				"unhandled": {Id: "unhandled", Status: model.CommandStatus_COMMAND_NOT_HANDLED_YET},
+			},
+		},
+		commandOutputStore: outputStore,
+		nowFunc:            func() time.Time { return now },
+		logger:             zap.NewNop(),
+	}
+
+	require.NoError(t, c.cleanCommandOutputs(context.Background()))
+	assert.Equal(t, []string{"recent", "unhandled"}, outputStore.ids)
+}
+
+func TestCleanDeploymentArtifacts(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	expired := now.Add(-deploymentartifactstore.ArtifactTTL - time.Minute).Unix()
+	recent := now.Add(-time.Minute).Unix()
+
+	artifactStore := &fakeDeploymentArtifactStore{
+		ids: []string{"expired", "recent", "running", "deleted"},
+	}
+	c := &Cleaner{
+		deploymentStore: &fakeDeploymentStore{
+			deployments: []*model.Deployment{
+				{Id: "expired", Status: model.DeploymentStatus_DEPLOYMENT_SUCCESS, CompletedAt: expired},
+				{Id: "recent", Status: model.DeploymentStatus_DEPLOYMENT_FAILURE, CompletedAt: recent},
+				{Id: "running", Status: model.DeploymentStatus_DEPLOYMENT_RUNNING},
+			},
+		},
+		deploymentArtifactStore: artifactStore,
+		nowFunc:                 func() time.Time { return now },
+		logger:                  zap.NewNop(),
+	}
+
+	require.NoError(t, c.cleanDeploymentArtifacts(context.Background()))
+	assert.Equal(t, []string{"recent", "running"}, artifactStore.ids)
+}
+
+func TestTimeoutDeploymentChains(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	timedOut := now.Add(-model.ChainNodeStartTimeout - time.Minute).Unix()
+	recent := now.Add(-time.Minute).Unix()
+
+	newChain := func(id string, node *model.ChainNode) *model.DeploymentChain {
+		return &model.DeploymentChain{
+			Id:     id,
+			Status: model.ChainStatus_DEPLOYMENT_CHAIN_RUNNING,
+			Blocks: []*model.ChainBlock{
+				{
+					Nodes: []*model.ChainNode{
+						{
+							ApplicationId:    "app-1",
+							DeploymentId:     "deployment-1",
+							DeploymentStatus: model.DeploymentStatus_DEPLOYMENT_SUCCESS,
+						},
+					},
+					Status: model.ChainStatus_DEPLOYMENT_CHAIN_SUCCESS,
+				},
+				{
+					Nodes:  []*model.ChainNode{node},
+					Status: model.ChainStatus_DEPLOYMENT_CHAIN_RUNNING,
+				},
+			},
+		}
+	}
+	store := &fakeDeploymentChainStore{
+		chains: map[string]*model.DeploymentChain{
+			"timed-out": newChain("timed-out", &model.ChainNode{
+				ApplicationId: "app-2",
+				TriggeredAt:   timedOut,
+			}),
+			"recently-triggered": newChain("recently-triggered", &model.ChainNode{
+				ApplicationId: "app-2",
+				TriggeredAt:   recent,
+			}),
+			"deployed": newChain("deployed", &model.ChainNode{
+				ApplicationId:    "app-2",
+				TriggeredAt:      timedOut,
+				DeploymentId:     "deployment-2",
+				DeploymentStatus: model.DeploymentStatus_DEPLOYMENT_RUNNING,
+			}),
+		},
+	}
+	c := &Cleaner{
+		deploymentChainStore: store,
+		nowFunc:              func() time.Time { return now },
+		logger:               zap.NewNop(),
+	}
+
+	require.NoError(t, c.timeoutDeploymentChains(context.Background()))
+	assert.Equal(t, []string{"timed-out"}, store.updated)
+	assert.Equal(t, model.ChainStatus_DEPLOYMENT_CHAIN_FAILURE, store.chains["timed-out"].Status)
+	assert.Equal(t, model.ChainStatus_DEPLOYMENT_CHAIN_RUNNING, store.chains["recently-triggered"].Status)
+}
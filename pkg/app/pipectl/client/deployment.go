@@ -17,10 +17,13 @@ package client
 import (
 	"context"
 	"fmt"
+	"io"
 	"strings"
 	"time"
 
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/pipe-cd/pipe/pkg/app/api/service/apiservice"
 	"github.com/pipe-cd/pipe/pkg/model"
@@ -94,3 +97,158 @@ func makeDeploymentStatusesMap(statuses []model.DeploymentStatus) map[model.Depl
 	}
 	return out
 }
+
+// PrintDeploymentLogs writes the logs of the stages of the given deployment to w.
+// When stageID is specified, only the logs of that stage are written.
+// When follow is true, it keeps streaming new logs until all stages have been completed.
+func PrintDeploymentLogs(
+	ctx context.Context,
+	cli apiservice.Client,
+	deploymentID, stageID string,
+	follow bool,
+	checkInterval time.Duration,
+	w io.Writer,
+) error {
+	deployment, err := getDeployment(ctx, cli, deploymentID)
+	if err != nil {
+		return err
+	}
+
+	var stageIDs []string
+	for _, s := range deployment.Stages {
+		if stageID == "" || s.Id == stageID {
+			stageIDs = append(stageIDs, s.Id)
+		}
+	}
+	if len(stageIDs) == 0 {
+		return fmt.Errorf("stage %s was not found in deployment %s", stageID, deploymentID)
+	}
+
+	for _, id := range stageIDs {
+		// Refresh the deployment to know the latest retried count of the stage.
+		stage, err := getStage(ctx, cli, deploymentID, id)
+		if err != nil {
+			return err
+		}
+
+		if !follow {
+			if err := printStageLogs(ctx, cli, deploymentID, stage, w); err != nil {
+				return err
+			}
+			continue
+		}
+		for {
+			if err := streamStageLogs(ctx, cli, deploymentID, stage, checkInterval, w); err != nil {
+				return err
+			}
+			// The stage may have been retried while streaming the logs.
+			// The logs of the new attempt are streamed from the beginning.
+			latest, err := getStage(ctx, cli, deploymentID, id)
+			if err != nil {
+				return err
+			}
+			if latest.RetriedCount == stage.RetriedCount {
+				break
+			}
+			stage = latest
+		}
+	}
+	return nil
+}
+
+func getStage(ctx context.Context, cli apiservice.Client, deploymentID, stageID string) (*model.PipelineStage, error) {
+	deployment, err := getDeployment(ctx, cli, deploymentID)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range deployment.Stages {
+		if s.Id == stageID {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("stage %s was not found in deployment %s", stageID, deploymentID)
+}
+
+func printStageLogs(ctx context.Context, cli apiservice.Client, deploymentID string, stage *model.PipelineStage, w io.Writer) error {
+	resp, err := cli.GetStageLog(ctx, &apiservice.GetStageLogRequest{
+		DeploymentId: deploymentID,
+		StageId:      stage.Id,
+		RetriedCount: stage.RetriedCount,
+	})
+	if status.Code(err) == codes.NotFound {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get logs of stage %s: %w", stage.Name, err)
+	}
+	for _, b := range resp.Blocks {
+		fmt.Fprintf(w, "[%s] %s\n", stage.Name, b.Log)
+	}
+	return nil
+}
+
+func streamStageLogs(ctx context.Context, cli apiservice.Client, deploymentID string, stage *model.PipelineStage, checkInterval time.Duration, w io.Writer) error {
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The log of a stage which never runs, e.g. the rollback stage of a succeeded deployment,
+	// is never completed. So the stream is closed a while after the deployment has been completed.
+	go func() {
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+		var deploymentCompleted bool
+		for {
+			select {
+			case <-streamCtx.Done():
+				return
+			case <-ticker.C:
+			}
+			if deploymentCompleted {
+				cancel()
+				return
+			}
+			if d, err := getDeployment(streamCtx, cli, deploymentID); err == nil {
+				deploymentCompleted = model.IsCompletedDeployment(d.Status)
+			}
+		}
+	}()
+
+	stream, err := cli.StreamStageLog(streamCtx, &apiservice.StreamStageLogRequest{
+		DeploymentId: deploymentID,
+		StageId:      stage.Id,
+		RetriedCount: stage.RetriedCount,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to stream logs of stage %s: %w", stage.Name, err)
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			// The stream was closed because the deployment has been completed.
+			if streamCtx.Err() != nil && ctx.Err() == nil {
+				return nil
+			}
+			return fmt.Errorf("failed to stream logs of stage %s: %w", stage.Name, err)
+		}
+		for _, b := range resp.Blocks {
+			fmt.Fprintf(w, "[%s] %s\n", stage.Name, b.Log)
+		}
+		if resp.Completed {
+			return nil
+		}
+	}
+}
+
+func getDeployment(ctx context.Context, cli apiservice.Client, id string) (*model.Deployment, error) {
+	resp, err := cli.GetDeployment(ctx, &apiservice.GetDeploymentRequest{
+		DeploymentId: id,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deployment %s: %w", id, err)
+	}
+	return resp.Deployment, nil
+}
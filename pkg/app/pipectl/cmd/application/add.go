@@ -33,6 +33,7 @@ type add struct {
 	envID         string
 	pipedID       string
 	cloudProvider string
+	labels        map[string]string
 
 	repoID         string
 	appDir         string
@@ -55,6 +56,7 @@ func newAddCommand(root *command) *cobra.Command {
 	cmd.Flags().StringVar(&c.envID, "env-id", c.envID, "The ID of environment where this application should belong to.")
 	cmd.Flags().StringVar(&c.pipedID, "piped-id", c.pipedID, "The ID of piped that should handle this applicaiton.")
 	cmd.Flags().StringVar(&c.cloudProvider, "cloud-provider", c.cloudProvider, "The cloud provider name. One of the registered providers in the piped configuration.")
+	cmd.Flags().StringToStringVar(&c.labels, "labels", c.labels, "The labels of the application. e.g. --labels=team=pipe-cd,tier=backend")
 
 	cmd.Flags().StringVar(&c.repoID, "repo-id", c.repoID, "The repository ID. One the registered repositories in the piped configuration.")
 	cmd.Flags().StringVar(&c.appDir, "app-dir", c.appDir, "The relative path from the root of repository to the application directory.")
@@ -96,6 +98,7 @@ func (c *add) run(ctx context.Context, t cli.Telemetry) error {
 		},
 		Kind:          model.ApplicationKind(appKind),
 		CloudProvider: c.cloudProvider,
+		Labels:        c.labels,
 	}
 
 	resp, err := cli.AddApplication(ctx, req)
@@ -36,6 +36,10 @@ func NewCommand() *cobra.Command {
 	cmd.AddCommand(newAddCommand(c))
 	cmd.AddCommand(newSyncCommand(c))
 	cmd.AddCommand(newGetCommand(c))
+	cmd.AddCommand(newListCommand(c))
+	cmd.AddCommand(newUpdateCommand(c))
+	cmd.AddCommand(newEnableCommand(c))
+	cmd.AddCommand(newDisableCommand(c))
 
 	c.clientOptions.RegisterPersistentFlags(cmd)
 
@@ -0,0 +1,65 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package application
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pipe-cd/pipe/pkg/app/api/service/apiservice"
+	"github.com/pipe-cd/pipe/pkg/cli"
+)
+
+type enable struct {
+	root *command
+
+	appID string
+}
+
+func newEnableCommand(root *command) *cobra.Command {
+	c := &enable{
+		root: root,
+	}
+	cmd := &cobra.Command{
+		Use:   "enable",
+		Short: "Enable an application.",
+		RunE:  cli.WithContext(c.run),
+	}
+
+	cmd.Flags().StringVar(&c.appID, "app-id", c.appID, "The application ID.")
+	cmd.MarkFlagRequired("app-id")
+
+	return cmd
+}
+
+func (c *enable) run(ctx context.Context, t cli.Telemetry) error {
+	cli, err := c.root.clientOptions.NewClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize client: %w", err)
+	}
+	defer cli.Close()
+
+	req := &apiservice.EnableApplicationRequest{
+		ApplicationId: c.appID,
+	}
+	if _, err := cli.EnableApplication(ctx, req); err != nil {
+		return fmt.Errorf("failed to enable application: %w", err)
+	}
+
+	t.Logger.Info(fmt.Sprintf("Successfully enabled application id = %s", c.appID))
+	return nil
+}
@@ -16,7 +16,6 @@ package application
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -24,19 +23,23 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/pipe-cd/pipe/pkg/app/api/service/apiservice"
+	"github.com/pipe-cd/pipe/pkg/app/pipectl/output"
 	"github.com/pipe-cd/pipe/pkg/cli"
+	"github.com/pipe-cd/pipe/pkg/model"
 )
 
 type get struct {
 	root *command
 
 	appID  string
+	output string
 	stdout io.Writer
 }
 
 func newGetCommand(root *command) *cobra.Command {
 	c := &get{
 		root:   root,
+		output: output.FormatJSON,
 		stdout: os.Stdout,
 	}
 	cmd := &cobra.Command{
@@ -46,6 +49,8 @@ func newGetCommand(root *command) *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&c.appID, "app-id", c.appID, "The application ID.")
+	output.AddFlag(cmd, &c.output)
+
 	cmd.MarkFlagRequired("app-id")
 
 	return cmd
@@ -67,11 +72,7 @@ func (c *get) run(ctx context.Context, _ cli.Telemetry) error {
 		return fmt.Errorf("failed to get application: %w", err)
 	}
 
-	bytes, err := json.Marshal(resp.Application)
-	if err != nil {
-		return fmt.Errorf("failed to marshal application: %w", err)
-	}
-
-	fmt.Fprintln(c.stdout, string(bytes))
-	return nil
+	return output.Print(c.stdout, c.output, resp.Application, func() output.Table {
+		return makeApplicationTable([]*model.Application{resp.Application})
+	})
 }
@@ -0,0 +1,123 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package application
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/pipe-cd/pipe/pkg/app/api/service/apiservice"
+	"github.com/pipe-cd/pipe/pkg/app/pipectl/output"
+	"github.com/pipe-cd/pipe/pkg/cli"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+type list struct {
+	root *command
+
+	envID      string
+	appKind    string
+	syncStatus string
+	disabled   bool
+	selector   string
+	output     string
+	stdout     io.Writer
+}
+
+func newListCommand(root *command) *cobra.Command {
+	c := &list{
+		root:   root,
+		output: output.FormatTable,
+		stdout: os.Stdout,
+	}
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Show the list of applications.",
+		RunE:  cli.WithContext(c.run),
+	}
+
+	cmd.Flags().StringVar(&c.envID, "env-id", c.envID, "Only show the applications in the given environment.")
+	cmd.Flags().StringVar(&c.appKind, "app-kind", c.appKind, "Only show the applications of the given kind. (KUBERNETES|TERRAFORM|LAMBDA|CLOUDRUN)")
+	cmd.Flags().StringVar(&c.syncStatus, "sync-status", c.syncStatus, "Only show the applications in the given sync status. (SYNCED|DEPLOYING|OUT_OF_SYNC)")
+	cmd.Flags().BoolVar(&c.disabled, "disabled", c.disabled, "Show the disabled applications instead of the enabled ones.")
+	cmd.Flags().StringVarP(&c.selector, "selector", "l", c.selector, "Only show the applications matching the given label selector. e.g. -l 'team=pipe-cd,tier in (backend)'")
+	output.AddFlag(cmd, &c.output)
+
+	return cmd
+}
+
+func (c *list) run(ctx context.Context, _ cli.Telemetry) error {
+	options := &apiservice.ListApplicationsRequest_Options{
+		Enabled:       wrapperspb.Bool(!c.disabled),
+		LabelSelector: c.selector,
+	}
+	if c.envID != "" {
+		options.EnvIds = []string{c.envID}
+	}
+	if c.appKind != "" {
+		kind, ok := model.ApplicationKind_value[c.appKind]
+		if !ok {
+			return fmt.Errorf("unsupported application kind %s", c.appKind)
+		}
+		options.Kinds = []model.ApplicationKind{model.ApplicationKind(kind)}
+	}
+	if c.syncStatus != "" {
+		s, ok := model.ApplicationSyncStatus_value[c.syncStatus]
+		if !ok {
+			return fmt.Errorf("unsupported sync status %s", c.syncStatus)
+		}
+		options.SyncStatuses = []model.ApplicationSyncStatus{model.ApplicationSyncStatus(s)}
+	}
+
+	cli, err := c.root.clientOptions.NewClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize client: %w", err)
+	}
+	defer cli.Close()
+
+	resp, err := cli.ListApplications(ctx, &apiservice.ListApplicationsRequest{
+		Options: options,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list applications: %w", err)
+	}
+
+	return output.Print(c.stdout, c.output, resp, func() output.Table {
+		return makeApplicationTable(resp.Applications)
+	})
+}
+
+func makeApplicationTable(apps []*model.Application) output.Table {
+	t := output.Table{
+		Header: []string{"ID", "NAME", "ENV", "KIND", "SYNC STATUS", "DISABLED"},
+	}
+	for _, app := range apps {
+		t.Rows = append(t.Rows, []string{
+			app.Id,
+			app.Name,
+			app.EnvId,
+			app.Kind.String(),
+			app.GetSyncState().GetStatus().String(),
+			strconv.FormatBool(app.Disabled),
+		})
+	}
+	return t
+}
@@ -0,0 +1,127 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package application
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pipe-cd/pipe/pkg/app/api/service/apiservice"
+	"github.com/pipe-cd/pipe/pkg/cli"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+type update struct {
+	root *command
+
+	appID         string
+	appName       string
+	appKind       string
+	envID         string
+	pipedID       string
+	cloudProvider string
+	labels        map[string]string
+	clearLabels   bool
+
+	repoID         string
+	appDir         string
+	configFileName string
+}
+
+func newUpdateCommand(root *command) *cobra.Command {
+	c := &update{
+		root: root,
+	}
+	cmd := &cobra.Command{
+		Use:   "update",
+		Short: "Update the configuration of an application.",
+		Long:  "Update the configuration of an application. The flags which are not specified keep their current values.\nThe specified labels replace all current ones. Use --clear-labels to remove them.",
+		RunE:  cli.WithContext(c.run),
+	}
+
+	cmd.Flags().StringVar(&c.appID, "app-id", c.appID, "The application ID.")
+	cmd.Flags().StringVar(&c.appName, "app-name", c.appName, "The application name.")
+	cmd.Flags().StringVar(&c.appKind, "app-kind", c.appKind, "The kind of application. (KUBERNETES|TERRAFORM|LAMBDA|CLOUDRUN)")
+	cmd.Flags().StringVar(&c.envID, "env-id", c.envID, "The ID of environment where this application should belong to.")
+	cmd.Flags().StringVar(&c.pipedID, "piped-id", c.pipedID, "The ID of piped that should handle this applicaiton.")
+	cmd.Flags().StringVar(&c.cloudProvider, "cloud-provider", c.cloudProvider, "The cloud provider name. One of the registered providers in the piped configuration.")
+	cmd.Flags().StringToStringVar(&c.labels, "labels", c.labels, "The labels of the application. e.g. --labels=team=pipe-cd,tier=backend")
+	cmd.Flags().BoolVar(&c.clearLabels, "clear-labels", c.clearLabels, "Whether to remove the current labels of the application before setting the specified ones.")
+
+	cmd.Flags().StringVar(&c.repoID, "repo-id", c.repoID, "The repository ID. One the registered repositories in the piped configuration.")
+	cmd.Flags().StringVar(&c.appDir, "app-dir", c.appDir, "The relative path from the root of repository to the application directory.")
+	cmd.Flags().StringVar(&c.configFileName, "config-file-name", c.configFileName, "The configuration file name.")
+
+	cmd.MarkFlagRequired("app-id")
+
+	return cmd
+}
+
+func (c *update) run(ctx context.Context, t cli.Telemetry) error {
+	cli, err := c.root.clientOptions.NewClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize client: %w", err)
+	}
+	defer cli.Close()
+
+	resp, err := cli.GetApplication(ctx, &apiservice.GetApplicationRequest{
+		ApplicationId: c.appID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get application: %w", err)
+	}
+	app := resp.Application
+
+	req := &apiservice.UpdateApplicationRequest{
+		ApplicationId: app.Id,
+		Name:          valueOrDefault(c.appName, app.Name),
+		EnvId:         valueOrDefault(c.envID, app.EnvId),
+		PipedId:       valueOrDefault(c.pipedID, app.PipedId),
+		GitPath: &model.ApplicationGitPath{
+			Repo: &model.ApplicationGitRepository{
+				Id: valueOrDefault(c.repoID, app.GitPath.GetRepo().GetId()),
+			},
+			Path:           valueOrDefault(c.appDir, app.GitPath.GetPath()),
+			ConfigFilename: valueOrDefault(c.configFileName, app.GitPath.GetConfigFilename()),
+		},
+		Kind:          app.Kind,
+		CloudProvider: valueOrDefault(c.cloudProvider, app.CloudProvider),
+		Labels:        c.labels,
+		ClearLabels:   c.clearLabels,
+	}
+	if c.appKind != "" {
+		appKind, ok := model.ApplicationKind_value[c.appKind]
+		if !ok {
+			return fmt.Errorf("unsupported application kind %s", c.appKind)
+		}
+		req.Kind = model.ApplicationKind(appKind)
+	}
+
+	if _, err := cli.UpdateApplication(ctx, req); err != nil {
+		return fmt.Errorf("failed to update application: %w", err)
+	}
+
+	t.Logger.Info(fmt.Sprintf("Successfully updated application id = %s", app.Id))
+	return nil
+}
+
+func valueOrDefault(value, defaultValue string) string {
+	if value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,69 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployment
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pipe-cd/pipe/pkg/app/api/service/apiservice"
+	"github.com/pipe-cd/pipe/pkg/cli"
+)
+
+type approve struct {
+	root *command
+
+	deploymentID string
+	stageID      string
+}
+
+func newApproveCommand(root *command) *cobra.Command {
+	c := &approve{
+		root: root,
+	}
+	cmd := &cobra.Command{
+		Use:   "approve",
+		Short: "Approve a WAIT_APPROVAL stage of a deployment.",
+		RunE:  cli.WithContext(c.run),
+	}
+
+	cmd.Flags().StringVar(&c.deploymentID, "deployment-id", c.deploymentID, "The deployment ID.")
+	cmd.Flags().StringVar(&c.stageID, "stage-id", c.stageID, "The ID of the stage waiting for approval.")
+	cmd.MarkFlagRequired("deployment-id")
+	cmd.MarkFlagRequired("stage-id")
+
+	return cmd
+}
+
+func (c *approve) run(ctx context.Context, t cli.Telemetry) error {
+	cli, err := c.root.clientOptions.NewClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize client: %w", err)
+	}
+	defer cli.Close()
+
+	resp, err := cli.ApproveStage(ctx, &apiservice.ApproveStageRequest{
+		DeploymentId: c.deploymentID,
+		StageId:      c.stageID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to approve stage: %w", err)
+	}
+
+	t.Logger.Info(fmt.Sprintf("Successfully requested to approve stage id = %s, command id = %s", c.stageID, resp.CommandId))
+	return nil
+}
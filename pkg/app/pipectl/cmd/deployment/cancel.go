@@ -0,0 +1,75 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployment
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pipe-cd/pipe/pkg/app/api/service/apiservice"
+	"github.com/pipe-cd/pipe/pkg/cli"
+)
+
+type cancel struct {
+	root *command
+
+	deploymentID    string
+	forceRollback   bool
+	forceNoRollback bool
+}
+
+func newCancelCommand(root *command) *cobra.Command {
+	c := &cancel{
+		root: root,
+	}
+	cmd := &cobra.Command{
+		Use:   "cancel",
+		Short: "Cancel a running deployment.",
+		RunE:  cli.WithContext(c.run),
+	}
+
+	cmd.Flags().StringVar(&c.deploymentID, "deployment-id", c.deploymentID, "The deployment ID.")
+	cmd.Flags().BoolVar(&c.forceRollback, "force-rollback", c.forceRollback, "Whether to rollback the deployment regardless of the configured auto-rollback option.")
+	cmd.Flags().BoolVar(&c.forceNoRollback, "force-no-rollback", c.forceNoRollback, "Whether to skip rolling back the deployment regardless of the configured auto-rollback option.")
+	cmd.MarkFlagRequired("deployment-id")
+
+	return cmd
+}
+
+func (c *cancel) run(ctx context.Context, t cli.Telemetry) error {
+	if c.forceRollback && c.forceNoRollback {
+		return fmt.Errorf("only one of --force-rollback and --force-no-rollback can be specified")
+	}
+
+	cli, err := c.root.clientOptions.NewClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize client: %w", err)
+	}
+	defer cli.Close()
+
+	resp, err := cli.CancelDeployment(ctx, &apiservice.CancelDeploymentRequest{
+		DeploymentId:    c.deploymentID,
+		ForceRollback:   c.forceRollback,
+		ForceNoRollback: c.forceNoRollback,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to cancel deployment: %w", err)
+	}
+
+	t.Logger.Info(fmt.Sprintf("Successfully requested to cancel deployment id = %s, command id = %s", c.deploymentID, resp.CommandId))
+	return nil
+}
@@ -33,6 +33,11 @@ func NewCommand() *cobra.Command {
 		Short: "Manage deployment resources.",
 	}
 
+	cmd.AddCommand(newListCommand(c))
+	cmd.AddCommand(newGetCommand(c))
+	cmd.AddCommand(newCancelCommand(c))
+	cmd.AddCommand(newApproveCommand(c))
+	cmd.AddCommand(newLogsCommand(c))
 	cmd.AddCommand(newWaitStatusCommand(c))
 
 	c.clientOptions.RegisterPersistentFlags(cmd)
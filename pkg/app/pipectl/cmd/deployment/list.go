@@ -0,0 +1,128 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployment
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pipe-cd/pipe/pkg/app/api/service/apiservice"
+	"github.com/pipe-cd/pipe/pkg/app/pipectl/output"
+	"github.com/pipe-cd/pipe/pkg/cli"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+type list struct {
+	root *command
+
+	appID    string
+	envID    string
+	appKind  string
+	status   string
+	selector string
+	limit    int32
+	output   string
+	stdout   io.Writer
+}
+
+func newListCommand(root *command) *cobra.Command {
+	c := &list{
+		root:   root,
+		limit:  20,
+		output: output.FormatTable,
+		stdout: os.Stdout,
+	}
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Show the list of the most recently updated deployments.",
+		RunE:  cli.WithContext(c.run),
+	}
+
+	cmd.Flags().StringVar(&c.appID, "app-id", c.appID, "Only show the deployments of the given application.")
+	cmd.Flags().StringVar(&c.envID, "env-id", c.envID, "Only show the deployments in the given environment.")
+	cmd.Flags().StringVar(&c.appKind, "app-kind", c.appKind, "Only show the deployments of the given application kind. (KUBERNETES|TERRAFORM|LAMBDA|CLOUDRUN)")
+	cmd.Flags().StringVar(&c.status, "status", c.status, "Only show the deployments in the given status. (DEPLOYMENT_PENDING|DEPLOYMENT_PLANNED|DEPLOYMENT_RUNNING|DEPLOYMENT_ROLLING_BACK|DEPLOYMENT_SUCCESS|DEPLOYMENT_FAILURE|DEPLOYMENT_CANCELLED)")
+	cmd.Flags().StringVarP(&c.selector, "selector", "l", c.selector, "Only show the deployments of the applications matching the given label selector. e.g. -l 'team=pipe-cd,tier in (backend)'")
+	cmd.Flags().Int32Var(&c.limit, "limit", c.limit, "The maximum number of deployments to show.")
+	output.AddFlag(cmd, &c.output)
+
+	return cmd
+}
+
+func (c *list) run(ctx context.Context, _ cli.Telemetry) error {
+	options := &apiservice.ListDeploymentsRequest_Options{
+		LabelSelector: c.selector,
+	}
+	if c.appID != "" {
+		options.ApplicationIds = []string{c.appID}
+	}
+	if c.envID != "" {
+		options.EnvIds = []string{c.envID}
+	}
+	if c.appKind != "" {
+		kind, ok := model.ApplicationKind_value[c.appKind]
+		if !ok {
+			return fmt.Errorf("unsupported application kind %s", c.appKind)
+		}
+		options.Kinds = []model.ApplicationKind{model.ApplicationKind(kind)}
+	}
+	if c.status != "" {
+		s, ok := model.DeploymentStatus_value[c.status]
+		if !ok {
+			return fmt.Errorf("unsupported deployment status %s", c.status)
+		}
+		options.Statuses = []model.DeploymentStatus{model.DeploymentStatus(s)}
+	}
+
+	cli, err := c.root.clientOptions.NewClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize client: %w", err)
+	}
+	defer cli.Close()
+
+	resp, err := cli.ListDeployments(ctx, &apiservice.ListDeploymentsRequest{
+		Options:  options,
+		PageSize: c.limit,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list deployments: %w", err)
+	}
+
+	return output.Print(c.stdout, c.output, resp, func() output.Table {
+		return makeDeploymentTable(resp.Deployments)
+	})
+}
+
+func makeDeploymentTable(deployments []*model.Deployment) output.Table {
+	t := output.Table{
+		Header: []string{"ID", "APPLICATION", "ENV", "KIND", "STATUS", "CREATED AT"},
+	}
+	for _, d := range deployments {
+		t.Rows = append(t.Rows, []string{
+			d.Id,
+			d.ApplicationName,
+			d.EnvId,
+			d.Kind.String(),
+			d.Status.String(),
+			time.Unix(d.CreatedAt, 0).UTC().Format(time.RFC3339),
+		})
+	}
+	return t
+}
@@ -0,0 +1,69 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployment
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pipe-cd/pipe/pkg/app/pipectl/client"
+	"github.com/pipe-cd/pipe/pkg/cli"
+)
+
+type logs struct {
+	root *command
+
+	deploymentID  string
+	stageID       string
+	follow        bool
+	checkInterval time.Duration
+	stdout        io.Writer
+}
+
+func newLogsCommand(root *command) *cobra.Command {
+	c := &logs{
+		root:          root,
+		checkInterval: 5 * time.Second,
+		stdout:        os.Stdout,
+	}
+	cmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Print the logs of the stages of a deployment.",
+		RunE:  cli.WithContext(c.run),
+	}
+
+	cmd.Flags().StringVar(&c.deploymentID, "deployment-id", c.deploymentID, "The deployment ID.")
+	cmd.Flags().StringVar(&c.stageID, "stage-id", c.stageID, "Only print the logs of the given stage.")
+	cmd.Flags().BoolVarP(&c.follow, "follow", "f", c.follow, "Keep waiting for new logs until the stages have been completed.")
+	cmd.Flags().DurationVar(&c.checkInterval, "check-interval", c.checkInterval, "The interval of checking new logs when following.")
+	cmd.MarkFlagRequired("deployment-id")
+
+	return cmd
+}
+
+func (c *logs) run(ctx context.Context, _ cli.Telemetry) error {
+	cli, err := c.root.clientOptions.NewClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize client: %w", err)
+	}
+	defer cli.Close()
+
+	return client.PrintDeploymentLogs(ctx, cli, c.deploymentID, c.stageID, c.follow, c.checkInterval, c.stdout)
+}
@@ -0,0 +1,75 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package piped
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pipe-cd/pipe/pkg/app/api/service/apiservice"
+	"github.com/pipe-cd/pipe/pkg/app/pipectl/output"
+	"github.com/pipe-cd/pipe/pkg/cli"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+type get struct {
+	root *command
+
+	pipedID string
+	output  string
+	stdout  io.Writer
+}
+
+func newGetCommand(root *command) *cobra.Command {
+	c := &get{
+		root:   root,
+		output: output.FormatJSON,
+		stdout: os.Stdout,
+	}
+	cmd := &cobra.Command{
+		Use:   "get",
+		Short: "Get piped information.",
+		RunE:  cli.WithContext(c.run),
+	}
+
+	cmd.Flags().StringVar(&c.pipedID, "piped-id", c.pipedID, "The piped ID.")
+	cmd.MarkFlagRequired("piped-id")
+	output.AddFlag(cmd, &c.output)
+
+	return cmd
+}
+
+func (c *get) run(ctx context.Context, _ cli.Telemetry) error {
+	cli, err := c.root.clientOptions.NewClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize client: %w", err)
+	}
+	defer cli.Close()
+
+	resp, err := cli.GetPiped(ctx, &apiservice.GetPipedRequest{
+		PipedId: c.pipedID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get piped: %w", err)
+	}
+
+	return output.Print(c.stdout, c.output, resp, func() output.Table {
+		return makePipedTable([]*model.Piped{resp.Piped})
+	})
+}
@@ -0,0 +1,94 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package piped
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/pipe-cd/pipe/pkg/app/api/service/apiservice"
+	"github.com/pipe-cd/pipe/pkg/app/pipectl/output"
+	"github.com/pipe-cd/pipe/pkg/cli"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+type list struct {
+	root *command
+
+	disabled bool
+	output   string
+	stdout   io.Writer
+}
+
+func newListCommand(root *command) *cobra.Command {
+	c := &list{
+		root:   root,
+		output: output.FormatTable,
+		stdout: os.Stdout,
+	}
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Show the list of pipeds.",
+		RunE:  cli.WithContext(c.run),
+	}
+
+	cmd.Flags().BoolVar(&c.disabled, "disabled", c.disabled, "Show the disabled pipeds instead of the enabled ones.")
+	output.AddFlag(cmd, &c.output)
+
+	return cmd
+}
+
+func (c *list) run(ctx context.Context, _ cli.Telemetry) error {
+	cli, err := c.root.clientOptions.NewClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize client: %w", err)
+	}
+	defer cli.Close()
+
+	resp, err := cli.ListPipeds(ctx, &apiservice.ListPipedsRequest{
+		Options: &apiservice.ListPipedsRequest_Options{
+			Enabled: wrapperspb.Bool(!c.disabled),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list pipeds: %w", err)
+	}
+
+	return output.Print(c.stdout, c.output, resp, func() output.Table {
+		return makePipedTable(resp.Pipeds)
+	})
+}
+
+func makePipedTable(pipeds []*model.Piped) output.Table {
+	t := output.Table{
+		Header: []string{"ID", "NAME", "VERSION", "STATUS", "DISABLED"},
+	}
+	for _, p := range pipeds {
+		t.Rows = append(t.Rows, []string{
+			p.Id,
+			p.Name,
+			p.Version,
+			p.Status.String(),
+			strconv.FormatBool(p.Disabled),
+		})
+	}
+	return t
+}
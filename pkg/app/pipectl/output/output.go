@@ -0,0 +1,98 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package output provides the way to print the resources
+// in one of the formats supported by pipectl.
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+	FormatTable = "table"
+)
+
+// Table is the tabular representation of resources.
+type Table struct {
+	Header []string
+	Rows   [][]string
+}
+
+// AddFlag registers the flag for specifying the output format to the given command.
+func AddFlag(cmd *cobra.Command, format *string) {
+	cmd.Flags().StringVarP(format, "output", "o", *format, fmt.Sprintf("The output format. (%s|%s|%s)", FormatJSON, FormatYAML, FormatTable))
+}
+
+// Print writes the given message to w in the specified format.
+// The table function is called only when the table format was specified.
+func Print(w io.Writer, format string, msg proto.Message, table func() Table) error {
+	switch format {
+	case FormatJSON:
+		data, err := marshalJSON(msg)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+
+	case FormatYAML:
+		data, err := marshalJSON(msg)
+		if err != nil {
+			return err
+		}
+		data, err = yaml.JSONToYAML(data)
+		if err != nil {
+			return fmt.Errorf("failed to convert to yaml: %w", err)
+		}
+		_, err = w.Write(data)
+		return err
+
+	case FormatTable:
+		return printTable(w, table())
+
+	default:
+		return fmt.Errorf("unsupported output format %q", format)
+	}
+}
+
+func marshalJSON(msg proto.Message) ([]byte, error) {
+	opts := protojson.MarshalOptions{
+		UseProtoNames: true,
+	}
+	data, err := opts.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal to json: %w", err)
+	}
+	return data, nil
+}
+
+func printTable(w io.Writer, t Table) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(t.Header, "\t"))
+	for _, r := range t.Rows {
+		fmt.Fprintln(tw, strings.Join(r, "\t"))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,83 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+func TestPrint(t *testing.T) {
+	env := &model.Environment{
+		Id:   "env-1",
+		Name: "staging",
+	}
+	table := func() Table {
+		return Table{
+			Header: []string{"ID", "NAME"},
+			Rows:   [][]string{{env.Id, env.Name}},
+		}
+	}
+
+	testcases := []struct {
+		name     string
+		format   string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "json",
+			format:   FormatJSON,
+			expected: "{\"id\":\"env-1\",\"name\":\"staging\"}\n",
+		},
+		{
+			name:     "yaml",
+			format:   FormatYAML,
+			expected: "id: env-1\nname: staging\n",
+		},
+		{
+			name:     "table",
+			format:   FormatTable,
+			expected: "ID     NAME\nenv-1  staging\n",
+		},
+		{
+			name:    "unsupported",
+			format:  "xml",
+			wantErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := Print(&buf, tc.format, env, table)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			// The output of protojson is not stable in whitespaces.
+			if tc.format == FormatJSON {
+				assert.JSONEq(t, tc.expected, buf.String())
+				return
+			}
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
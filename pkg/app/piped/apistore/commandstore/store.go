@@ -119,11 +119,12 @@ func (s *store) sync(ctx context.Context) error {
 	)
 	for _, cmd := range resp.Commands {
 		switch cmd.Type {
-		case model.Command_SYNC_APPLICATION, model.Command_UPDATE_APPLICATION_CONFIG:
+		case model.Command_SYNC_APPLICATION, model.Command_UPDATE_APPLICATION_CONFIG, model.Command_PRUNE_APPLICATION_RESOURCES,
+			model.Command_GET_KUBERNETES_POD_LOGS, model.Command_GET_KUBERNETES_RESOURCE_EVENTS:
 			applicationCommands = append(applicationCommands, s.makeReportableCommand(cmd))
 		case model.Command_CANCEL_DEPLOYMENT:
 			deploymentCommands = append(deploymentCommands, s.makeReportableCommand(cmd))
-		case model.Command_APPROVE_STAGE:
+		case model.Command_APPROVE_STAGE, model.Command_REJECT_STAGE:
 			stageCommands = append(stageCommands, s.makeReportableCommand(cmd))
 		}
 	}
@@ -202,13 +203,13 @@ func (s *store) ListStageCommands(deploymentID, stageID string) []model.Reportab
 func (s *store) makeReportableCommand(c *model.Command) model.ReportableCommand {
 	return model.ReportableCommand{
 		Command: c,
-		Report: func(ctx context.Context, status model.CommandStatus, metadata map[string]string) error {
-			return s.reportCommandHandled(ctx, c, status, metadata)
+		Report: func(ctx context.Context, status model.CommandStatus, metadata map[string]string, output []byte) error {
+			return s.reportCommandHandled(ctx, c, status, metadata, output)
 		},
 	}
 }
 
-func (s *store) reportCommandHandled(ctx context.Context, c *model.Command, status model.CommandStatus, metadata map[string]string) error {
+func (s *store) reportCommandHandled(ctx context.Context, c *model.Command, status model.CommandStatus, metadata map[string]string, output []byte) error {
 	now := time.Now()
 
 	s.mu.Lock()
@@ -220,6 +221,7 @@ func (s *store) reportCommandHandled(ctx context.Context, c *model.Command, stat
 		Status:    status,
 		Metadata:  metadata,
 		HandledAt: now.Unix(),
+		Output:    output,
 	})
 	return err
 }
@@ -0,0 +1,257 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chartrepo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/pipe-cd/pipe/pkg/app/piped/imageprovider/ecr"
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+// The version of helm used to login and pull charts from OCI registries.
+// Helm supports "helm pull oci://..." since 3.7 and makes OCI support GA since 3.8.
+const ociHelmVersion = "3.8.2"
+
+var (
+	pullGroup = &singleflight.Group{}
+
+	ociMu             sync.RWMutex
+	ociCacheDir       = filepath.Join(os.TempDir(), "helm-oci-charts")
+	ociRegistries     []config.HelmChartRegistry
+	ociImageProviders []config.PipedImageProvider
+)
+
+// InitOCIRegistries logs in to all specified OCI registries and sets the directory
+// where the pulled charts should be cached.
+// https://helm.sh/docs/topics/registries/
+// helm registry login harbor.example.com --username my-username --password-stdin
+func InitOCIRegistries(ctx context.Context, registries []config.HelmChartRegistry, imageProviders []config.PipedImageProvider, cacheDir string, reg registry, logger *zap.Logger) error {
+	ociMu.Lock()
+	if cacheDir != "" {
+		ociCacheDir = cacheDir
+	}
+	ociRegistries = registries
+	ociImageProviders = imageProviders
+	ociMu.Unlock()
+
+	for i := range registries {
+		if err := login(ctx, &registries[i], imageProviders, reg, logger); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PullOCIChart pulls the given version of the chart from an OCI registry
+// and returns the path to the directory containing the unpacked chart.
+// Because a chart version is immutable, the pulled chart is cached
+// and reused in the subsequent calls without hitting the network.
+// e.g. ref: oci://harbor.example.com/charts/helloworld
+func PullOCIChart(ctx context.Context, ref, version string, reg registry, logger *zap.Logger) (string, error) {
+	if !strings.HasPrefix(ref, config.OCIChartScheme) {
+		return "", fmt.Errorf("invalid OCI chart reference %s", ref)
+	}
+	if version == "" {
+		return "", fmt.Errorf("version of OCI chart %s must be specified", ref)
+	}
+
+	ociMu.RLock()
+	cacheDir := ociCacheDir
+	ociMu.RUnlock()
+
+	// The reference and version come from the deployment configuration
+	// so they must not be able to point outside the cache directory.
+	chartDir := filepath.Join(cacheDir, strings.TrimPrefix(ref, config.OCIChartScheme), version)
+	if rel, err := filepath.Rel(cacheDir, chartDir); err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid OCI chart %s:%s, it must not point outside the cache directory", ref, version)
+	}
+
+	path, err, _ := pullGroup.Do(chartDir, func() (interface{}, error) {
+		if _, err := os.Stat(chartDir); err == nil {
+			return chartDir, nil
+		}
+		err := pull(ctx, ref, version, chartDir, reg, logger)
+		if err == nil || !isAuthError(err) {
+			return chartDir, err
+		}
+
+		// The credentials may be expired (e.g. ECR token is valid only for 12 hours)
+		// so we login again and retry.
+		r := findRegistry(ref)
+		if r == nil {
+			return chartDir, err
+		}
+		ociMu.RLock()
+		providers := ociImageProviders
+		ociMu.RUnlock()
+		if e := login(ctx, r, providers, reg, logger); e != nil {
+			logger.Error("failed to login again to chart registry", zap.Error(e))
+			return chartDir, err
+		}
+		return chartDir, pull(ctx, ref, version, chartDir, reg, logger)
+	})
+	if err != nil {
+		return "", err
+	}
+	return path.(string), nil
+}
+
+func pull(ctx context.Context, ref, version, chartDir string, reg registry, logger *zap.Logger) error {
+	helm, _, err := reg.Helm(ctx, ociHelmVersion)
+	if err != nil {
+		return fmt.Errorf("failed to find helm to pull chart (%w)", err)
+	}
+
+	parentDir := filepath.Dir(chartDir)
+	if err := os.MkdirAll(parentDir, 0755); err != nil {
+		return fmt.Errorf("failed to create chart cache directory: %w", err)
+	}
+	// Unpack into a temporary directory first to avoid leaving a broken chart in the cache.
+	workDir, err := ioutil.TempDir(parentDir, "pull")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	logger.Info(fmt.Sprintf("start pulling chart %s:%s", ref, version))
+	args := []string{"pull", ref, "--version", version, "--untar", "--untardir", workDir}
+	cmd := exec.CommandContext(ctx, helm, args...)
+	cmd.Env = append(os.Environ(), "HELM_EXPERIMENTAL_OCI=1")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to pull chart %s:%s: %s (%w)", ref, version, string(out), err)
+	}
+
+	unpacked := filepath.Join(workDir, filepath.Base(ref))
+	if err := os.Rename(unpacked, chartDir); err != nil {
+		return fmt.Errorf("failed to move pulled chart to cache directory: %w", err)
+	}
+	logger.Info(fmt.Sprintf("successfully pulled chart %s:%s", ref, version))
+	return nil
+}
+
+func login(ctx context.Context, r *config.HelmChartRegistry, imageProviders []config.PipedImageProvider, reg registry, logger *zap.Logger) error {
+	username, password, err := registryCredentials(ctx, r, imageProviders, logger)
+	if err != nil {
+		return fmt.Errorf("failed to get credentials of chart registry %s: %w", r.Address, err)
+	}
+	if username == "" && password == "" {
+		return nil
+	}
+
+	helm, _, err := reg.Helm(ctx, ociHelmVersion)
+	if err != nil {
+		return fmt.Errorf("failed to find helm to login registries (%w)", err)
+	}
+
+	args := []string{"registry", "login", registryHost(r.Address), "--username", username, "--password-stdin"}
+	cmd := exec.CommandContext(ctx, helm, args...)
+	cmd.Env = append(os.Environ(), "HELM_EXPERIMENTAL_OCI=1")
+	cmd.Stdin = bytes.NewBufferString(password)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to login to chart registry %s: %s (%w)", r.Address, string(out), err)
+	}
+	logger.Info(fmt.Sprintf("successfully logged in to chart registry: %s", r.Address))
+	return nil
+}
+
+// registryCredentials returns the username and password used to login to the given registry.
+// They are taken from the referenced image provider when the registry is using one.
+func registryCredentials(ctx context.Context, r *config.HelmChartRegistry, imageProviders []config.PipedImageProvider, logger *zap.Logger) (username, password string, err error) {
+	if r.ImageProvider == "" {
+		return r.Username, r.Password, nil
+	}
+
+	var p *config.PipedImageProvider
+	for i := range imageProviders {
+		if imageProviders[i].Name == r.ImageProvider {
+			p = &imageProviders[i]
+			break
+		}
+	}
+	if p == nil {
+		return "", "", fmt.Errorf("image provider %s was not found", r.ImageProvider)
+	}
+
+	switch p.Type {
+	case model.ImageProviderTypeDockerHub:
+		data, err := ioutil.ReadFile(p.DockerHubConfig.PasswordFile)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to read password file: %w", err)
+		}
+		return p.DockerHubConfig.Username, strings.TrimSpace(string(data)), nil
+
+	case model.ImageProviderTypeGCR:
+		data, err := ioutil.ReadFile(p.GCRConfig.CredentialsFile)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to read credentials file: %w", err)
+		}
+		// https://cloud.google.com/container-registry/docs/advanced-authentication#json-key
+		return "_json_key", string(data), nil
+
+	case model.ImageProviderTypeECR:
+		e, err := ecr.NewECR(p.Name, p.ECRConfig.Region,
+			ecr.WithRegistryID(p.ECRConfig.RegistryID),
+			ecr.WithCredentialsFile(p.ECRConfig.CredentialsFile),
+			ecr.WithProfile(p.ECRConfig.Profile),
+			ecr.WithLogger(logger),
+		)
+		if err != nil {
+			return "", "", err
+		}
+		return e.GetAuthorizationToken(ctx)
+
+	default:
+		return "", "", fmt.Errorf("unsupported image provider type: %s", p.Type)
+	}
+}
+
+// findRegistry returns the configured registry storing the given chart.
+func findRegistry(ref string) *config.HelmChartRegistry {
+	host := registryHost(strings.TrimPrefix(ref, config.OCIChartScheme))
+
+	ociMu.RLock()
+	defer ociMu.RUnlock()
+	for i := range ociRegistries {
+		if registryHost(ociRegistries[i].Address) == host {
+			r := ociRegistries[i]
+			return &r
+		}
+	}
+	return nil
+}
+
+func registryHost(address string) string {
+	return strings.SplitN(address, "/", 2)[0]
+}
+
+func isAuthError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "unauthorized") || strings.Contains(msg, "denied")
+}
@@ -0,0 +1,164 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chartrepo
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+type fakeRegistry struct{}
+
+func (r fakeRegistry) Helm(ctx context.Context, version string) (string, bool, error) {
+	return "", false, errors.New("helm is not available")
+}
+
+func TestRegistryCredentials(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chartrepo-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	passwordFile := filepath.Join(dir, "password")
+	require.NoError(t, ioutil.WriteFile(passwordFile, []byte("docker-password\n"), 0600))
+	credentialsFile := filepath.Join(dir, "credentials.json")
+	require.NoError(t, ioutil.WriteFile(credentialsFile, []byte(`{"type":"service_account"}`), 0600))
+
+	imageProviders := []config.PipedImageProvider{
+		{
+			Name: "dockerhub",
+			Type: model.ImageProviderTypeDockerHub,
+			DockerHubConfig: &config.ImageProviderDockerHubConfig{
+				Username:     "docker-user",
+				PasswordFile: passwordFile,
+			},
+		},
+		{
+			Name: "gcr",
+			Type: model.ImageProviderTypeGCR,
+			GCRConfig: &config.ImageProviderGCRConfig{
+				CredentialsFile: credentialsFile,
+			},
+		},
+	}
+
+	testcases := []struct {
+		name             string
+		registry         config.HelmChartRegistry
+		expectedUsername string
+		expectedPassword string
+		expectedErr      bool
+	}{
+		{
+			name: "given credentials",
+			registry: config.HelmChartRegistry{
+				Address:  "harbor.example.com",
+				Username: "user",
+				Password: "pass",
+			},
+			expectedUsername: "user",
+			expectedPassword: "pass",
+		},
+		{
+			name: "dockerhub image provider",
+			registry: config.HelmChartRegistry{
+				Address:       "registry-1.docker.io",
+				ImageProvider: "dockerhub",
+			},
+			expectedUsername: "docker-user",
+			expectedPassword: "docker-password",
+		},
+		{
+			name: "gcr image provider",
+			registry: config.HelmChartRegistry{
+				Address:       "gcr.io",
+				ImageProvider: "gcr",
+			},
+			expectedUsername: "_json_key",
+			expectedPassword: `{"type":"service_account"}`,
+		},
+		{
+			name: "missing image provider",
+			registry: config.HelmChartRegistry{
+				Address:       "gcr.io",
+				ImageProvider: "unknown",
+			},
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			username, password, err := registryCredentials(context.Background(), &tc.registry, imageProviders, zap.NewNop())
+			assert.Equal(t, tc.expectedErr, err != nil)
+			assert.Equal(t, tc.expectedUsername, username)
+			assert.Equal(t, tc.expectedPassword, password)
+		})
+	}
+}
+
+func TestPullOCIChart(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chartrepo-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ctx := context.Background()
+	logger := zap.NewNop()
+	registries := []config.HelmChartRegistry{
+		{Address: "harbor.example.com"},
+	}
+	require.NoError(t, InitOCIRegistries(ctx, registries, nil, dir, fakeRegistry{}, logger))
+
+	// The cached chart should be returned without running helm.
+	cached := filepath.Join(dir, "harbor.example.com/charts/helloworld/v0.1.0")
+	require.NoError(t, os.MkdirAll(cached, 0755))
+
+	path, err := PullOCIChart(ctx, "oci://harbor.example.com/charts/helloworld", "v0.1.0", fakeRegistry{}, logger)
+	require.NoError(t, err)
+	assert.Equal(t, cached, path)
+
+	_, err = PullOCIChart(ctx, "oci://harbor.example.com/charts/helloworld", "v0.2.0", fakeRegistry{}, logger)
+	assert.Error(t, err)
+
+	_, err = PullOCIChart(ctx, "harbor.example.com/charts/helloworld", "v0.1.0", fakeRegistry{}, logger)
+	assert.Error(t, err)
+
+	// The reference and version must not point outside the cache directory.
+	outside := []struct {
+		ref     string
+		version string
+	}{
+		{ref: "oci://harbor.example.com/charts/helloworld", version: "../../../../outside"},
+		{ref: "oci://../../outside", version: "v0.1.0"},
+		{ref: "oci://harbor.example.com/../../..", version: ".."},
+	}
+	for _, o := range outside {
+		_, err = PullOCIChart(ctx, o.ref, o.version, fakeRegistry{}, logger)
+		require.Error(t, err, "%s:%s", o.ref, o.version)
+		assert.Contains(t, err.Error(), "outside the cache directory")
+	}
+
+	assert.NotNil(t, findRegistry("oci://harbor.example.com/charts/helloworld"))
+	assert.Nil(t, findRegistry("oci://gcr.io/charts/helloworld"))
+}
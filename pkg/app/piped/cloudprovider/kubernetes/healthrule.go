@@ -0,0 +1,151 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/util/jsonpath"
+
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+// FindHealthRule returns the rule configured for the apiVersion and kind of the given resource.
+func FindHealthRule(rules []config.KubernetesHealthRule, key ResourceKey) (config.KubernetesHealthRule, bool) {
+	for _, r := range rules {
+		if r.APIVersion == key.APIVersion && r.Kind == key.Kind {
+			return r, true
+		}
+	}
+	return config.KubernetesHealthRule{}, false
+}
+
+// DetermineHealthByRules determines the health status of the given resource
+// by using the rule configured for its apiVersion and kind.
+// The last returned value is false when no rule was configured for the resource.
+func DetermineHealthByRules(rules []config.KubernetesHealthRule, m Manifest) (config.KubernetesHealthStatus, string, bool) {
+	rule, ok := FindHealthRule(rules, m.Key)
+	if !ok {
+		return "", "", false
+	}
+	status, desc := evaluateHealthRule(rule, m.u)
+	return status, desc, true
+}
+
+func evaluateHealthRule(rule config.KubernetesHealthRule, obj *unstructured.Unstructured) (config.KubernetesHealthStatus, string) {
+	for _, c := range rule.Checks {
+		var (
+			matched bool
+			message string
+			err     error
+		)
+		if c.Condition != nil {
+			matched, message = matchCondition(*c.Condition, obj)
+		} else {
+			matched, err = matchJSONPath(c.JSONPath, c.Value, obj)
+		}
+		if err != nil {
+			// A broken rule must not make the resource look healthy.
+			return config.KubernetesHealthStatusUnhealthy, fmt.Sprintf("Unable to evaluate the health rule: %v", err)
+		}
+		if !matched {
+			continue
+		}
+
+		switch {
+		case c.Description != "":
+			return c.Status, c.Description
+		case message != "":
+			return c.Status, message
+		default:
+			return c.Status, fmt.Sprintf("%s is %s", rule.Kind, strings.ToLower(string(c.Status)))
+		}
+	}
+	status := rule.GetDefaultStatus()
+	return status, fmt.Sprintf("%s is %s because no health check was matched", rule.Kind, strings.ToLower(string(status)))
+}
+
+// matchCondition checks whether the resource has the given status condition
+// and returns the message of that condition when matched.
+func matchCondition(cond config.KubernetesHealthCondition, obj *unstructured.Unstructured) (bool, string) {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		m, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if fmt.Sprint(m["type"]) != cond.Type {
+			continue
+		}
+		if fmt.Sprint(m["status"]) != cond.GetStatus() {
+			return false, ""
+		}
+		if cond.Reason != "" && fmt.Sprint(m["reason"]) != cond.Reason {
+			return false, ""
+		}
+		message, _ := m["message"].(string)
+		return true, message
+	}
+	return false, ""
+}
+
+// IsLatestGenerationObserved reports whether the controller of the given resource
+// has observed its most recent generation, so that its status reflects the applied spec.
+// It is always true for the resources not reporting their observed generation.
+func IsLatestGenerationObserved(m Manifest) bool {
+	observed, found, err := unstructured.NestedInt64(m.u.Object, "status", "observedGeneration")
+	if err != nil || !found {
+		return true
+	}
+	return observed == m.u.GetGeneration()
+}
+
+// matchJSONPath checks whether the result of the JSONPath expression equals the given value.
+// An empty value matches any non-empty result.
+func matchJSONPath(expr, value string, obj *unstructured.Unstructured) (bool, error) {
+	jp := jsonpath.New("health")
+	jp.AllowMissingKeys(true)
+	if err := jp.Parse(expr); err != nil {
+		return false, fmt.Errorf("invalid jsonPath %q: %w", expr, err)
+	}
+
+	var buf bytes.Buffer
+	if err := jp.Execute(&buf, obj.Object); err != nil {
+		return false, fmt.Errorf("failed to execute jsonPath %q: %w", expr, err)
+	}
+	result := strings.TrimSpace(buf.String())
+	if value == "" {
+		return result != "", nil
+	}
+	return result == value, nil
+}
+
+// makeResourceHealthStatus converts the health status determined by the rules
+// into the one of the live state. Since the live state has no status for
+// the progressing and unhealthy resources, that is shown in the description.
+func makeResourceHealthStatus(status config.KubernetesHealthStatus, desc string) (model.KubernetesResourceState_HealthStatus, string) {
+	switch status {
+	case config.KubernetesHealthStatusHealthy:
+		return model.KubernetesResourceState_HEALTHY, desc
+	case config.KubernetesHealthStatusProgressing:
+		return model.KubernetesResourceState_OTHER, "Progressing: " + desc
+	default:
+		return model.KubernetesResourceState_OTHER, "Unhealthy: " + desc
+	}
+}
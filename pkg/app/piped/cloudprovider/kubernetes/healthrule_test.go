@@ -0,0 +1,256 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+func TestDetermineHealthByRules(t *testing.T) {
+	rules := []config.KubernetesHealthRule{
+		{
+			APIVersion: "cert-manager.io/v1",
+			Kind:       "Certificate",
+			Checks: []config.KubernetesHealthCheck{
+				{
+					Condition: &config.KubernetesHealthCondition{Type: "Ready"},
+					Status:    config.KubernetesHealthStatusHealthy,
+				},
+				{
+					Condition: &config.KubernetesHealthCondition{Type: "Ready", Status: "False", Reason: "Failed"},
+					Status:    config.KubernetesHealthStatusUnhealthy,
+				},
+			},
+		},
+		{
+			APIVersion: "argoproj.io/v1alpha1",
+			Kind:       "Rollout",
+			Checks: []config.KubernetesHealthCheck{
+				{
+					JSONPath: "{.status.phase}",
+					Value:    "Healthy",
+					Status:   config.KubernetesHealthStatusHealthy,
+				},
+				{
+					JSONPath:    "{.status.phase}",
+					Value:       "Degraded",
+					Status:      config.KubernetesHealthStatusUnhealthy,
+					Description: "Rollout is degraded",
+				},
+			},
+		},
+	}
+
+	testcases := []struct {
+		name         string
+		manifest     string
+		expectedOK   bool
+		expected     config.KubernetesHealthStatus
+		expectedDesc string
+	}{
+		{
+			name: "no rule for the kind",
+			manifest: `
+apiVersion: kafka.strimzi.io/v1beta1
+kind: KafkaTopic
+metadata:
+  name: topic
+`,
+			expectedOK: false,
+		},
+		{
+			name: "ready certificate",
+			manifest: `
+apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: cert
+status:
+  conditions:
+  - type: Ready
+    status: "True"
+    message: Certificate is up to date and has not expired
+`,
+			expectedOK:   true,
+			expected:     config.KubernetesHealthStatusHealthy,
+			expectedDesc: "Certificate is up to date and has not expired",
+		},
+		{
+			name: "failed certificate",
+			manifest: `
+apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: cert
+status:
+  conditions:
+  - type: Ready
+    status: "False"
+    reason: Failed
+    message: The certificate request has failed to complete
+`,
+			expectedOK:   true,
+			expected:     config.KubernetesHealthStatusUnhealthy,
+			expectedDesc: "The certificate request has failed to complete",
+		},
+		{
+			name: "certificate without status",
+			manifest: `
+apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: cert
+`,
+			expectedOK:   true,
+			expected:     config.KubernetesHealthStatusProgressing,
+			expectedDesc: "Certificate is progressing because no health check was matched",
+		},
+		{
+			name: "healthy rollout",
+			manifest: `
+apiVersion: argoproj.io/v1alpha1
+kind: Rollout
+metadata:
+  name: rollout
+status:
+  phase: Healthy
+`,
+			expectedOK:   true,
+			expected:     config.KubernetesHealthStatusHealthy,
+			expectedDesc: "Rollout is healthy",
+		},
+		{
+			name: "degraded rollout",
+			manifest: `
+apiVersion: argoproj.io/v1alpha1
+kind: Rollout
+metadata:
+  name: rollout
+status:
+  phase: Degraded
+`,
+			expectedOK:   true,
+			expected:     config.KubernetesHealthStatusUnhealthy,
+			expectedDesc: "Rollout is degraded",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			manifests, err := ParseManifests(tc.manifest)
+			require.NoError(t, err)
+			require.Equal(t, 1, len(manifests))
+
+			status, desc, ok := DetermineHealthByRules(rules, manifests[0])
+			assert.Equal(t, tc.expectedOK, ok)
+			assert.Equal(t, tc.expected, status)
+			assert.Equal(t, tc.expectedDesc, desc)
+		})
+	}
+}
+
+func TestDetermineResourceHealthWithRules(t *testing.T) {
+	rules := []config.KubernetesHealthRule{
+		{
+			APIVersion: "serving.knative.dev/v1",
+			Kind:       "Service",
+			Checks: []config.KubernetesHealthCheck{
+				{
+					Condition: &config.KubernetesHealthCondition{Type: "Ready"},
+					Status:    config.KubernetesHealthStatusHealthy,
+				},
+			},
+		},
+	}
+	manifests, err := ParseManifests(`
+apiVersion: serving.knative.dev/v1
+kind: Service
+metadata:
+  name: hello
+status:
+  conditions:
+  - type: Ready
+    status: Unknown
+`)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(manifests))
+
+	status, desc := determineResourceHealth(manifests[0].Key, manifests[0].u, rules)
+	assert.Equal(t, model.KubernetesResourceState_OTHER, status)
+	assert.Equal(t, "Progressing: Service is progressing because no health check was matched", desc)
+
+	status, desc = determineResourceHealth(manifests[0].Key, manifests[0].u, nil)
+	assert.Equal(t, model.KubernetesResourceState_UNKNOWN, status)
+	assert.Equal(t, "Unreadable resource kind serving.knative.dev/v1/Service", desc)
+}
+
+func TestIsLatestGenerationObserved(t *testing.T) {
+	testcases := []struct {
+		name     string
+		manifest string
+		expected bool
+	}{
+		{
+			name: "no observed generation",
+			manifest: `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  generation: 1
+`,
+			expected: true,
+		},
+		{
+			name: "latest generation was observed",
+			manifest: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  generation: 2
+status:
+  observedGeneration: 2
+`,
+			expected: true,
+		},
+		{
+			name: "previous generation was observed",
+			manifest: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  generation: 3
+status:
+  observedGeneration: 2
+`,
+			expected: false,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			manifests, err := ParseManifests(tc.manifest)
+			require.NoError(t, err)
+			require.Equal(t, 1, len(manifests))
+			assert.Equal(t, tc.expected, IsLatestGenerationObserved(manifests[0]))
+		})
+	}
+}
@@ -17,11 +17,13 @@ package kubernetes
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"go.uber.org/zap"
@@ -53,7 +55,6 @@ func (c *Helm) TemplateLocalChart(ctx context.Context, appName, appDir, namespac
 
 	args := []string{
 		"template",
-		"--no-hooks",
 		releaseName,
 		chartPath,
 	}
@@ -62,14 +63,12 @@ func (c *Helm) TemplateLocalChart(ctx context.Context, appName, appDir, namespac
 		args = append(args, fmt.Sprintf("--namespace=%s", namespace))
 	}
 
-	if opts != nil {
-		for _, v := range opts.ValueFiles {
-			args = append(args, "-f", v)
-		}
-		for k, v := range opts.SetFiles {
-			args = append(args, "--set-file", fmt.Sprintf("%s=%s", k, v))
-		}
+	optionArgs, cleanup, err := makeHelmOptionArgs(opts)
+	if err != nil {
+		return "", err
 	}
+	defer cleanup()
+	args = append(args, optionArgs...)
 
 	var stdout, stderr bytes.Buffer
 	cmd := exec.CommandContext(ctx, c.execPath, args...)
@@ -131,7 +130,6 @@ func (c *Helm) TemplateRemoteChart(ctx context.Context, appName, appDir, namespa
 
 	args := []string{
 		"template",
-		"--no-hooks",
 		releaseName,
 		fmt.Sprintf("%s/%s", chart.Repository, chart.Name),
 		fmt.Sprintf("--version=%s", chart.Version),
@@ -141,14 +139,12 @@ func (c *Helm) TemplateRemoteChart(ctx context.Context, appName, appDir, namespa
 		args = append(args, fmt.Sprintf("--namespace=%s", namespace))
 	}
 
-	if opts != nil {
-		for _, v := range opts.ValueFiles {
-			args = append(args, "-f", v)
-		}
-		for k, v := range opts.SetFiles {
-			args = append(args, "--set-file", fmt.Sprintf("%s=%s", k, v))
-		}
+	optionArgs, cleanup, err := makeHelmOptionArgs(opts)
+	if err != nil {
+		return "", err
 	}
+	defer cleanup()
+	args = append(args, optionArgs...)
 
 	c.logger.Info(fmt.Sprintf("start templating a chart from Helm repository for application %s", appName),
 		zap.Any("args", args),
@@ -183,3 +179,69 @@ func (c *Helm) TemplateRemoteChart(ctx context.Context, appName, appDir, namespa
 	}
 	return executor()
 }
+
+// makeHelmOptionArgs returns the arguments of helm template command for the given options.
+// Because the inline values are passed through a temporary file,
+// the returned cleanup function must be called after running the command.
+func makeHelmOptionArgs(opts *config.InputHelmOptions) (args []string, cleanup func(), err error) {
+	cleanup = func() {}
+	if opts == nil {
+		return []string{"--no-hooks"}, cleanup, nil
+	}
+
+	if !opts.EnableHooks {
+		args = append(args, "--no-hooks")
+	}
+	if opts.IncludeCRDs {
+		args = append(args, "--include-crds")
+	}
+	if opts.KubeVersion != "" {
+		args = append(args, fmt.Sprintf("--kube-version=%s", opts.KubeVersion))
+	}
+	for _, v := range opts.APIVersions {
+		args = append(args, "--api-versions", v)
+	}
+	for _, v := range opts.ValueFiles {
+		args = append(args, "-f", v)
+	}
+
+	// The inline values are placed after the value files to take precedence over them.
+	if len(opts.Values) > 0 {
+		// JSON is a valid YAML so the values can be written as it is.
+		data, err := json.Marshal(opts.Values)
+		if err != nil {
+			return nil, cleanup, fmt.Errorf("unable to marshal helm values: %w", err)
+		}
+		f, err := ioutil.TempFile("", "helm-values-*.json")
+		if err != nil {
+			return nil, cleanup, fmt.Errorf("unable to create temporary file for helm values: %w", err)
+		}
+		cleanup = func() { os.Remove(f.Name()) }
+		_, err = f.Write(data)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			cleanup()
+			return nil, func() {}, fmt.Errorf("unable to write helm values: %w", err)
+		}
+		args = append(args, "-f", f.Name())
+	}
+
+	for _, k := range sortedKeys(opts.SetFiles) {
+		args = append(args, "--set-file", fmt.Sprintf("%s=%s", k, opts.SetFiles[k]))
+	}
+	for _, k := range sortedKeys(opts.SetValues) {
+		args = append(args, "--set", fmt.Sprintf("%s=%s", k, opts.SetValues[k]))
+	}
+	return args, cleanup, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -16,6 +16,8 @@ package kubernetes
 
 import (
 	"context"
+	"io/ioutil"
+	"os"
 	"strings"
 	"testing"
 
@@ -24,6 +26,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/pipe-cd/pipe/pkg/app/piped/toolregistry"
+	"github.com/pipe-cd/pipe/pkg/config"
 )
 
 func TestTemplateLocalChart(t *testing.T) {
@@ -73,3 +76,46 @@ func TestTemplateLocalChart_WithNamespace(t *testing.T) {
 		require.Equal(t, namespace, metadata["namespace"])
 	}
 }
+
+func TestMakeHelmOptionArgs(t *testing.T) {
+	args, cleanup, err := makeHelmOptionArgs(nil)
+	require.NoError(t, err)
+	cleanup()
+	assert.Equal(t, []string{"--no-hooks"}, args)
+
+	opts := &config.InputHelmOptions{
+		ValueFiles:  []string{"values.yaml"},
+		SetFiles:    map[string]string{"config": "config.txt"},
+		SetValues:   map[string]string{"image.tag": "v1.0.0", "replicas": "2"},
+		Values:      map[string]interface{}{"foo": "bar"},
+		APIVersions: []string{"monitoring.coreos.com/v1"},
+		KubeVersion: "1.18.0",
+		IncludeCRDs: true,
+		EnableHooks: true,
+	}
+	args, cleanup, err = makeHelmOptionArgs(opts)
+	require.NoError(t, err)
+	require.Equal(t, 14, len(args))
+
+	// The inline values are written into a temporary file.
+	valuesFile := args[7]
+	data, err := ioutil.ReadFile(valuesFile)
+	require.NoError(t, err)
+	assert.Equal(t, `{"foo":"bar"}`, string(data))
+
+	expected := []string{
+		"--include-crds",
+		"--kube-version=1.18.0",
+		"--api-versions", "monitoring.coreos.com/v1",
+		"-f", "values.yaml",
+		"-f", valuesFile,
+		"--set-file", "config=config.txt",
+		"--set", "image.tag=v1.0.0",
+		"--set", "replicas=2",
+	}
+	assert.Equal(t, expected, args)
+
+	cleanup()
+	_, err = os.Stat(valuesFile)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,105 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	helmHookAnnotation             = "helm.sh/hook"
+	helmHookWeightAnnotation       = "helm.sh/hook-weight"
+	helmHookDeletePolicyAnnotation = "helm.sh/hook-delete-policy"
+
+	HelmHookDeletePolicyBeforeHookCreation = "before-hook-creation"
+	HelmHookDeletePolicyHookSucceeded      = "hook-succeeded"
+	HelmHookDeletePolicyHookFailed         = "hook-failed"
+)
+
+// HelmHooks contains the hook manifests rendered from a Helm chart
+// ordered by their weights.
+type HelmHooks struct {
+	// The hooks should be run before syncing the resources.
+	PreSync []Manifest
+	// The hooks should be run after syncing the resources.
+	PostSync []Manifest
+}
+
+// IsEmpty returns true when there is no hook to run.
+func (h HelmHooks) IsEmpty() bool {
+	return len(h.PreSync) == 0 && len(h.PostSync) == 0
+}
+
+// SplitHelmHooks separates the Helm hook manifests from the given manifests.
+// Only install and upgrade hooks are returned, the other hooks such as test or delete
+// are dropped because they are not used while syncing the application.
+func SplitHelmHooks(manifests []Manifest) ([]Manifest, HelmHooks) {
+	var (
+		resources = make([]Manifest, 0, len(manifests))
+		hooks     HelmHooks
+	)
+	for _, m := range manifests {
+		value, ok := m.GetAnnotations()[helmHookAnnotation]
+		if !ok {
+			resources = append(resources, m)
+			continue
+		}
+		var pre, post bool
+		for _, h := range strings.Split(value, ",") {
+			switch strings.TrimSpace(h) {
+			case "pre-install", "pre-upgrade":
+				pre = true
+			case "post-install", "post-upgrade":
+				post = true
+			}
+		}
+		if pre {
+			hooks.PreSync = append(hooks.PreSync, m)
+		}
+		if post {
+			hooks.PostSync = append(hooks.PostSync, m)
+		}
+	}
+	sortHelmHooks(hooks.PreSync)
+	sortHelmHooks(hooks.PostSync)
+	return resources, hooks
+}
+
+// HelmHookDeletePolicies returns the delete policies of the given hook.
+// Helm uses before-hook-creation when nothing was specified.
+func HelmHookDeletePolicies(m Manifest) map[string]struct{} {
+	policies := make(map[string]struct{})
+	for _, p := range strings.Split(m.GetAnnotations()[helmHookDeletePolicyAnnotation], ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			policies[p] = struct{}{}
+		}
+	}
+	if len(policies) == 0 {
+		policies[HelmHookDeletePolicyBeforeHookCreation] = struct{}{}
+	}
+	return policies
+}
+
+func sortHelmHooks(hooks []Manifest) {
+	weight := func(m Manifest) int {
+		w, _ := strconv.Atoi(strings.TrimSpace(m.GetAnnotations()[helmHookWeightAnnotation]))
+		return w
+	}
+	sort.SliceStable(hooks, func(i, j int) bool {
+		return weight(hooks[i]) < weight(hooks[j])
+	})
+}
@@ -0,0 +1,77 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitHelmHooks(t *testing.T) {
+	manifests, err := ParseManifests(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+  annotations:
+    helm.sh/hook: pre-install,pre-upgrade
+    helm.sh/hook-weight: "5"
+    helm.sh/hook-delete-policy: hook-succeeded
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: migrate-config
+  annotations:
+    helm.sh/hook: pre-upgrade
+    helm.sh/hook-weight: "-1"
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: notify
+  annotations:
+    helm.sh/hook: post-install
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: test
+  annotations:
+    helm.sh/hook: test
+`)
+	require.NoError(t, err)
+
+	resources, hooks := SplitHelmHooks(manifests)
+	require.Equal(t, 1, len(resources))
+	assert.Equal(t, "app", resources[0].Key.Name)
+
+	require.Equal(t, 2, len(hooks.PreSync))
+	assert.Equal(t, "migrate-config", hooks.PreSync[0].Key.Name)
+	assert.Equal(t, "migrate", hooks.PreSync[1].Key.Name)
+
+	require.Equal(t, 1, len(hooks.PostSync))
+	assert.Equal(t, "notify", hooks.PostSync[0].Key.Name)
+
+	assert.Equal(t, map[string]struct{}{HelmHookDeletePolicyHookSucceeded: {}}, HelmHookDeletePolicies(hooks.PreSync[1]))
+	assert.Equal(t, map[string]struct{}{HelmHookDeletePolicyBeforeHookCreation: {}}, HelmHookDeletePolicies(hooks.PostSync[0]))
+}
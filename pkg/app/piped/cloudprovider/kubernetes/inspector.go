@@ -0,0 +1,91 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/pipe-cd/pipe/pkg/app/piped/toolregistry"
+	"github.com/pipe-cd/pipe/pkg/config"
+)
+
+// Inspector reads the runtime information of the running resources
+// such as the logs of the containers and the events of the resources.
+type Inspector interface {
+	// PodLogs returns the logs of a container of the given pod.
+	PodLogs(ctx context.Context, namespace, pod string, opts PodLogsOptions) ([]byte, error)
+	// Events returns the events related to the given resource.
+	Events(ctx context.Context, key ResourceKey) ([]byte, error)
+}
+
+type inspector struct {
+	clusterConfig *config.CloudProviderKubernetesConfig
+	logger        *zap.Logger
+
+	// The kubectl is prepared at the first use.
+	// Preparing is retried at the next use when it failed.
+	kubectl   *Kubectl
+	kubectlMu sync.Mutex
+}
+
+// NewInspector creates a new Inspector for the cluster specified by the given cloud provider configuration.
+func NewInspector(cfg *config.CloudProviderKubernetesConfig, logger *zap.Logger) Inspector {
+	return &inspector{
+		clusterConfig: cfg,
+		logger:        logger.Named("kubernetes-inspector"),
+	}
+}
+
+func (i *inspector) getKubectl(ctx context.Context) (*Kubectl, error) {
+	i.kubectlMu.Lock()
+	defer i.kubectlMu.Unlock()
+
+	if i.kubectl != nil {
+		return i.kubectl, nil
+	}
+	path, installed, err := toolregistry.DefaultRegistry().Kubectl(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("no kubectl (%v)", err)
+	}
+	if installed {
+		i.logger.Info("kubectl has just been installed because of no pre-installed binary")
+	}
+	kubectl := NewKubectl("", path)
+	if i.clusterConfig != nil {
+		kubectl = kubectl.WithCluster(i.clusterConfig.MasterURL, i.clusterConfig.KubeConfigPath)
+	}
+	i.kubectl = kubectl
+	return kubectl, nil
+}
+
+func (i *inspector) PodLogs(ctx context.Context, namespace, pod string, opts PodLogsOptions) ([]byte, error) {
+	kubectl, err := i.getKubectl(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return kubectl.PodLogs(ctx, namespace, pod, opts)
+}
+
+func (i *inspector) Events(ctx context.Context, key ResourceKey) ([]byte, error) {
+	kubectl, err := i.getKubectl(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return kubectl.Events(ctx, key.Namespace, key)
+}
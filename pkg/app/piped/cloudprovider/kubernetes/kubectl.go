@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os/exec"
 	"strings"
 
@@ -25,9 +26,11 @@ import (
 )
 
 type Kubectl struct {
-	version  string
-	execPath string
-	config   *rest.Config
+	version        string
+	execPath       string
+	config         *rest.Config
+	masterURL      string
+	kubeConfigPath string
 }
 
 func NewKubectl(version, path string) *Kubectl {
@@ -37,6 +40,29 @@ func NewKubectl(version, path string) *Kubectl {
 	}
 }
 
+// WithCluster returns a copy of this Kubectl that runs all commands
+// against the cluster specified by the given master URL and kubeconfig.
+// Empty values mean using the default ones of kubectl.
+func (c *Kubectl) WithCluster(masterURL, kubeConfigPath string) *Kubectl {
+	cc := *c
+	cc.masterURL = masterURL
+	cc.kubeConfigPath = kubeConfigPath
+	return &cc
+}
+
+// makeArgs returns a new args list that already contains
+// the flags for connecting to the configured cluster.
+func (c *Kubectl) makeArgs(size int) []string {
+	args := make([]string, 0, size+4)
+	if c.kubeConfigPath != "" {
+		args = append(args, "--kubeconfig", c.kubeConfigPath)
+	}
+	if c.masterURL != "" {
+		args = append(args, "--server", c.masterURL)
+	}
+	return args
+}
+
 func (c *Kubectl) Apply(ctx context.Context, namespace string, manifest Manifest) (err error) {
 	defer func() {
 		metricsKubectlCalled(c.version, "apply", err == nil)
@@ -47,7 +73,7 @@ func (c *Kubectl) Apply(ctx context.Context, namespace string, manifest Manifest
 		return err
 	}
 
-	args := make([]string, 0, 5)
+	args := c.makeArgs(5)
 	if namespace != "" {
 		args = append(args, "-n", namespace)
 	}
@@ -64,12 +90,54 @@ func (c *Kubectl) Apply(ctx context.Context, namespace string, manifest Manifest
 	return nil
 }
 
+// ServerSideApply applies all given manifests at once by using server-side apply.
+// The applied fields are owned by the PipeCD field manager and an ApplyConflictError
+// is returned when some of them are owned by other managers unless forceConflicts is true.
+func (c *Kubectl) ServerSideApply(ctx context.Context, namespace string, manifests []Manifest, forceConflicts bool) (err error) {
+	defer func() {
+		metricsKubectlCalled(c.version, "server-side-apply", err == nil)
+	}()
+
+	var buf bytes.Buffer
+	for i, m := range manifests {
+		data, err := m.YamlBytes()
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		buf.Write(data)
+	}
+
+	args := c.makeArgs(8)
+	if namespace != "" {
+		args = append(args, "-n", namespace)
+	}
+	args = append(args, "apply", "--server-side", fmt.Sprintf("--field-manager=%s", FieldManager), "-f", "-")
+	if forceConflicts {
+		args = append(args, "--force-conflicts")
+	}
+
+	cmd := exec.CommandContext(ctx, c.execPath, args...)
+	cmd.Stdin = &buf
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if conflicts := parseApplyConflicts(string(out)); len(conflicts) > 0 {
+			return &ApplyConflictError{Conflicts: conflicts}
+		}
+		return fmt.Errorf("failed to apply: %s (%v)", string(out), err)
+	}
+	return nil
+}
+
 func (c *Kubectl) Delete(ctx context.Context, namespace string, r ResourceKey) (err error) {
 	defer func() {
 		metricsKubectlCalled(c.version, "delete", err == nil)
 	}()
 
-	args := make([]string, 0, 5)
+	args := c.makeArgs(5)
 	if namespace != "" {
 		args = append(args, "-n", namespace)
 	}
@@ -86,3 +154,188 @@ func (c *Kubectl) Delete(ctx context.Context, namespace string, r ResourceKey) (
 	}
 	return nil
 }
+
+func (c *Kubectl) Get(ctx context.Context, namespace string, r ResourceKey) (m Manifest, err error) {
+	defer func() {
+		metricsKubectlCalled(c.version, "get", err == nil)
+	}()
+
+	args := c.makeArgs(6)
+	if namespace != "" {
+		args = append(args, "-n", namespace)
+	}
+	args = append(args, "get", r.Kind, r.Name, "-o", "yaml")
+
+	cmd := exec.CommandContext(ctx, c.execPath, args...)
+	out, err := cmd.Output()
+	if err != nil {
+		return Manifest{}, fmt.Errorf("failed to get: %s, %v", string(out), err)
+	}
+	manifests, err := ParseManifests(string(out))
+	if err != nil {
+		return Manifest{}, err
+	}
+	if len(manifests) != 1 {
+		return Manifest{}, fmt.Errorf("unexpected number of manifests: %d", len(manifests))
+	}
+	return manifests[0], nil
+}
+
+// Logs writes the logs of all containers of the given resource into the writer.
+// It keeps streaming until the containers have been terminated or the context is done.
+func (c *Kubectl) Logs(ctx context.Context, namespace string, r ResourceKey, w io.Writer) (err error) {
+	defer func() {
+		metricsKubectlCalled(c.version, "logs", err == nil)
+	}()
+
+	args := c.makeArgs(7)
+	if namespace != "" {
+		args = append(args, "-n", namespace)
+	}
+	args = append(args, "logs", "--follow", "--all-containers", fmt.Sprintf("%s/%s", strings.ToLower(r.Kind), r.Name))
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.execPath, args...)
+	cmd.Stdout = w
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to get logs: %s, %v", stderr.String(), err)
+	}
+	return nil
+}
+
+// PodLogsOptions specifies which logs of a pod container should be read.
+type PodLogsOptions struct {
+	// The name of the container. Empty means the only container of the pod.
+	Container string
+	// The number of lines from the end of the logs. Zero means all lines.
+	TailLines int64
+	// Only the logs newer than this number of seconds. Zero means all logs.
+	SinceSeconds int64
+	// Whether to read the logs of the previous terminated container.
+	Previous bool
+	// The maximum number of bytes to read. Zero means no limit.
+	LimitBytes int64
+}
+
+// PodLogs returns the logs of a container of the given pod.
+func (c *Kubectl) PodLogs(ctx context.Context, namespace, pod string, opts PodLogsOptions) (out []byte, err error) {
+	defer func() {
+		metricsKubectlCalled(c.version, "logs", err == nil)
+	}()
+
+	args := c.makeArgs(9)
+	if namespace != "" {
+		args = append(args, "-n", namespace)
+	}
+	args = append(args, "logs", pod)
+	if opts.Container != "" {
+		args = append(args, "-c", opts.Container)
+	}
+	if opts.TailLines > 0 {
+		args = append(args, fmt.Sprintf("--tail=%d", opts.TailLines))
+	}
+	if opts.SinceSeconds > 0 {
+		args = append(args, fmt.Sprintf("--since=%ds", opts.SinceSeconds))
+	}
+	if opts.Previous {
+		args = append(args, "--previous")
+	}
+	if opts.LimitBytes > 0 {
+		args = append(args, fmt.Sprintf("--limit-bytes=%d", opts.LimitBytes))
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.execPath, args...)
+	cmd.Stderr = &stderr
+
+	out, err = cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get logs: %s, %v", stderr.String(), err)
+	}
+	return out, nil
+}
+
+// Events returns the events related to the given resource in the readable table format.
+func (c *Kubectl) Events(ctx context.Context, namespace string, r ResourceKey) (out []byte, err error) {
+	defer func() {
+		metricsKubectlCalled(c.version, "get-events", err == nil)
+	}()
+
+	args := c.makeArgs(7)
+	if namespace != "" {
+		args = append(args, "-n", namespace)
+	}
+	args = append(args,
+		"get", "events",
+		"--field-selector", fmt.Sprintf("involvedObject.kind=%s,involvedObject.name=%s", r.Kind, r.Name),
+		"--sort-by", ".lastTimestamp",
+	)
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.execPath, args...)
+	cmd.Stderr = &stderr
+
+	out, err = cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get events: %s, %v", stderr.String(), err)
+	}
+	return out, nil
+}
+
+// ApplyConflictError is returned when server-side apply failed
+// because some of the applied fields are owned by other field managers.
+type ApplyConflictError struct {
+	// The readable descriptions of all conflicts.
+	Conflicts []string
+}
+
+func (e *ApplyConflictError) Error() string {
+	return fmt.Sprintf("apply failed with %d conflict(s): %s", len(e.Conflicts), strings.Join(e.Conflicts, "; "))
+}
+
+// parseApplyConflicts extracts the conflicts from the output of server-side apply.
+// The output looks like:
+//   Name: "foo", Namespace: "default"
+//   for: "STDIN": Apply failed with 1 conflict: conflict with "kube-controller-manager" using apps/v1: .spec.replicas
+// or in case of multiple conflicts:
+//   error: Apply failed with 2 conflicts: conflicts with "manager-a":
+//   - .spec.replicas
+//   - .spec.template.spec.containers[name="helloworld"].image
+func parseApplyConflicts(out string) []string {
+	var (
+		conflicts []string
+		resource  string
+	)
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "Name: ") {
+			resource = line
+			continue
+		}
+		if strings.HasPrefix(line, "- ") && len(conflicts) > 0 {
+			last := len(conflicts) - 1
+			sep := ", "
+			if strings.HasSuffix(conflicts[last], ":") {
+				sep = " "
+			}
+			conflicts[last] += sep + strings.TrimPrefix(line, "- ")
+			continue
+		}
+
+		idx := strings.Index(line, "conflict with \"")
+		if i := strings.Index(line, "conflicts with \""); i >= 0 && (idx < 0 || i < idx) {
+			idx = i
+		}
+		if idx < 0 {
+			continue
+		}
+		conflict := line[idx:]
+		if resource != "" {
+			conflict = fmt.Sprintf("%s: %s", resource, conflict)
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	return conflicts
+}
@@ -0,0 +1,74 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseApplyConflicts(t *testing.T) {
+	testcases := []struct {
+		name     string
+		out      string
+		expected []string
+	}{
+		{
+			name:     "no conflict",
+			out:      "deployment.apps/simple serverside-applied\n",
+			expected: nil,
+		},
+		{
+			name: "single conflict",
+			out: `error: Apply failed with 1 conflict: conflict with "kube-controller-manager" using apps/v1: .spec.replicas
+Please review the fields above--they currently have other managers.`,
+			expected: []string{
+				`conflict with "kube-controller-manager" using apps/v1: .spec.replicas`,
+			},
+		},
+		{
+			name: "multiple conflicts",
+			out: `error: Apply failed with 3 conflicts: conflicts with "manager-a":
+- .spec.replicas
+- .spec.template.spec.containers[name="helloworld"].image
+conflicts with "manager-b" using v1:
+- .data.key
+Please review the fields above--they currently have other managers.`,
+			expected: []string{
+				`conflicts with "manager-a": .spec.replicas, .spec.template.spec.containers[name="helloworld"].image`,
+				`conflicts with "manager-b" using v1: .data.key`,
+			},
+		},
+		{
+			name: "conflict on one of the batched resources",
+			out: `deployment.apps/simple serverside-applied
+Error from server (Conflict): error when applying patch:
+to:
+Resource: "/v1, Resource=configmaps", GroupVersionKind: "/v1, Kind=ConfigMap"
+Name: "simple", Namespace: "default"
+for: "STDIN": Apply failed with 1 conflict: conflict with "kubectl-edit" using v1: .data.key`,
+			expected: []string{
+				`Name: "simple", Namespace: "default": conflict with "kubectl-edit" using v1: .data.key`,
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			conflicts := parseApplyConflicts(tc.out)
+			assert.Equal(t, tc.expected, conflicts)
+		})
+	}
+}
@@ -24,6 +24,7 @@ import (
 
 	"go.uber.org/zap"
 
+	"github.com/pipe-cd/pipe/pkg/app/piped/chartrepo"
 	"github.com/pipe-cd/pipe/pkg/app/piped/toolregistry"
 	"github.com/pipe-cd/pipe/pkg/config"
 	"github.com/pipe-cd/pipe/pkg/git"
@@ -33,6 +34,9 @@ var (
 	ErrNotFound = errors.New("not found")
 )
 
+// FieldManager is the name of the field manager used for server-side apply.
+const FieldManager = "pipecd"
+
 const (
 	LabelManagedBy            = "pipecd.dev/managed-by"             // Always be piped.
 	LabelPiped                = "pipecd.dev/piped"                  // The id of piped handling this application.
@@ -70,8 +74,12 @@ type Applier interface {
 	Apply(ctx context.Context) error
 	// ApplyManifest does applying the given manifest.
 	ApplyManifest(ctx context.Context, manifest Manifest) error
+	// ServerSideApply applies all given manifests by using server-side apply.
+	ServerSideApply(ctx context.Context, manifests []Manifest, forceConflicts bool) error
 	// Delete deletes the given resource from Kubernetes cluster.
 	Delete(ctx context.Context, key ResourceKey) error
+	// Get returns the live manifest of the given resource in Kubernetes cluster.
+	Get(ctx context.Context, key ResourceKey) (Manifest, error)
 }
 
 type gitClient interface {
@@ -90,6 +98,7 @@ type provider struct {
 	repoDir        string
 	configFileName string
 	input          config.KubernetesDeploymentInput
+	clusterConfig  *config.CloudProviderKubernetesConfig
 	logger         *zap.Logger
 
 	kubectl          *Kubectl
@@ -112,8 +121,19 @@ func initSharedGitClient(logger *zap.Logger) error {
 	return err
 }
 
-func NewProvider(appName, appDir, repoDir, configFileName string, input config.KubernetesDeploymentInput, logger *zap.Logger) Provider {
-	return &provider{
+type Option func(*provider)
+
+// WithClusterConfig configures the provider to apply and delete resources
+// on the cluster specified by the given cloud provider configuration.
+// Without this option the default cluster of kubectl will be used.
+func WithClusterConfig(cfg *config.CloudProviderKubernetesConfig) Option {
+	return func(p *provider) {
+		p.clusterConfig = cfg
+	}
+}
+
+func NewProvider(appName, appDir, repoDir, configFileName string, input config.KubernetesDeploymentInput, logger *zap.Logger, opts ...Option) Provider {
+	p := &provider{
 		appName:        appName,
 		appDir:         appDir,
 		repoDir:        repoDir,
@@ -121,6 +141,10 @@ func NewProvider(appName, appDir, repoDir, configFileName string, input config.K
 		input:          input,
 		logger:         logger.Named("kubernetes-provider"),
 	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
 }
 
 func NewManifestLoader(appName, appDir, repoDir, configFileName string, input config.KubernetesDeploymentInput, logger *zap.Logger) ManifestLoader {
@@ -140,6 +164,9 @@ func (p *provider) init(ctx context.Context) {
 	if p.initErr != nil {
 		return
 	}
+	if p.clusterConfig != nil {
+		p.kubectl = p.kubectl.WithCluster(p.clusterConfig.MasterURL, p.clusterConfig.KubeConfigPath)
+	}
 
 	switch p.templatingMethod {
 	case TemplatingMethodHelm:
@@ -175,6 +202,24 @@ func (p *provider) LoadManifests(ctx context.Context) (manifests []Manifest, err
 				sharedGitClient,
 				p.input.HelmOptions)
 
+		case p.input.HelmChart.IsOCI():
+			var chartPath string
+			chartPath, err = chartrepo.PullOCIChart(ctx,
+				p.input.HelmChart.OCIReference(),
+				p.input.HelmChart.Version,
+				toolregistry.DefaultRegistry(),
+				p.logger)
+			if err != nil {
+				err = fmt.Errorf("unable to pull helm chart from OCI registry: %w", err)
+				return
+			}
+			data, err = p.helm.TemplateLocalChart(ctx,
+				p.appName,
+				p.appDir,
+				p.input.Namespace,
+				chartPath,
+				p.input.HelmOptions)
+
 		case p.input.HelmChart.Repository != "":
 			chart := helmRemoteChart{
 				Repository: p.input.HelmChart.Repository,
@@ -237,6 +282,33 @@ func (p *provider) ApplyManifest(ctx context.Context, manifest Manifest) error {
 	return p.kubectl.Apply(ctx, p.getNamespaceToRun(manifest.Key), manifest)
 }
 
+// ServerSideApply applies all given manifests by using server-side apply.
+// The manifests are applied in batches, one for each namespace.
+func (p *provider) ServerSideApply(ctx context.Context, manifests []Manifest, forceConflicts bool) error {
+	p.initOnce.Do(func() { p.init(ctx) })
+	if p.initErr != nil {
+		return p.initErr
+	}
+
+	var (
+		namespaces []string
+		batches    = make(map[string][]Manifest)
+	)
+	for _, m := range manifests {
+		ns := p.getNamespaceToRun(m.Key)
+		if _, ok := batches[ns]; !ok {
+			namespaces = append(namespaces, ns)
+		}
+		batches[ns] = append(batches[ns], m)
+	}
+	for _, ns := range namespaces {
+		if err := p.kubectl.ServerSideApply(ctx, ns, batches[ns], forceConflicts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Delete deletes the given resource from Kubernetes cluster.
 func (p *provider) Delete(ctx context.Context, k ResourceKey) (err error) {
 	p.initOnce.Do(func() { p.init(ctx) })
@@ -247,6 +319,16 @@ func (p *provider) Delete(ctx context.Context, k ResourceKey) (err error) {
 	return p.kubectl.Delete(ctx, p.getNamespaceToRun(k), k)
 }
 
+// Get returns the live manifest of the given resource in Kubernetes cluster.
+func (p *provider) Get(ctx context.Context, k ResourceKey) (Manifest, error) {
+	p.initOnce.Do(func() { p.init(ctx) })
+	if p.initErr != nil {
+		return Manifest{}, p.initErr
+	}
+
+	return p.kubectl.Get(ctx, p.getNamespaceToRun(k), k)
+}
+
 // getNamespaceToRun returns namespace used on kubectl apply/delete commands.
 // priority: config.KubernetesDeploymentInput > kubernetes.ResourceKey
 func (p *provider) getNamespaceToRun(k ResourceKey) string {
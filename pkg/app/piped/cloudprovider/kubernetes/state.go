@@ -27,15 +27,19 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/kubernetes/scheme"
 
+	"github.com/pipe-cd/pipe/pkg/config"
 	"github.com/pipe-cd/pipe/pkg/model"
 )
 
-func MakeKubernetesResourceState(uid string, key ResourceKey, obj *unstructured.Unstructured, now time.Time) model.KubernetesResourceState {
+// MakeKubernetesResourceState builds the state of the given resource.
+// The given health rules are used to determine the health status of the resources
+// whose kinds are not known by piped, such as custom resources.
+func MakeKubernetesResourceState(uid string, key ResourceKey, obj *unstructured.Unstructured, rules []config.KubernetesHealthRule, now time.Time) model.KubernetesResourceState {
 	var (
 		owners       = obj.GetOwnerReferences()
 		ownerIDs     = make([]string, 0, len(owners))
 		creationTime = obj.GetCreationTimestamp()
-		status, desc = determineResourceHealth(key, obj)
+		status, desc = determineResourceHealth(key, obj, rules)
 	)
 
 	for _, owner := range owners {
@@ -63,7 +67,11 @@ func MakeKubernetesResourceState(uid string, key ResourceKey, obj *unstructured.
 	return state
 }
 
-func determineResourceHealth(key ResourceKey, obj *unstructured.Unstructured) (status model.KubernetesResourceState_HealthStatus, desc string) {
+func determineResourceHealth(key ResourceKey, obj *unstructured.Unstructured, rules []config.KubernetesHealthRule) (status model.KubernetesResourceState_HealthStatus, desc string) {
+	if health, d, ok := DetermineHealthByRules(rules, MakeManifest(key, obj)); ok {
+		return makeResourceHealthStatus(health, d)
+	}
+
 	if !IsKubernetesBuiltInResource(key.APIVersion) {
 		desc = fmt.Sprintf("Unreadable resource kind %s/%s", key.APIVersion, key.Kind)
 		return
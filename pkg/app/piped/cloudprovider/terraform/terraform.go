@@ -17,6 +17,7 @@ package terraform
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os/exec"
@@ -25,6 +26,10 @@ import (
 	"strings"
 )
 
+// ErrStalePlan is returned when applying a plan file
+// which can no longer be applied because the state was changed after it was created.
+var ErrStalePlan = errors.New("saved plan is stale")
+
 type Terraform struct {
 	execPath string
 	dir      string
@@ -104,6 +109,17 @@ func (r PlanResult) NoChanges() bool {
 }
 
 func (t *Terraform) Plan(ctx context.Context, w io.Writer) (PlanResult, error) {
+	return t.plan(ctx, w, "")
+}
+
+// SavePlan works like Plan but also saves the generated plan into the given file
+// so that it can be applied later by ApplyPlan.
+// The path of the plan file is relative to the working directory.
+func (t *Terraform) SavePlan(ctx context.Context, w io.Writer, planFile string) (PlanResult, error) {
+	return t.plan(ctx, w, planFile)
+}
+
+func (t *Terraform) plan(ctx context.Context, w io.Writer, planFile string) (PlanResult, error) {
 	args := []string{
 		"plan",
 		// TODO: Remove this -no-color flag after parsePlanResult supports parsing the message containing color codes.
@@ -115,6 +131,9 @@ func (t *Terraform) Plan(ctx context.Context, w io.Writer) (PlanResult, error) {
 	for _, f := range t.varFiles {
 		args = append(args, fmt.Sprintf("-var-file=%s", f))
 	}
+	if planFile != "" {
+		args = append(args, fmt.Sprintf("-out=%s", planFile))
+	}
 	args = append(args, "-lock=false", ".")
 
 	var buf bytes.Buffer
@@ -195,3 +214,36 @@ func (t *Terraform) Apply(ctx context.Context, w io.Writer) error {
 	io.WriteString(w, fmt.Sprintf("terraform %s", strings.Join(args, " ")))
 	return cmd.Run()
 }
+
+// ApplyPlan applies the plan file saved by SavePlan.
+// ErrStalePlan is returned when the state was changed after the plan was created.
+func (t *Terraform) ApplyPlan(ctx context.Context, w io.Writer, planFile string) error {
+	// The variables must not be specified here
+	// because they were already saved into the plan file.
+	args := []string{
+		"apply",
+		"-input=false",
+		planFile,
+	}
+
+	var buf bytes.Buffer
+	stdout := io.MultiWriter(w, &buf)
+
+	cmd := exec.CommandContext(ctx, t.execPath, args...)
+	cmd.Dir = t.dir
+	cmd.Stdout = stdout
+	cmd.Stderr = stdout
+
+	io.WriteString(w, fmt.Sprintf("terraform %s", strings.Join(args, " ")))
+	if err := cmd.Run(); err != nil {
+		if isStalePlanOutput(buf.String()) {
+			return ErrStalePlan
+		}
+		return err
+	}
+	return nil
+}
+
+func isStalePlanOutput(out string) bool {
+	return strings.Contains(out, "Saved plan is stale")
+}
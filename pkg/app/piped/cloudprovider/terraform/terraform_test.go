@@ -13,3 +13,41 @@
 // limitations under the License.
 
 package terraform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsStalePlanOutput(t *testing.T) {
+	testcases := []struct {
+		name     string
+		out      string
+		expected bool
+	}{
+		{
+			name: "stale plan",
+			out: `
+Error: Saved plan is stale
+
+The given plan file can no longer be applied because the state was changed by
+another operation after the plan was created.
+`,
+			expected: true,
+		},
+		{
+			name: "other error",
+			out: `
+Error: Failed to load plugin schemas
+`,
+			expected: false,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := isStalePlanOutput(tc.out)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
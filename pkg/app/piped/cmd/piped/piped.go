@@ -20,12 +20,16 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
 
 	"github.com/pipe-cd/pipe/pkg/admin"
 	"github.com/pipe-cd/pipe/pkg/app/api/service/pipedservice"
@@ -35,12 +39,15 @@ import (
 	"github.com/pipe-cd/pipe/pkg/app/piped/apistore/deploymentstore"
 	"github.com/pipe-cd/pipe/pkg/app/piped/apistore/environmentstore"
 	"github.com/pipe-cd/pipe/pkg/app/piped/chartrepo"
+	"github.com/pipe-cd/pipe/pkg/app/piped/configreloader"
 	"github.com/pipe-cd/pipe/pkg/app/piped/controller"
 	"github.com/pipe-cd/pipe/pkg/app/piped/driftdetector"
 	"github.com/pipe-cd/pipe/pkg/app/piped/imagewatcher"
 	"github.com/pipe-cd/pipe/pkg/app/piped/livestatereporter"
 	"github.com/pipe-cd/pipe/pkg/app/piped/livestatestore"
 	"github.com/pipe-cd/pipe/pkg/app/piped/notifier"
+	"github.com/pipe-cd/pipe/pkg/app/piped/resourceinspector"
+	"github.com/pipe-cd/pipe/pkg/app/piped/resourcepruner"
 	"github.com/pipe-cd/pipe/pkg/app/piped/statsreporter"
 	"github.com/pipe-cd/pipe/pkg/app/piped/toolregistry"
 	"github.com/pipe-cd/pipe/pkg/app/piped/trigger"
@@ -62,6 +69,7 @@ import (
 
 type piped struct {
 	configFile                           string
+	configSecretsDir                     string
 	insecure                             bool
 	certFile                             string
 	adminPort                            int
@@ -84,6 +92,7 @@ func NewCommand() *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&p.configFile, "config-file", p.configFile, "The path to the configuration file.")
+	cmd.Flags().StringVar(&p.configSecretsDir, "config-secrets-dir", p.configSecretsDir, "The path to the directory containing the secrets referenced by the centrally managed configuration.")
 
 	cmd.Flags().BoolVar(&p.insecure, "insecure", p.insecure, "Whether disabling transport security while connecting to control-plane.")
 	cmd.Flags().StringVar(&p.certFile, "cert-file", p.certFile, "The path to the TLS certificate file.")
@@ -103,12 +112,35 @@ func (p *piped) run(ctx context.Context, t cli.Telemetry) (runErr error) {
 	group, ctx := errgroup.WithContext(ctx)
 
 	// Load piped configuration from specified file.
-	cfg, err := p.loadConfig()
+	localCfg, err := p.loadLocalConfig()
 	if err != nil {
 		t.Logger.Error("failed to load piped configuration", zap.Error(err))
 		return err
 	}
 
+	// Make gRPC client and connect to the API.
+	apiClient, err := p.createAPIClient(ctx, localCfg.APIAddress, localCfg.ProjectID, localCfg.PipedID, localCfg.PipedKeyFile, t.Logger)
+	if err != nil {
+		t.Logger.Error("failed to create gRPC client to control plane", zap.Error(err))
+		return err
+	}
+
+	// Use the centrally managed configuration instead of the local one if it was set.
+	cfg, err := p.loadConfig(ctx, apiClient)
+	if err != nil {
+		t.Logger.Error("failed to load piped configuration", zap.Error(err))
+		return err
+	}
+	if cfg.ConfigVersion > 0 {
+		t.Logger.Info(fmt.Sprintf("using the centrally managed configuration of version %d", cfg.ConfigVersion))
+	}
+
+	// The config reloader applies the changes of the configuration
+	// to the registered components without restarting piped.
+	reloader := configreloader.NewReloader(cfg, func(ctx context.Context) (*config.PipedSpec, error) {
+		return p.loadConfig(ctx, apiClient)
+	}, t.Logger)
+
 	// Initialize notifier and add piped events.
 	notifier, err := notifier.NewNotifier(cfg, t.Logger)
 	if err != nil {
@@ -118,6 +150,7 @@ func (p *piped) run(ctx context.Context, t cli.Telemetry) (runErr error) {
 	group.Go(func() error {
 		return notifier.Run(ctx)
 	})
+	reloader.Register("notifier", notifier)
 
 	// Configure SSH config if needed.
 	if cfg.Git.ShouldConfigureSSHConfig() {
@@ -149,11 +182,14 @@ func (p *piped) run(ctx context.Context, t cli.Telemetry) (runErr error) {
 		}
 	}
 
-	// Make gRPC client and connect to the API.
-	apiClient, err := p.createAPIClient(ctx, cfg.APIAddress, cfg.ProjectID, cfg.PipedID, cfg.PipedKeyFile, t.Logger)
-	if err != nil {
-		t.Logger.Error("failed to create gRPC client to control plane", zap.Error(err))
-		return err
+	// Login to configured OCI registries storing Helm charts.
+	if len(cfg.ChartRegistries) > 0 {
+		reg := toolregistry.DefaultRegistry()
+		cacheDir := filepath.Join(p.toolsDir, "charts")
+		if err := chartrepo.InitOCIRegistries(ctx, cfg.ChartRegistries, cfg.ImageProviders, cacheDir, reg, t.Logger); err != nil {
+			t.Logger.Error("failed to login to configured chart registries", zap.Error(err))
+			return err
+		}
 	}
 
 	// Send the newest piped meta to the control-plane.
@@ -269,6 +305,25 @@ func (p *piped) run(ctx context.Context, t cli.Telemetry) (runErr error) {
 		return err
 	}
 
+	// Start running deployment trigger.
+	// It is started before the drift detector since
+	// the detected drifts are passed to it for the automatic sync.
+	tr := trigger.NewTrigger(
+		apiClient,
+		gitClient,
+		applicationLister,
+		commandLister,
+		environmentStore,
+		notifier,
+		cfg,
+		p.gracePeriod,
+		t.Logger,
+	)
+	group.Go(func() error {
+		return tr.Run(ctx)
+	})
+	reloader.Register("trigger", tr)
+
 	// Start running application application drift detector.
 	{
 		d := driftdetector.NewDetector(
@@ -277,6 +332,7 @@ func (p *piped) run(ctx context.Context, t cli.Telemetry) (runErr error) {
 			gitClient,
 			liveStateGetter,
 			apiClient,
+			tr,
 			appManifestsCache,
 			cfg,
 			decrypter,
@@ -285,6 +341,7 @@ func (p *piped) run(ctx context.Context, t cli.Telemetry) (runErr error) {
 		group.Go(func() error {
 			return d.Run(ctx)
 		})
+		reloader.Register("drift-detector", d)
 	}
 
 	// Start running deployment controller.
@@ -308,23 +365,32 @@ func (p *piped) run(ctx context.Context, t cli.Telemetry) (runErr error) {
 		group.Go(func() error {
 			return c.Run(ctx)
 		})
+		reloader.Register("controller", c)
 	}
 
-	// Start running deployment trigger.
+	// Start running resource pruner.
 	{
-		t := trigger.NewTrigger(
-			apiClient,
-			gitClient,
-			applicationLister,
+		pr := resourcepruner.NewPruner(
 			commandLister,
-			environmentStore,
-			notifier,
+			livestatestore.LiveResourceLister{Getter: liveStateGetter},
 			cfg,
-			p.gracePeriod,
 			t.Logger,
 		)
 		group.Go(func() error {
-			return t.Run(ctx)
+			return pr.Run(ctx)
+		})
+	}
+
+	// Start running resource inspector.
+	{
+		ri := resourceinspector.NewInspector(
+			commandLister,
+			liveStateGetter,
+			cfg,
+			t.Logger,
+		)
+		group.Go(func() error {
+			return ri.Run(ctx)
 		})
 	}
 
@@ -340,6 +406,16 @@ func (p *piped) run(ctx context.Context, t cli.Telemetry) (runErr error) {
 		})
 	}
 
+	// Start running config reloader.
+	// The piped meta must be reported after applying to all other components
+	// since it acknowledges the applied configuration version to the control-plane.
+	reloader.Register("piped-meta", configreloader.ComponentFunc(func(ctx context.Context, cfg *config.PipedSpec) error {
+		return p.sendPipedMeta(ctx, apiClient, cfg, t.Logger)
+	}))
+	group.Go(func() error {
+		return reloader.Run(ctx)
+	})
+
 	// Wait until all piped components have finished.
 	// A terminating signal or a finish of any components
 	// could trigger the finish of piped.
@@ -393,8 +469,48 @@ func (p *piped) createAPIClient(ctx context.Context, address, projectID, pipedID
 	return client, nil
 }
 
-// loadConfig reads the Piped configuration data from the specified file.
-func (p *piped) loadConfig() (*config.PipedSpec, error) {
+// loadConfig loads the centrally managed configuration of this piped
+// if it was set in the control-plane, otherwise the local configuration file is used.
+func (p *piped) loadConfig(ctx context.Context, client pipedservice.Client) (*config.PipedSpec, error) {
+	local, err := p.loadLocalConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.GetDesiredPipedConfig(ctx, &pipedservice.GetDesiredPipedConfigRequest{})
+	if status.Code(err) == codes.NotFound {
+		return local, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the desired configuration from control-plane (%w)", err)
+	}
+
+	cfg, err := config.DecodeCentralPipedSpec([]byte(resp.Revision.Data), local, p.resolveConfigSecret)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration of version %d (%w)", resp.Revision.Version, err)
+	}
+	cfg.ConfigVersion = resp.Revision.Version
+	if p.enableDefaultKubernetesCloudProvider {
+		cfg.EnableDefaultKubernetesCloudProvider()
+	}
+	return cfg, nil
+}
+
+// resolveConfigSecret reads the value of the secret referenced in the centrally managed configuration.
+// Each secret is stored as a file named by its name inside the configured secrets directory.
+func (p *piped) resolveConfigSecret(name string) (string, error) {
+	if p.configSecretsDir == "" {
+		return "", fmt.Errorf("config-secrets-dir must be set to resolve the secret references")
+	}
+	data, err := ioutil.ReadFile(filepath.Join(p.configSecretsDir, name))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// loadLocalConfig reads the Piped configuration data from the specified file.
+func (p *piped) loadLocalConfig() (*config.PipedSpec, error) {
 	cfg, err := config.LoadFromYAML(p.configFile)
 	if err != nil {
 		return nil, err
@@ -451,6 +567,7 @@ func (p *piped) sendPipedMeta(ctx context.Context, client pipedservice.Client, c
 	var (
 		req = &pipedservice.ReportPipedMetaRequest{
 			Version:        version.Get().Version,
+			ConfigVersion:  cfg.ConfigVersion,
 			Repositories:   repos,
 			CloudProviders: make([]*model.Piped_CloudProvider, 0, len(cfg.CloudProviders)),
 		}
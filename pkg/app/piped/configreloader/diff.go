@@ -0,0 +1,160 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configreloader
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pipe-cd/pipe/pkg/config"
+)
+
+// reloadableFields contains the fields whose changes
+// can be applied to the running piped.
+var reloadableFields = map[string]struct{}{
+	"webAddress":               {},
+	"syncInterval":             {},
+	"maxConcurrentDeployments": {},
+	"repositories":             {},
+	"analysisProviders":        {},
+	"notifications.routes":     {},
+	"notifications.receivers":  {},
+	"configVersion":            {},
+}
+
+// Change represents a change of a field in the piped configuration.
+// For a list of named items, the names of the changed items are listed.
+type Change struct {
+	Field   string
+	Added   []string
+	Removed []string
+	Updated []string
+}
+
+type Changes []Change
+
+// UnreloadableFields returns the list of changed fields
+// whose changes could not be applied without restarting piped.
+func (cs Changes) UnreloadableFields() []string {
+	var fields []string
+	for _, c := range cs {
+		if _, ok := reloadableFields[c.Field]; !ok {
+			fields = append(fields, c.Field)
+		}
+	}
+	return fields
+}
+
+// namedListKeys contains the fields holding a list of named items
+// and the key field used to match their items.
+var namedListKeys = map[string]string{
+	"repositories":            "RepoID",
+	"chartRepositories":       "Name",
+	"chartRegistries":         "Address",
+	"cloudProviders":          "Name",
+	"analysisProviders":       "Name",
+	"imageProviders":          "Name",
+	"notifications.routes":    "Name",
+	"notifications.receivers": "Name",
+}
+
+// nestedFields contains the struct fields whose own fields are compared separately.
+var nestedFields = map[string]struct{}{
+	"notifications": {},
+}
+
+// Diff compares the given configurations and returns the list of changed fields.
+// All fields are compared, so a newly added field is treated as unreloadable
+// until it is listed in reloadableFields.
+func Diff(x, y *config.PipedSpec) Changes {
+	return diffStruct("", reflect.ValueOf(x).Elem(), reflect.ValueOf(y).Elem())
+}
+
+func diffStruct(prefix string, x, y reflect.Value) Changes {
+	var cs Changes
+	for i := 0; i < x.NumField(); i++ {
+		var (
+			field  = prefix + fieldName(x.Type().Field(i))
+			vx, vy = x.Field(i), y.Field(i)
+		)
+		if _, ok := nestedFields[field]; ok {
+			cs = append(cs, diffStruct(field+".", vx, vy)...)
+			continue
+		}
+		if key, ok := namedListKeys[field]; ok {
+			if c, ok := diffNamedList(field, vx.Interface(), vy.Interface(), key); ok {
+				cs = append(cs, c)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(vx.Interface(), vy.Interface()) {
+			cs = append(cs, Change{Field: field})
+		}
+	}
+	return cs
+}
+
+// fieldName returns the name of the given field in the configuration file.
+// The Go field name starting with a lower case letter is used for the fields
+// which have no name in the configuration file.
+func fieldName(f reflect.StructField) string {
+	name := strings.Split(f.Tag.Get("json"), ",")[0]
+	if name != "" && name != "-" {
+		return name
+	}
+	return strings.ToLower(f.Name[:1]) + f.Name[1:]
+}
+
+// diffNamedList compares two slices of structs by matching their items
+// with the value of the given key field.
+func diffNamedList(field string, x, y interface{}, key string) (Change, bool) {
+	var (
+		c  = Change{Field: field}
+		mx = namedItems(x, key)
+		my = namedItems(y, key)
+	)
+	for name, vx := range mx {
+		vy, ok := my[name]
+		switch {
+		case !ok:
+			c.Removed = append(c.Removed, name)
+		case !reflect.DeepEqual(vx, vy):
+			c.Updated = append(c.Updated, name)
+		}
+	}
+	for name := range my {
+		if _, ok := mx[name]; !ok {
+			c.Added = append(c.Added, name)
+		}
+	}
+	if len(c.Added)+len(c.Removed)+len(c.Updated) == 0 {
+		return c, false
+	}
+	sort.Strings(c.Added)
+	sort.Strings(c.Removed)
+	sort.Strings(c.Updated)
+	return c, true
+}
+
+func namedItems(list interface{}, key string) map[string]interface{} {
+	v := reflect.ValueOf(list)
+	m := make(map[string]interface{}, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		item := v.Index(i)
+		m[item.FieldByName(key).String()] = item.Interface()
+	}
+	return m
+}
@@ -0,0 +1,114 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configreloader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pipe-cd/pipe/pkg/config"
+)
+
+func TestDiff(t *testing.T) {
+	base := func() *config.PipedSpec {
+		return &config.PipedSpec{
+			ProjectID:  "project",
+			PipedID:    "piped",
+			APIAddress: "api:443",
+			Repositories: []config.PipedRepository{
+				{RepoID: "repo-1", Remote: "git@github.com:org/repo-1.git", Branch: "master"},
+				{RepoID: "repo-2", Remote: "git@github.com:org/repo-2.git", Branch: "master"},
+			},
+			Notifications: config.Notifications{
+				Routes: []config.NotificationRoute{
+					{Name: "all", Receiver: "slack"},
+				},
+			},
+		}
+	}
+
+	testcases := []struct {
+		name         string
+		update       func(s *config.PipedSpec)
+		expected     Changes
+		unreloadable []string
+	}{
+		{
+			name:   "no change",
+			update: func(s *config.PipedSpec) {},
+		},
+		{
+			name: "repositories were changed",
+			update: func(s *config.PipedSpec) {
+				s.Repositories[0].Branch = "main"
+				s.Repositories[1] = config.PipedRepository{RepoID: "repo-3"}
+			},
+			expected: Changes{
+				{
+					Field:   "repositories",
+					Added:   []string{"repo-3"},
+					Removed: []string{"repo-2"},
+					Updated: []string{"repo-1"},
+				},
+			},
+		},
+		{
+			name: "notification route was added",
+			update: func(s *config.PipedSpec) {
+				s.Notifications.Routes = append(s.Notifications.Routes, config.NotificationRoute{Name: "prod", Receiver: "slack"})
+			},
+			expected: Changes{
+				{Field: "notifications.routes", Added: []string{"prod"}},
+			},
+		},
+		{
+			name: "unreloadable fields were changed",
+			update: func(s *config.PipedSpec) {
+				s.APIAddress = "new-api:443"
+				s.CloudProviders = []config.PipedCloudProvider{{Name: "kubernetes"}}
+				s.MaxConcurrentDeployments = 3
+			},
+			expected: Changes{
+				{Field: "apiAddress"},
+				{Field: "maxConcurrentDeployments"},
+				{Field: "cloudProviders", Added: []string{"kubernetes"}},
+			},
+			unreloadable: []string{"apiAddress", "cloudProviders"},
+		},
+		{
+			name: "fields which are not listed were changed",
+			update: func(s *config.PipedSpec) {
+				s.ChartRegistries = []config.HelmChartRegistry{{Address: "harbor.example.com"}}
+				s.ImageWatcher.Repos = []config.PipedImageWatcherRepoTarget{{RepoID: "repo-1"}}
+			},
+			expected: Changes{
+				{Field: "chartRegistries", Added: []string{"harbor.example.com"}},
+				{Field: "imageWatcher"},
+			},
+			unreloadable: []string{"chartRegistries", "imageWatcher"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			x, y := base(), base()
+			tc.update(y)
+			changes := Diff(x, y)
+			assert.Equal(t, tc.expected, changes)
+			assert.Equal(t, tc.unreloadable, changes.UnreloadableFields())
+		})
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package configreloader provides a piped component
+// that periodically reloads the piped configuration and
+// applies its changes to the running components without restarting.
+package configreloader
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/pipe-cd/pipe/pkg/config"
+)
+
+const defaultCheckInterval = 30 * time.Second
+
+// Component is a piped component that can accept a new configuration while running.
+type Component interface {
+	UpdateConfig(ctx context.Context, cfg *config.PipedSpec) error
+}
+
+// ComponentFunc is an adapter to allow the use of ordinary function as a Component.
+type ComponentFunc func(ctx context.Context, cfg *config.PipedSpec) error
+
+// UpdateConfig calls f(ctx, cfg).
+func (f ComponentFunc) UpdateConfig(ctx context.Context, cfg *config.PipedSpec) error {
+	return f(ctx, cfg)
+}
+
+// Loader loads the newest validated piped configuration.
+type Loader func(ctx context.Context) (*config.PipedSpec, error)
+
+type component struct {
+	name string
+	Component
+}
+
+type Reloader struct {
+	loader        Loader
+	current       *config.PipedSpec
+	components    []component
+	checkInterval time.Duration
+	// The error message of the most recently rejected configuration.
+	// This is used to avoid logging the same error on every check.
+	lastRejection string
+	logger        *zap.Logger
+}
+
+// NewReloader creates a new Reloader that starts from the given configuration.
+func NewReloader(current *config.PipedSpec, loader Loader, logger *zap.Logger) *Reloader {
+	return &Reloader{
+		loader:        loader,
+		current:       current,
+		checkInterval: defaultCheckInterval,
+		logger:        logger.Named("config-reloader"),
+	}
+}
+
+// Register adds a component that should receive the configuration changes.
+// This must be called before starting Run.
+func (r *Reloader) Register(name string, c Component) {
+	r.components = append(r.components, component{
+		name:      name,
+		Component: c,
+	})
+}
+
+// Run starts checking the piped configuration until the specified context has done.
+func (r *Reloader) Run(ctx context.Context) error {
+	r.logger.Info("start running config reloader")
+
+	ticker := time.NewTicker(r.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.reload(ctx)
+
+		case <-ctx.Done():
+			r.logger.Info("config reloader has been stopped")
+			return nil
+		}
+	}
+}
+
+func (r *Reloader) reload(ctx context.Context) {
+	cfg, err := r.loader(ctx)
+	if err != nil {
+		r.reject(fmt.Sprintf("failed to load the piped configuration: %v", err))
+		return
+	}
+
+	changes := Diff(r.current, cfg)
+	if len(changes) == 0 {
+		r.lastRejection = ""
+		return
+	}
+	if fields := changes.UnreloadableFields(); len(fields) > 0 {
+		r.reject(fmt.Sprintf("the changes of %v require restarting piped", fields))
+		return
+	}
+	for _, c := range changes {
+		r.logger.Info("detected a change in the piped configuration",
+			zap.String("field", c.Field),
+			zap.Strings("added", c.Added),
+			zap.Strings("removed", c.Removed),
+			zap.Strings("updated", c.Updated),
+		)
+	}
+
+	var failed []string
+	for _, c := range r.components {
+		if err := c.UpdateConfig(ctx, cfg); err != nil {
+			r.logger.Error("failed to apply the new piped configuration",
+				zap.String("component", c.name),
+				zap.Error(err),
+			)
+			failed = append(failed, c.name)
+		}
+	}
+	// The current configuration is kept to retry applying the new one on the next check
+	// since the failed components are still running with the current one.
+	if len(failed) > 0 {
+		r.reject(fmt.Sprintf("failed to apply the new piped configuration to %v", failed))
+		return
+	}
+	r.lastRejection = ""
+	r.current = cfg
+	r.logger.Info(fmt.Sprintf("successfully applied the new piped configuration to %d components", len(r.components)))
+}
+
+func (r *Reloader) reject(reason string) {
+	if reason == r.lastRejection {
+		return
+	}
+	r.lastRejection = reason
+	r.logger.Error("rejected the new piped configuration, continue running with the current one",
+		zap.String("reason", reason),
+	)
+}
@@ -0,0 +1,85 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configreloader
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/pipe-cd/pipe/pkg/config"
+)
+
+type fakeComponent struct {
+	configs []*config.PipedSpec
+	err     error
+}
+
+func (c *fakeComponent) UpdateConfig(_ context.Context, cfg *config.PipedSpec) error {
+	c.configs = append(c.configs, cfg)
+	return c.err
+}
+
+func TestReload(t *testing.T) {
+	var (
+		current = &config.PipedSpec{PipedID: "piped", WebAddress: "https://pipecd.dev"}
+		next    *config.PipedSpec
+		loadErr error
+		comp    = &fakeComponent{}
+		ctx     = context.Background()
+	)
+	r := NewReloader(current, func(_ context.Context) (*config.PipedSpec, error) {
+		return next, loadErr
+	}, zap.NewNop())
+	r.Register("fake", comp)
+
+	// Invalid configuration must be rejected.
+	loadErr = errors.New("invalid")
+	r.reload(ctx)
+	assert.Len(t, comp.configs, 0)
+	assert.Equal(t, current, r.current)
+
+	// The changes of unreloadable fields must be rejected.
+	loadErr = nil
+	next = &config.PipedSpec{PipedID: "another-piped", WebAddress: "https://pipecd.dev"}
+	r.reload(ctx)
+	assert.Len(t, comp.configs, 0)
+	assert.Equal(t, current, r.current)
+
+	// Nothing to do when there is no change.
+	next = &config.PipedSpec{PipedID: "piped", WebAddress: "https://pipecd.dev"}
+	r.reload(ctx)
+	assert.Len(t, comp.configs, 0)
+
+	// The current configuration must be kept when a component failed to apply the changes.
+	next = &config.PipedSpec{PipedID: "piped", WebAddress: "https://new.pipecd.dev"}
+	comp.err = errors.New("failed")
+	r.reload(ctx)
+	require.Len(t, comp.configs, 1)
+	assert.Equal(t, current, r.current)
+	assert.NotEqual(t, "", r.lastRejection)
+
+	// Reloadable changes must be applied.
+	comp.err = nil
+	r.reload(ctx)
+	require.Len(t, comp.configs, 2)
+	assert.Equal(t, next, comp.configs[1])
+	assert.Equal(t, next, r.current)
+	assert.Equal(t, "", r.lastRejection)
+}
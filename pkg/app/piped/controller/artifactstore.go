@@ -0,0 +1,86 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pipe-cd/pipe/pkg/app/api/service/pipedservice"
+	"github.com/pipe-cd/pipe/pkg/crypto"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+type encryptDecrypter interface {
+	Encrypt(text string) (string, error)
+	Decrypt(encryptedText string) (string, error)
+}
+
+// artifactStore saves and loads the artifacts of a deployment
+// through the control-plane.
+// The artifacts are encrypted with the piped key since they may contain
+// sensitive data, such as the secrets in a Terraform plan,
+// which must not be readable by anyone having access to the filestore.
+type artifactStore struct {
+	apiClient  apiClient
+	deployment *model.Deployment
+	newCipher  func() (encryptDecrypter, error)
+}
+
+func newArtifactStore(apiClient apiClient, d *model.Deployment, pipedKeyFile string) *artifactStore {
+	return &artifactStore{
+		apiClient:  apiClient,
+		deployment: d,
+		newCipher: func() (encryptDecrypter, error) {
+			return crypto.NewAESEncryptDecrypter(pipedKeyFile)
+		},
+	}
+}
+
+func (s *artifactStore) Get(ctx context.Context, name string) ([]byte, error) {
+	c, err := s.newCipher()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.apiClient.GetDeploymentArtifact(ctx, &pipedservice.GetDeploymentArtifactRequest{
+		DeploymentId: s.deployment.Id,
+		Name:         name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	content, err := c.Decrypt(string(resp.Content))
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt the artifact, the piped key may have been changed since it was saved: %w", err)
+	}
+	return []byte(content), nil
+}
+
+func (s *artifactStore) Put(ctx context.Context, name string, content []byte) error {
+	c, err := s.newCipher()
+	if err != nil {
+		return err
+	}
+	encrypted, err := c.Encrypt(string(content))
+	if err != nil {
+		return fmt.Errorf("unable to encrypt the artifact: %w", err)
+	}
+	_, err = s.apiClient.PutDeploymentArtifact(ctx, &pipedservice.PutDeploymentArtifactRequest{
+		DeploymentId: s.deployment.Id,
+		Name:         name,
+		Content:      []byte(encrypted),
+	})
+	return err
+}
@@ -0,0 +1,77 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/pipe-cd/pipe/pkg/app/api/service/pipedservice"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+type fakeArtifactAPIClient struct {
+	apiClient
+	artifacts map[string][]byte
+}
+
+func (c *fakeArtifactAPIClient) PutDeploymentArtifact(_ context.Context, req *pipedservice.PutDeploymentArtifactRequest, _ ...grpc.CallOption) (*pipedservice.PutDeploymentArtifactResponse, error) {
+	c.artifacts[req.DeploymentId+"/"+req.Name] = req.Content
+	return &pipedservice.PutDeploymentArtifactResponse{}, nil
+}
+
+func (c *fakeArtifactAPIClient) GetDeploymentArtifact(_ context.Context, req *pipedservice.GetDeploymentArtifactRequest, _ ...grpc.CallOption) (*pipedservice.GetDeploymentArtifactResponse, error) {
+	return &pipedservice.GetDeploymentArtifactResponse{
+		Content: c.artifacts[req.DeploymentId+"/"+req.Name],
+	}, nil
+}
+
+func TestArtifactStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "artifactstore")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	keyFile := filepath.Join(dir, "piped-key")
+	require.NoError(t, ioutil.WriteFile(keyFile, []byte("01234567890123456789012345678901234567890123456789"), 0600))
+
+	var (
+		ctx     = context.Background()
+		client  = &fakeArtifactAPIClient{artifacts: make(map[string][]byte)}
+		s       = newArtifactStore(client, &model.Deployment{Id: "deployment-id"}, keyFile)
+		content = []byte("plan containing secrets")
+	)
+	require.NoError(t, s.Put(ctx, "terraform-plan", content))
+
+	// The control-plane receives only the encrypted content.
+	stored := client.artifacts["deployment-id/terraform-plan"]
+	require.NotEmpty(t, stored)
+	assert.NotContains(t, string(stored), string(content))
+
+	got, err := s.Get(ctx, "terraform-plan")
+	require.NoError(t, err)
+	assert.Equal(t, content, got)
+
+	// The artifact saved with another key can not be read.
+	require.NoError(t, ioutil.WriteFile(keyFile, []byte("98765432109876543210987654321098765432109876543210"), 0600))
+	_, err = s.Get(ctx, "terraform-plan")
+	assert.Error(t, err)
+}
@@ -18,6 +18,9 @@
 // the deployment pipeline and update the deployment status to PLANNED.
 // Whenever a new PLANNED deployment is detected, controller spawns a new scheduler
 // for scheduling and running its pipeline executors.
+// A PLANNED deployment is kept in the queue while the piped is running
+// the maximum number of deployments or while one of its deployment locks
+// is being held by another running deployment.
 package controller
 
 import (
@@ -25,6 +28,8 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -47,7 +52,10 @@ type apiClient interface {
 	ReportDeploymentPlanned(ctx context.Context, req *pipedservice.ReportDeploymentPlannedRequest, opts ...grpc.CallOption) (*pipedservice.ReportDeploymentPlannedResponse, error)
 	ReportDeploymentStatusChanged(ctx context.Context, req *pipedservice.ReportDeploymentStatusChangedRequest, opts ...grpc.CallOption) (*pipedservice.ReportDeploymentStatusChangedResponse, error)
 	ReportDeploymentCompleted(ctx context.Context, req *pipedservice.ReportDeploymentCompletedRequest, opts ...grpc.CallOption) (*pipedservice.ReportDeploymentCompletedResponse, error)
+	TriggerDeploymentChain(ctx context.Context, req *pipedservice.TriggerDeploymentChainRequest, opts ...grpc.CallOption) (*pipedservice.TriggerDeploymentChainResponse, error)
 	SaveDeploymentMetadata(ctx context.Context, req *pipedservice.SaveDeploymentMetadataRequest, opts ...grpc.CallOption) (*pipedservice.SaveDeploymentMetadataResponse, error)
+	PutDeploymentArtifact(ctx context.Context, req *pipedservice.PutDeploymentArtifactRequest, opts ...grpc.CallOption) (*pipedservice.PutDeploymentArtifactResponse, error)
+	GetDeploymentArtifact(ctx context.Context, req *pipedservice.GetDeploymentArtifactRequest, opts ...grpc.CallOption) (*pipedservice.GetDeploymentArtifactResponse, error)
 	ReportApplicationMostRecentDeployment(ctx context.Context, req *pipedservice.ReportApplicationMostRecentDeploymentRequest, opts ...grpc.CallOption) (*pipedservice.ReportApplicationMostRecentDeploymentResponse, error)
 
 	ReportStageStatusChanged(ctx context.Context, req *pipedservice.ReportStageStatusChangedRequest, opts ...grpc.CallOption) (*pipedservice.ReportStageStatusChangedResponse, error)
@@ -93,6 +101,7 @@ type sealedSecretDecrypter interface {
 
 type DeploymentController interface {
 	Run(ctx context.Context) error
+	UpdateConfig(ctx context.Context, cfg *config.PipedSpec) error
 }
 
 var (
@@ -100,6 +109,10 @@ var (
 	schedulerStaleDuration = time.Hour
 )
 
+// deploymentLocksMetadataKey is the key of the deployment metadata
+// where the planner stores the deployment locks of the deployment.
+const deploymentLocksMetadataKey = "deployment-locks"
+
 type controller struct {
 	apiClient             apiClient
 	gitClient             gitClient
@@ -111,6 +124,7 @@ type controller struct {
 	notifier              notifier
 	sealedSecretDecrypter sealedSecretDecrypter
 	pipedConfig           *config.PipedSpec
+	configCh              chan *config.PipedSpec
 	appManifestsCache     cache.Cache
 	logPersister          logpersister.Persister
 
@@ -128,6 +142,9 @@ type controller struct {
 	// Map from deployment ID to the completion time
 	// of the done schedulers.
 	doneSchedulers map[string]time.Time
+	// Map from deployment ID to the PLANNED deployment
+	// that is waiting in the queue for being scheduled.
+	queuedDeployments map[string]*queuedDeployment
 	// Map from application ID to its most recently successful commit hash.
 	mostRecentlySuccessfulCommits map[string]string
 	// WaitGroup for waiting the completions of all planners, schedulers.
@@ -172,12 +189,14 @@ func NewController(
 		sealedSecretDecrypter: ssd,
 		appManifestsCache:     appManifestsCache,
 		pipedConfig:           pipedConfig,
+		configCh:              make(chan *config.PipedSpec),
 		logPersister:          lp,
 
 		planners:                      make(map[string]*planner),
 		donePlanners:                  make(map[string]time.Time),
 		schedulers:                    make(map[string]*scheduler),
 		doneSchedulers:                make(map[string]time.Time),
+		queuedDeployments:             make(map[string]*queuedDeployment),
 		mostRecentlySuccessfulCommits: make(map[string]string),
 
 		syncInternal: 10 * time.Second,
@@ -228,7 +247,13 @@ L:
 			// after piped is restarted all running deployments need to be loaded firstly.
 			c.syncSchedulers(ctx)
 			c.syncPlanners(ctx)
-			c.checkCommands()
+			c.checkCommands(ctx)
+
+		case cfg := <-c.configCh:
+			// The running planners and schedulers keep using the configuration
+			// they were started with, only the new ones will use this.
+			c.pipedConfig = cfg
+			c.logger.Info("piped configuration for the new deployments has been updated")
 		}
 	}
 
@@ -243,9 +268,19 @@ L:
 	return err
 }
 
+// UpdateConfig passes the given piped configuration to the running controller.
+func (c *controller) UpdateConfig(ctx context.Context, cfg *config.PipedSpec) error {
+	select {
+	case c.configCh <- cfg:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // checkCommands lists all unhandled commands for running deployments
 // and forwards them to their planners and schedulers.
-func (c *controller) checkCommands() {
+func (c *controller) checkCommands(ctx context.Context) {
 	commands := c.commandLister.ListDeploymentCommands()
 	for _, cmd := range commands {
 		if cmd.GetCancelDeployment() == nil {
@@ -271,6 +306,15 @@ func (c *controller) checkCommands() {
 			)
 		}
 
+		if qd, ok := c.queuedDeployments[cmd.DeploymentId]; ok {
+			handled = true
+			c.cancelQueuedDeployment(ctx, qd.deployment, cmd)
+			c.logger.Info("a command CancelDeployment was handled for a queued deployment",
+				zap.String("app-id", cmd.ApplicationId),
+				zap.String("deployment-id", cmd.DeploymentId),
+			)
+		}
+
 		if !handled {
 			c.logger.Info("a command CancelDeployment is still not handled",
 				zap.String("app-id", cmd.ApplicationId),
@@ -462,44 +506,199 @@ func (c *controller) syncSchedulers(ctx context.Context) error {
 	// Add missing schedulers.
 	planneds := c.deploymentLister.ListPlanneds()
 	runnings := c.deploymentLister.ListRunnings()
-	runnings = append(runnings, planneds...)
 
-	if len(runnings) == 0 {
+	if len(runnings)+len(planneds) == 0 {
+		c.queuedDeployments = make(map[string]*queuedDeployment)
 		return nil
 	}
 
-	c.logger.Info(fmt.Sprintf("there are %d planned/running deployments for scheduling", len(runnings)),
+	c.logger.Info(fmt.Sprintf("there are %d planned/running deployments for scheduling", len(runnings)+len(planneds)),
 		zap.Int("count", len(c.schedulers)),
 	)
 
+	// The RUNNING deployments were already admitted before (e.g. before piped restarted)
+	// so they are resumed without checking the concurrency limit and the deployment locks.
 	for _, d := range runnings {
+		c.addScheduler(ctx, d)
+	}
+
+	// The PLANNED deployments are admitted in order of their trigger time.
+	// Since the listed deployments are read-only we sort a copied list.
+	sorted := make([]*model.Deployment, len(planneds))
+	copy(sorted, planneds)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].TriggerBefore(sorted[j])
+	})
+
+	queued := make(map[string]*queuedDeployment, len(c.queuedDeployments))
+	for _, d := range sorted {
 		// Ignore already processed one.
 		if _, ok := c.doneSchedulers[d.Id]; ok {
 			continue
 		}
-		if s, ok := c.schedulers[d.ApplicationId]; ok {
-			if s.ID() != d.Id {
-				c.logger.Warn("detected an application that has more than one running deployments",
-					zap.String("app-id", d.ApplicationId),
-					zap.String("handling-deployment-id", s.ID()),
-					zap.String("deployment-id", d.Id),
-				)
+		if _, ok := c.schedulers[d.ApplicationId]; !ok {
+			if reason, ok := c.checkDeploymentQueue(d); ok {
+				queued[d.Id] = c.queueDeployment(ctx, d, reason)
+				continue
 			}
-			continue
 		}
-		s, err := c.startNewScheduler(ctx, d)
-		if err != nil {
+		c.addScheduler(ctx, d)
+	}
+	c.queuedDeployments = queued
+
+	return nil
+}
+
+// addScheduler starts a new scheduler for the given deployment
+// if that deployment is not being handled by any scheduler.
+func (c *controller) addScheduler(ctx context.Context, d *model.Deployment) {
+	// Ignore already processed one.
+	if _, ok := c.doneSchedulers[d.Id]; ok {
+		return
+	}
+	if s, ok := c.schedulers[d.ApplicationId]; ok {
+		if s.ID() != d.Id {
+			c.logger.Warn("detected an application that has more than one running deployments",
+				zap.String("app-id", d.ApplicationId),
+				zap.String("handling-deployment-id", s.ID()),
+				zap.String("deployment-id", d.Id),
+			)
+		}
+		return
+	}
+	s, err := c.startNewScheduler(ctx, d)
+	if err != nil {
+		return
+	}
+	c.schedulers[d.ApplicationId] = s
+	c.logger.Info("added a new scheduler",
+		zap.String("deployment-id", d.Id),
+		zap.String("app-id", d.ApplicationId),
+		zap.Int("count", len(c.schedulers)),
+	)
+}
+
+type queuedDeployment struct {
+	deployment *model.Deployment
+	reason     string
+}
+
+// checkDeploymentQueue checks whether the given PLANNED deployment must wait in the queue.
+// When it must, the returned string describes the waiting reason.
+func (c *controller) checkDeploymentQueue(d *model.Deployment) (string, bool) {
+	if max := c.pipedConfig.MaxConcurrentDeployments; max > 0 && len(c.schedulers) >= max {
+		return fmt.Sprintf("Waiting for a free slot since this piped is running the maximum number of concurrent deployments (%d)", max), true
+	}
+
+	locks := deploymentLocks(d)
+	if len(locks) == 0 {
+		return "", false
+	}
+	for _, s := range c.schedulers {
+		if s.IsDone() {
 			continue
 		}
-		c.schedulers[d.ApplicationId] = s
-		c.logger.Info("added a new scheduler",
+		for _, held := range deploymentLocks(s.deployment) {
+			for _, lock := range locks {
+				if lock != held {
+					continue
+				}
+				return fmt.Sprintf("Waiting for deployment lock %q held by deployment %s of application %s", lock, s.ID(), s.deployment.ApplicationId), true
+			}
+		}
+	}
+	return "", false
+}
+
+// queueDeployment keeps the given deployment in the queue and
+// reports the waiting reason to the control-plane if it was changed.
+func (c *controller) queueDeployment(ctx context.Context, d *model.Deployment, reason string) *queuedDeployment {
+	if qd, ok := c.queuedDeployments[d.Id]; ok && qd.reason == reason {
+		return qd
+	}
+
+	logger := c.logger.With(
+		zap.String("deployment-id", d.Id),
+		zap.String("app-id", d.ApplicationId),
+		zap.String("reason", reason),
+	)
+	req := &pipedservice.ReportDeploymentStatusChangedRequest{
+		DeploymentId: d.Id,
+		Status:       model.DeploymentStatus_DEPLOYMENT_PLANNED,
+		StatusReason: reason,
+	}
+	if _, err := c.apiClient.ReportDeploymentStatusChanged(ctx, req); err != nil {
+		// Leave the reason empty to retry reporting at the next sync.
+		logger.Error("failed to report the waiting reason of queued deployment", zap.Error(err))
+		return &queuedDeployment{deployment: d}
+	}
+
+	logger.Info("deployment was queued")
+	return &queuedDeployment{deployment: d, reason: reason}
+}
+
+// cancelQueuedDeployment marks the given queued deployment as CANCELLED
+// and reports the result of the handled command.
+func (c *controller) cancelQueuedDeployment(ctx context.Context, d *model.Deployment, cmd model.ReportableCommand) {
+	var (
+		err    error
+		now    = time.Now()
+		reason = fmt.Sprintf("Deployment was cancelled by %s while waiting in the queue", cmd.Commander)
+		req    = &pipedservice.ReportDeploymentCompletedRequest{
+			DeploymentId:  d.Id,
+			Status:        model.DeploymentStatus_DEPLOYMENT_CANCELLED,
+			StatusReason:  reason,
+			StageStatuses: d.StageStatusMap(),
+			CompletedAt:   now.Unix(),
+		}
+		resp  *pipedservice.ReportDeploymentCompletedResponse
+		retry = pipedservice.NewRetry(10)
+	)
+
+	for retry.WaitNext(ctx) {
+		if resp, err = c.apiClient.ReportDeploymentCompleted(ctx, req); err == nil {
+			notifyDeploymentChainCompleted(c.notifier, resp.CompletedDeploymentChain)
+			break
+		}
+		err = fmt.Errorf("failed to report deployment status to control-plane: %w", err)
+	}
+	if err != nil {
+		c.logger.Error("failed to mark queued deployment to be cancelled",
 			zap.String("deployment-id", d.Id),
-			zap.String("app-id", d.ApplicationId),
-			zap.Int("count", len(c.schedulers)),
+			zap.Error(err),
 		)
+		return
 	}
 
-	return nil
+	var envName string
+	if env, ok := c.environmentLister.Get(d.EnvId); ok {
+		envName = env.Name
+	}
+	c.notifier.Notify(model.Event{
+		Type: model.EventType_EVENT_DEPLOYMENT_CANCELLED,
+		Metadata: &model.EventDeploymentCancelled{
+			Deployment: d,
+			EnvName:    envName,
+			Commander:  cmd.Commander,
+		},
+	})
+
+	// Mark as done to ignore it even when the deployment lister returns not fresh data.
+	c.doneSchedulers[d.Id] = now
+	delete(c.queuedDeployments, d.Id)
+
+	if err := cmd.Report(ctx, model.CommandStatus_COMMAND_SUCCEEDED, nil, nil); err != nil {
+		c.logger.Error("failed to report command status", zap.Error(err))
+	}
+}
+
+// deploymentLocks returns the list of deployment locks stored in the metadata of the given deployment.
+func deploymentLocks(d *model.Deployment) []string {
+	value, ok := d.Metadata[deploymentLocksMetadataKey]
+	if !ok || value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
 }
 
 // startNewScheduler creates and starts running a new scheduler
@@ -592,11 +791,10 @@ func (c *controller) getMostRecentlySuccessfulDeployment(ctx context.Context, ap
 }
 
 type appLiveResourceLister struct {
-	lister        liveResourceLister
-	cloudProvider string
-	appID         string
+	lister liveResourceLister
+	appID  string
 }
 
-func (l appLiveResourceLister) ListKubernetesResources() ([]provider.Manifest, bool) {
-	return l.lister.ListKubernetesAppLiveResources(l.cloudProvider, l.appID)
+func (l appLiveResourceLister) ListKubernetesResources(cloudProvider string) ([]provider.Manifest, bool) {
+	return l.lister.ListKubernetesAppLiveResources(cloudProvider, l.appID)
 }
@@ -13,3 +13,85 @@
 // limitations under the License.
 
 package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+func TestCheckDeploymentQueue(t *testing.T) {
+	newDeployment := func(id, appID, locks string) *model.Deployment {
+		d := &model.Deployment{
+			Id:            id,
+			ApplicationId: appID,
+		}
+		if locks != "" {
+			d.Metadata = map[string]string{
+				deploymentLocksMetadataKey: locks,
+			}
+		}
+		return d
+	}
+
+	testcases := []struct {
+		name          string
+		maxConcurrent int
+		runnings      []*model.Deployment
+		deployment    *model.Deployment
+		expected      string
+		expectedQueue bool
+	}{
+		{
+			name:       "no limit and no lock",
+			runnings:   []*model.Deployment{newDeployment("d1", "app-1", "")},
+			deployment: newDeployment("d2", "app-2", ""),
+		},
+		{
+			name:          "reached the maximum number of concurrent deployments",
+			maxConcurrent: 1,
+			runnings:      []*model.Deployment{newDeployment("d1", "app-1", "")},
+			deployment:    newDeployment("d2", "app-2", ""),
+			expected:      "Waiting for a free slot since this piped is running the maximum number of concurrent deployments (1)",
+			expectedQueue: true,
+		},
+		{
+			name:          "under the maximum number of concurrent deployments",
+			maxConcurrent: 2,
+			runnings:      []*model.Deployment{newDeployment("d1", "app-1", "")},
+			deployment:    newDeployment("d2", "app-2", ""),
+		},
+		{
+			name:          "lock is being held by another deployment",
+			runnings:      []*model.Deployment{newDeployment("d1", "app-1", "db,network")},
+			deployment:    newDeployment("d2", "app-2", "network"),
+			expected:      `Waiting for deployment lock "network" held by deployment d1 of application app-1`,
+			expectedQueue: true,
+		},
+		{
+			name:       "lock is not being held",
+			runnings:   []*model.Deployment{newDeployment("d1", "app-1", "db")},
+			deployment: newDeployment("d2", "app-2", "network"),
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &controller{
+				pipedConfig: &config.PipedSpec{
+					MaxConcurrentDeployments: tc.maxConcurrent,
+				},
+				schedulers: make(map[string]*scheduler, len(tc.runnings)),
+			}
+			for _, d := range tc.runnings {
+				c.schedulers[d.ApplicationId] = &scheduler{deployment: d}
+			}
+			reason, queued := c.checkDeploymentQueue(tc.deployment)
+			assert.Equal(t, tc.expectedQueue, queued)
+			assert.Equal(t, tc.expected, reason)
+		})
+	}
+}
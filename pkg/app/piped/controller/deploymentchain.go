@@ -0,0 +1,125 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/pipe-cd/pipe/pkg/app/api/service/pipedservice"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+// triggerDeploymentChain requests control-plane to trigger the downstream applications
+// configured in the deployment configuration of the current deployment.
+// This must be called before reporting the completion of the deployment
+// to ensure that the chain will not be marked as completed too early.
+func (s *scheduler) triggerDeploymentChain(ctx context.Context) error {
+	chain := s.genericDeploymentConfig.Chain
+	if chain == nil || len(chain.Applications) == 0 {
+		return nil
+	}
+
+	var (
+		err  error
+		resp *pipedservice.TriggerDeploymentChainResponse
+		req  = &pipedservice.TriggerDeploymentChainRequest{
+			DeploymentId: s.deployment.Id,
+			Applications: make([]*pipedservice.TriggerDeploymentChainRequest_Application, 0, len(chain.Applications)),
+		}
+		retry = pipedservice.NewRetry(10)
+	)
+	for _, app := range chain.Applications {
+		req.Applications = append(req.Applications, &pipedservice.TriggerDeploymentChainRequest_Application{
+			Name:    app.Name,
+			EnvName: app.Env,
+		})
+	}
+
+	for retry.WaitNext(ctx) {
+		if resp, err = s.apiClient.TriggerDeploymentChain(ctx, req); err == nil {
+			break
+		}
+		retryable := isRetryableChainError(err)
+		err = fmt.Errorf("failed to trigger deployment chain: %w", err)
+		if !retryable {
+			break
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if resp.Created {
+		s.notifier.Notify(model.Event{
+			Type: model.EventType_EVENT_DEPLOYMENT_CHAIN_TRIGGERED,
+			Metadata: &model.EventDeploymentChainTriggered{
+				DeploymentChain: resp.DeploymentChain,
+				Deployment:      s.deployment,
+				EnvName:         s.envName,
+			},
+		})
+	}
+	return nil
+}
+
+// isRetryableChainError reports whether the given error returned
+// while triggering a deployment chain may be resolved by retrying.
+// For example, a chain which was already completed or cancelled will never accept the request.
+func isRetryableChainError(err error) bool {
+	switch status.Code(err) {
+	case codes.FailedPrecondition, codes.NotFound, codes.InvalidArgument:
+		return false
+	}
+	return true
+}
+
+// notifyDeploymentChainCompleted sends an event about the completion of
+// the given deployment chain. Nothing is sent when the chain is nil.
+func notifyDeploymentChainCompleted(n notifier, chain *model.DeploymentChain) {
+	if chain == nil {
+		return
+	}
+	switch chain.Status {
+	case model.ChainStatus_DEPLOYMENT_CHAIN_SUCCESS:
+		n.Notify(model.Event{
+			Type: model.EventType_EVENT_DEPLOYMENT_CHAIN_SUCCEEDED,
+			Metadata: &model.EventDeploymentChainSucceeded{
+				DeploymentChain: chain,
+			},
+		})
+
+	case model.ChainStatus_DEPLOYMENT_CHAIN_FAILURE:
+		n.Notify(model.Event{
+			Type: model.EventType_EVENT_DEPLOYMENT_CHAIN_FAILED,
+			Metadata: &model.EventDeploymentChainFailed{
+				DeploymentChain: chain,
+				Reason:          chain.StatusReason,
+			},
+		})
+
+	case model.ChainStatus_DEPLOYMENT_CHAIN_CANCELLED:
+		n.Notify(model.Event{
+			Type: model.EventType_EVENT_DEPLOYMENT_CHAIN_CANCELLED,
+			Metadata: &model.EventDeploymentChainCancelled{
+				DeploymentChain: chain,
+				Commander:       chain.CancelledBy,
+			},
+		})
+	}
+}
@@ -17,7 +17,9 @@ package controller
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"go.uber.org/atomic"
@@ -189,7 +191,7 @@ func (p *planner) Run(ctx context.Context) error {
 		if cmd != nil {
 			desc := fmt.Sprintf("Deployment was cancelled by %s while planning", cmd.Commander)
 			p.reportDeploymentCancelled(ctx, cmd.Commander, desc)
-			return cmd.Report(ctx, model.CommandStatus_COMMAND_SUCCEEDED, nil)
+			return cmd.Report(ctx, model.CommandStatus_COMMAND_SUCCEEDED, nil, nil)
 		}
 	default:
 	}
@@ -198,9 +200,77 @@ func (p *planner) Run(ctx context.Context) error {
 		return p.reportDeploymentFailed(ctx, fmt.Sprintf("Unable to plan the deployment (%v)", err))
 	}
 
+	ds, err := in.TargetDSP.GetReadOnly(ctx, ioutil.Discard)
+	if err != nil {
+		return p.reportDeploymentFailed(ctx, fmt.Sprintf("Unable to prepare deploy source data at target commit (%v)", err))
+	}
+	out.Stages = pln.AddHookStages(out.Stages, ds.GenericDeploymentConfig.Hooks, p.nowFunc())
+
+	if err := p.saveDeploymentLocks(ctx, ds.GenericDeploymentConfig.DeploymentLocks); err != nil {
+		return p.reportDeploymentFailed(ctx, fmt.Sprintf("Unable to save the deployment locks (%v)", err))
+	}
+
+	// The diff is only for reviewing so the deployment can continue without it.
+	if out.Diff != "" {
+		if err := p.saveManifestsDiff(ctx, out.Diff); err != nil {
+			p.logger.Error("failed to save the diff of manifests", zap.Error(err))
+		}
+	}
+
 	return p.reportDeploymentPlanned(ctx, p.lastSuccessfulCommitHash, out)
 }
 
+// saveDeploymentLocks stores the deployment locks specified in the deployment configuration
+// into the deployment metadata so that the controller can acquire them before scheduling.
+func (p *planner) saveDeploymentLocks(ctx context.Context, locks []string) error {
+	if len(locks) == 0 {
+		return nil
+	}
+
+	metadata := make(map[string]string, len(p.deployment.Metadata)+1)
+	for k, v := range p.deployment.Metadata {
+		metadata[k] = v
+	}
+	metadata[deploymentLocksMetadataKey] = strings.Join(locks, ",")
+
+	var (
+		err   error
+		retry = pipedservice.NewRetry(10)
+		req   = &pipedservice.SaveDeploymentMetadataRequest{
+			DeploymentId: p.deployment.Id,
+			Metadata:     metadata,
+		}
+	)
+	for retry.WaitNext(ctx) {
+		if _, err = p.apiClient.SaveDeploymentMetadata(ctx, req); err == nil {
+			return nil
+		}
+		err = fmt.Errorf("failed to save deployment metadata to control-plane: %v", err)
+	}
+	return err
+}
+
+// saveManifestsDiff stores the rendered diff of manifests as a deployment artifact
+// so that it can be reviewed from the web before approving the deployment.
+func (p *planner) saveManifestsDiff(ctx context.Context, diff string) error {
+	var (
+		err   error
+		retry = pipedservice.NewRetry(10)
+		req   = &pipedservice.PutDeploymentArtifactRequest{
+			DeploymentId: p.deployment.Id,
+			Name:         model.DeploymentManifestsDiffArtifactName,
+			Content:      []byte(diff),
+		}
+	)
+	for retry.WaitNext(ctx) {
+		if _, err = p.apiClient.PutDeploymentArtifact(ctx, req); err == nil {
+			return nil
+		}
+		err = fmt.Errorf("failed to put deployment artifact to control-plane: %v", err)
+	}
+	return err
+}
+
 func (p *planner) reportDeploymentPlanned(ctx context.Context, runningCommitHash string, out pln.Output) error {
 	var (
 		err   error
@@ -265,7 +335,9 @@ func (p *planner) reportDeploymentFailed(ctx context.Context, reason string) err
 	}()
 
 	for retry.WaitNext(ctx) {
-		if _, err = p.apiClient.ReportDeploymentCompleted(ctx, req); err == nil {
+		var resp *pipedservice.ReportDeploymentCompletedResponse
+		if resp, err = p.apiClient.ReportDeploymentCompleted(ctx, req); err == nil {
+			notifyDeploymentChainCompleted(p.notifier, resp.CompletedDeploymentChain)
 			return nil
 		}
 		err = fmt.Errorf("failed to report deployment status to control-plane: %v", err)
@@ -303,7 +375,9 @@ func (p *planner) reportDeploymentCancelled(ctx context.Context, commander, reas
 	}()
 
 	for retry.WaitNext(ctx) {
-		if _, err = p.apiClient.ReportDeploymentCompleted(ctx, req); err == nil {
+		var resp *pipedservice.ReportDeploymentCompletedResponse
+		if resp, err = p.apiClient.ReportDeploymentCompleted(ctx, req); err == nil {
+			notifyDeploymentChainCompleted(p.notifier, resp.CompletedDeploymentChain)
 			return nil
 		}
 		err = fmt.Errorf("failed to report deployment status to control-plane: %v", err)
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
+	"strconv"
 	"time"
 
 	"go.uber.org/atomic"
@@ -53,6 +54,7 @@ type scheduler struct {
 	liveResourceLister    liveResourceLister
 	logPersister          logpersister.Persister
 	metadataStore         *metadataStore
+	artifactStore         *artifactStore
 	notifier              notifier
 	sealedSecretDecrypter sealedSecretDecrypter
 	pipedConfig           *config.PipedSpec
@@ -117,6 +119,7 @@ func newScheduler(
 		liveResourceLister:    liveResourceLister,
 		logPersister:          lp,
 		metadataStore:         NewMetadataStore(apiClient, d),
+		artifactStore:         newArtifactStore(apiClient, d, pipedConfig.PipedKeyFile),
 		notifier:              notifier,
 		sealedSecretDecrypter: ssd,
 		pipedConfig:           pipedConfig,
@@ -387,6 +390,15 @@ func (s *scheduler) Run(ctx context.Context) error {
 		}
 	}
 
+	// Trigger the downstream applications before completing this deployment
+	// so that its chain can be extended with the next block.
+	if deploymentStatus == model.DeploymentStatus_DEPLOYMENT_SUCCESS {
+		if err := s.triggerDeploymentChain(ctx); err != nil {
+			s.logger.Error("failed to trigger deployment chain", zap.Error(err))
+			statusReason = fmt.Sprintf("The deployment was completed successfully but failed to trigger its downstream applications (%v)", err)
+		}
+	}
+
 	if model.IsCompletedDeployment(deploymentStatus) {
 		err := s.reportDeploymentCompleted(ctx, deploymentStatus, statusReason, cancelCommander)
 		if err == nil && deploymentStatus == model.DeploymentStatus_DEPLOYMENT_SUCCESS {
@@ -396,7 +408,7 @@ func (s *scheduler) Run(ctx context.Context) error {
 	}
 
 	if cancelCommand != nil {
-		if err := cancelCommand.Report(ctx, model.CommandStatus_COMMAND_SUCCEEDED, nil); err != nil {
+		if err := cancelCommand.Report(ctx, model.CommandStatus_COMMAND_SUCCEEDED, nil, nil); err != nil {
 			s.logger.Error("failed to report command status", zap.Error(err))
 		}
 	}
@@ -426,6 +438,10 @@ func (s *scheduler) executeStage(sig executor.StopSignal, ps model.PipelineStage
 			return model.StageStatus_STAGE_FAILURE
 		}
 		originalStatus = model.StageStatus_STAGE_RUNNING
+
+		if ps.Name == model.StageWaitApproval.String() {
+			s.notifyWaitApproval(&ps)
+		}
 	}
 
 	// Check the existence of the specified cloud provider.
@@ -442,6 +458,9 @@ func (s *scheduler) executeStage(sig executor.StopSignal, ps model.PipelineStage
 	var stageConfigFound bool
 	if ps.Predefined {
 		stageConfig, stageConfigFound = pln.GetPredefinedStage(ps.Id)
+		if stageConfigFound && stageConfig.Name == model.StageHook {
+			stageConfig.HookStageOptions, stageConfigFound = s.findHookStageOptions(ps.Id)
+		}
 	} else {
 		stageConfig, stageConfigFound = s.genericDeploymentConfig.GetStage(ps.Index)
 	}
@@ -467,9 +486,8 @@ func (s *scheduler) executeStage(sig executor.StopSignal, ps model.PipelineStage
 		stageID:      ps.Id,
 	}
 	alrLister := appLiveResourceLister{
-		lister:        s.liveResourceLister,
-		cloudProvider: app.CloudProvider,
-		appID:         app.Id,
+		lister: s.liveResourceLister,
+		appID:  app.Id,
 	}
 	input := executor.Input{
 		Stage:                 &ps,
@@ -482,6 +500,7 @@ func (s *scheduler) executeStage(sig executor.StopSignal, ps model.PipelineStage
 		CommandLister:         cmdLister,
 		LogPersister:          lp,
 		MetadataStore:         s.metadataStore,
+		ArtifactStore:         s.artifactStore,
 		AppManifestsCache:     s.appManifestsCache,
 		AppLiveResourceLister: alrLister,
 		Logger:                s.logger,
@@ -510,6 +529,37 @@ func (s *scheduler) executeStage(sig executor.StopSignal, ps model.PipelineStage
 	return originalStatus
 }
 
+func (s *scheduler) notifyWaitApproval(ps *model.PipelineStage) {
+	minApproverNum, _ := strconv.Atoi(ps.Metadata[model.MinApproverNumStageMetadataKey])
+	s.notifier.Notify(model.Event{
+		Type: model.EventType_EVENT_DEPLOYMENT_WAIT_APPROVAL,
+		Metadata: &model.EventDeploymentWaitApproval{
+			Deployment:     s.deployment,
+			EnvName:        s.envName,
+			StageId:        ps.Id,
+			Approvers:      model.StageApprovers(ps),
+			MinApproverNum: int32(minApproverNum),
+		},
+	})
+}
+
+// findHookStageOptions returns the options of the pre-sync or post-sync hook
+// configured in the deployment configuration for the given predefined stage.
+func (s *scheduler) findHookStageOptions(stageID string) (*config.HookStageOptions, bool) {
+	hooks := s.genericDeploymentConfig.Hooks
+	if hooks == nil {
+		return nil, false
+	}
+	switch stageID {
+	case pln.PredefinedStagePreSyncHook:
+		return hooks.PreSync, hooks.PreSync != nil
+	case pln.PredefinedStagePostSyncHook:
+		return hooks.PostSync, hooks.PostSync != nil
+	default:
+		return nil, false
+	}
+}
+
 func (s *scheduler) reportStageStatus(ctx context.Context, stageID string, status model.StageStatus, requires []string) error {
 	var (
 		err error
@@ -610,7 +660,9 @@ func (s *scheduler) reportDeploymentCompleted(ctx context.Context, status model.
 
 	// Update deployment status on remote.
 	for retry.WaitNext(ctx) {
-		if _, err = s.apiClient.ReportDeploymentCompleted(ctx, req); err == nil {
+		var resp *pipedservice.ReportDeploymentCompletedResponse
+		if resp, err = s.apiClient.ReportDeploymentCompleted(ctx, req); err == nil {
+			notifyDeploymentChainCompleted(s.notifier, resp.CompletedDeploymentChain)
 			return nil
 		}
 		err = fmt.Errorf("failed to report deployment status to control-plane: %w", err)
@@ -23,8 +23,8 @@ import (
 )
 
 type Renderer struct {
-	leftPadding    int
-	maskPathPrefix string
+	leftPadding      int
+	maskPathPrefixes []string
 }
 
 type RenderOption func(*Renderer)
@@ -37,9 +37,11 @@ func WithLeftPadding(p int) RenderOption {
 	}
 }
 
+// WithMaskPath masks the values of all nodes whose path starts with the given prefix.
+// It can be specified multiple times to mask several paths.
 func WithMaskPath(prefix string) RenderOption {
 	return func(r *Renderer) {
-		r.maskPathPrefix = prefix
+		r.maskPathPrefixes = append(r.maskPathPrefixes, prefix)
 	}
 }
 
@@ -121,7 +123,7 @@ func (r *Renderer) Render(ns Nodes) string {
 
 		lastStep := n.Path[pathLen-1]
 		valueX, valueY := n.ValueX, n.ValueY
-		if r.maskPathPrefix != "" && strings.HasPrefix(n.PathString, r.maskPathPrefix) {
+		if r.shouldMask(n.PathString) {
 			valueX = reflect.ValueOf(maskString)
 			valueY = reflect.ValueOf(maskString)
 		}
@@ -135,6 +137,15 @@ func (r *Renderer) Render(ns Nodes) string {
 	return b.String()
 }
 
+func (r *Renderer) shouldMask(path string) bool {
+	for _, prefix := range r.maskPathPrefixes {
+		if prefix != "" && strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 func pathDuplicateDepth(x, y []PathStep) int {
 	minLen := len(x)
 	if minLen > len(y) {
@@ -21,6 +21,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 func TestRenderNodeValue(t *testing.T) {
@@ -204,3 +205,38 @@ func TestRenderPrimitiveValue(t *testing.T) {
 		})
 	}
 }
+
+func TestRenderWithMaskPaths(t *testing.T) {
+	x := unstructured.Unstructured{Object: map[string]interface{}{
+		"data":       map[string]interface{}{"password": "old"},
+		"stringData": map[string]interface{}{"token": "old"},
+		"type":       "Opaque",
+	}}
+	y := unstructured.Unstructured{Object: map[string]interface{}{
+		"data":       map[string]interface{}{"password": "new"},
+		"stringData": map[string]interface{}{"token": "new"},
+		"type":       "Generic",
+	}}
+	result, err := DiffUnstructureds(x, y)
+	require.NoError(t, err)
+
+	renderer := NewRenderer(WithMaskPath("data"), WithMaskPath("stringData"))
+	got := renderer.Render(result.Nodes())
+
+	expected := `data:
+  #data.password
+- password: *****
++ password: *****
+
+stringData:
+  #stringData.token
+- token: *****
++ token: *****
+
+#type
+- type: Opaque
++ type: Generic
+
+`
+	assert.Equal(t, expected, got)
+}
@@ -50,6 +50,14 @@ type gitClient interface {
 
 type apiClient interface {
 	ReportApplicationSyncState(ctx context.Context, req *pipedservice.ReportApplicationSyncStateRequest, opts ...grpc.CallOption) (*pipedservice.ReportApplicationSyncStateResponse, error)
+	GetDriftDetectionConfig(ctx context.Context, req *pipedservice.GetDriftDetectionConfigRequest, opts ...grpc.CallOption) (*pipedservice.GetDriftDetectionConfigResponse, error)
+}
+
+// How long the drift detection settings of the project are reused before fetching again.
+const projectConfigCacheTTL = time.Minute
+
+type syncStateNotifier interface {
+	NotifySyncState(ctx context.Context, appID string, state *model.ApplicationSyncState) error
 }
 
 type sealedSecretDecrypter interface {
@@ -61,11 +69,19 @@ type Detector interface {
 }
 
 type detector struct {
-	apiClient  apiClient
-	detectors  []providerDetector
-	syncStates map[string]model.ApplicationSyncState
-	mu         sync.RWMutex
-	logger     *zap.Logger
+	apiClient         apiClient
+	syncStateNotifier syncStateNotifier
+	detectors         []providerDetector
+	syncStates        map[string]model.ApplicationSyncState
+	mu                sync.RWMutex
+	logger            *zap.Logger
+
+	projectConfig          *model.ProjectDriftDetectionConfig
+	projectConfigFetchedAt time.Time
+	projectConfigMu        sync.Mutex
+
+	config   *config.PipedSpec
+	configMu sync.RWMutex
 }
 
 type providerDetector interface {
@@ -79,6 +95,7 @@ func NewDetector(
 	gitClient gitClient,
 	stateGetter livestatestore.Getter,
 	apiClient apiClient,
+	syncStateNotifier syncStateNotifier,
 	appManifestsCache cache.Cache,
 	cfg *config.PipedSpec,
 	ssd sealedSecretDecrypter,
@@ -86,10 +103,12 @@ func NewDetector(
 ) *detector {
 
 	d := &detector{
-		apiClient:  apiClient,
-		detectors:  make([]providerDetector, 0, len(cfg.CloudProviders)),
-		syncStates: make(map[string]model.ApplicationSyncState),
-		logger:     logger.Named("drift-detector"),
+		apiClient:         apiClient,
+		syncStateNotifier: syncStateNotifier,
+		detectors:         make([]providerDetector, 0, len(cfg.CloudProviders)),
+		syncStates:        make(map[string]model.ApplicationSyncState),
+		config:            cfg,
+		logger:            logger.Named("drift-detector"),
 	}
 
 	for _, cp := range cfg.CloudProviders {
@@ -107,8 +126,9 @@ func NewDetector(
 				gitClient,
 				sg,
 				d,
+				d,
 				appManifestsCache,
-				cfg,
+				d,
 				ssd,
 				logger,
 			))
@@ -144,6 +164,22 @@ func (d *detector) Run(ctx context.Context) error {
 	return nil
 }
 
+// UpdateConfig passes the given piped configuration to the running detectors.
+// The repositories added or changed by the configuration update are cloned on their next check.
+func (d *detector) UpdateConfig(_ context.Context, cfg *config.PipedSpec) error {
+	d.configMu.Lock()
+	d.config = cfg
+	d.configMu.Unlock()
+	return nil
+}
+
+// GetRepository finds a repository with the given ID from the most recent piped configuration.
+func (d *detector) GetRepository(repoID string) (config.PipedRepository, bool) {
+	d.configMu.RLock()
+	defer d.configMu.RUnlock()
+	return d.config.GetRepository(repoID)
+}
+
 func (d *detector) ReportApplicationSyncState(ctx context.Context, appID string, state model.ApplicationSyncState) error {
 	d.mu.RLock()
 	curState, ok := d.syncStates[appID]
@@ -170,5 +206,39 @@ func (d *detector) ReportApplicationSyncState(ctx context.Context, appID string,
 	d.syncStates[appID] = state
 	d.mu.Unlock()
 
+	// Only the status changes are passed to let the automatic sync
+	// handle every configuration drift once.
+	if ok && curState.Status == state.Status {
+		return nil
+	}
+	if err := d.syncStateNotifier.NotifySyncState(ctx, appID, &state); err != nil {
+		d.logger.Error("failed to notify application sync state",
+			zap.String("application-id", appID),
+			zap.Error(err),
+		)
+	}
+
 	return nil
 }
+
+// ListKubernetesIgnoreFields returns the fields ignored by default
+// while detecting the drift of all Kubernetes applications in the project.
+// The project settings are cached for a while to reduce the API calls.
+func (d *detector) ListKubernetesIgnoreFields(ctx context.Context) []*model.KubernetesDriftIgnoreField {
+	d.projectConfigMu.Lock()
+	defer d.projectConfigMu.Unlock()
+
+	if time.Since(d.projectConfigFetchedAt) < projectConfigCacheTTL {
+		return d.projectConfig.GetKubernetesIgnoreFields()
+	}
+
+	resp, err := d.apiClient.GetDriftDetectionConfig(ctx, &pipedservice.GetDriftDetectionConfigRequest{})
+	if err != nil {
+		// Keep using the previous settings until the next try.
+		d.logger.Error("failed to get drift detection settings of the project", zap.Error(err))
+		return d.projectConfig.GetKubernetesIgnoreFields()
+	}
+	d.projectConfig = resp.DriftDetection
+	d.projectConfigFetchedAt = time.Now()
+	return d.projectConfig.GetKubernetesIgnoreFields()
+}
@@ -55,6 +55,14 @@ type reporter interface {
 	ReportApplicationSyncState(ctx context.Context, appID string, state model.ApplicationSyncState) error
 }
 
+type repositoryGetter interface {
+	GetRepository(repoID string) (config.PipedRepository, bool)
+}
+
+type ignoreFieldsLister interface {
+	ListKubernetesIgnoreFields(ctx context.Context) []*model.KubernetesDriftIgnoreField
+}
+
 type detector struct {
 	provider              config.PipedCloudProvider
 	appLister             applicationLister
@@ -62,14 +70,17 @@ type detector struct {
 	gitClient             gitClient
 	stateGetter           kubernetes.Getter
 	reporter              reporter
+	ignoreFieldsLister    ignoreFieldsLister
 	appManifestsCache     cache.Cache
 	interval              time.Duration
-	config                *config.PipedSpec
+	repositoryGetter      repositoryGetter
 	sealedSecretDecrypter sealedSecretDecrypter
 	logger                *zap.Logger
 
-	gitRepos   map[string]git.Repo
-	syncStates map[string]model.ApplicationSyncState
+	gitRepos map[string]git.Repo
+	// The configuration used to clone each repository in gitRepos.
+	gitRepoConfigs map[string]config.PipedRepository
+	syncStates     map[string]model.ApplicationSyncState
 }
 
 func NewDetector(
@@ -79,8 +90,9 @@ func NewDetector(
 	gitClient gitClient,
 	stateGetter kubernetes.Getter,
 	reporter reporter,
+	ignoreFieldsLister ignoreFieldsLister,
 	appManifestsCache cache.Cache,
-	cfg *config.PipedSpec,
+	rg repositoryGetter,
 	ssd sealedSecretDecrypter,
 	logger *zap.Logger,
 ) *detector {
@@ -95,11 +107,13 @@ func NewDetector(
 		gitClient:             gitClient,
 		stateGetter:           stateGetter,
 		reporter:              reporter,
+		ignoreFieldsLister:    ignoreFieldsLister,
 		appManifestsCache:     appManifestsCache,
 		interval:              time.Minute,
-		config:                cfg,
+		repositoryGetter:      rg,
 		sealedSecretDecrypter: ssd,
 		gitRepos:              make(map[string]git.Repo),
+		gitRepoConfigs:        make(map[string]config.PipedRepository),
 		syncStates:            make(map[string]model.ApplicationSyncState),
 		logger:                logger,
 	}
@@ -131,6 +145,8 @@ func (d *detector) check(ctx context.Context) error {
 		err             error
 		applications    = d.listApplications()
 		headDeployments = d.deploymentLister.ListAppHeadDeployments()
+		// The project settings are fetched lazily once in every check.
+		projectIgnoreFields []config.KubernetesDriftIgnoreField
 	)
 
 	for repoID, apps := range applications {
@@ -153,16 +169,30 @@ func (d *detector) check(ctx context.Context) error {
 		if len(notDeployingApps) == 0 {
 			continue
 		}
+		if projectIgnoreFields == nil {
+			projectIgnoreFields = makeIgnoreFields(d.ignoreFieldsLister.ListKubernetesIgnoreFields(ctx))
+		}
 
 		// Next, we have to clone the lastest commit of repository
 		// to compare the states.
-		gitRepo, ok := d.gitRepos[repoID]
+		// The repository configuration is looked up on every check
+		// since it can be changed by reloading the piped configuration.
+		repoCfg, ok := d.repositoryGetter.GetRepository(repoID)
 		if !ok {
-			// Clone repository for the first time.
-			repoCfg, ok := d.config.GetRepository(repoID)
-			if !ok {
-				d.logger.Error(fmt.Sprintf("repository %s was not found in piped configuration", repoID))
-				continue
+			d.logger.Error(fmt.Sprintf("repository %s was not found in piped configuration", repoID))
+			continue
+		}
+		gitRepo, ok := d.gitRepos[repoID]
+		if !ok || d.gitRepoConfigs[repoID] != repoCfg {
+			// Clone repository for the first time or again after its configuration was changed.
+			if ok {
+				if err := gitRepo.Clean(); err != nil {
+					d.logger.Warn("failed to clean the repository cloned with the old configuration",
+						zap.String("repo-id", repoID),
+						zap.Error(err),
+					)
+				}
+				delete(d.gitRepos, repoID)
 			}
 			gitRepo, err = d.gitClient.Clone(ctx, repoID, repoCfg.Remote, repoCfg.Branch, "")
 			if err != nil {
@@ -173,6 +203,7 @@ func (d *detector) check(ctx context.Context) error {
 				continue
 			}
 			d.gitRepos[repoID] = gitRepo
+			d.gitRepoConfigs[repoID] = repoCfg
 		}
 
 		// Fetch to update the repository.
@@ -196,7 +227,7 @@ func (d *detector) check(ctx context.Context) error {
 		}
 
 		for _, app := range notDeployingApps {
-			if err := d.checkApplication(ctx, app, gitRepo, headCommit); err != nil {
+			if err := d.checkApplication(ctx, app, gitRepo, headCommit, projectIgnoreFields); err != nil {
 				d.logger.Error(fmt.Sprintf("failed to check application: %s", app.Id), zap.Error(err))
 			}
 		}
@@ -205,9 +236,17 @@ func (d *detector) check(ctx context.Context) error {
 	return nil
 }
 
-func (d *detector) checkApplication(ctx context.Context, app *model.Application, repo git.Repo, headCommit git.Commit) error {
+func (d *detector) checkApplication(ctx context.Context, app *model.Application, repo git.Repo, headCommit git.Commit, projectIgnoreFields []config.KubernetesDriftIgnoreField) error {
+	cfg, err := d.loadDeploymentConfiguration(repo.GetPath(), app)
+	if err != nil {
+		return fmt.Errorf("failed to load deployment configuration: %w", err)
+	}
+	if cfg.KubernetesDeploymentSpec == nil {
+		return fmt.Errorf("unsupport application kind %s", cfg.Kind)
+	}
+
 	watchingResourceKinds := d.stateGetter.GetWatchingResourceKinds()
-	headManifests, err := d.loadHeadManifests(ctx, app, repo, headCommit, watchingResourceKinds)
+	headManifests, err := d.loadHeadManifests(ctx, app, cfg, repo, headCommit, watchingResourceKinds)
 	if err != nil {
 		return err
 	}
@@ -221,8 +260,17 @@ func (d *detector) checkApplication(ctx context.Context, app *model.Application,
 	// Divide manifests into separate groups.
 	adds, deletes, headInters, liveInters := groupManifests(headManifests, liveManifests)
 
+	// The fields configured for the application are ignored in addition to the project's ones.
+	ignoreFields := projectIgnoreFields
+	if dd := cfg.KubernetesDeploymentSpec.DriftDetection; dd != nil {
+		ignoreFields = append(ignoreFields[:len(ignoreFields):len(ignoreFields)], dd.IgnoreFields...)
+	}
+
 	// Now we will go to check the diff intersection group.
-	changes := make(map[provider.Manifest]*diff.Result)
+	var (
+		changes = make(map[provider.Manifest]diff.Nodes)
+		ignores = make(map[provider.Manifest]diff.Nodes)
+	)
 	for i := 0; i < len(headInters); i++ {
 		result, err := provider.Diff(headInters[i], liveInters[i], diff.WithIgnoreAddingMapKeys())
 		if err != nil {
@@ -232,20 +280,26 @@ func (d *detector) checkApplication(ctx context.Context, app *model.Application,
 		if !result.HasDiff() {
 			continue
 		}
-		changes[headInters[i]] = result
+		nodes, ignored := filterIgnoredNodes(headInters[i].Key, result.Nodes(), ignoreFields)
+		if len(ignored) > 0 {
+			ignores[headInters[i]] = ignored
+		}
+		if len(nodes) > 0 {
+			changes[headInters[i]] = nodes
+		}
 	}
 
 	// No diffs means this application is in SYNCED state.
 	if len(adds) == 0 && len(deletes) == 0 && len(changes) == 0 {
-		state := makeSyncedState()
+		state := makeSyncedState(ignores)
 		return d.reporter.ReportApplicationSyncState(ctx, app.Id, state)
 	}
 
-	state := makeOutOfSyncState(adds, deletes, changes, headCommit.Hash)
+	state := makeOutOfSyncState(adds, deletes, changes, ignores, headCommit.Hash)
 	return d.reporter.ReportApplicationSyncState(ctx, app.Id, state)
 }
 
-func (d *detector) loadHeadManifests(ctx context.Context, app *model.Application, repo git.Repo, headCommit git.Commit, watchingResourceKinds []provider.APIVersionKind) ([]provider.Manifest, error) {
+func (d *detector) loadHeadManifests(ctx context.Context, app *model.Application, cfg *config.Config, repo git.Repo, headCommit git.Commit, watchingResourceKinds []provider.APIVersionKind) ([]provider.Manifest, error) {
 	var (
 		manifestCache = provider.AppManifestsCache{
 			AppID:  app.Id,
@@ -259,11 +313,6 @@ func (d *detector) loadHeadManifests(ctx context.Context, app *model.Application
 	manifests, ok := manifestCache.Get(headCommit.Hash)
 	if !ok {
 		// When the manifests were not in the cache we have to load them.
-		cfg, err := d.loadDeploymentConfiguration(repoDir, app)
-		if err != nil {
-			return nil, fmt.Errorf("failed to load deployment configuration: %w", err)
-		}
-
 		gds, ok := cfg.GetGenericDeployment()
 		if !ok {
 			return nil, fmt.Errorf("unsupport application kind %s", cfg.Kind)
@@ -291,6 +340,7 @@ func (d *detector) loadHeadManifests(ctx context.Context, app *model.Application
 		}
 
 		loader := provider.NewManifestLoader(app.Name, appDir, repoDir, app.GitPath.ConfigFilename, cfg.KubernetesDeploymentSpec.Input, d.logger)
+		var err error
 		manifests, err = loader.LoadManifests(ctx)
 		if err != nil {
 			err = fmt.Errorf("failed to load new manifests: %w", err)
@@ -299,6 +349,9 @@ func (d *detector) loadHeadManifests(ctx context.Context, app *model.Application
 		manifestCache.Put(headCommit.Hash, manifests)
 	}
 
+	// The Helm hooks are short-lived so they are not compared with the live resources.
+	manifests, _ = provider.SplitHelmHooks(manifests)
+
 	watchingMap := make(map[provider.APIVersionKind]struct{}, len(watchingResourceKinds))
 	for _, k := range watchingResourceKinds {
 		watchingMap[k] = struct{}{}
@@ -452,16 +505,19 @@ func makeDeployingState(deployment *model.Deployment) model.ApplicationSyncState
 	}
 }
 
-func makeSyncedState() model.ApplicationSyncState {
+func makeSyncedState(ignores map[provider.Manifest]diff.Nodes) model.ApplicationSyncState {
+	var b strings.Builder
+	writeIgnoredDiffs(&b, ignores)
+
 	return model.ApplicationSyncState{
 		Status:      model.ApplicationSyncStatus_SYNCED,
 		ShortReason: "",
-		Reason:      "",
+		Reason:      b.String(),
 		Timestamp:   time.Now().Unix(),
 	}
 }
 
-func makeOutOfSyncState(adds, deletes []provider.Manifest, changes map[provider.Manifest]*diff.Result, commit string) model.ApplicationSyncState {
+func makeOutOfSyncState(adds, deletes []provider.Manifest, changes, ignores map[provider.Manifest]diff.Nodes, commit string) model.ApplicationSyncState {
 	total := len(adds) + len(deletes) + len(changes)
 	shortReason := fmt.Sprintf("There are %d manifests not synced (%d adds, %d deletes, %d changes)", total, len(adds), len(deletes), len(changes))
 
@@ -498,7 +554,7 @@ func makeOutOfSyncState(adds, deletes []provider.Manifest, changes map[provider.
 
 		index++
 		b.WriteString(fmt.Sprintf("* %d. %s\n\n", index, m.Key.ReadableString()))
-		b.WriteString(renderer.Render(d.Nodes()))
+		b.WriteString(renderer.Render(d))
 		b.WriteString("\n")
 
 		prints++
@@ -511,6 +567,11 @@ func makeOutOfSyncState(adds, deletes []provider.Manifest, changes map[provider.
 		b.WriteString(fmt.Sprintf("... (diffs from %d other manifests are omitted\n", len(changes)-prints))
 	}
 
+	if len(ignores) > 0 {
+		b.WriteString("\n")
+		writeIgnoredDiffs(&b, ignores)
+	}
+
 	return model.ApplicationSyncState{
 		Status:      model.ApplicationSyncStatus_OUT_OF_SYNC,
 		ShortReason: shortReason,
@@ -530,3 +591,75 @@ func filterIgnoringManifests(manifests []provider.Manifest) []provider.Manifest
 	}
 	return out
 }
+
+// makeIgnoreFields converts the ignored fields configured for the project into the config ones.
+func makeIgnoreFields(fields []*model.KubernetesDriftIgnoreField) []config.KubernetesDriftIgnoreField {
+	out := make([]config.KubernetesDriftIgnoreField, 0, len(fields))
+	for _, f := range fields {
+		out = append(out, config.KubernetesDriftIgnoreField{
+			Kind:  f.Kind,
+			Name:  f.Name,
+			Paths: f.Paths,
+		})
+	}
+	return out
+}
+
+// filterIgnoredNodes divides the diff nodes of the given resource
+// into the ones that should be reported and the ignored ones.
+func filterIgnoredNodes(key provider.ResourceKey, nodes diff.Nodes, fields []config.KubernetesDriftIgnoreField) (remains, ignored diff.Nodes) {
+	ignoredPaths := make(map[string]struct{})
+	for _, f := range fields {
+		if f.Kind != "" && f.Kind != key.Kind {
+			continue
+		}
+		if f.Name != "" && f.Name != key.Name {
+			continue
+		}
+		for _, p := range f.Paths {
+			matches, err := nodes.Find(p)
+			if err != nil {
+				continue
+			}
+			for _, n := range matches {
+				ignoredPaths[n.PathString] = struct{}{}
+			}
+		}
+	}
+	if len(ignoredPaths) == 0 {
+		return nodes, nil
+	}
+
+	for _, n := range nodes {
+		if _, ok := ignoredPaths[n.PathString]; ok {
+			ignored = append(ignored, n)
+			continue
+		}
+		remains = append(remains, n)
+	}
+	return remains, ignored
+}
+
+// writeIgnoredDiffs writes the paths of the ignored differences
+// sorted by the resource to keep the reason stable between checks.
+func writeIgnoredDiffs(b *strings.Builder, ignores map[provider.Manifest]diff.Nodes) {
+	if len(ignores) == 0 {
+		return
+	}
+	keys := make([]provider.ResourceKey, 0, len(ignores))
+	paths := make(map[provider.ResourceKey][]string, len(ignores))
+	for m, nodes := range ignores {
+		keys = append(keys, m.Key)
+		for _, n := range nodes {
+			paths[m.Key] = append(paths[m.Key], n.PathString)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+
+	b.WriteString("Ignored differences:\n")
+	for _, k := range keys {
+		b.WriteString(fmt.Sprintf("- %s: %s\n", k.ReadableString(), strings.Join(paths[k], ", ")))
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/kubernetes"
+	"github.com/pipe-cd/pipe/pkg/app/piped/diff"
 	"github.com/pipe-cd/pipe/pkg/config"
 )
 
@@ -216,3 +217,87 @@ data:
 		string(data),
 	)
 }
+
+func TestFilterIgnoredNodes(t *testing.T) {
+	var (
+		key = provider.ResourceKey{
+			APIVersion: "apps/v1",
+			Kind:       "Deployment",
+			Name:       "simple",
+		}
+		nodes = diff.Nodes{
+			{PathString: "metadata.annotations.foo"},
+			{PathString: "spec.replicas"},
+			{PathString: "spec.template.spec.containers.1.image"},
+		}
+	)
+	testcases := []struct {
+		name            string
+		fields          []config.KubernetesDriftIgnoreField
+		expectedRemains diff.Nodes
+		expectedIgnored diff.Nodes
+	}{
+		{
+			name:            "no ignored field",
+			expectedRemains: nodes,
+		},
+		{
+			name: "ignore replicas of all deployments",
+			fields: []config.KubernetesDriftIgnoreField{
+				{Kind: "Deployment", Paths: []string{`^spec\.replicas$`}},
+			},
+			expectedRemains: diff.Nodes{nodes[0], nodes[2]},
+			expectedIgnored: diff.Nodes{nodes[1]},
+		},
+		{
+			name: "ignore multiple paths of the named resource",
+			fields: []config.KubernetesDriftIgnoreField{
+				{Kind: "Deployment", Name: "simple", Paths: []string{`^spec\.replicas$`, `^spec\.template\.spec\.containers\.1\.`}},
+			},
+			expectedRemains: diff.Nodes{nodes[0]},
+			expectedIgnored: diff.Nodes{nodes[1], nodes[2]},
+		},
+		{
+			name: "other kind",
+			fields: []config.KubernetesDriftIgnoreField{
+				{Kind: "StatefulSet", Paths: []string{`^spec\.replicas$`}},
+			},
+			expectedRemains: nodes,
+		},
+		{
+			name: "other name",
+			fields: []config.KubernetesDriftIgnoreField{
+				{Name: "other", Paths: []string{`.*`}},
+			},
+			expectedRemains: nodes,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			remains, ignored := filterIgnoredNodes(key, nodes, tc.fields)
+			assert.Equal(t, tc.expectedRemains, remains)
+			assert.Equal(t, tc.expectedIgnored, ignored)
+		})
+	}
+}
+
+func TestMakeSyncedStateWithIgnoredDiffs(t *testing.T) {
+	ignores := map[provider.Manifest]diff.Nodes{
+		{Key: provider.ResourceKey{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "default", Name: "b"}}: {
+			{PathString: "spec.replicas"},
+		},
+		{Key: provider.ResourceKey{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "default", Name: "a"}}: {
+			{PathString: "spec.replicas"},
+			{PathString: "spec.template.metadata.annotations.foo"},
+		},
+	}
+
+	state := makeSyncedState(nil)
+	assert.Equal(t, "", state.Reason)
+
+	state = makeSyncedState(ignores)
+	expected := "Ignored differences:\n" +
+		"- name=\"a\", kind=\"Deployment\", namespace=\"default\", apiVersion=\"apps/v1\": spec.replicas, spec.template.metadata.annotations.foo\n" +
+		"- name=\"b\", kind=\"Deployment\", namespace=\"default\", apiVersion=\"apps/v1\": spec.replicas\n"
+	assert.Equal(t, expected, state.Reason)
+}
@@ -52,12 +52,21 @@ type MetadataStore interface {
 	SetStageMetadata(ctx context.Context, stageID string, metadata map[string]string) error
 }
 
+// ArtifactStore saves and loads the files generated while handling the deployment,
+// such as the plan file to be used by a later stage.
+// The files are encrypted so that only the piped can read them.
+type ArtifactStore interface {
+	Get(ctx context.Context, name string) ([]byte, error)
+	Put(ctx context.Context, name string, content []byte) error
+}
+
 type CommandLister interface {
 	ListCommands() []model.ReportableCommand
 }
 
 type AppLiveResourceLister interface {
-	ListKubernetesResources() ([]provider.Manifest, bool)
+	// ListKubernetesResources returns the live resources of the application in the given cloud provider.
+	ListKubernetesResources(cloudProvider string) ([]provider.Manifest, bool)
 }
 
 type Input struct {
@@ -72,6 +81,7 @@ type Input struct {
 	CommandLister         CommandLister
 	LogPersister          LogPersister
 	MetadataStore         MetadataStore
+	ArtifactStore         ArtifactStore
 	AppManifestsCache     cache.Cache
 	AppLiveResourceLister AppLiveResourceLister
 	Logger                *zap.Logger
@@ -0,0 +1,73 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package hook provides an executor for the HOOK stage
+// which runs a Kubernetes Job and waits until it completes.
+package hook
+
+import (
+	"context"
+
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/kubernetes"
+	"github.com/pipe-cd/pipe/pkg/app/piped/executor"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+type Executor struct {
+	executor.Input
+
+	findKubectl func(ctx context.Context, version string) (*provider.Kubectl, error)
+}
+
+type registerer interface {
+	Register(stage model.Stage, f executor.Factory) error
+}
+
+// Register registers this executor factory into a given registerer.
+func Register(r registerer) {
+	f := func(in executor.Input) executor.Executor {
+		return &Executor{
+			Input:       in,
+			findKubectl: findKubectl,
+		}
+	}
+	r.Register(model.StageHook, f)
+}
+
+// Execute runs the configured Job and maps its result to the stage status.
+func (e *Executor) Execute(sig executor.StopSignal) model.StageStatus {
+	var (
+		ctx            = sig.Context()
+		originalStatus = e.Stage.Status
+		opts           = e.StageConfig.HookStageOptions
+	)
+	if opts == nil {
+		e.LogPersister.Errorf("Malformed configuration for stage %s", e.Stage.Name)
+		return model.StageStatus_STAGE_FAILURE
+	}
+
+	ds, err := e.TargetDSP.Get(ctx, e.LogPersister)
+	if err != nil {
+		e.LogPersister.Errorf("Failed to prepare target deploy source data (%v)", err)
+		return executor.DetermineStageStatus(sig.Signal(), originalStatus, model.StageStatus_STAGE_FAILURE)
+	}
+
+	if err := e.runJob(ctx, sig, ds.DeploymentConfig, ds.AppDir, opts); err != nil {
+		e.LogPersister.Errorf("Failed to run the hook (%v)", err)
+		return executor.DetermineStageStatus(sig.Signal(), originalStatus, model.StageStatus_STAGE_FAILURE)
+	}
+
+	e.LogPersister.Success("Successfully ran the hook")
+	return executor.DetermineStageStatus(sig.Signal(), originalStatus, model.StageStatus_STAGE_SUCCESS)
+}
@@ -0,0 +1,205 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hook
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"path/filepath"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/kubernetes"
+	"github.com/pipe-cd/pipe/pkg/app/piped/executor"
+	"github.com/pipe-cd/pipe/pkg/app/piped/toolregistry"
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+const (
+	jobDeleteTimeout = 30 * time.Second
+)
+
+var (
+	jobCheckInterval = 5 * time.Second
+)
+
+// runJob creates the Kubernetes Job specified in the given manifest file,
+// streams its logs and waits until it has been completed or failed.
+func (e *Executor) runJob(ctx context.Context, sig executor.StopSignal, cfg *config.Config, appDir string, opts *config.HookStageOptions) error {
+	manifests, err := provider.LoadManifestsFromYAMLFile(filepath.Join(appDir, opts.JobManifest))
+	if err != nil {
+		return fmt.Errorf("failed to load job manifest %s: %w", opts.JobManifest, err)
+	}
+	if len(manifests) != 1 || manifests[0].Key.Kind != provider.KindJob {
+		return fmt.Errorf("job manifest %s must contain exactly one Job", opts.JobManifest)
+	}
+
+	// Jobs are immutable so a unique name is given to each run of the hook.
+	job := manifests[0].Duplicate(makeJobName(manifests[0].Key.Name, e.Deployment.Id, e.Stage.Id))
+	namespace := opts.Namespace
+	if namespace == "" {
+		namespace = job.Key.Namespace
+	}
+
+	var kubectlVersion string
+	if cfg != nil && cfg.KubernetesDeploymentSpec != nil {
+		kubectlVersion = cfg.KubernetesDeploymentSpec.Input.KubectlVersion
+	}
+	clusterCfg, err := e.findClusterConfig(cfg, opts.CloudProvider)
+	if err != nil {
+		return err
+	}
+	kubectl, err := e.findKubectl(ctx, kubectlVersion)
+	if err != nil {
+		return err
+	}
+	kubectl = kubectl.WithCluster(clusterCfg.MasterURL, clusterCfg.KubeConfigPath)
+
+	if err := kubectl.Apply(ctx, namespace, job); err != nil {
+		return err
+	}
+	e.LogPersister.Infof("Created job %s, waiting for it to complete", job.Key.Name)
+
+	err = e.waitJob(ctx, kubectl, namespace, job.Key)
+
+	// Stop the running job when the stage was cancelled or timed out.
+	if s := sig.Signal(); s == executor.StopSignalCancel || s == executor.StopSignalTimeout {
+		dctx, cancel := context.WithTimeout(context.Background(), jobDeleteTimeout)
+		defer cancel()
+		if derr := kubectl.Delete(dctx, namespace, job.Key); derr != nil {
+			e.LogPersister.Errorf("Failed to delete job %s (%v)", job.Key.Name, derr)
+		} else {
+			e.LogPersister.Infof("Deleted job %s", job.Key.Name)
+		}
+	}
+	return err
+}
+
+func (e *Executor) waitJob(ctx context.Context, kubectl *provider.Kubectl, namespace string, key provider.ResourceKey) error {
+	var (
+		ticker   = time.NewTicker(jobCheckInterval)
+		logsCh   chan error
+		streamed bool
+	)
+	defer ticker.Stop()
+
+	// The logs can only be streamed after the pod has started,
+	// so we keep retrying at every check until it succeeds once.
+	streamLogs := func() {
+		logsCh = make(chan error, 1)
+		go func(ch chan<- error) {
+			ch <- kubectl.Logs(ctx, namespace, key, e.LogPersister)
+		}(logsCh)
+	}
+	streamLogs()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case err := <-logsCh:
+			streamed = err == nil
+			logsCh = nil
+			continue
+
+		case <-ticker.C:
+			if !streamed && logsCh == nil {
+				streamLogs()
+			}
+		}
+
+		m, err := kubectl.Get(ctx, namespace, key)
+		if err != nil {
+			return err
+		}
+		done, err := jobCompleted(m)
+		if !done && err == nil {
+			continue
+		}
+		// Wait for the remaining logs before reporting the result.
+		if !streamed && logsCh == nil {
+			streamLogs()
+		}
+		if logsCh != nil {
+			select {
+			case <-logsCh:
+			case <-ctx.Done():
+			}
+		}
+		return err
+	}
+}
+
+// jobCompleted returns true if the given Job has finished.
+// A non-nil error is returned together when it was failed.
+func jobCompleted(m provider.Manifest) (bool, error) {
+	var job batchv1.Job
+	if err := m.ConvertToStructuredObject(&job); err != nil {
+		return false, err
+	}
+	for _, c := range job.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batchv1.JobComplete:
+			return true, nil
+		case batchv1.JobFailed:
+			return true, fmt.Errorf("job %s failed: %s", m.Key.Name, c.Message)
+		}
+	}
+	return false, nil
+}
+
+// findClusterConfig returns the configuration of the Kubernetes cluster where the Job should be run.
+// When no cloud provider was specified, the one of the application is used.
+func (e *Executor) findClusterConfig(cfg *config.Config, name string) (*config.CloudProviderKubernetesConfig, error) {
+	if name == "" {
+		name = e.Deployment.CloudProvider
+		if cfg != nil && cfg.KubernetesDeploymentSpec != nil {
+			switch cps := cfg.KubernetesDeploymentSpec.CloudProviders; {
+			case len(cps) == 1:
+				name = cps[0]
+			case len(cps) > 1:
+				return nil, fmt.Errorf("cloudProvider must be specified since the application is deployed to %d cloud providers", len(cps))
+			}
+		}
+	}
+	cp, ok := e.PipedConfig.FindCloudProvider(name, model.CloudProviderKubernetes)
+	if !ok || cp.KubernetesConfig == nil {
+		return nil, fmt.Errorf("kubernetes cloud provider %s was not found in the piped configuration", name)
+	}
+	return cp.KubernetesConfig, nil
+}
+
+func makeJobName(name, deploymentID, stageID string) string {
+	h := fnv.New32a()
+	h.Write([]byte(deploymentID))
+	h.Write([]byte(stageID))
+	return fmt.Sprintf("%s-%08x", name, h.Sum32())
+}
+
+func findKubectl(ctx context.Context, version string) (*provider.Kubectl, error) {
+	path, _, err := toolregistry.DefaultRegistry().Kubectl(ctx, version)
+	if err != nil {
+		return nil, fmt.Errorf("no kubectl %s (%v)", version, err)
+	}
+	return provider.NewKubectl(version, path), nil
+}
@@ -0,0 +1,297 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hook
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/kubernetes"
+	"github.com/pipe-cd/pipe/pkg/app/piped/executor"
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+type fakeLogPersister struct{}
+
+func (l *fakeLogPersister) Write(p []byte) (int, error)         { return len(p), nil }
+func (l *fakeLogPersister) Info(_ string)                       {}
+func (l *fakeLogPersister) Infof(_ string, _ ...interface{})    {}
+func (l *fakeLogPersister) Success(_ string)                    {}
+func (l *fakeLogPersister) Successf(_ string, _ ...interface{}) {}
+func (l *fakeLogPersister) Error(_ string)                      {}
+func (l *fakeLogPersister) Errorf(_ string, _ ...interface{})   {}
+
+// fakeKubectlScript records the arguments of every call
+// and reports the Job as still running.
+const fakeKubectlScript = `#!/bin/sh
+echo "$@" >> %s
+case "$*" in
+*" apply "*)
+  cat > /dev/null
+  ;;
+*" get "*)
+  cat <<EOF
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+status:
+  active: 1
+EOF
+  ;;
+esac
+`
+
+func TestJobCompleted(t *testing.T) {
+	testcases := []struct {
+		name         string
+		manifest     string
+		expectedDone bool
+		expectedErr  bool
+	}{
+		{
+			name: "running",
+			manifest: `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+status:
+  active: 1
+`,
+			expectedDone: false,
+		},
+		{
+			name: "completed",
+			manifest: `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+status:
+  succeeded: 1
+  conditions:
+  - type: Complete
+    status: "True"
+`,
+			expectedDone: true,
+		},
+		{
+			name: "failed",
+			manifest: `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+status:
+  failed: 1
+  conditions:
+  - type: Failed
+    status: "True"
+    message: Job has reached the specified backoff limit
+`,
+			expectedDone: true,
+			expectedErr:  true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			manifests, err := provider.ParseManifests(tc.manifest)
+			require.NoError(t, err)
+			require.Equal(t, 1, len(manifests))
+
+			done, err := jobCompleted(manifests[0])
+			assert.Equal(t, tc.expectedDone, done)
+			assert.Equal(t, tc.expectedErr, err != nil)
+		})
+	}
+}
+
+func TestMakeJobName(t *testing.T) {
+	pre := makeJobName("migrate", "deployment-1", "PreSyncHook")
+	post := makeJobName("migrate", "deployment-1", "PostSyncHook")
+
+	assert.Equal(t, pre, makeJobName("migrate", "deployment-1", "PreSyncHook"))
+	assert.NotEqual(t, pre, post)
+	assert.Equal(t, len("migrate-")+8, len(pre))
+}
+
+func TestRunJobOnCluster(t *testing.T) {
+	jobCheckInterval = 10 * time.Millisecond
+
+	dir, err := ioutil.TempDir("", "hook")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	var (
+		argsFile    = filepath.Join(dir, "args")
+		kubectlPath = filepath.Join(dir, "kubectl")
+		jobManifest = `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+spec:
+  template:
+    spec:
+      containers:
+      - name: migrate
+        image: migrate:v1
+      restartPolicy: Never
+`
+	)
+	require.NoError(t, ioutil.WriteFile(kubectlPath, []byte(fmt.Sprintf(fakeKubectlScript, argsFile)), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "job.yaml"), []byte(jobManifest), 0644))
+
+	e := &Executor{
+		Input: executor.Input{
+			Stage:      &model.PipelineStage{Id: "stage-1"},
+			Deployment: &model.Deployment{Id: "deployment-1", CloudProvider: "app-cluster"},
+			PipedConfig: &config.PipedSpec{
+				CloudProviders: []config.PipedCloudProvider{
+					{
+						Name: "app-cluster",
+						Type: model.CloudProviderKubernetes,
+						KubernetesConfig: &config.CloudProviderKubernetesConfig{
+							MasterURL:      "https://app-cluster",
+							KubeConfigPath: "/etc/kube/app-cluster",
+						},
+					},
+				},
+			},
+			LogPersister: &fakeLogPersister{},
+		},
+		findKubectl: func(_ context.Context, version string) (*provider.Kubectl, error) {
+			return provider.NewKubectl(version, kubectlPath), nil
+		},
+	}
+
+	sig, handler := executor.NewStopSignal()
+	doneCh := make(chan error, 1)
+	go func() {
+		doneCh <- e.runJob(sig.Context(), sig, nil, dir, &config.HookStageOptions{
+			JobManifest: "job.yaml",
+			Namespace:   "hooks",
+		})
+	}()
+
+	// Cancel the stage once the Job has been checked to let it be deleted.
+	require.Eventually(t, func() bool {
+		data, _ := ioutil.ReadFile(argsFile)
+		return strings.Contains(string(data), " get ")
+	}, 10*time.Second, 10*time.Millisecond)
+	handler.Cancel()
+	require.Error(t, <-doneCh)
+
+	data, err := ioutil.ReadFile(argsFile)
+	require.NoError(t, err)
+	calls := make(map[string]bool)
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		require.True(t, strings.HasPrefix(line, "--kubeconfig /etc/kube/app-cluster --server https://app-cluster -n hooks "), line)
+		calls[strings.Fields(line)[6]] = true
+	}
+	assert.Equal(t, map[string]bool{"apply": true, "get": true, "logs": true, "delete": true}, calls)
+}
+
+func TestFindClusterConfig(t *testing.T) {
+	pipedConfig := &config.PipedSpec{
+		CloudProviders: []config.PipedCloudProvider{
+			{
+				Name:             "cluster-1",
+				Type:             model.CloudProviderKubernetes,
+				KubernetesConfig: &config.CloudProviderKubernetesConfig{MasterURL: "https://cluster-1"},
+			},
+			{
+				Name:             "cluster-2",
+				Type:             model.CloudProviderKubernetes,
+				KubernetesConfig: &config.CloudProviderKubernetesConfig{MasterURL: "https://cluster-2"},
+			},
+			{
+				Name: "terraform",
+				Type: model.CloudProviderTerraform,
+			},
+		},
+	}
+	k8sConfig := func(cloudProviders ...string) *config.Config {
+		return &config.Config{
+			KubernetesDeploymentSpec: &config.KubernetesDeploymentSpec{CloudProviders: cloudProviders},
+		}
+	}
+	testcases := []struct {
+		name              string
+		appCloudProvider  string
+		cfg               *config.Config
+		hookCloudProvider string
+		expected          string
+		expectedErr       bool
+	}{
+		{
+			name:             "cloud provider of the application",
+			appCloudProvider: "cluster-1",
+			cfg:              k8sConfig(),
+			expected:         "https://cluster-1",
+		},
+		{
+			name:             "single cloud provider of the deployment",
+			appCloudProvider: "cluster-1",
+			cfg:              k8sConfig("cluster-2"),
+			expected:         "https://cluster-2",
+		},
+		{
+			name:             "multiple cloud providers without the hook one",
+			appCloudProvider: "cluster-1",
+			cfg:              k8sConfig("cluster-1", "cluster-2"),
+			expectedErr:      true,
+		},
+		{
+			name:              "specified cloud provider",
+			appCloudProvider:  "terraform",
+			hookCloudProvider: "cluster-2",
+			expected:          "https://cluster-2",
+		},
+		{
+			name:             "non kubernetes cloud provider",
+			appCloudProvider: "terraform",
+			expectedErr:      true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := &Executor{
+				Input: executor.Input{
+					Deployment:  &model.Deployment{CloudProvider: tc.appCloudProvider},
+					PipedConfig: pipedConfig,
+				},
+			}
+			cfg, err := e.findClusterConfig(tc.cfg, tc.hookCloudProvider)
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, cfg.MasterURL)
+		})
+	}
+}
@@ -49,6 +49,9 @@ func (e *deployExecutor) ensureBaselineRollout(ctx context.Context) model.StageS
 	}
 	e.LogPersister.Successf("Successfully loaded %d manifests", len(manifests))
 
+	// The Helm hooks are run only by K8S_SYNC and K8S_PRIMARY_ROLLOUT stages.
+	manifests, _ = provider.SplitHelmHooks(manifests)
+
 	if len(manifests) == 0 {
 		e.LogPersister.Error("This application has no running Kubernetes manifests to handle")
 		return model.StageStatus_STAGE_FAILURE
@@ -83,7 +86,11 @@ func (e *deployExecutor) ensureBaselineRollout(ctx context.Context) model.StageS
 
 	// Start rolling out the resources for BASELINE variant.
 	e.LogPersister.Info("Start rolling out BASELINE variant...")
-	if err := applyManifests(ctx, e.provider, baselineManifests, e.deployCfg.Input.Namespace, e.LogPersister); err != nil {
+	if err := applyManifests(ctx, e.provider, baselineManifests, newApplyOptions(e.deployCfg.Input, options.ServerSideApply), e.LogPersister); err != nil {
+		return model.StageStatus_STAGE_FAILURE
+	}
+	if err := waitForHealthyResources(ctx, e.provider, baselineManifests, e.healthRules, e.LogPersister); err != nil {
+		e.LogPersister.Errorf("Failed while waiting for the resources to be healthy (%v)", err)
 		return model.StageStatus_STAGE_FAILURE
 	}
 
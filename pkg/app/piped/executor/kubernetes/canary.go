@@ -53,6 +53,9 @@ func (e *deployExecutor) ensureCanaryRollout(ctx context.Context) model.StageSta
 	}
 	e.LogPersister.Successf("Successfully loaded %d manifests", len(manifests))
 
+	// The Helm hooks are run only by K8S_SYNC and K8S_PRIMARY_ROLLOUT stages.
+	manifests, _ = provider.SplitHelmHooks(manifests)
+
 	if len(manifests) == 0 {
 		e.LogPersister.Error("This application has no Kubernetes manifests to handle")
 		return model.StageStatus_STAGE_FAILURE
@@ -88,7 +91,11 @@ func (e *deployExecutor) ensureCanaryRollout(ctx context.Context) model.StageSta
 
 	// Start rolling out the resources for CANARY variant.
 	e.LogPersister.Info("Start rolling out CANARY variant...")
-	if err := applyManifests(ctx, e.provider, canaryManifests, e.deployCfg.Input.Namespace, e.LogPersister); err != nil {
+	if err := applyManifests(ctx, e.provider, canaryManifests, newApplyOptions(e.deployCfg.Input, options.ServerSideApply), e.LogPersister); err != nil {
+		return model.StageStatus_STAGE_FAILURE
+	}
+	if err := waitForHealthyResources(ctx, e.provider, canaryManifests, e.healthRules, e.LogPersister); err != nil {
+		e.LogPersister.Errorf("Failed while waiting for the resources to be healthy (%v)", err)
 		return model.StageStatus_STAGE_FAILURE
 	}
 
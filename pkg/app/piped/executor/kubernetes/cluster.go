@@ -0,0 +1,199 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/kubernetes"
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+const (
+	touchedCloudProvidersMetadataKey = "touched-cloud-providers"
+)
+
+type clusterProvider struct {
+	cloudProvider string
+	provider.Provider
+}
+
+// multiClusterProvider is a provider that loads manifests once
+// but applies and deletes resources on all of its clusters.
+type multiClusterProvider struct {
+	// The loader rendering manifests without connecting to any cluster.
+	loader    provider.ManifestLoader
+	providers []clusterProvider
+}
+
+func (p *multiClusterProvider) LoadManifests(ctx context.Context) ([]provider.Manifest, error) {
+	return p.loader.LoadManifests(ctx)
+}
+
+func (p *multiClusterProvider) Apply(ctx context.Context) error {
+	for _, cp := range p.providers {
+		if err := cp.Apply(ctx); err != nil {
+			return fmt.Errorf("cloud provider %s: %w", cp.cloudProvider, err)
+		}
+	}
+	return nil
+}
+
+func (p *multiClusterProvider) ApplyManifest(ctx context.Context, manifest provider.Manifest) error {
+	for _, cp := range p.providers {
+		if err := cp.ApplyManifest(ctx, manifest); err != nil {
+			return fmt.Errorf("cloud provider %s: %w", cp.cloudProvider, err)
+		}
+	}
+	return nil
+}
+
+func (p *multiClusterProvider) ServerSideApply(ctx context.Context, manifests []provider.Manifest, forceConflicts bool) error {
+	for _, cp := range p.providers {
+		if err := cp.ServerSideApply(ctx, manifests, forceConflicts); err != nil {
+			return fmt.Errorf("cloud provider %s: %w", cp.cloudProvider, err)
+		}
+	}
+	return nil
+}
+
+// Delete deletes the given resource from all clusters.
+// ErrNotFound is returned only when the resource was not found in any cluster.
+func (p *multiClusterProvider) Delete(ctx context.Context, key provider.ResourceKey) error {
+	var (
+		notFounds int
+		errs      []string
+	)
+	for _, cp := range p.providers {
+		err := cp.Delete(ctx, key)
+		if err == nil {
+			continue
+		}
+		if errors.Is(err, provider.ErrNotFound) {
+			notFounds++
+			continue
+		}
+		errs = append(errs, fmt.Sprintf("cloud provider %s: %v", cp.cloudProvider, err))
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+	if notFounds == len(p.providers) {
+		return provider.ErrNotFound
+	}
+	return nil
+}
+
+// Get always returns an error since the live manifests may differ between clusters.
+// Use forEachCluster to inspect the resource in every cluster.
+func (p *multiClusterProvider) Get(ctx context.Context, key provider.ResourceKey) (provider.Manifest, error) {
+	return provider.Manifest{}, fmt.Errorf("unable to get %s from %d clusters at once, it must be got from each cluster", key, len(p.providers))
+}
+
+// forEachCluster calls the given function with the provider of every cluster
+// the given provider is applying resources to.
+func forEachCluster(p provider.Provider, fn func(cloudProvider string, p provider.Provider) error) error {
+	mp, ok := p.(*multiClusterProvider)
+	if !ok {
+		return fn("", p)
+	}
+	for _, cp := range mp.providers {
+		if err := fn(cp.cloudProvider, cp.Provider); err != nil {
+			return fmt.Errorf("cloud provider %s: %w", cp.cloudProvider, err)
+		}
+	}
+	return nil
+}
+
+// newClusterProvider returns a provider for applying resources on the given cloud providers.
+// The factory is called with a nil configuration to build the provider used only for rendering manifests.
+func newClusterProvider(cloudProviders []string, pipedConfig *config.PipedSpec, factory func(cfg *config.CloudProviderKubernetesConfig) provider.Provider) (provider.Provider, error) {
+	providers := make([]clusterProvider, 0, len(cloudProviders))
+	for _, name := range cloudProviders {
+		cp, ok := pipedConfig.FindCloudProvider(name, model.CloudProviderKubernetes)
+		if !ok {
+			return nil, fmt.Errorf("kubernetes cloud provider %s was not found in the piped configuration", name)
+		}
+		providers = append(providers, clusterProvider{
+			cloudProvider: name,
+			Provider:      factory(cp.KubernetesConfig),
+		})
+	}
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("no cloud provider to deploy")
+	}
+	if len(providers) == 1 {
+		return providers[0].Provider, nil
+	}
+	return &multiClusterProvider{
+		loader:    factory(nil),
+		providers: providers,
+	}, nil
+}
+
+// determineDeploymentCloudProviders returns the list of cloud providers where the application is deployed.
+func determineDeploymentCloudProviders(cfg *config.KubernetesDeploymentSpec, defaultCloudProvider string) []string {
+	if len(cfg.CloudProviders) > 0 {
+		return cfg.CloudProviders
+	}
+	return []string{defaultCloudProvider}
+}
+
+// determineStageCloudProviders returns the list of cloud providers where the given stage should be executed.
+func determineStageCloudProviders(cfg *config.KubernetesDeploymentSpec, stage config.PipelineStage, defaultCloudProvider string) ([]string, error) {
+	targets := determineDeploymentCloudProviders(cfg, defaultCloudProvider)
+	names := config.K8sStageCloudProviders(stage)
+	if len(names) == 0 {
+		return targets, nil
+	}
+	for _, name := range names {
+		found := false
+		for _, t := range targets {
+			if t == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("cloud provider %s is not one of the deployment's cloud providers", name)
+		}
+	}
+	return names, nil
+}
+
+// mergeCloudProviders returns a sorted list of unique cloud providers
+// from the comma-separated list and the given names.
+func mergeCloudProviders(value string, names []string) []string {
+	set := make(map[string]struct{}, len(names))
+	for _, name := range strings.Split(value, ",") {
+		if name != "" {
+			set[name] = struct{}{}
+		}
+	}
+	for _, name := range names {
+		set[name] = struct{}{}
+	}
+	out := make([]string, 0, len(set))
+	for name := range set {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,193 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/kubernetes"
+	"github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/kubernetes/providertest"
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+func TestMultiClusterProviderDelete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	newProvider := func(err error) provider.Provider {
+		p := providertest.NewMockProvider(ctrl)
+		p.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(err)
+		return p
+	}
+
+	testcases := []struct {
+		name         string
+		errs         []error
+		wantErr      bool
+		wantNotFound bool
+	}{
+		{
+			name: "deleted from all clusters",
+			errs: []error{nil, nil},
+		},
+		{
+			name: "not found in some clusters",
+			errs: []error{provider.ErrNotFound, nil},
+		},
+		{
+			name:         "not found in all clusters",
+			errs:         []error{provider.ErrNotFound, provider.ErrNotFound},
+			wantErr:      true,
+			wantNotFound: true,
+		},
+		{
+			name:    "failed in one cluster",
+			errs:    []error{nil, errors.New("unexpected error")},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &multiClusterProvider{}
+			for _, err := range tc.errs {
+				p.providers = append(p.providers, clusterProvider{
+					cloudProvider: "cluster",
+					Provider:      newProvider(err),
+				})
+			}
+			err := p.Delete(context.Background(), provider.ResourceKey{Name: "foo"})
+			assert.Equal(t, tc.wantErr, err != nil)
+			assert.Equal(t, tc.wantNotFound, errors.Is(err, provider.ErrNotFound))
+		})
+	}
+}
+
+func TestMultiClusterProviderLoadAndGet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	manifests := []provider.Manifest{{Key: provider.ResourceKey{Name: "foo"}}}
+	loader := providertest.NewMockProvider(ctrl)
+	loader.EXPECT().LoadManifests(gomock.Any()).Return(manifests, nil)
+
+	// The providers of the clusters must be neither used to render nor to get manifests.
+	p := &multiClusterProvider{
+		loader: loader,
+		providers: []clusterProvider{
+			{cloudProvider: "cluster-1", Provider: providertest.NewMockProvider(ctrl)},
+			{cloudProvider: "cluster-2", Provider: providertest.NewMockProvider(ctrl)},
+		},
+	}
+	got, err := p.LoadManifests(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, manifests, got)
+
+	_, err = p.Get(context.Background(), provider.ResourceKey{Name: "foo"})
+	assert.Error(t, err)
+}
+
+func TestNewClusterProvider(t *testing.T) {
+	pipedConfig := &config.PipedSpec{
+		CloudProviders: []config.PipedCloudProvider{
+			{
+				Name:             "cluster-1",
+				Type:             model.CloudProviderKubernetes,
+				KubernetesConfig: &config.CloudProviderKubernetesConfig{MasterURL: "https://cluster-1"},
+			},
+			{
+				Name:             "cluster-2",
+				Type:             model.CloudProviderKubernetes,
+				KubernetesConfig: &config.CloudProviderKubernetesConfig{MasterURL: "https://cluster-2"},
+			},
+		},
+	}
+	var masterURLs []string
+	factory := func(cfg *config.CloudProviderKubernetesConfig) provider.Provider {
+		if cfg != nil {
+			masterURLs = append(masterURLs, cfg.MasterURL)
+		}
+		return nil
+	}
+
+	p, err := newClusterProvider([]string{"cluster-1", "cluster-2"}, pipedConfig, factory)
+	require.NoError(t, err)
+	assert.IsType(t, &multiClusterProvider{}, p)
+	assert.Equal(t, []string{"https://cluster-1", "https://cluster-2"}, masterURLs)
+
+	_, err = newClusterProvider([]string{"cluster-3"}, pipedConfig, factory)
+	assert.Error(t, err)
+}
+
+func TestDetermineStageCloudProviders(t *testing.T) {
+	canaryStage := func(cloudProviders ...string) config.PipelineStage {
+		return config.PipelineStage{
+			Name: model.StageK8sCanaryRollout,
+			K8sCanaryRolloutStageOptions: &config.K8sCanaryRolloutStageOptions{
+				CloudProviders: cloudProviders,
+			},
+		}
+	}
+	testcases := []struct {
+		name     string
+		cfg      *config.KubernetesDeploymentSpec
+		stage    config.PipelineStage
+		expected []string
+		wantErr  bool
+	}{
+		{
+			name:     "application cloud provider",
+			cfg:      &config.KubernetesDeploymentSpec{},
+			stage:    canaryStage(),
+			expected: []string{"default"},
+		},
+		{
+			name:     "all deployment cloud providers",
+			cfg:      &config.KubernetesDeploymentSpec{CloudProviders: []string{"cluster-1", "cluster-2"}},
+			stage:    canaryStage(),
+			expected: []string{"cluster-1", "cluster-2"},
+		},
+		{
+			name:     "stage cloud providers",
+			cfg:      &config.KubernetesDeploymentSpec{CloudProviders: []string{"cluster-1", "cluster-2"}},
+			stage:    canaryStage("cluster-2"),
+			expected: []string{"cluster-2"},
+		},
+		{
+			name:    "unknown stage cloud provider",
+			cfg:     &config.KubernetesDeploymentSpec{CloudProviders: []string{"cluster-1", "cluster-2"}},
+			stage:   canaryStage("cluster-3"),
+			wantErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := determineStageCloudProviders(tc.cfg, tc.stage, "default")
+			assert.Equal(t, tc.wantErr, err != nil)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestMergeCloudProviders(t *testing.T) {
+	assert.Equal(t, []string{"cluster-1"}, mergeCloudProviders("", []string{"cluster-1"}))
+	assert.Equal(t, []string{"cluster-1", "cluster-2"}, mergeCloudProviders("cluster-2", []string{"cluster-1", "cluster-2"}))
+}
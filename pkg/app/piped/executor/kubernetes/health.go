@@ -0,0 +1,110 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/kubernetes"
+	"github.com/pipe-cd/pipe/pkg/app/piped/executor"
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+const (
+	healthCheckInterval = 5 * time.Second
+)
+
+// makeHealthRules returns the health rules configured for each given cloud provider.
+// The rules of a single cloud provider are also registered with an empty name
+// because forEachCluster does not give a name when there is only one cluster.
+func makeHealthRules(cloudProviders []string, pipedConfig *config.PipedSpec) map[string][]config.KubernetesHealthRule {
+	rules := make(map[string][]config.KubernetesHealthRule, len(cloudProviders))
+	for _, name := range cloudProviders {
+		cp, ok := pipedConfig.FindCloudProvider(name, model.CloudProviderKubernetes)
+		if !ok || cp.KubernetesConfig == nil {
+			continue
+		}
+		rules[name] = cp.KubernetesConfig.HealthRules
+	}
+	if len(cloudProviders) == 1 {
+		rules[""] = rules[cloudProviders[0]]
+	}
+	return rules
+}
+
+// waitForHealthyResources waits until all given resources having a configured health rule
+// become healthy on every cluster of the given provider.
+// It fails as soon as one of them was determined as unhealthy.
+func waitForHealthyResources(ctx context.Context, p provider.Provider, manifests []provider.Manifest, rules map[string][]config.KubernetesHealthRule, lp executor.LogPersister) error {
+	return forEachCluster(p, func(cloudProvider string, p provider.Provider) error {
+		clusterRules := rules[cloudProvider]
+		keys := make([]provider.ResourceKey, 0, len(manifests))
+		for _, m := range manifests {
+			if _, ok := provider.FindHealthRule(clusterRules, m.Key); ok {
+				keys = append(keys, m.Key)
+			}
+		}
+		if len(keys) == 0 {
+			return nil
+		}
+
+		lp.Infof("Waiting for %d resources to be healthy based on the configured health rules", len(keys))
+		for _, k := range keys {
+			if err := waitForHealthyResource(ctx, p, k, clusterRules, lp); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func waitForHealthyResource(ctx context.Context, p provider.Provider, key provider.ResourceKey, rules []config.KubernetesHealthRule, lp executor.LogPersister) error {
+	ticker := time.NewTicker(healthCheckInterval)
+	defer ticker.Stop()
+
+	var last string
+	for {
+		m, err := p.Get(ctx, key)
+		if err != nil {
+			return fmt.Errorf("unable to get resource %s: %w", key.ReadableString(), err)
+		}
+		// The status is not evaluated until the controller has observed the applied spec
+		// to avoid deciding the health from the status of the previous generation.
+		status, desc := config.KubernetesHealthStatusProgressing, "waiting for the controller to observe the latest generation"
+		if provider.IsLatestGenerationObserved(m) {
+			status, desc, _ = provider.DetermineHealthByRules(rules, m)
+		}
+		switch status {
+		case config.KubernetesHealthStatusHealthy:
+			lp.Successf("- %s is healthy", key.ReadableString())
+			return nil
+		case config.KubernetesHealthStatusUnhealthy:
+			return fmt.Errorf("resource %s is unhealthy: %s", key.ReadableString(), desc)
+		}
+		if desc != last {
+			lp.Infof("- %s is progressing: %s", key.ReadableString(), desc)
+			last = desc
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("resource %s did not become healthy: %w", key.ReadableString(), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
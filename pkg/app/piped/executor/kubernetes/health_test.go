@@ -0,0 +1,123 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/kubernetes"
+	"github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/kubernetes/providertest"
+	"github.com/pipe-cd/pipe/pkg/config"
+)
+
+func TestWaitForHealthyResources(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	manifests, err := provider.ParseManifests(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+---
+apiVersion: example.com/v1
+kind: Database
+metadata:
+  name: db
+`)
+	require.NoError(t, err)
+
+	rules := map[string][]config.KubernetesHealthRule{
+		"": {
+			{
+				APIVersion: "example.com/v1",
+				Kind:       "Database",
+				Checks: []config.KubernetesHealthCheck{
+					{
+						JSONPath: "{.status.phase}",
+						Value:    "Ready",
+						Status:   config.KubernetesHealthStatusHealthy,
+					},
+					{
+						JSONPath: "{.status.phase}",
+						Value:    "Failed",
+						Status:   config.KubernetesHealthStatusUnhealthy,
+					},
+				},
+			},
+		},
+	}
+
+	testcases := []struct {
+		name      string
+		live      string
+		expectErr bool
+	}{
+		{
+			name: "healthy",
+			live: `
+apiVersion: example.com/v1
+kind: Database
+metadata:
+  name: db
+status:
+  phase: Ready
+`,
+		},
+		{
+			name: "healthy after observing the latest generation",
+			live: `
+apiVersion: example.com/v1
+kind: Database
+metadata:
+  name: db
+  generation: 2
+status:
+  observedGeneration: 2
+  phase: Ready
+`,
+		},
+		{
+			name: "unhealthy",
+			live: `
+apiVersion: example.com/v1
+kind: Database
+metadata:
+  name: db
+status:
+  phase: Failed
+`,
+			expectErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			live, err := provider.ParseManifests(tc.live)
+			require.NoError(t, err)
+
+			// Only the resource having a health rule is checked.
+			p := providertest.NewMockProvider(ctrl)
+			p.EXPECT().Get(gomock.Any(), manifests[1].Key).Return(live[0], nil)
+
+			err = waitForHealthyResources(context.Background(), p, manifests, rules, &fakeLogPersister{})
+			assert.Equal(t, tc.expectErr, err != nil)
+		})
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/kubernetes"
+	"github.com/pipe-cd/pipe/pkg/app/piped/executor"
+	"github.com/pipe-cd/pipe/pkg/config"
+)
+
+const (
+	helmHookCheckInterval = 5 * time.Second
+)
+
+// helmHookTimeout returns how long to wait for each hook.
+// The timeout of the stage is used when it is not configured in the helm options.
+// Zero means the hooks are waited for until the stage is stopped.
+func helmHookTimeout(input config.KubernetesDeploymentInput, stageTimeout config.Duration) time.Duration {
+	if o := input.HelmOptions; o != nil && o.HookTimeout > 0 {
+		return o.HookTimeout.Duration()
+	}
+	return stageTimeout.Duration()
+}
+
+// runHelmHooks runs the given hooks one by one on every cluster of the given provider.
+// The next hook is started only after the previous one has been completed.
+// The hooks are applied in the same way as the resources of the stage.
+func runHelmHooks(ctx context.Context, p provider.Provider, phase string, hooks []provider.Manifest, opts applyOptions, timeout time.Duration, lp executor.LogPersister) error {
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	lp.Infof("Start running %d %s hooks", len(hooks), phase)
+	err := forEachCluster(p, func(cloudProvider string, p provider.Provider) error {
+		if cloudProvider != "" {
+			lp.Infof("Running %s hooks on cloud provider %s", phase, cloudProvider)
+		}
+		for _, h := range hooks {
+			if err := runHelmHook(ctx, p, h, opts, timeout, lp); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		lp.Errorf("Failed while running %s hooks (%v)", phase, err)
+		return err
+	}
+
+	lp.Successf("Successfully ran %d %s hooks", len(hooks), phase)
+	return nil
+}
+
+func runHelmHook(ctx context.Context, p provider.Provider, hook provider.Manifest, opts applyOptions, timeout time.Duration, lp executor.LogPersister) error {
+	policies := provider.HelmHookDeletePolicies(hook)
+
+	if _, ok := policies[provider.HelmHookDeletePolicyBeforeHookCreation]; ok {
+		if err := p.Delete(ctx, hook.Key); err != nil && !errors.Is(err, provider.ErrNotFound) {
+			return fmt.Errorf("unable to delete the previous hook %s: %w", hook.Key.ReadableString(), err)
+		}
+	}
+
+	var err error
+	if opts.serverSide {
+		err = p.ServerSideApply(ctx, []provider.Manifest{hook}, opts.forceConflicts)
+	} else {
+		err = p.ApplyManifest(ctx, hook)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to apply hook %s: %w", hook.Key.ReadableString(), err)
+	}
+	lp.Infof("- applied hook: %s", hook.Key.ReadableString())
+
+	err = waitHelmHook(ctx, p, hook.Key, timeout)
+
+	policy := provider.HelmHookDeletePolicyHookSucceeded
+	if err != nil {
+		policy = provider.HelmHookDeletePolicyHookFailed
+	}
+	if _, ok := policies[policy]; ok {
+		if derr := p.Delete(ctx, hook.Key); derr != nil && !errors.Is(derr, provider.ErrNotFound) {
+			lp.Errorf("- unable to delete hook %s (%v)", hook.Key.ReadableString(), derr)
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("hook %s failed: %w", hook.Key.ReadableString(), err)
+	}
+	lp.Successf("- completed hook: %s", hook.Key.ReadableString())
+	return nil
+}
+
+// waitHelmHook waits until the given hook has been completed or the timeout has passed.
+// As well as Helm, only Jobs and Pods are waited for,
+// the other resources are considered completed once they were applied.
+func waitHelmHook(ctx context.Context, p provider.Provider, key provider.ResourceKey, timeout time.Duration) error {
+	if key.Kind != provider.KindJob && key.Kind != provider.KindPod {
+		return nil
+	}
+
+	wctx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		wctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	timedOut := func() bool {
+		return ctx.Err() == nil && errors.Is(wctx.Err(), context.DeadlineExceeded)
+	}
+
+	ticker := time.NewTicker(helmHookCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		m, err := p.Get(wctx, key)
+		if err != nil {
+			if timedOut() {
+				return fmt.Errorf("hook timed out after %v", timeout)
+			}
+			return err
+		}
+		done, err := helmHookCompleted(m)
+		if done || err != nil {
+			return err
+		}
+
+		select {
+		case <-wctx.Done():
+			if timedOut() {
+				return fmt.Errorf("hook timed out after %v", timeout)
+			}
+			return wctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// helmHookCompleted returns true if the given Job or Pod has finished.
+// A non-nil error is returned together when it was failed.
+func helmHookCompleted(m provider.Manifest) (bool, error) {
+	switch m.Key.Kind {
+	case provider.KindJob:
+		var job batchv1.Job
+		if err := m.ConvertToStructuredObject(&job); err != nil {
+			return false, err
+		}
+		for _, c := range job.Status.Conditions {
+			if c.Status != corev1.ConditionTrue {
+				continue
+			}
+			switch c.Type {
+			case batchv1.JobComplete:
+				return true, nil
+			case batchv1.JobFailed:
+				return true, fmt.Errorf("job failed: %s", c.Message)
+			}
+		}
+		return false, nil
+
+	case provider.KindPod:
+		var pod corev1.Pod
+		if err := m.ConvertToStructuredObject(&pod); err != nil {
+			return false, err
+		}
+		switch pod.Status.Phase {
+		case corev1.PodSucceeded:
+			return true, nil
+		case corev1.PodFailed:
+			return true, fmt.Errorf("pod failed: %s", pod.Status.Message)
+		}
+		return false, nil
+	}
+	return true, nil
+}
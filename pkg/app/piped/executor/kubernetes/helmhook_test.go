@@ -0,0 +1,240 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/kubernetes"
+	"github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/kubernetes/providertest"
+	"github.com/pipe-cd/pipe/pkg/config"
+)
+
+func TestRunHelmHooks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	hooks, err := provider.ParseManifests(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: migrate-config
+  annotations:
+    helm.sh/hook: pre-install
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+  annotations:
+    helm.sh/hook: pre-install
+    helm.sh/hook-delete-policy: hook-succeeded
+`)
+	require.NoError(t, err)
+
+	completedJob, err := provider.ParseManifests(`
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+status:
+  conditions:
+  - type: Complete
+    status: "True"
+`)
+	require.NoError(t, err)
+
+	var (
+		configKey = hooks[0].Key
+		jobKey    = hooks[1].Key
+		p         = providertest.NewMockProvider(ctrl)
+	)
+	gomock.InOrder(
+		// The config map is recreated and considered completed once it was applied.
+		p.EXPECT().Delete(gomock.Any(), configKey).Return(provider.ErrNotFound),
+		p.EXPECT().ApplyManifest(gomock.Any(), hooks[0]).Return(nil),
+		// The job is deleted after its completion.
+		p.EXPECT().ApplyManifest(gomock.Any(), hooks[1]).Return(nil),
+		p.EXPECT().Get(gomock.Any(), jobKey).Return(completedJob[0], nil),
+		p.EXPECT().Delete(gomock.Any(), jobKey).Return(nil),
+	)
+
+	err = runHelmHooks(context.Background(), p, "pre-sync", hooks, applyOptions{}, 0, &fakeLogPersister{})
+	assert.NoError(t, err)
+
+	// The hooks are applied by using server-side apply when the stage uses it.
+	p = providertest.NewMockProvider(ctrl)
+	gomock.InOrder(
+		p.EXPECT().Delete(gomock.Any(), configKey).Return(provider.ErrNotFound),
+		p.EXPECT().ServerSideApply(gomock.Any(), hooks[:1], true).Return(nil),
+		p.EXPECT().ServerSideApply(gomock.Any(), hooks[1:], true).Return(nil),
+		p.EXPECT().Get(gomock.Any(), jobKey).Return(completedJob[0], nil),
+		p.EXPECT().Delete(gomock.Any(), jobKey).Return(nil),
+	)
+	err = runHelmHooks(context.Background(), p, "pre-sync", hooks, applyOptions{serverSide: true, forceConflicts: true}, 0, &fakeLogPersister{})
+	assert.NoError(t, err)
+}
+
+func TestRunHelmHooksTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	hooks, err := provider.ParseManifests(`
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+  annotations:
+    helm.sh/hook: pre-install
+    helm.sh/hook-delete-policy: hook-failed
+`)
+	require.NoError(t, err)
+
+	runningJob, err := provider.ParseManifests(`
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+status:
+  active: 1
+`)
+	require.NoError(t, err)
+
+	var (
+		jobKey = hooks[0].Key
+		p      = providertest.NewMockProvider(ctrl)
+	)
+	gomock.InOrder(
+		p.EXPECT().ApplyManifest(gomock.Any(), hooks[0]).Return(nil),
+		p.EXPECT().Get(gomock.Any(), jobKey).Return(runningJob[0], nil),
+		// The job is deleted after it timed out.
+		p.EXPECT().Delete(gomock.Any(), jobKey).Return(nil),
+	)
+
+	err = runHelmHooks(context.Background(), p, "pre-sync", hooks, applyOptions{}, 10*time.Millisecond, &fakeLogPersister{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "hook timed out after 10ms")
+}
+
+func TestHelmHookTimeout(t *testing.T) {
+	testcases := []struct {
+		name         string
+		input        config.KubernetesDeploymentInput
+		stageTimeout config.Duration
+		expected     time.Duration
+	}{
+		{
+			name:     "no timeout",
+			expected: 0,
+		},
+		{
+			name:         "stage timeout",
+			stageTimeout: config.Duration(10 * time.Minute),
+			expected:     10 * time.Minute,
+		},
+		{
+			name: "configured hook timeout",
+			input: config.KubernetesDeploymentInput{
+				HelmOptions: &config.InputHelmOptions{
+					HookTimeout: config.Duration(time.Minute),
+				},
+			},
+			stageTimeout: config.Duration(10 * time.Minute),
+			expected:     time.Minute,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := helmHookTimeout(tc.input, tc.stageTimeout)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestHelmHookCompleted(t *testing.T) {
+	testcases := []struct {
+		name     string
+		manifest string
+		done     bool
+		wantErr  bool
+	}{
+		{
+			name: "running job",
+			manifest: `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: job
+status:
+  active: 1
+`,
+		},
+		{
+			name: "failed job",
+			manifest: `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: job
+status:
+  conditions:
+  - type: Failed
+    status: "True"
+    message: BackoffLimitExceeded
+`,
+			done:    true,
+			wantErr: true,
+		},
+		{
+			name: "succeeded pod",
+			manifest: `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: pod
+status:
+  phase: Succeeded
+`,
+			done: true,
+		},
+		{
+			name: "service account",
+			manifest: `
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: sa
+`,
+			done: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			manifests, err := provider.ParseManifests(tc.manifest)
+			require.NoError(t, err)
+			require.Equal(t, 1, len(manifests))
+
+			done, err := helmHookCompleted(manifests[0])
+			assert.Equal(t, tc.done, done)
+			assert.Equal(t, tc.wantErr, err != nil)
+		})
+	}
+}
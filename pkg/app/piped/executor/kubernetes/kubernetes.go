@@ -39,9 +39,11 @@ const (
 type deployExecutor struct {
 	executor.Input
 
-	commit    string
-	deployCfg *config.KubernetesDeploymentSpec
-	provider  provider.Provider
+	commit         string
+	deployCfg      *config.KubernetesDeploymentSpec
+	cloudProviders []string
+	provider       provider.Provider
+	healthRules    map[string][]config.KubernetesHealthRule
 }
 
 type registerer interface {
@@ -88,10 +90,39 @@ func (e *deployExecutor) Execute(sig executor.StopSignal) model.StageStatus {
 		return model.StageStatus_STAGE_FAILURE
 	}
 
-	e.provider = provider.NewProvider(e.Deployment.ApplicationName, ds.AppDir, ds.RepoDir, e.Deployment.GitPath.ConfigFilename, e.deployCfg.Input, e.Logger)
+	cloudProviders, err := determineStageCloudProviders(e.deployCfg, e.StageConfig, e.Deployment.CloudProvider)
+	if err != nil {
+		e.LogPersister.Errorf("Unable to determine the cloud providers to execute this stage (%v)", err)
+		return model.StageStatus_STAGE_FAILURE
+	}
+	e.cloudProviders = cloudProviders
+	e.provider, err = newClusterProvider(cloudProviders, e.PipedConfig, func(cfg *config.CloudProviderKubernetesConfig) provider.Provider {
+		return provider.NewProvider(e.Deployment.ApplicationName, ds.AppDir, ds.RepoDir, e.Deployment.GitPath.ConfigFilename, e.deployCfg.Input, e.Logger, provider.WithClusterConfig(cfg))
+	})
+	if err != nil {
+		e.LogPersister.Errorf("Unable to prepare kubernetes provider (%v)", err)
+		return model.StageStatus_STAGE_FAILURE
+	}
+	e.healthRules = makeHealthRules(cloudProviders, e.PipedConfig)
+	if len(e.deployCfg.CloudProviders) > 0 {
+		e.LogPersister.Infof("This stage will be executed on %d cloud providers: %s", len(cloudProviders), strings.Join(cloudProviders, ", "))
+	}
+
+	// Remember all clusters touched by this deployment
+	// to be able to roll them back when the deployment failed.
+	value, _ := e.MetadataStore.Get(touchedCloudProvidersMetadataKey)
+	touched := strings.Join(mergeCloudProviders(value, cloudProviders), ",")
+	if touched != value {
+		if err := e.MetadataStore.Set(ctx, touchedCloudProvidersMetadataKey, touched); err != nil {
+			e.LogPersister.Errorf("Unable to save the touched cloud providers to the deployment metadata (%v)", err)
+			return model.StageStatus_STAGE_FAILURE
+		}
+	}
+
 	e.Logger.Info("start executing kubernetes stage",
 		zap.String("stage-name", e.Stage.Name),
 		zap.String("app-dir", ds.AppDir),
+		zap.Strings("cloud-providers", cloudProviders),
 	)
 
 	var (
@@ -200,11 +231,31 @@ func addBuiltinAnnontations(manifests []provider.Manifest, variant, hash, pipedI
 	}
 }
 
-func applyManifests(ctx context.Context, applier provider.Applier, manifests []provider.Manifest, namespace string, lp executor.LogPersister) error {
-	if namespace == "" {
+// applyOptions represents how manifests should be applied.
+type applyOptions struct {
+	namespace      string
+	serverSide     bool
+	forceConflicts bool
+}
+
+// newApplyOptions returns the options for applying manifests.
+// Server-side apply is used when it is enabled in either the input or the stage.
+func newApplyOptions(input config.KubernetesDeploymentInput, stageServerSideApply bool) applyOptions {
+	return applyOptions{
+		namespace:      input.Namespace,
+		serverSide:     input.ServerSideApply || stageServerSideApply,
+		forceConflicts: input.ForceConflicts,
+	}
+}
+
+func applyManifests(ctx context.Context, applier provider.Applier, manifests []provider.Manifest, opts applyOptions, lp executor.LogPersister) error {
+	if opts.namespace == "" {
 		lp.Infof("Start applying %d manifests", len(manifests))
 	} else {
-		lp.Infof("Start applying %d manifests to %q namespace", len(manifests), namespace)
+		lp.Infof("Start applying %d manifests to %q namespace", len(manifests), opts.namespace)
+	}
+	if opts.serverSide {
+		return serverSideApplyManifests(ctx, applier, manifests, opts.forceConflicts, lp)
 	}
 	for _, m := range manifests {
 		if err := applier.ApplyManifest(ctx, m); err != nil {
@@ -217,6 +268,32 @@ func applyManifests(ctx context.Context, applier provider.Applier, manifests []p
 	return nil
 }
 
+func serverSideApplyManifests(ctx context.Context, applier provider.Applier, manifests []provider.Manifest, forceConflicts bool, lp executor.LogPersister) error {
+	if forceConflicts {
+		lp.Infof("Using server-side apply with field manager %q and forcing conflicts", provider.FieldManager)
+	} else {
+		lp.Infof("Using server-side apply with field manager %q", provider.FieldManager)
+	}
+	if err := applier.ServerSideApply(ctx, manifests, forceConflicts); err != nil {
+		var conflictErr *provider.ApplyConflictError
+		if !errors.As(err, &conflictErr) {
+			lp.Errorf("Failed to apply manifests (%v)", err)
+			return err
+		}
+		lp.Errorf("Failed to apply manifests because %d field(s) are managed by other field managers:", len(conflictErr.Conflicts))
+		for _, c := range conflictErr.Conflicts {
+			lp.Errorf("- %s", c)
+		}
+		lp.Info("Remove the conflicted fields from the manifests or set forceConflicts to true to take over their ownership")
+		return err
+	}
+	for _, m := range manifests {
+		lp.Successf("- applied manifest: %s", m.Key.ReadableString())
+	}
+	lp.Successf("Successfully applied %d manifests", len(manifests))
+	return nil
+}
+
 func deleteResources(ctx context.Context, applier provider.Applier, resources []provider.ResourceKey, lp executor.LogPersister) error {
 	resourcesLen := len(resources)
 	if resourcesLen == 0 {
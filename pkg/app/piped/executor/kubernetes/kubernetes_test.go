@@ -323,3 +323,61 @@ func TestDeleteResources(t *testing.T) {
 		})
 	}
 }
+
+func TestApplyManifests(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	manifests := []provider.Manifest{{}, {}}
+	testcases := []struct {
+		name     string
+		provider provider.Provider
+		opts     applyOptions
+		wantErr  bool
+	}{
+		{
+			name: "client-side apply",
+			provider: func() provider.Provider {
+				p := providertest.NewMockProvider(ctrl)
+				p.EXPECT().ApplyManifest(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+				return p
+			}(),
+		},
+		{
+			name: "server-side apply",
+			opts: applyOptions{serverSide: true},
+			provider: func() provider.Provider {
+				p := providertest.NewMockProvider(ctrl)
+				p.EXPECT().ServerSideApply(gomock.Any(), manifests, false).Return(nil)
+				return p
+			}(),
+		},
+		{
+			name: "server-side apply with conflicts",
+			opts: applyOptions{serverSide: true},
+			provider: func() provider.Provider {
+				p := providertest.NewMockProvider(ctrl)
+				p.EXPECT().ServerSideApply(gomock.Any(), manifests, false).Return(&provider.ApplyConflictError{
+					Conflicts: []string{`conflict with "kube-controller-manager" using apps/v1: .spec.replicas`},
+				})
+				return p
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "server-side apply with forcing conflicts",
+			opts: applyOptions{serverSide: true, forceConflicts: true},
+			provider: func() provider.Provider {
+				p := providertest.NewMockProvider(ctrl)
+				p.EXPECT().ServerSideApply(gomock.Any(), manifests, true).Return(nil)
+				return p
+			}(),
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := applyManifests(context.Background(), tc.provider, manifests, tc.opts, &fakeLogPersister{})
+			assert.Equal(t, tc.wantErr, err != nil)
+		})
+	}
+}
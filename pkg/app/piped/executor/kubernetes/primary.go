@@ -51,6 +51,10 @@ func (e *deployExecutor) ensurePrimaryRollout(ctx context.Context) model.StageSt
 	}
 	e.LogPersister.Successf("Successfully loaded %d manifests", len(manifests))
 
+	// The Helm hooks are not a part of the application resources.
+	// They are run before and after rolling out the PRIMARY variant.
+	manifests, hooks := provider.SplitHelmHooks(manifests)
+
 	routingMethod := config.DetermineKubernetesTrafficRoutingMethod(e.deployCfg.TrafficRouting)
 	var primaryManifests []provider.Manifest
 	if routingMethod == config.KubernetesTrafficRoutingMethodPodSelector {
@@ -107,13 +111,27 @@ func (e *deployExecutor) ensurePrimaryRollout(ctx context.Context) model.StageSt
 		e.Deployment.ApplicationId,
 	)
 
+	applyOpts := newApplyOptions(e.deployCfg.Input, options.ServerSideApply)
+	hookTimeout := helmHookTimeout(e.deployCfg.Input, e.StageConfig.Timeout)
+	if err := runHelmHooks(ctx, e.provider, "pre-sync", hooks.PreSync, applyOpts, hookTimeout, e.LogPersister); err != nil {
+		return model.StageStatus_STAGE_FAILURE
+	}
+
 	// Start applying all manifests to add or update running resources.
 	e.LogPersister.Info("Start rolling out PRIMARY variant...")
-	if err := applyManifests(ctx, e.provider, primaryManifests, e.deployCfg.Input.Namespace, e.LogPersister); err != nil {
+	if err := applyManifests(ctx, e.provider, primaryManifests, applyOpts, e.LogPersister); err != nil {
+		return model.StageStatus_STAGE_FAILURE
+	}
+	if err := waitForHealthyResources(ctx, e.provider, primaryManifests, e.healthRules, e.LogPersister); err != nil {
+		e.LogPersister.Errorf("Failed while waiting for the resources to be healthy (%v)", err)
 		return model.StageStatus_STAGE_FAILURE
 	}
 	e.LogPersister.Success("Successfully rolled out PRIMARY variant")
 
+	if err := runHelmHooks(ctx, e.provider, "post-sync", hooks.PostSync, applyOpts, hookTimeout, e.LogPersister); err != nil {
+		return model.StageStatus_STAGE_FAILURE
+	}
+
 	if !options.Prune {
 		e.LogPersister.Info("Resource GC was skipped because sync.prune was not configured")
 		return model.StageStatus_STAGE_SUCCESS
@@ -22,6 +22,7 @@ import (
 
 	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/kubernetes"
 	"github.com/pipe-cd/pipe/pkg/app/piped/executor"
+	"github.com/pipe-cd/pipe/pkg/config"
 	"github.com/pipe-cd/pipe/pkg/model"
 )
 
@@ -67,10 +68,27 @@ func (e *rollbackExecutor) ensureRollback(ctx context.Context) model.StageStatus
 		return model.StageStatus_STAGE_FAILURE
 	}
 
-	p := provider.NewProvider(e.Deployment.ApplicationName, ds.AppDir, ds.RepoDir, e.Deployment.GitPath.ConfigFilename, deployCfg.Input, e.Logger)
+	// Rollback all clusters touched by the deployment.
+	// When no stage has been executed, the clusters of the running deployment are used.
+	cloudProviders := determineDeploymentCloudProviders(deployCfg, e.Deployment.CloudProvider)
+	if value, ok := e.MetadataStore.Get(touchedCloudProvidersMetadataKey); ok && value != "" {
+		cloudProviders = strings.Split(value, ",")
+	}
+	p, err := newClusterProvider(cloudProviders, e.PipedConfig, func(cfg *config.CloudProviderKubernetesConfig) provider.Provider {
+		return provider.NewProvider(e.Deployment.ApplicationName, ds.AppDir, ds.RepoDir, e.Deployment.GitPath.ConfigFilename, deployCfg.Input, e.Logger, provider.WithClusterConfig(cfg))
+	})
+	if err != nil {
+		e.LogPersister.Errorf("Unable to prepare kubernetes provider (%v)", err)
+		return model.StageStatus_STAGE_FAILURE
+	}
+	if len(cloudProviders) > 1 {
+		e.LogPersister.Infof("Rolling back on %d cloud providers: %s", len(cloudProviders), strings.Join(cloudProviders, ", "))
+	}
+
 	e.Logger.Info("start executing kubernetes stage",
 		zap.String("stage-name", e.Stage.Name),
 		zap.String("app-dir", ds.AppDir),
+		zap.Strings("cloud-providers", cloudProviders),
 	)
 
 	// Firstly, we reapply all manifests at running commit
@@ -85,6 +103,9 @@ func (e *rollbackExecutor) ensureRollback(ctx context.Context) model.StageStatus
 	}
 	e.LogPersister.Successf("Successfully loaded %d manifests", len(manifests))
 
+	// The Helm hooks are not run while rolling back.
+	manifests, _ = provider.SplitHelmHooks(manifests)
+
 	// Because the loaded maninests are read-only
 	// we duplicate them to avoid updating the shared manifests data in cache.
 	manifests = duplicateManifests(manifests, "")
@@ -111,7 +132,7 @@ func (e *rollbackExecutor) ensureRollback(ctx context.Context) model.StageStatus
 	)
 
 	// Start applying all manifests to add or update running resources.
-	if err := applyManifests(ctx, p, manifests, deployCfg.Input.Namespace, e.LogPersister); err != nil {
+	if err := applyManifests(ctx, p, manifests, newApplyOptions(deployCfg.Input, deployCfg.PrimaryServerSideApply()), e.LogPersister); err != nil {
 		return model.StageStatus_STAGE_FAILURE
 	}
 
@@ -43,6 +43,15 @@ func (e *deployExecutor) ensureSync(ctx context.Context) model.StageStatus {
 	// we duplicate them to avoid updating the shared manifests data in cache.
 	manifests = duplicateManifests(manifests, "")
 
+	// The Helm hooks are not a part of the application resources.
+	// They are run before and after applying the resources.
+	manifests, hooks := provider.SplitHelmHooks(manifests)
+	applyOpts := newApplyOptions(e.deployCfg.Input, e.deployCfg.QuickSync.ServerSideApply)
+	hookTimeout := helmHookTimeout(e.deployCfg.Input, e.StageConfig.Timeout)
+	if err := runHelmHooks(ctx, e.provider, "pre-sync", hooks.PreSync, applyOpts, hookTimeout, e.LogPersister); err != nil {
+		return model.StageStatus_STAGE_FAILURE
+	}
+
 	// When addVariantLabelToSelector is true, ensure that all workloads
 	// have the variant label in their selector.
 	if e.deployCfg.QuickSync.AddVariantLabelToSelector {
@@ -65,7 +74,15 @@ func (e *deployExecutor) ensureSync(ctx context.Context) model.StageStatus {
 	)
 
 	// Start applying all manifests to add or update running resources.
-	if err := applyManifests(ctx, e.provider, manifests, e.deployCfg.Input.Namespace, e.LogPersister); err != nil {
+	if err := applyManifests(ctx, e.provider, manifests, applyOpts, e.LogPersister); err != nil {
+		return model.StageStatus_STAGE_FAILURE
+	}
+	if err := waitForHealthyResources(ctx, e.provider, manifests, e.healthRules, e.LogPersister); err != nil {
+		e.LogPersister.Errorf("Failed while waiting for the resources to be healthy (%v)", err)
+		return model.StageStatus_STAGE_FAILURE
+	}
+
+	if err := runHelmHooks(ctx, e.provider, "post-sync", hooks.PostSync, applyOpts, hookTimeout, e.LogPersister); err != nil {
 		return model.StageStatus_STAGE_FAILURE
 	}
 
@@ -86,22 +103,29 @@ func (e *deployExecutor) ensureSync(ctx context.Context) model.StageStatus {
 	}
 
 	// Find the running resources that are not defined in Git for removing.
+	// The live resources are listed and deleted on each cluster separately.
 	e.LogPersister.Info("Start finding all running resources but no longer defined in Git")
-	liveResources, ok := e.AppLiveResourceLister.ListKubernetesResources()
-	if !ok {
-		e.LogPersister.Info("There is no data about live resource so no resource will be removed")
-		return model.StageStatus_STAGE_SUCCESS
-	}
+	err = forEachCluster(e.provider, func(cloudProvider string, p provider.Provider) error {
+		if cloudProvider == "" {
+			cloudProvider = e.cloudProviders[0]
+		}
+		liveResources, ok := e.AppLiveResourceLister.ListKubernetesResources(cloudProvider)
+		if !ok {
+			e.LogPersister.Infof("There is no data about live resource in cloud provider %s so no resource will be removed from it", cloudProvider)
+			return nil
+		}
 
-	removeKeys := findRemoveResources(manifests, liveResources)
-	if len(removeKeys) == 0 {
-		e.LogPersister.Info("There are no live resources should be removed")
-		return model.StageStatus_STAGE_SUCCESS
-	}
-	e.LogPersister.Infof("Found %d live resources that are no longer defined in Git", len(removeKeys))
+		removeKeys := findRemoveResources(manifests, liveResources)
+		if len(removeKeys) == 0 {
+			e.LogPersister.Infof("There are no live resources should be removed from cloud provider %s", cloudProvider)
+			return nil
+		}
+		e.LogPersister.Infof("Found %d live resources that are no longer defined in Git in cloud provider %s", len(removeKeys), cloudProvider)
 
-	// Start deleting all running resources that are not defined in Git.
-	if err := deleteResources(ctx, e.provider, removeKeys, e.LogPersister); err != nil {
+		// Start deleting all running resources that are not defined in Git.
+		return deleteResources(ctx, p, removeKeys, e.LogPersister)
+	})
+	if err != nil {
 		return model.StageStatus_STAGE_FAILURE
 	}
 
@@ -63,6 +63,9 @@ func (e *deployExecutor) ensureTrafficRouting(ctx context.Context) model.StageSt
 	}
 	e.LogPersister.Successf("Successfully loaded %d manifests", len(manifests))
 
+	// The Helm hooks are run only by K8S_SYNC and K8S_PRIMARY_ROLLOUT stages.
+	manifests, _ = provider.SplitHelmHooks(manifests)
+
 	if len(manifests) == 0 {
 		e.LogPersister.Error("There are no kubernetes manifests to handle")
 		return model.StageStatus_STAGE_FAILURE
@@ -132,7 +135,7 @@ func (e *deployExecutor) ensureTrafficRouting(ctx context.Context) model.StageSt
 		canaryPercent,
 		baselinePercent,
 	)
-	if err := applyManifests(ctx, e.provider, []provider.Manifest{trafficRoutingManifest}, e.deployCfg.Input.Namespace, e.LogPersister); err != nil {
+	if err := applyManifests(ctx, e.provider, []provider.Manifest{trafficRoutingManifest}, newApplyOptions(e.deployCfg.Input, e.deployCfg.PrimaryServerSideApply()), e.LogPersister); err != nil {
 		return model.StageStatus_STAGE_FAILURE
 	}
 
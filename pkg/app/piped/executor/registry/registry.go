@@ -21,6 +21,7 @@ import (
 	"github.com/pipe-cd/pipe/pkg/app/piped/executor"
 	"github.com/pipe-cd/pipe/pkg/app/piped/executor/analysis"
 	"github.com/pipe-cd/pipe/pkg/app/piped/executor/cloudrun"
+	"github.com/pipe-cd/pipe/pkg/app/piped/executor/hook"
 	"github.com/pipe-cd/pipe/pkg/app/piped/executor/kubernetes"
 	"github.com/pipe-cd/pipe/pkg/app/piped/executor/lambda"
 	"github.com/pipe-cd/pipe/pkg/app/piped/executor/terraform"
@@ -97,6 +98,7 @@ func DefaultRegistry() Registry {
 func init() {
 	analysis.Register(defaultRegistry)
 	cloudrun.Register(defaultRegistry)
+	hook.Register(defaultRegistry)
 	kubernetes.Register(defaultRegistry)
 	lambda.Register(defaultRegistry)
 	terraform.Register(defaultRegistry)
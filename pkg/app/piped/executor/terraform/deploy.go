@@ -16,6 +16,10 @@ package terraform
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
 
 	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/terraform"
 	"github.com/pipe-cd/pipe/pkg/app/piped/executor"
@@ -23,6 +27,14 @@ import (
 	"github.com/pipe-cd/pipe/pkg/model"
 )
 
+const (
+	// The name of the file to which the plan is saved in the application directory.
+	planFileName = ".pipecd.tfplan"
+	// The key of the stage metadata holding the name of the artifact
+	// where the plan of a TERRAFORM_PLAN stage was saved.
+	planArtifactMetadataKey = "terraform-plan-artifact"
+)
+
 type deployExecutor struct {
 	executor.Input
 
@@ -142,12 +154,17 @@ func (e *deployExecutor) ensurePlan(ctx context.Context) model.StageStatus {
 		return model.StageStatus_STAGE_FAILURE
 	}
 
-	planResult, err := cmd.Plan(ctx, e.LogPersister)
+	planResult, err := cmd.SavePlan(ctx, e.LogPersister, planFileName)
 	if err != nil {
 		e.LogPersister.Errorf("Failed to plan (%v)", err)
 		return model.StageStatus_STAGE_FAILURE
 	}
 
+	// Save the plan so that the TERRAFORM_APPLY stage can apply exactly what was planned here.
+	if ok := e.savePlan(ctx); !ok {
+		return model.StageStatus_STAGE_FAILURE
+	}
+
 	if planResult.NoChanges() {
 		e.LogPersister.Success("No changes to apply")
 		return model.StageStatus_STAGE_SUCCESS
@@ -173,7 +190,28 @@ func (e *deployExecutor) ensureApply(ctx context.Context) model.StageStatus {
 		return model.StageStatus_STAGE_FAILURE
 	}
 
-	if err := cmd.Apply(ctx, e.LogPersister); err != nil {
+	planStage, ok := e.findPlanStage()
+	if !ok {
+		e.LogPersister.Info("No TERRAFORM_PLAN stage was found before this stage, the current changes will be planned and applied")
+		if err := cmd.Apply(ctx, e.LogPersister); err != nil {
+			e.LogPersister.Errorf("Failed to apply changes (%v)", err)
+			return model.StageStatus_STAGE_FAILURE
+		}
+		e.LogPersister.Success("Successfully applied changes")
+		return model.StageStatus_STAGE_SUCCESS
+	}
+
+	if ok := e.loadPlan(ctx, planStage); !ok {
+		return model.StageStatus_STAGE_FAILURE
+	}
+
+	e.LogPersister.Infof("Start applying the plan saved by stage %s", planStage.Name)
+	err := cmd.ApplyPlan(ctx, e.LogPersister, planFileName)
+	if errors.Is(err, provider.ErrStalePlan) {
+		e.LogPersister.Error("Refused to apply the saved plan because it is stale. The infrastructure was changed after the plan was created, so the approved changes are no longer what would be applied. Please trigger a new deployment to plan and review the changes again.")
+		return model.StageStatus_STAGE_FAILURE
+	}
+	if err != nil {
 		e.LogPersister.Errorf("Failed to apply changes (%v)", err)
 		return model.StageStatus_STAGE_FAILURE
 	}
@@ -181,3 +219,70 @@ func (e *deployExecutor) ensureApply(ctx context.Context) model.StageStatus {
 	e.LogPersister.Success("Successfully applied changes")
 	return model.StageStatus_STAGE_SUCCESS
 }
+
+// savePlan stores the plan file generated in this stage as a deployment artifact
+// and links it to this stage through the stage metadata.
+func (e *deployExecutor) savePlan(ctx context.Context) bool {
+	content, err := ioutil.ReadFile(filepath.Join(e.appDir, planFileName))
+	if err != nil {
+		e.LogPersister.Errorf("Unable to read the saved plan file (%v)", err)
+		return false
+	}
+
+	name := planArtifactName(e.Stage.Id)
+	if err := e.ArtifactStore.Put(ctx, name, content); err != nil {
+		e.LogPersister.Errorf("Unable to store the plan file (%v)", err)
+		return false
+	}
+
+	metadata := map[string]string{
+		planArtifactMetadataKey: name,
+	}
+	if err := e.MetadataStore.SetStageMetadata(ctx, e.Stage.Id, metadata); err != nil {
+		e.LogPersister.Errorf("Unable to save the stage metadata (%v)", err)
+		return false
+	}
+
+	e.LogPersister.Info("Saved the plan to be applied by the TERRAFORM_APPLY stage")
+	return true
+}
+
+// loadPlan restores the plan file saved by the given stage into the application directory.
+func (e *deployExecutor) loadPlan(ctx context.Context, planStage *model.PipelineStage) bool {
+	metadata, _ := e.MetadataStore.GetStageMetadata(planStage.Id)
+	name := metadata[planArtifactMetadataKey]
+	if name == "" {
+		e.LogPersister.Errorf("Unable to find the plan saved by stage %s", planStage.Name)
+		return false
+	}
+
+	content, err := e.ArtifactStore.Get(ctx, name)
+	if err != nil {
+		e.LogPersister.Errorf("Unable to load the plan saved by stage %s (%v)", planStage.Name, err)
+		return false
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(e.appDir, planFileName), content, 0600); err != nil {
+		e.LogPersister.Errorf("Unable to write the plan file (%v)", err)
+		return false
+	}
+	return true
+}
+
+// findPlanStage returns the last TERRAFORM_PLAN stage placed before the current stage.
+func (e *deployExecutor) findPlanStage() (*model.PipelineStage, bool) {
+	var found *model.PipelineStage
+	for _, s := range e.Deployment.Stages {
+		if s.Name != model.StageTerraformPlan.String() || s.Index >= e.Stage.Index {
+			continue
+		}
+		if found == nil || s.Index > found.Index {
+			found = s
+		}
+	}
+	return found, found != nil
+}
+
+func planArtifactName(stageID string) string {
+	return fmt.Sprintf("terraform-plan-%s", stageID)
+}
@@ -13,3 +13,58 @@
 // limitations under the License.
 
 package terraform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pipe-cd/pipe/pkg/app/piped/executor"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+func TestFindPlanStage(t *testing.T) {
+	stages := []*model.PipelineStage{
+		{Id: "stage-0", Name: model.StageTerraformPlan.String(), Index: 0},
+		{Id: "stage-1", Name: model.StageWaitApproval.String(), Index: 1},
+		{Id: "stage-2", Name: model.StageTerraformApply.String(), Index: 2},
+		{Id: "stage-3", Name: model.StageTerraformPlan.String(), Index: 3},
+		{Id: "stage-4", Name: model.StageTerraformApply.String(), Index: 4},
+	}
+	testcases := []struct {
+		name     string
+		stage    *model.PipelineStage
+		expected string
+	}{
+		{
+			name:     "no plan stage before",
+			stage:    &model.PipelineStage{Id: "stage-x", Index: 0},
+			expected: "",
+		},
+		{
+			name:     "the only plan stage before",
+			stage:    stages[2],
+			expected: "stage-0",
+		},
+		{
+			name:     "the last plan stage before",
+			stage:    stages[4],
+			expected: "stage-3",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := &deployExecutor{
+				Input: executor.Input{
+					Stage:      tc.stage,
+					Deployment: &model.Deployment{Stages: stages},
+				},
+			}
+			got, ok := e.findPlanStage()
+			assert.Equal(t, tc.expected != "", ok)
+			if ok {
+				assert.Equal(t, tc.expected, got.Id)
+			}
+		})
+	}
+}
@@ -16,18 +16,37 @@ package waitapproval
 
 import (
 	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/pipe-cd/pipe/pkg/app/piped/executor"
+	"github.com/pipe-cd/pipe/pkg/config"
 	"github.com/pipe-cd/pipe/pkg/model"
 )
 
 const (
-	approvedByKey = "ApprovedBy"
+	approvedByKey   = "ApprovedBy"
+	approvalsKey    = "Approvals"
+	rejectedByKey   = "RejectedBy"
+	rejectedAtKey   = "RejectedAt"
+	rejectReasonKey = "RejectReason"
+	startTimeKey    = "WaitingStartedAt"
+
+	checkInterval = 5 * time.Second
 )
 
+// approval represents an approval given to the stage.
+// The list of approvals is stored in the stage metadata for audit.
+type approval struct {
+	Approver   string `json:"approver"`
+	Team       string `json:"team,omitempty"`
+	ApprovedAt int64  `json:"approvedAt"`
+}
+
 type Executor struct {
 	executor.Input
 }
@@ -46,23 +65,65 @@ func Register(r registerer) {
 	r.Register(model.StageWaitApproval, f)
 }
 
-// Execute starts waiting until an approval from one of the specified users.
+// Execute starts waiting until enough approvals from the specified approvers
+// or a rejection from one of them.
 func (e *Executor) Execute(sig executor.StopSignal) model.StageStatus {
 	var (
 		originalStatus = e.Stage.Status
 		ctx            = sig.Context()
-		ticker         = time.NewTicker(5 * time.Second)
+		opts           = e.StageConfig.WaitApprovalStageOptions
 	)
+	if opts == nil {
+		opts = &config.WaitApprovalStageOptions{}
+	}
+	minApproverNum := opts.GetMinApproverNum()
+
+	// Continue with the approvals and the start time saved by the previous run.
+	approvals := e.retrieveApprovals()
+	if len(approvals) >= minApproverNum {
+		return model.StageStatus_STAGE_SUCCESS
+	}
+	startTime := e.retrieveStartTime()
+	if startTime.IsZero() {
+		startTime = time.Now()
+		e.saveMetadata(ctx, map[string]string{
+			startTimeKey: strconv.FormatInt(startTime.Unix(), 10),
+		})
+	}
+
+	var timeoutCh <-chan time.Time
+	if opts.Timeout > 0 {
+		timer := time.NewTimer(opts.Timeout.Duration() - time.Since(startTime))
+		defer timer.Stop()
+		timeoutCh = timer.C
+	}
+
+	ticker := time.NewTicker(checkInterval)
 	defer ticker.Stop()
 
-	e.LogPersister.Info("Waiting for an approval...")
+	if len(opts.Approvers) > 0 {
+		e.LogPersister.Infof("Waiting for %d approval(s) from %s...", minApproverNum, strings.Join(opts.Approvers, ", "))
+	} else {
+		e.LogPersister.Infof("Waiting for %d approval(s)...", minApproverNum)
+	}
+
 	for {
 		select {
 		case <-ticker.C:
-			if commander, ok := e.checkApproval(ctx); ok {
-				e.LogPersister.Infof("Got an approval from %s", commander)
+			var done bool
+			approvals, done = e.handleCommands(ctx, opts.Approvers, minApproverNum, approvals)
+			if !done {
+				continue
+			}
+			if len(approvals) >= minApproverNum {
+				e.LogPersister.Successf("Got enough approvals from %s", joinApprovers(approvals))
 				return model.StageStatus_STAGE_SUCCESS
 			}
+			return model.StageStatus_STAGE_FAILURE
+
+		case <-timeoutCh:
+			e.LogPersister.Errorf("Timed out while waiting for approvals (got %d/%d)", len(approvals), minApproverNum)
+			return model.StageStatus_STAGE_FAILURE
 
 		case s := <-sig.Ch():
 			switch s {
@@ -77,35 +138,148 @@ func (e *Executor) Execute(sig executor.StopSignal) model.StageStatus {
 	}
 }
 
-func (e *Executor) checkApproval(ctx context.Context) (string, bool) {
-	var approveCmd *model.ReportableCommand
-	commands := e.CommandLister.ListCommands()
+// handleCommands handles the approve and reject commands sent to this stage.
+// It returns the updated approvals and whether the stage has been completed
+// by enough approvals or a rejection.
+func (e *Executor) handleCommands(ctx context.Context, approvers []string, minApproverNum int, approvals []approval) ([]approval, bool) {
+	for _, cmd := range e.CommandLister.ListCommands() {
+		switch {
+		case cmd.GetApproveStage() != nil:
+			team := cmd.GetApproveStage().CommanderTeam
+			if !model.IsStageApprover(approvers, cmd.Commander, team) {
+				e.LogPersister.Errorf("Ignored the approval from %s who is not in the list of approvers", cmd.Commander)
+				e.reportCommand(ctx, cmd, model.CommandStatus_COMMAND_FAILED)
+				continue
+			}
+			if hasApproved(approvals, cmd.Commander) {
+				e.LogPersister.Infof("Ignored the approval from %s who has already approved", cmd.Commander)
+				e.reportCommand(ctx, cmd, model.CommandStatus_COMMAND_SUCCEEDED)
+				continue
+			}
+
+			updated := append(approvals, approval{
+				Approver:   cmd.Commander,
+				Team:       team,
+				ApprovedAt: time.Now().Unix(),
+			})
+			if err := e.saveApprovals(ctx, updated); err != nil {
+				e.LogPersister.Errorf("Unabled to save approver information to deployment, %v", err)
+				return approvals, false
+			}
+			approvals = updated
+			e.reportCommand(ctx, cmd, model.CommandStatus_COMMAND_SUCCEEDED)
+			e.LogPersister.Infof("Got an approval from %s (%d/%d)", cmd.Commander, len(approvals), minApproverNum)
+
+			if len(approvals) >= minApproverNum {
+				return approvals, true
+			}
+
+		case cmd.GetRejectStage() != nil:
+			rejection := cmd.GetRejectStage()
+			if !model.IsStageApprover(approvers, cmd.Commander, rejection.CommanderTeam) {
+				e.LogPersister.Errorf("Ignored the rejection from %s who is not in the list of approvers", cmd.Commander)
+				e.reportCommand(ctx, cmd, model.CommandStatus_COMMAND_FAILED)
+				continue
+			}
+
+			metadata := map[string]string{
+				rejectedByKey:   cmd.Commander,
+				rejectedAtKey:   strconv.FormatInt(time.Now().Unix(), 10),
+				rejectReasonKey: rejection.Reason,
+			}
+			if err := e.saveMetadata(ctx, metadata); err != nil {
+				e.LogPersister.Errorf("Unabled to save rejection information to deployment, %v", err)
+				return approvals, false
+			}
+			e.reportCommand(ctx, cmd, model.CommandStatus_COMMAND_SUCCEEDED)
 
-	for i, cmd := range commands {
-		if cmd.GetApproveStage() != nil {
-			approveCmd = &commands[i]
-			break
+			if rejection.Reason != "" {
+				e.LogPersister.Errorf("Rejected by %s: %s", cmd.Commander, rejection.Reason)
+			} else {
+				e.LogPersister.Errorf("Rejected by %s", cmd.Commander)
+			}
+			return approvals, true
 		}
 	}
-	if approveCmd == nil {
-		return "", false
+	return approvals, false
+}
+
+func (e *Executor) reportCommand(ctx context.Context, cmd model.ReportableCommand, status model.CommandStatus) {
+	if err := cmd.Report(ctx, status, nil, nil); err != nil {
+		e.Logger.Error("failed to report handled command", zap.Error(err))
+	}
+}
+
+func (e *Executor) retrieveApprovals() []approval {
+	metadata, ok := e.MetadataStore.GetStageMetadata(e.Stage.Id)
+	if !ok {
+		return nil
+	}
+	var approvals []approval
+	if err := json.Unmarshal([]byte(metadata[approvalsKey]), &approvals); err != nil {
+		return nil
+	}
+	return approvals
+}
+
+func (e *Executor) saveApprovals(ctx context.Context, approvals []approval) error {
+	data, err := json.Marshal(approvals)
+	if err != nil {
+		return err
 	}
+	return e.saveMetadata(ctx, map[string]string{
+		approvedByKey: joinApprovers(approvals),
+		approvalsKey:  string(data),
+	})
+}
 
-	metadata := map[string]string{
-		approvedByKey: approveCmd.Commander,
+func (e *Executor) retrieveStartTime() (t time.Time) {
+	metadata, ok := e.MetadataStore.GetStageMetadata(e.Stage.Id)
+	if !ok {
+		return
+	}
+	s, ok := metadata[startTimeKey]
+	if !ok {
+		return
+	}
+	ut, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return
 	}
+	return time.Unix(ut, 0)
+}
+
+// saveMetadata merges the given values into the current stage metadata and saves them.
+func (e *Executor) saveMetadata(ctx context.Context, values map[string]string) error {
+	metadata := make(map[string]string, len(values))
 	if ori, ok := e.MetadataStore.GetStageMetadata(e.Stage.Id); ok {
 		for k, v := range ori {
 			metadata[k] = v
 		}
 	}
+	for k, v := range values {
+		metadata[k] = v
+	}
 	if err := e.MetadataStore.SetStageMetadata(ctx, e.Stage.Id, metadata); err != nil {
-		e.LogPersister.Errorf("Unabled to save approver information to deployment, %v", err)
-		return "", false
+		e.Logger.Error("failed to store metadata", zap.Error(err))
+		return err
 	}
+	return nil
+}
 
-	if err := approveCmd.Report(ctx, model.CommandStatus_COMMAND_SUCCEEDED, nil); err != nil {
-		e.Logger.Error("failed to report handled command", zap.Error(err))
+func hasApproved(approvals []approval, user string) bool {
+	for _, a := range approvals {
+		if a.Approver == user {
+			return true
+		}
+	}
+	return false
+}
+
+func joinApprovers(approvals []approval) string {
+	names := make([]string, 0, len(approvals))
+	for _, a := range approvals {
+		names = append(names, a.Approver)
 	}
-	return approveCmd.Commander, true
+	return strings.Join(names, ",")
 }
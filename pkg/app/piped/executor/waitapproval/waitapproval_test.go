@@ -0,0 +1,155 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package waitapproval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/pipe-cd/pipe/pkg/app/piped/executor"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+type fakeLogPersister struct{}
+
+func (l *fakeLogPersister) Write(_ []byte) (int, error)         { return 0, nil }
+func (l *fakeLogPersister) Info(_ string)                       {}
+func (l *fakeLogPersister) Infof(_ string, _ ...interface{})    {}
+func (l *fakeLogPersister) Success(_ string)                    {}
+func (l *fakeLogPersister) Successf(_ string, _ ...interface{}) {}
+func (l *fakeLogPersister) Error(_ string)                      {}
+func (l *fakeLogPersister) Errorf(_ string, _ ...interface{})   {}
+
+type fakeMetadataStore struct {
+	stages map[string]map[string]string
+}
+
+func (m *fakeMetadataStore) Get(_ string) (string, bool)              { return "", false }
+func (m *fakeMetadataStore) Set(_ context.Context, _, _ string) error { return nil }
+func (m *fakeMetadataStore) GetStageMetadata(id string) (map[string]string, bool) {
+	md, ok := m.stages[id]
+	return md, ok
+}
+func (m *fakeMetadataStore) SetStageMetadata(_ context.Context, id string, md map[string]string) error {
+	m.stages[id] = md
+	return nil
+}
+
+type fakeCommandLister struct {
+	commands []model.ReportableCommand
+}
+
+func (l *fakeCommandLister) ListCommands() []model.ReportableCommand {
+	return l.commands
+}
+
+func makeCommand(commander string, cmd *model.Command, reported map[string]model.CommandStatus) model.ReportableCommand {
+	cmd.Commander = commander
+	return model.ReportableCommand{
+		Command: cmd,
+		Report: func(_ context.Context, status model.CommandStatus, _ map[string]string, _ []byte) error {
+			reported[commander] = status
+			return nil
+		},
+	}
+}
+
+func approveCommand(commander, team string, reported map[string]model.CommandStatus) model.ReportableCommand {
+	return makeCommand(commander, &model.Command{
+		ApproveStage: &model.Command_ApproveStage{CommanderTeam: team},
+	}, reported)
+}
+
+func rejectCommand(commander string, reported map[string]model.CommandStatus) model.ReportableCommand {
+	return makeCommand(commander, &model.Command{
+		RejectStage: &model.Command_RejectStage{Reason: "not ready"},
+	}, reported)
+}
+
+func newExecutor(commands ...model.ReportableCommand) *Executor {
+	return &Executor{
+		Input: executor.Input{
+			Stage:         &model.PipelineStage{Id: "stage-1"},
+			CommandLister: &fakeCommandLister{commands: commands},
+			LogPersister:  &fakeLogPersister{},
+			MetadataStore: &fakeMetadataStore{stages: map[string]map[string]string{}},
+			Logger:        zap.NewNop(),
+		},
+	}
+}
+
+func TestHandleCommands(t *testing.T) {
+	ctx := context.Background()
+	approvers := []string{"foo", "bar", "pipe-cd/sre"}
+
+	t.Run("requires enough approvals from approvers", func(t *testing.T) {
+		reported := make(map[string]model.CommandStatus)
+		e := newExecutor(
+			approveCommand("foo", "", reported),
+			approveCommand("stranger", "", reported),
+			approveCommand("baz", "pipe-cd/sre", reported),
+		)
+
+		approvals, done := e.handleCommands(ctx, approvers, 3, nil)
+		assert.False(t, done)
+		require.Equal(t, 2, len(approvals))
+		assert.Equal(t, model.CommandStatus_COMMAND_FAILED, reported["stranger"])
+		assert.Equal(t, model.CommandStatus_COMMAND_SUCCEEDED, reported["foo"])
+		assert.Equal(t, model.CommandStatus_COMMAND_SUCCEEDED, reported["baz"])
+
+		// Approvals were saved so that they can be restored after restarting.
+		assert.Equal(t, approvals, e.retrieveApprovals())
+		md, _ := e.MetadataStore.GetStageMetadata("stage-1")
+		assert.Equal(t, "foo,baz", md[approvedByKey])
+
+		e.CommandLister = &fakeCommandLister{commands: []model.ReportableCommand{
+			approveCommand("foo", "", reported),
+			approveCommand("bar", "", reported),
+		}}
+		approvals, done = e.handleCommands(ctx, approvers, 3, approvals)
+		assert.True(t, done)
+		assert.Equal(t, "foo,baz,bar", joinApprovers(approvals))
+	})
+
+	t.Run("rejected by an approver", func(t *testing.T) {
+		reported := make(map[string]model.CommandStatus)
+		e := newExecutor(
+			approveCommand("foo", "", reported),
+			rejectCommand("bar", reported),
+		)
+
+		approvals, done := e.handleCommands(ctx, approvers, 2, nil)
+		assert.True(t, done)
+		assert.Equal(t, 1, len(approvals))
+		assert.Equal(t, model.CommandStatus_COMMAND_SUCCEEDED, reported["bar"])
+
+		md, _ := e.MetadataStore.GetStageMetadata("stage-1")
+		assert.Equal(t, "bar", md[rejectedByKey])
+		assert.Equal(t, "not ready", md[rejectReasonKey])
+	})
+
+	t.Run("rejection from a non-approver is ignored", func(t *testing.T) {
+		reported := make(map[string]model.CommandStatus)
+		e := newExecutor(rejectCommand("stranger", reported))
+
+		_, done := e.handleCommands(ctx, approvers, 1, nil)
+		assert.False(t, done)
+		assert.Equal(t, model.CommandStatus_COMMAND_FAILED, reported["stranger"])
+	})
+}
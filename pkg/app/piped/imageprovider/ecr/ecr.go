@@ -16,6 +16,7 @@ package ecr
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"sort"
 	"strings"
@@ -182,3 +183,33 @@ func (e *ECR) GetLatestImage(ctx context.Context, image *model.ImageName) (*mode
 		Tag:       latest,
 	}, nil
 }
+
+// GetAuthorizationToken gives back the username and password that can be used
+// to login to the registry. The returned password is valid for 12 hours.
+func (e *ECR) GetAuthorizationToken(ctx context.Context) (username, password string, err error) {
+	input := &ecr.GetAuthorizationTokenInput{}
+	if e.registryID != "" {
+		input.RegistryIds = []*string{aws.String(e.registryID)}
+	}
+	out, err := e.client.GetAuthorizationTokenWithContext(ctx, input)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get authorization token: %w", err)
+	}
+	if len(out.AuthorizationData) == 0 || out.AuthorizationData[0].AuthorizationToken == nil {
+		return "", "", fmt.Errorf("no authorization token was returned")
+	}
+	return decodeAuthorizationToken(*out.AuthorizationData[0].AuthorizationToken)
+}
+
+// decodeAuthorizationToken decodes the base64 encoded "user:password" token.
+func decodeAuthorizationToken(token string) (username, password string, err error) {
+	data, err := base64.StdEncoding.DecodeString(token)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to decode authorization token: %w", err)
+	}
+	ss := strings.SplitN(string(data), ":", 2)
+	if len(ss) != 2 {
+		return "", "", fmt.Errorf("invalid authorization token format")
+	}
+	return ss[0], ss[1], nil
+}
@@ -13,3 +13,44 @@
 // limitations under the License.
 
 package ecr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeAuthorizationToken(t *testing.T) {
+	testcases := []struct {
+		name             string
+		token            string
+		expectedUsername string
+		expectedPassword string
+		expectedErr      bool
+	}{
+		{
+			name:             "valid token",
+			token:            "QVdTOnBhc3M6d29yZA==",
+			expectedUsername: "AWS",
+			expectedPassword: "pass:word",
+		},
+		{
+			name:        "not base64 encoded",
+			token:       "AWS:password",
+			expectedErr: true,
+		},
+		{
+			name:        "missing password",
+			token:       "QVdT",
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			username, password, err := decodeAuthorizationToken(tc.token)
+			assert.Equal(t, tc.expectedErr, err != nil)
+			assert.Equal(t, tc.expectedUsername, username)
+			assert.Equal(t, tc.expectedPassword, password)
+		})
+	}
+}
@@ -35,6 +35,7 @@ type kubernetesReporter struct {
 	provider              config.PipedCloudProvider
 	appLister             applicationLister
 	stateGetter           kubernetes.Getter
+	clusterGetters        map[string]kubernetes.Getter
 	eventIterator         kubernetes.EventIterator
 	apiClient             apiClient
 	flushInterval         time.Duration
@@ -44,7 +45,7 @@ type kubernetesReporter struct {
 	snapshotVersions map[string]model.ApplicationLiveStateVersion
 }
 
-func newKubernetesReporter(cp config.PipedCloudProvider, appLister applicationLister, stateGetter kubernetes.Getter, apiClient apiClient, logger *zap.Logger) *kubernetesReporter {
+func newKubernetesReporter(cp config.PipedCloudProvider, appLister applicationLister, stateGetter kubernetes.Getter, clusterGetters map[string]kubernetes.Getter, apiClient apiClient, logger *zap.Logger) *kubernetesReporter {
 	logger = logger.Named("kubernetes-reporter").With(
 		zap.String("cloud-provider", cp.Name),
 	)
@@ -52,6 +53,7 @@ func newKubernetesReporter(cp config.PipedCloudProvider, appLister applicationLi
 		provider:              cp,
 		appLister:             appLister,
 		stateGetter:           stateGetter,
+		clusterGetters:        clusterGetters,
 		eventIterator:         stateGetter.NewEventIterator(),
 		apiClient:             apiClient,
 		flushInterval:         5 * time.Second,
@@ -108,6 +110,12 @@ func (r *kubernetesReporter) flushSnapshots(ctx context.Context) error {
 			continue
 		}
 
+		version := &model.ApplicationLiveStateVersion{
+			Timestamp: state.Version.Timestamp,
+			Index:     state.Version.Index,
+		}
+		resources := r.mergeClusterStates(app.Id, state.Resources, version)
+
 		snapshot := &model.ApplicationLiveStateSnapshot{
 			ApplicationId: app.Id,
 			EnvId:         app.EnvId,
@@ -115,9 +123,9 @@ func (r *kubernetesReporter) flushSnapshots(ctx context.Context) error {
 			ProjectId:     app.ProjectId,
 			Kind:          app.Kind,
 			Kubernetes: &model.KubernetesApplicationLiveState{
-				Resources: state.Resources,
+				Resources: resources,
 			},
-			Version: &state.Version,
+			Version: version,
 		}
 		snapshot.DetermineAppHealthStatus()
 		req := &pipedservice.ReportApplicationLiveStateRequest{
@@ -137,6 +145,27 @@ func (r *kubernetesReporter) flushSnapshots(ctx context.Context) error {
 	return nil
 }
 
+// mergeClusterStates appends the resources of the given application running on the other clusters
+// to the given resources. The given version is updated to the latest one of all merged states.
+func (r *kubernetesReporter) mergeClusterStates(appID string, resources []*model.KubernetesResourceState, version *model.ApplicationLiveStateVersion) []*model.KubernetesResourceState {
+	for name, g := range r.clusterGetters {
+		if name == r.provider.Name {
+			continue
+		}
+		s, ok := g.GetKubernetesAppLiveState(appID)
+		if !ok {
+			continue
+		}
+		resources = append(resources, s.Resources...)
+		v := &s.Version
+		if v.Timestamp > version.Timestamp || (v.Timestamp == version.Timestamp && v.Index > version.Index) {
+			version.Timestamp = v.Timestamp
+			version.Index = v.Index
+		}
+	}
+	return resources
+}
+
 func (r *kubernetesReporter) flushEvents(ctx context.Context) error {
 	events := r.eventIterator.Next(maxNumEventsPerRequest)
 	if len(events) == 0 {
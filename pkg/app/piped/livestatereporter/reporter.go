@@ -27,6 +27,7 @@ import (
 
 	"github.com/pipe-cd/pipe/pkg/app/api/service/pipedservice"
 	"github.com/pipe-cd/pipe/pkg/app/piped/livestatestore"
+	"github.com/pipe-cd/pipe/pkg/app/piped/livestatestore/kubernetes"
 	"github.com/pipe-cd/pipe/pkg/config"
 	"github.com/pipe-cd/pipe/pkg/model"
 )
@@ -60,15 +61,27 @@ func NewReporter(appLister applicationLister, stateGetter livestatestore.Getter,
 		logger:    logger.Named("live-state-reporter"),
 	}
 
+	// An application can be deployed to multiple Kubernetes clusters
+	// so every Kubernetes reporter needs to see the state of all clusters.
+	kubernetesGetters := make(map[string]kubernetes.Getter)
+	for _, cp := range cfg.CloudProviders {
+		if cp.Type != model.CloudProviderKubernetes {
+			continue
+		}
+		if sg, ok := stateGetter.KubernetesGetter(cp.Name); ok {
+			kubernetesGetters[cp.Name] = sg
+		}
+	}
+
 	for _, cp := range cfg.CloudProviders {
 		switch cp.Type {
 		case model.CloudProviderKubernetes:
-			sg, ok := stateGetter.KubernetesGetter(cp.Name)
+			sg, ok := kubernetesGetters[cp.Name]
 			if !ok {
 				r.logger.Error(fmt.Sprintf("unable to find live state getter for cloud provider: %s", cp.Name))
 				continue
 			}
-			r.reporters = append(r.reporters, newKubernetesReporter(cp, appLister, sg, apiClient, logger))
+			r.reporters = append(r.reporters, newKubernetesReporter(cp, appLister, sg, kubernetesGetters, apiClient, logger))
 
 		default:
 		}
@@ -22,11 +22,14 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/kubernetes"
+	"github.com/pipe-cd/pipe/pkg/config"
 	"github.com/pipe-cd/pipe/pkg/model"
 )
 
 type appNodes struct {
 	appID         string
+	cloudProvider string
+	healthRules   []config.KubernetesHealthRule
 	managingNodes map[string]node
 	dependedNodes map[string]node
 	version       model.ApplicationLiveStateVersion
@@ -59,8 +62,9 @@ func (a *appNodes) addManagingResource(uid string, key provider.ResourceKey, obj
 		appID:        a.appID,
 		key:          key,
 		unstructured: obj,
-		state:        provider.MakeKubernetesResourceState(uid, key, obj, now),
+		state:        provider.MakeKubernetesResourceState(uid, key, obj, a.healthRules, now),
 	}
+	n.state.CloudProvider = a.cloudProvider
 
 	a.mu.Lock()
 	oriNode, hasOriNode := a.managingNodes[uid]
@@ -113,8 +117,9 @@ func (a *appNodes) addDependedResource(uid string, key provider.ResourceKey, obj
 		appID:        a.appID,
 		key:          key,
 		unstructured: obj,
-		state:        provider.MakeKubernetesResourceState(uid, key, obj, now),
+		state:        provider.MakeKubernetesResourceState(uid, key, obj, a.healthRules, now),
 	}
+	n.state.CloudProvider = a.cloudProvider
 
 	a.mu.Lock()
 	oriNode, hasOriNode := a.dependedNodes[uid]
@@ -76,10 +76,12 @@ func NewStore(cfg *config.CloudProviderKubernetesConfig, pipedConfig *config.Pip
 		config:      cfg,
 		pipedConfig: pipedConfig,
 		store: &store{
-			pipedConfig: pipedConfig,
-			apps:        make(map[string]*appNodes),
-			resources:   make(map[string]appResource),
-			iterators:   make(map[int]int, 1),
+			pipedConfig:   pipedConfig,
+			cloudProvider: cloudProvider,
+			healthRules:   cfg.HealthRules,
+			apps:          make(map[string]*appNodes),
+			resources:     make(map[string]appResource),
+			iterators:     make(map[int]int, 1),
 		},
 		firstSyncedCh: make(chan error, 1),
 		logger:        logger,
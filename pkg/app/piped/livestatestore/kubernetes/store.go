@@ -34,6 +34,10 @@ const (
 
 type store struct {
 	pipedConfig *config.PipedSpec
+	// The name of the cloud provider of the watching cluster.
+	cloudProvider string
+	// The rules to determine the health status of the custom resources.
+	healthRules []config.KubernetesHealthRule
 	apps        map[string]*appNodes
 	// The map with the key is "resource's uid" and the value is "appResource".
 	// Because the depended resource does not include the appID in its annotations
@@ -106,6 +110,8 @@ func (s *store) addResource(obj *unstructured.Unstructured, appID string) {
 		if !ok {
 			app = &appNodes{
 				appID:         appID,
+				cloudProvider: s.cloudProvider,
+				healthRules:   s.healthRules,
 				managingNodes: make(map[string]node),
 				dependedNodes: make(map[string]node),
 				version: model.ApplicationLiveStateVersion{
@@ -28,9 +28,12 @@ type matcher struct {
 	ignoreApps   map[string]struct{}
 	envs         map[string]struct{}
 	ignoreEnvs   map[string]struct{}
+	labels       model.LabelSelector
 }
 
 func newMatcher(cfg config.NotificationRoute) *matcher {
+	// The selector was already validated while loading the configuration.
+	labels, _ := model.ParseLabelSelector(cfg.LabelSelector)
 	return &matcher{
 		events:       makeStringMap(cfg.Events, "EVENT"),
 		ignoreEvents: makeStringMap(cfg.IgnoreEvents, "EVENT"),
@@ -40,6 +43,7 @@ func newMatcher(cfg config.NotificationRoute) *matcher {
 		ignoreApps:   makeStringMap(cfg.IgnoreApps, ""),
 		envs:         makeStringMap(cfg.Envs, ""),
 		ignoreEnvs:   makeStringMap(cfg.IgnoreEnvs, ""),
+		labels:       labels,
 	}
 }
 
@@ -47,6 +51,10 @@ type appNameMetadata interface {
 	GetAppName() string
 }
 
+type appLabelsMetadata interface {
+	GetAppLabels() map[string]string
+}
+
 type envNameMetadata interface {
 	GetEnvName() string
 }
@@ -95,6 +103,9 @@ func (m *matcher) Match(event model.Event) bool {
 			return false
 		}
 	}
+	if md, ok := event.Metadata.(appLabelsMetadata); ok && !m.labels.Matches(md.GetAppLabels()) {
+		return false
+	}
 
 	return true
 }
@@ -160,6 +160,40 @@ func TestMatch(t *testing.T) {
 				}: true,
 			},
 		},
+		{
+			name: "filter by labels",
+			config: config.NotificationRoute{
+				LabelSelector: "team=pipe-cd,tier notin (test)",
+			},
+			matchings: map[model.Event]bool{
+				{
+					Type: model.EventType_EVENT_DEPLOYMENT_TRIGGERED,
+					Metadata: &model.EventDeploymentTriggered{
+						Deployment: &model.Deployment{
+							Labels: map[string]string{"team": "pipe-cd", "tier": "backend"},
+						},
+					},
+				}: true,
+				{
+					Type: model.EventType_EVENT_DEPLOYMENT_PLANNED,
+					Metadata: &model.EventDeploymentPlanned{
+						Deployment: &model.Deployment{
+							Labels: map[string]string{"team": "pipe-cd", "tier": "test"},
+						},
+					},
+				}: false,
+				{
+					Type: model.EventType_EVENT_DEPLOYMENT_SUCCEEDED,
+					Metadata: &model.EventDeploymentSucceeded{
+						Deployment: &model.Deployment{},
+					},
+				}: false,
+				{
+					Type:     model.EventType_EVENT_PIPED_STARTED,
+					Metadata: &model.EventPipedStarted{},
+				}: true,
+			},
+		},
 	}
 
 	for _, tc := range testcases {
@@ -19,11 +19,11 @@ package notifier
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"go.uber.org/atomic"
 	"go.uber.org/zap"
-	"golang.org/x/sync/errgroup"
 
 	"github.com/pipe-cd/pipe/pkg/config"
 	"github.com/pipe-cd/pipe/pkg/model"
@@ -33,6 +33,8 @@ import (
 type Notifier struct {
 	config      *config.PipedSpec
 	handlers    []handler
+	configCh    chan *config.PipedSpec
+	mu          sync.RWMutex
 	gracePeriod time.Duration
 	closed      atomic.Bool
 	logger      *zap.Logger
@@ -41,6 +43,8 @@ type Notifier struct {
 type handler struct {
 	matcher *matcher
 	sender  sender
+	cancel  context.CancelFunc
+	doneCh  chan struct{}
 }
 
 type sender interface {
@@ -51,6 +55,21 @@ type sender interface {
 
 func NewNotifier(cfg *config.PipedSpec, logger *zap.Logger) (*Notifier, error) {
 	logger = logger.Named("notifier")
+	handlers, err := buildHandlers(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Notifier{
+		config:      cfg,
+		handlers:    handlers,
+		configCh:    make(chan *config.PipedSpec),
+		gracePeriod: 10 * time.Second,
+		logger:      logger,
+	}, nil
+}
+
+func buildHandlers(cfg *config.PipedSpec, logger *zap.Logger) ([]handler, error) {
 	receivers := make(map[string]config.NotificationReceiver, len(cfg.Notifications.Receivers))
 	for _, r := range cfg.Notifications.Receivers {
 		receivers[r.Name] = r
@@ -78,25 +97,14 @@ func NewNotifier(cfg *config.PipedSpec, logger *zap.Logger) (*Notifier, error) {
 			sender:  sd,
 		})
 	}
-
-	return &Notifier{
-		config:      cfg,
-		handlers:    handlers,
-		gracePeriod: 10 * time.Second,
-		logger:      logger,
-	}, nil
+	return handlers, nil
 }
 
 func (n *Notifier) Run(ctx context.Context) error {
-	group, ctx := errgroup.WithContext(ctx)
-
 	// Start running all senders.
-	for i := range n.handlers {
-		sender := n.handlers[i].sender
-		group.Go(func() error {
-			return sender.Run(ctx)
-		})
-	}
+	n.mu.Lock()
+	n.startHandlers(ctx, n.handlers)
+	n.mu.Unlock()
 
 	// Send the PIPED_STARTED event.
 	n.Notify(model.Event{
@@ -108,9 +116,16 @@ func (n *Notifier) Run(ctx context.Context) error {
 	})
 
 	n.logger.Info(fmt.Sprintf("all %d notifiers have been started", len(n.handlers)))
-	if err := group.Wait(); err != nil {
-		n.logger.Error("failed while running", zap.Error(err))
-		return err
+
+L:
+	for {
+		select {
+		case cfg := <-n.configCh:
+			n.applyConfig(ctx, cfg)
+
+		case <-ctx.Done():
+			break L
+		}
 	}
 
 	// Send the PIPED_STOPPED event.
@@ -124,23 +139,83 @@ func (n *Notifier) Run(ctx context.Context) error {
 
 	// Mark to ignore all incoming events from this time and close all senders.
 	n.closed.Store(true)
+	n.mu.Lock()
+	handlers := n.handlers
+	n.mu.Unlock()
+	n.stopHandlers(handlers)
+
+	n.logger.Info(fmt.Sprintf("all %d notifiers have been stopped", len(handlers)))
+	return nil
+}
+
+// UpdateConfig passes the given piped configuration to the running notifier
+// to rebuild its notification routes and receivers.
+func (n *Notifier) UpdateConfig(ctx context.Context, cfg *config.PipedSpec) error {
+	select {
+	case n.configCh <- cfg:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// applyConfig starts the senders for the routes of the given configuration,
+// switches to them and then stops the previous ones after sending their remaining events.
+func (n *Notifier) applyConfig(ctx context.Context, cfg *config.PipedSpec) {
+	handlers, err := buildHandlers(cfg, n.logger)
+	if err != nil {
+		n.logger.Error("failed to build notification handlers from the new configuration", zap.Error(err))
+		return
+	}
+	n.startHandlers(ctx, handlers)
+
+	n.mu.Lock()
+	old := n.handlers
+	n.handlers = handlers
+	n.mu.Unlock()
+
+	n.stopHandlers(old)
+	n.logger.Info(fmt.Sprintf("notification routes have been reloaded, %d notifiers are running", len(handlers)))
+}
+
+func (n *Notifier) startHandlers(ctx context.Context, handlers []handler) {
+	for i := range handlers {
+		var (
+			sender        = handlers[i].sender
+			doneCh        = make(chan struct{})
+			sctx, scancel = context.WithCancel(ctx)
+		)
+		handlers[i].cancel = scancel
+		handlers[i].doneCh = doneCh
+		go func() {
+			defer close(doneCh)
+			if err := sender.Run(sctx); err != nil {
+				n.logger.Error("failed while running sender", zap.Error(err))
+			}
+		}()
+	}
+}
+
+func (n *Notifier) stopHandlers(handlers []handler) {
 	ctx, cancel := context.WithTimeout(context.Background(), n.gracePeriod)
 	defer cancel()
 
-	for i := range n.handlers {
-		sender := n.handlers[i].sender
-		sender.Close(ctx)
+	for i := range handlers {
+		handlers[i].cancel()
+		<-handlers[i].doneCh
+		handlers[i].sender.Close(ctx)
 	}
-
-	n.logger.Info(fmt.Sprintf("all %d notifiers have been stopped", len(n.handlers)))
-	return nil
 }
 
 func (n *Notifier) Notify(event model.Event) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
 	if n.closed.Load() {
 		n.logger.Warn("ignore an event because notifier is already closed", zap.String("type", event.Type.String()))
 		return
 	}
+
 	for _, h := range n.handlers {
 		if !h.matcher.Match(event) {
 			continue
@@ -150,6 +150,20 @@ func (s *slack) buildSlackMessage(event model.Event, webURL string) (slackMessag
 			{"Started At", makeSlackDate(d.CreatedAt), true},
 		}
 	}
+	generateDeploymentChainEventData := func(c *model.DeploymentChain) {
+		link = webURL + "/deployment_chains/" + c.Id
+		apps := make([]string, 0)
+		for _, b := range c.Blocks {
+			for _, n := range b.Nodes {
+				apps = append(apps, n.ApplicationName)
+			}
+		}
+		fields = []slackField{
+			{"Deployment Chain", makeSlackLink(truncateText(c.Id, 8), link), true},
+			{"Started At", makeSlackDate(c.CreatedAt), true},
+			{"Applications", strings.Join(apps, " → "), false},
+		}
+	}
 	generatePipedEventData := func(id, version string) {
 		link = webURL + "/settings/piped"
 		fields = []slackField{
@@ -162,6 +176,7 @@ func (s *slack) buildSlackMessage(event model.Event, webURL string) (slackMessag
 	case model.EventType_EVENT_DEPLOYMENT_TRIGGERED:
 		md := event.Metadata.(*model.EventDeploymentTriggered)
 		title = fmt.Sprintf("Triggered a new deployment for %q", md.Deployment.ApplicationName)
+		text = md.Deployment.Trigger.Reason
 		generateDeploymentEventData(md.Deployment, md.EnvName)
 
 	case model.EventType_EVENT_DEPLOYMENT_PLANNED:
@@ -190,6 +205,38 @@ func (s *slack) buildSlackMessage(event model.Event, webURL string) (slackMessag
 		color = slackWarnColor
 		generateDeploymentEventData(md.Deployment, md.EnvName)
 
+	case model.EventType_EVENT_DEPLOYMENT_WAIT_APPROVAL:
+		md := event.Metadata.(*model.EventDeploymentWaitApproval)
+		title = fmt.Sprintf("Deployment for %q is waiting for approval", md.Deployment.ApplicationName)
+		text = fmt.Sprintf("%d approval(s) required at stage %s", md.MinApproverNum, md.StageId)
+		color = slackWarnColor
+		generateDeploymentEventData(md.Deployment, md.EnvName)
+		if len(md.Approvers) > 0 {
+			fields = append(fields, slackField{"Approvers", strings.Join(md.Approvers, ", "), false})
+		}
+		if s.config.InteractiveApproval {
+			msg := makeSlackMessage(title, link, text, color, timestamp, fields...)
+			msg.Attachments[0].CallbackID = model.SlackApprovalCallbackID
+			msg.Attachments[0].Actions = makeSlackApprovalActions(md.Deployment.Id, md.StageId)
+			return msg, true
+		}
+
+	case model.EventType_EVENT_APPLICATION_DRIFT_REPEATED:
+		md := event.Metadata.(*model.EventApplicationDriftRepeated)
+		title = fmt.Sprintf("Configuration drift of %q came back after automatic sync", md.Application.Name)
+		text = md.State.ShortReason
+		if md.AutoSyncAttempts >= md.MaxAutoSyncAttempts {
+			text += "\nAutomatic sync has been stopped until the next deployment."
+		}
+		color = slackWarnColor
+		link = webURL + "/applications/" + md.Application.Id
+		fields = []slackField{
+			{"Env", truncateText(md.EnvName, 8), true},
+			{"Application", makeSlackLink(md.Application.Name, link), true},
+			{"Kind", strings.ToLower(md.Application.Kind.String()), true},
+			{"Automatic Syncs", fmt.Sprintf("%d/%d", md.AutoSyncAttempts, md.MaxAutoSyncAttempts), true},
+		}
+
 	case model.EventType_EVENT_PIPED_STARTED:
 		md := event.Metadata.(*model.EventPipedStarted)
 		title = "A piped has been started"
@@ -200,6 +247,31 @@ func (s *slack) buildSlackMessage(event model.Event, webURL string) (slackMessag
 		title = "A piped has been stopped"
 		generatePipedEventData(md.Id, md.Version)
 
+	case model.EventType_EVENT_DEPLOYMENT_CHAIN_TRIGGERED:
+		md := event.Metadata.(*model.EventDeploymentChainTriggered)
+		title = fmt.Sprintf("Triggered a new deployment chain from %q", md.Deployment.ApplicationName)
+		generateDeploymentChainEventData(md.DeploymentChain)
+
+	case model.EventType_EVENT_DEPLOYMENT_CHAIN_SUCCEEDED:
+		md := event.Metadata.(*model.EventDeploymentChainSucceeded)
+		title = "Deployment chain was completed successfully"
+		color = slackSuccessColor
+		generateDeploymentChainEventData(md.DeploymentChain)
+
+	case model.EventType_EVENT_DEPLOYMENT_CHAIN_FAILED:
+		md := event.Metadata.(*model.EventDeploymentChainFailed)
+		title = "Deployment chain was failed"
+		text = md.Reason
+		color = slackErrorColor
+		generateDeploymentChainEventData(md.DeploymentChain)
+
+	case model.EventType_EVENT_DEPLOYMENT_CHAIN_CANCELLED:
+		md := event.Metadata.(*model.EventDeploymentChainCancelled)
+		title = "Deployment chain was cancelled"
+		text = md.DeploymentChain.StatusReason
+		color = slackWarnColor
+		generateDeploymentChainEventData(md.DeploymentChain)
+
 	// TODO: Support application type of notification event.
 	default:
 		return slackMessage{}, false
@@ -221,6 +293,17 @@ type slackAttachment struct {
 	Color     string       `json:"color,omitempty"`
 	Markdown  []string     `json:"mrkdwn_in,omitempty"`
 	Timestamp int64        `json:"ts,omitempty"`
+	// The fields for the interactive messages.
+	CallbackID string        `json:"callback_id,omitempty"`
+	Actions    []slackAction `json:"actions,omitempty"`
+}
+
+type slackAction struct {
+	Name  string `json:"name"`
+	Text  string `json:"text"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+	Style string `json:"style,omitempty"`
 }
 
 type slackField struct {
@@ -229,6 +312,26 @@ type slackField struct {
 	Short bool   `json:"short"`
 }
 
+func makeSlackApprovalActions(deploymentID, stageID string) []slackAction {
+	value := model.MakeSlackApprovalValue(deploymentID, stageID)
+	return []slackAction{
+		{
+			Name:  model.SlackApproveActionName,
+			Text:  "Approve",
+			Type:  "button",
+			Value: value,
+			Style: "primary",
+		},
+		{
+			Name:  model.SlackRejectActionName,
+			Text:  "Reject",
+			Type:  "button",
+			Value: value,
+			Style: "danger",
+		},
+	}
+}
+
 func makeSlackLink(title, url string) string {
 	return fmt.Sprintf("<%s|%s>", url, title)
 }
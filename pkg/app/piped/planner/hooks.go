@@ -0,0 +1,78 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"time"
+
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+// AddHookStages adds the configured pre-sync and post-sync hook stages into the given pipeline.
+// The pre-sync hook is placed before the first visible stage and
+// the post-sync hook is placed after the last visible stage.
+// The invisible stages such as rollback are kept at the end of the pipeline.
+func AddHookStages(stages []*model.PipelineStage, hooks *config.DeploymentHooks, now time.Time) []*model.PipelineStage {
+	if hooks == nil || (hooks.PreSync == nil && hooks.PostSync == nil) {
+		return stages
+	}
+
+	var (
+		visibles   = make([]*model.PipelineStage, 0, len(stages))
+		invisibles = make([]*model.PipelineStage, 0, 1)
+		out        = make([]*model.PipelineStage, 0, len(stages)+2)
+	)
+	for _, s := range stages {
+		if s.Visible && s.Name != model.StageRollback.String() {
+			visibles = append(visibles, s)
+			continue
+		}
+		invisibles = append(invisibles, s)
+	}
+
+	if hooks.PreSync != nil {
+		pre := makeHookStage(PredefinedStagePreSyncHook, now)
+		if len(visibles) > 0 {
+			visibles[0].Requires = []string{pre.Id}
+		}
+		visibles = append([]*model.PipelineStage{pre}, visibles...)
+	}
+
+	if hooks.PostSync != nil {
+		post := makeHookStage(PredefinedStagePostSyncHook, now)
+		if len(visibles) > 0 {
+			post.Requires = []string{visibles[len(visibles)-1].Id}
+		}
+		visibles = append(visibles, post)
+	}
+
+	out = append(out, visibles...)
+	return append(out, invisibles...)
+}
+
+func makeHookStage(id string, now time.Time) *model.PipelineStage {
+	s, _ := GetPredefinedStage(id)
+	return &model.PipelineStage{
+		Id:         s.Id,
+		Name:       s.Name.String(),
+		Desc:       s.Desc,
+		Predefined: true,
+		Visible:    true,
+		Status:     model.StageStatus_STAGE_NOT_STARTED_YET,
+		CreatedAt:  now.Unix(),
+		UpdatedAt:  now.Unix(),
+	}
+}
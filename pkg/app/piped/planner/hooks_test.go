@@ -0,0 +1,93 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+func TestAddHookStages(t *testing.T) {
+	now := time.Now()
+	makeStages := func() []*model.PipelineStage {
+		return []*model.PipelineStage{
+			{Id: "stage-0", Name: model.StageK8sCanaryRollout.String(), Visible: true},
+			{Id: "stage-1", Name: model.StageK8sPrimaryRollout.String(), Visible: true, Requires: []string{"stage-0"}},
+			{Id: PredefinedStageRollback, Name: model.StageRollback.String(), Visible: false},
+		}
+	}
+	hook := &config.HookStageOptions{JobManifest: "hooks/job.yaml"}
+
+	testcases := []struct {
+		name             string
+		hooks            *config.DeploymentHooks
+		expectedIDs      []string
+		expectedRequires map[string][]string
+	}{
+		{
+			name:        "no hook",
+			hooks:       nil,
+			expectedIDs: []string{"stage-0", "stage-1", PredefinedStageRollback},
+			expectedRequires: map[string][]string{
+				"stage-0": nil,
+				"stage-1": {"stage-0"},
+			},
+		},
+		{
+			name: "pre-sync hook only",
+			hooks: &config.DeploymentHooks{
+				PreSync: hook,
+			},
+			expectedIDs: []string{PredefinedStagePreSyncHook, "stage-0", "stage-1", PredefinedStageRollback},
+			expectedRequires: map[string][]string{
+				PredefinedStagePreSyncHook: nil,
+				"stage-0":                  {PredefinedStagePreSyncHook},
+				"stage-1":                  {"stage-0"},
+			},
+		},
+		{
+			name: "both hooks",
+			hooks: &config.DeploymentHooks{
+				PreSync:  hook,
+				PostSync: hook,
+			},
+			expectedIDs: []string{PredefinedStagePreSyncHook, "stage-0", "stage-1", PredefinedStagePostSyncHook, PredefinedStageRollback},
+			expectedRequires: map[string][]string{
+				PredefinedStagePreSyncHook:  nil,
+				"stage-0":                   {PredefinedStagePreSyncHook},
+				"stage-1":                   {"stage-0"},
+				PredefinedStagePostSyncHook: {"stage-1"},
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			stages := AddHookStages(makeStages(), tc.hooks, now)
+			ids := make([]string, 0, len(stages))
+			for _, s := range stages {
+				ids = append(ids, s.Id)
+				if requires, ok := tc.expectedRequires[s.Id]; ok {
+					assert.Equal(t, requires, s.Requires, s.Id)
+				}
+			}
+			assert.Equal(t, tc.expectedIDs, ids)
+		})
+	}
+}
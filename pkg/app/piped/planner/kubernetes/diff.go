@@ -0,0 +1,114 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/kubernetes"
+	"github.com/pipe-cd/pipe/pkg/app/piped/diff"
+)
+
+// renderManifestsDiff renders the per-resource diff between the running and target manifests
+// to let the reviewers know what will be changed by the deployment.
+// The data of Secrets is masked.
+func renderManifestsDiff(olds, news []provider.Manifest, runningCommit, targetCommit string) (string, error) {
+	var (
+		oldMap = make(map[provider.ResourceKey]provider.Manifest, len(olds))
+		newMap = make(map[provider.ResourceKey]provider.Manifest, len(news))
+		keys   = make([]provider.ResourceKey, 0, len(olds)+len(news))
+	)
+	for _, m := range olds {
+		oldMap[m.Key] = m
+		keys = append(keys, m.Key)
+	}
+	for _, m := range news {
+		newMap[m.Key] = m
+		if _, ok := oldMap[m.Key]; !ok {
+			keys = append(keys, m.Key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].IsLess(keys[j])
+	})
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("--- Running (commit %s)\n", shortCommit(runningCommit)))
+	b.WriteString(fmt.Sprintf("+++ Target (commit %s)\n\n", shortCommit(targetCommit)))
+
+	index := 0
+	for _, key := range keys {
+		oldManifest, inOld := oldMap[key]
+		newManifest, inNew := newMap[key]
+
+		var mark string
+		switch {
+		case !inOld:
+			mark = "+"
+			oldManifest = makeEmptyManifest(key)
+		case !inNew:
+			mark = "-"
+			newManifest = makeEmptyManifest(key)
+		default:
+			mark = "*"
+		}
+
+		result, err := provider.Diff(oldManifest, newManifest)
+		if err != nil {
+			return "", fmt.Errorf("failed to calculate the diff of %s: %w", key.ReadableString(), err)
+		}
+		if !result.HasDiff() {
+			continue
+		}
+
+		opts := []diff.RenderOption{
+			diff.WithLeftPadding(1),
+		}
+		if key.IsSecret() {
+			opts = append(opts, diff.WithMaskPath("data"), diff.WithMaskPath("stringData"))
+		}
+		renderer := diff.NewRenderer(opts...)
+
+		index++
+		b.WriteString(fmt.Sprintf("%s %d. %s\n\n", mark, index, key.ReadableString()))
+		b.WriteString(renderer.Render(result.Nodes()))
+		b.WriteString("\n")
+	}
+
+	if index == 0 {
+		b.WriteString("No manifest was changed\n")
+	}
+	return b.String(), nil
+}
+
+func makeEmptyManifest(key provider.ResourceKey) provider.Manifest {
+	return provider.MakeManifest(key, &unstructured.Unstructured{
+		Object: map[string]interface{}{},
+	})
+}
+
+func shortCommit(hash string) string {
+	if hash == "" {
+		return "none"
+	}
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
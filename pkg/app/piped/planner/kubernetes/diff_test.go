@@ -0,0 +1,147 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/kubernetes"
+)
+
+const (
+	runningManifests = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: simple
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+      - name: helloworld
+        image: gcr.io/pipecd/helloworld:v0.1.0
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: simple
+data:
+  password: b2xk
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: removed
+data:
+  key: value
+`
+	targetManifests = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: simple
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+      - name: helloworld
+        image: gcr.io/pipecd/helloworld:v0.2.0
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: simple
+data:
+  password: bmV3
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: simple
+spec:
+  ports:
+  - port: 9085
+`
+	expectedManifestsDiff = `--- Running (commit 0123456)
++++ Target (commit abcdefg)
+
+* 1. name="simple", kind="Deployment", namespace="default", apiVersion="apps/v1"
+
+  spec:
+    template:
+      spec:
+        containers:
+          -
+            #spec.template.spec.containers.0.image
+-           image: gcr.io/pipecd/helloworld:v0.1.0
++           image: gcr.io/pipecd/helloworld:v0.2.0
+
+
+- 2. name="removed", kind="ConfigMap", namespace="default", apiVersion="v1"
+
+  #apiVersion
+- apiVersion: v1
+
+  #data
+- data:
+-   key: value
+
+  #kind
+- kind: ConfigMap
+
+  #metadata
+- metadata:
+-   name: removed
+
+
+* 3. name="simple", kind="Secret", namespace="default", apiVersion="v1"
+
+  data:
+    #data.password
+-   password: *****
++   password: *****
+
+
++ 4. name="simple", kind="Service", namespace="default", apiVersion="v1"
+
+  #apiVersion
++ apiVersion: v1
+
+  #kind
++ kind: Service
+
+  #metadata
++ metadata:
++   name: simple
+
+  #spec
++ spec:
++   ports:
++     - port: 9085
+
+
+`
+)
+
+func TestRenderManifestsDiff(t *testing.T) {
+	olds, err := provider.ParseManifests(runningManifests)
+	require.NoError(t, err)
+	news, err := provider.ParseManifests(targetManifests)
+	require.NoError(t, err)
+
+	got, err := renderManifestsDiff(olds, news, "0123456789", "abcdefghij")
+	require.NoError(t, err)
+	assert.Equal(t, expectedManifestsDiff, got)
+}
+
+func TestRenderManifestsDiffNoChange(t *testing.T) {
+	manifests, err := provider.ParseManifests(runningManifests)
+	require.NoError(t, err)
+
+	got, err := renderManifestsDiff(manifests, manifests, "0123456789", "abcdefghij")
+	require.NoError(t, err)
+	assert.Equal(t, "--- Running (commit 0123456)\n+++ Target (commit abcdefg)\n\nNo manifest was changed\n", got)
+}
@@ -26,9 +26,9 @@ import (
 
 	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/kubernetes"
 	"github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/kubernetes/resource"
-	"github.com/pipe-cd/pipe/pkg/app/piped/deploysource"
 	"github.com/pipe-cd/pipe/pkg/app/piped/diff"
 	"github.com/pipe-cd/pipe/pkg/app/piped/planner"
+	"github.com/pipe-cd/pipe/pkg/config"
 	"github.com/pipe-cd/pipe/pkg/model"
 )
 
@@ -88,12 +88,26 @@ func (p *Planner) Plan(ctx context.Context, in planner.Input) (out planner.Outpu
 		out.Version = version
 	}
 
+	// Render the diff from the running manifests to let the reviewers know what will be changed.
+	// Since it is only for reviewing, failing to render it does not fail the planning.
+	defer func() {
+		if err != nil {
+			return
+		}
+		d, e := renderDiff(ctx, in, cfg.Input, manifestCache, newManifests)
+		if e != nil {
+			in.Logger.Error("unable to render the diff of manifests", zap.Error(e))
+			return
+		}
+		out.Diff = d
+	}()
+
 	// If the deployment was triggered by forcing via web UI,
 	// we rely on the user's decision.
 	switch in.Deployment.Trigger.SyncStrategy {
 	case model.SyncStrategy_QUICK_SYNC:
 		out.Stages = buildQuickSyncPipeline(cfg.Input.AutoRollback, time.Now())
-		out.Summary = fmt.Sprintf("Quick sync by applying all manifests (forced via web)", out.Version)
+		out.Summary = "Quick sync by applying all manifests (forced via web)"
 		return
 	case model.SyncStrategy_PIPELINE:
 		if cfg.Pipeline == nil {
@@ -153,23 +167,9 @@ func (p *Planner) Plan(ctx context.Context, in planner.Input) (out planner.Outpu
 	}
 
 	// Load manifests of the previously applied commit.
-	oldManifests, ok := manifestCache.Get(in.MostRecentSuccessfulCommitHash)
-	if !ok {
-		// When the manifests were not in the cache we have to load them.
-		var runningDs *deploysource.DeploySource
-		runningDs, err = in.RunningDSP.Get(ctx, ioutil.Discard)
-		if err != nil {
-			err = fmt.Errorf("failed to prepare the running deploy source data (%v)", err)
-			return
-		}
-
-		loader := provider.NewManifestLoader(in.Deployment.ApplicationName, runningDs.AppDir, runningDs.RepoDir, in.Deployment.GitPath.ConfigFilename, cfg.Input, in.Logger)
-		oldManifests, err = loader.LoadManifests(ctx)
-		if err != nil {
-			err = fmt.Errorf("failed to load previously deployed manifests: %w", err)
-			return
-		}
-		manifestCache.Put(in.MostRecentSuccessfulCommitHash, oldManifests)
+	oldManifests, err := loadRunningManifests(ctx, in, cfg.Input, manifestCache)
+	if err != nil {
+		return
 	}
 
 	progressive, desc := decideStrategy(oldManifests, newManifests)
@@ -184,6 +184,41 @@ func (p *Planner) Plan(ctx context.Context, in planner.Input) (out planner.Outpu
 	return
 }
 
+// loadRunningManifests loads the manifests of the most recently successful commit.
+func loadRunningManifests(ctx context.Context, in planner.Input, input config.KubernetesDeploymentInput, manifestCache provider.AppManifestsCache) ([]provider.Manifest, error) {
+	manifests, ok := manifestCache.Get(in.MostRecentSuccessfulCommitHash)
+	if ok {
+		return manifests, nil
+	}
+
+	// When the manifests were not in the cache we have to load them.
+	runningDs, err := in.RunningDSP.Get(ctx, ioutil.Discard)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare the running deploy source data (%v)", err)
+	}
+
+	loader := provider.NewManifestLoader(in.Deployment.ApplicationName, runningDs.AppDir, runningDs.RepoDir, in.Deployment.GitPath.ConfigFilename, input, in.Logger)
+	manifests, err = loader.LoadManifests(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load previously deployed manifests: %w", err)
+	}
+	manifestCache.Put(in.MostRecentSuccessfulCommitHash, manifests)
+	return manifests, nil
+}
+
+// renderDiff renders the diff between the running and the given target manifests.
+// All target manifests are considered as added ones for the first deployment.
+func renderDiff(ctx context.Context, in planner.Input, input config.KubernetesDeploymentInput, manifestCache provider.AppManifestsCache, newManifests []provider.Manifest) (string, error) {
+	var oldManifests []provider.Manifest
+	if in.MostRecentSuccessfulCommitHash != "" {
+		var err error
+		if oldManifests, err = loadRunningManifests(ctx, in, input, manifestCache); err != nil {
+			return "", err
+		}
+	}
+	return renderManifestsDiff(oldManifests, newManifests, in.MostRecentSuccessfulCommitHash, in.Deployment.Trigger.Commit.Hash)
+}
+
 // First up, checks to see if the workload's `spec.template` has been changed,
 // and then checks if the configmap/secret's data.
 func decideStrategy(olds, news []provider.Manifest) (progressive bool, desc string) {
@@ -21,6 +21,7 @@ package planner
 
 import (
 	"context"
+	"strconv"
 	"strings"
 
 	"go.uber.org/zap"
@@ -51,14 +52,19 @@ type Output struct {
 	Version string
 	Stages  []*model.PipelineStage
 	Summary string
+	// The rendered diff between the running and target manifests.
+	// Empty means the planner does not support rendering it.
+	Diff string
 }
 
 // MakeInitialStageMetadata makes the initial metadata for the given state configuration.
 func MakeInitialStageMetadata(cfg config.PipelineStage) map[string]string {
 	switch cfg.Name {
 	case model.StageWaitApproval:
+		opts := cfg.WaitApprovalStageOptions
 		return map[string]string{
-			"Approvers": strings.Join(cfg.WaitApprovalStageOptions.Approvers, ","),
+			model.ApproversStageMetadataKey:      strings.Join(opts.Approvers, ","),
+			model.MinApproverNumStageMetadataKey: strconv.Itoa(opts.GetMinApproverNum()),
 		}
 	default:
 		return nil
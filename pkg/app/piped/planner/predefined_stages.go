@@ -25,6 +25,8 @@ const (
 	PredefinedStageCloudRunSync  = "CloudRunSync"
 	PredefinedStageLambdaSync    = "LambdaSync"
 	PredefinedStageRollback      = "Rollback"
+	PredefinedStagePreSyncHook   = "PreSyncHook"
+	PredefinedStagePostSyncHook  = "PostSyncHook"
 )
 
 var predefinedStages = map[string]config.PipelineStage{
@@ -53,6 +55,16 @@ var predefinedStages = map[string]config.PipelineStage{
 		Name: model.StageRollback,
 		Desc: "Rollback the deployment",
 	},
+	PredefinedStagePreSyncHook: {
+		Id:   PredefinedStagePreSyncHook,
+		Name: model.StageHook,
+		Desc: "Run the pre-sync hook",
+	},
+	PredefinedStagePostSyncHook: {
+		Id:   PredefinedStagePostSyncHook,
+		Name: model.StageHook,
+		Desc: "Run the post-sync hook",
+	},
 }
 
 // GetPredefinedStage finds and returns the predefined stage for the given id.
@@ -0,0 +1,201 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package resourceinspector provides a piped component
+// that reads the logs and events of the running resources on demand.
+package resourceinspector
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/kubernetes"
+	"github.com/pipe-cd/pipe/pkg/app/piped/livestatestore/kubernetes"
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+const (
+	checkCommandInterval = 5 * time.Second
+	commandTimeout       = 30 * time.Second
+	// The maximum size of the logs returned by a command
+	// to not overload the control-plane.
+	maxPodLogsBytes = 1024 * 1024
+)
+
+type commandLister interface {
+	ListApplicationCommands() []model.ReportableCommand
+}
+
+type liveStateGetter interface {
+	KubernetesGetter(cloudProvider string) (kubernetes.Getter, bool)
+}
+
+type Inspector struct {
+	commandLister   commandLister
+	liveStateGetter liveStateGetter
+	config          *config.PipedSpec
+	// The inspectors of all used Kubernetes cloud providers.
+	// This is accessed only by the Run goroutine.
+	kubernetesInspectors map[string]provider.Inspector
+	logger               *zap.Logger
+}
+
+// NewInspector creates a new Inspector instance.
+func NewInspector(cl commandLister, lsg liveStateGetter, cfg *config.PipedSpec, logger *zap.Logger) *Inspector {
+	return &Inspector{
+		commandLister:        cl,
+		liveStateGetter:      lsg,
+		config:               cfg,
+		kubernetesInspectors: make(map[string]provider.Inspector),
+		logger:               logger.Named("resource-inspector"),
+	}
+}
+
+// Run starts handling the GET_KUBERNETES_POD_LOGS and GET_KUBERNETES_RESOURCE_EVENTS commands.
+func (i *Inspector) Run(ctx context.Context) error {
+	i.logger.Info("start running resource inspector")
+
+	ticker := time.NewTicker(checkCommandInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			i.logger.Info("resource inspector has been stopped")
+			return nil
+		case <-ticker.C:
+			i.checkCommands(ctx)
+		}
+	}
+}
+
+func (i *Inspector) checkCommands(ctx context.Context) {
+	for _, cmd := range i.commandLister.ListApplicationCommands() {
+		var (
+			output []byte
+			err    error
+		)
+		switch {
+		case cmd.GetKubernetesPodLogs != nil:
+			output, err = i.getPodLogs(ctx, cmd.GetKubernetesPodLogs)
+		case cmd.GetKubernetesResourceEvents != nil:
+			output, err = i.getResourceEvents(ctx, cmd.GetKubernetesResourceEvents)
+		default:
+			continue
+		}
+
+		status := model.CommandStatus_COMMAND_SUCCEEDED
+		if err != nil {
+			i.logger.Error("failed to inspect application resource",
+				zap.String("app-id", cmd.ApplicationId),
+				zap.String("command-id", cmd.Id),
+				zap.Error(err),
+			)
+			status = model.CommandStatus_COMMAND_FAILED
+			// The error is returned as the output to let the commander know why it failed.
+			output = []byte(err.Error())
+		}
+
+		if err := cmd.Report(ctx, status, nil, output); err != nil {
+			i.logger.Error("failed to report command status", zap.Error(err))
+		}
+	}
+}
+
+func (i *Inspector) getPodLogs(ctx context.Context, cmd *model.Command_GetKubernetesPodLogs) ([]byte, error) {
+	key := provider.ResourceKey{
+		APIVersion: "v1",
+		Kind:       provider.KindPod,
+		Namespace:  cmd.Namespace,
+		Name:       cmd.PodName,
+	}
+	ki, err := i.getKubernetesInspector(cmd.CloudProvider, cmd.ApplicationId, key)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	return ki.PodLogs(ctx, cmd.Namespace, cmd.PodName, provider.PodLogsOptions{
+		Container:    cmd.ContainerName,
+		TailLines:    cmd.TailLines,
+		SinceSeconds: cmd.SinceSeconds,
+		Previous:     cmd.Previous,
+		LimitBytes:   maxPodLogsBytes,
+	})
+}
+
+func (i *Inspector) getResourceEvents(ctx context.Context, cmd *model.Command_GetKubernetesResourceEvents) ([]byte, error) {
+	key := provider.ResourceKey{
+		APIVersion: cmd.ApiVersion,
+		Kind:       cmd.Kind,
+		Namespace:  cmd.Namespace,
+		Name:       cmd.Name,
+	}
+	ki, err := i.getKubernetesInspector(cmd.CloudProvider, cmd.ApplicationId, key)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	return ki.Events(ctx, key)
+}
+
+// getKubernetesInspector returns the inspector for the given cloud provider
+// after ensuring that the given resource belongs to the application.
+// This prevents reading the data of the resources of other applications.
+func (i *Inspector) getKubernetesInspector(cloudProvider, appID string, key provider.ResourceKey) (provider.Inspector, error) {
+	cp, ok := i.config.FindCloudProvider(cloudProvider, model.CloudProviderKubernetes)
+	if !ok {
+		return nil, fmt.Errorf("cloud provider %s was not found", cloudProvider)
+	}
+
+	getter, ok := i.liveStateGetter.KubernetesGetter(cp.Name)
+	if !ok {
+		return nil, fmt.Errorf("no live state store for cloud provider %s", cp.Name)
+	}
+	state, ok := getter.GetKubernetesAppLiveState(appID)
+	if !ok || !containsResource(state.Resources, key) {
+		return nil, fmt.Errorf("resource %s was not found in application %s", key.ReadableString(), appID)
+	}
+
+	if ki, ok := i.kubernetesInspectors[cp.Name]; ok {
+		return ki, nil
+	}
+	ki := provider.NewInspector(cp.KubernetesConfig, i.logger)
+	i.kubernetesInspectors[cp.Name] = ki
+	return ki, nil
+}
+
+// containsResource reports whether the given resource is one of the given resource states.
+// The api version is compared only when it was specified.
+func containsResource(resources []*model.KubernetesResourceState, key provider.ResourceKey) bool {
+	for _, r := range resources {
+		if r.Kind != key.Kind || r.Name != key.Name || r.Namespace != key.Namespace {
+			continue
+		}
+		if key.APIVersion != "" && r.ApiVersion != key.APIVersion {
+			continue
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourceinspector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/kubernetes"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+func TestContainsResource(t *testing.T) {
+	resources := []*model.KubernetesResourceState{
+		{
+			ApiVersion: "apps/v1",
+			Kind:       "Deployment",
+			Namespace:  "default",
+			Name:       "simple",
+		},
+		{
+			ApiVersion: "v1",
+			Kind:       "Pod",
+			Namespace:  "default",
+			Name:       "simple-6d8c7b9f4-x2k8p",
+		},
+	}
+
+	testcases := []struct {
+		name     string
+		key      provider.ResourceKey
+		expected bool
+	}{
+		{
+			name: "pod of the application",
+			key: provider.ResourceKey{
+				APIVersion: "v1",
+				Kind:       "Pod",
+				Namespace:  "default",
+				Name:       "simple-6d8c7b9f4-x2k8p",
+			},
+			expected: true,
+		},
+		{
+			name: "no api version specified",
+			key: provider.ResourceKey{
+				Kind:      "Deployment",
+				Namespace: "default",
+				Name:      "simple",
+			},
+			expected: true,
+		},
+		{
+			name: "different namespace",
+			key: provider.ResourceKey{
+				APIVersion: "v1",
+				Kind:       "Pod",
+				Namespace:  "kube-system",
+				Name:       "simple-6d8c7b9f4-x2k8p",
+			},
+			expected: false,
+		},
+		{
+			name: "different api version",
+			key: provider.ResourceKey{
+				APIVersion: "apps/v1beta1",
+				Kind:       "Deployment",
+				Namespace:  "default",
+				Name:       "simple",
+			},
+			expected: false,
+		},
+		{
+			name: "pod of another application",
+			key: provider.ResourceKey{
+				APIVersion: "v1",
+				Kind:       "Pod",
+				Namespace:  "default",
+				Name:       "other",
+			},
+			expected: false,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := containsResource(resources, tc.key)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
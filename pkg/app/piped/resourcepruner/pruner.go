@@ -0,0 +1,147 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package resourcepruner provides a piped component
+// that removes the running resources of the deleted applications.
+package resourcepruner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/kubernetes"
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+const (
+	checkCommandInterval = 10 * time.Second
+	prunedResourcesKey   = "PrunedResources"
+)
+
+type commandLister interface {
+	ListApplicationCommands() []model.ReportableCommand
+}
+
+type liveResourceLister interface {
+	ListKubernetesAppLiveResources(cloudProvider, appID string) ([]provider.Manifest, bool)
+}
+
+type resourceDeleter interface {
+	Delete(ctx context.Context, key provider.ResourceKey) error
+}
+
+type Pruner struct {
+	commandLister      commandLister
+	liveResourceLister liveResourceLister
+	config             *config.PipedSpec
+	newDeleter         func(cfg *config.CloudProviderKubernetesConfig) resourceDeleter
+	logger             *zap.Logger
+}
+
+// NewPruner creates a new Pruner instance.
+func NewPruner(cl commandLister, lrl liveResourceLister, cfg *config.PipedSpec, logger *zap.Logger) *Pruner {
+	p := &Pruner{
+		commandLister:      cl,
+		liveResourceLister: lrl,
+		config:             cfg,
+		logger:             logger.Named("resource-pruner"),
+	}
+	p.newDeleter = func(cfg *config.CloudProviderKubernetesConfig) resourceDeleter {
+		return provider.NewProvider("", "", "", "", config.KubernetesDeploymentInput{}, p.logger, provider.WithClusterConfig(cfg))
+	}
+	return p
+}
+
+// Run starts handling the PRUNE_APPLICATION_RESOURCES commands.
+func (p *Pruner) Run(ctx context.Context) error {
+	p.logger.Info("start running resource pruner")
+
+	ticker := time.NewTicker(checkCommandInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			p.logger.Info("resource pruner has been stopped")
+			return nil
+		case <-ticker.C:
+			p.checkCommands(ctx)
+		}
+	}
+}
+
+func (p *Pruner) checkCommands(ctx context.Context) {
+	for _, cmd := range p.commandLister.ListApplicationCommands() {
+		pruneCmd := cmd.GetPruneApplicationResources()
+		if pruneCmd == nil {
+			continue
+		}
+
+		status := model.CommandStatus_COMMAND_SUCCEEDED
+		num, err := p.prune(ctx, pruneCmd)
+		if err != nil {
+			p.logger.Error("failed to prune application resources",
+				zap.String("app-id", pruneCmd.ApplicationId),
+				zap.Error(err),
+			)
+			status = model.CommandStatus_COMMAND_FAILED
+		}
+
+		metadata := map[string]string{
+			prunedResourcesKey: fmt.Sprintf("%d", num),
+		}
+		if err := cmd.Report(ctx, status, metadata, nil); err != nil {
+			p.logger.Error("failed to report command status", zap.Error(err))
+		}
+	}
+}
+
+// prune deletes all live resources of the given application
+// and returns the number of the deleted ones.
+func (p *Pruner) prune(ctx context.Context, cmd *model.Command_PruneApplicationResources) (int, error) {
+	cp, ok := p.config.FindCloudProvider(cmd.CloudProvider, model.CloudProviderKubernetes)
+	if !ok {
+		return 0, fmt.Errorf("cloud provider %s was not found", cmd.CloudProvider)
+	}
+
+	manifests, ok := p.liveResourceLister.ListKubernetesAppLiveResources(cp.Name, cmd.ApplicationId)
+	if !ok {
+		return 0, fmt.Errorf("no live state store for cloud provider %s", cp.Name)
+	}
+
+	var (
+		// The resources are deleted from the cluster of the cloud provider
+		// the same as the live state store watching them.
+		kp      = p.newDeleter(cp.KubernetesConfig)
+		deleted = 0
+	)
+	for _, m := range manifests {
+		err := kp.Delete(ctx, m.Key)
+		if err == nil {
+			p.logger.Info(fmt.Sprintf("deleted resource %s of application %s", m.Key.ReadableString(), cmd.ApplicationId))
+			deleted++
+			continue
+		}
+		if errors.Is(err, provider.ErrNotFound) {
+			continue
+		}
+		return deleted, fmt.Errorf("failed to delete resource %s: %w", m.Key.ReadableString(), err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,245 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourcepruner
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	provider "github.com/pipe-cd/pipe/pkg/app/piped/cloudprovider/kubernetes"
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+type fakeCommandLister struct {
+	commands []model.ReportableCommand
+}
+
+func (l *fakeCommandLister) ListApplicationCommands() []model.ReportableCommand {
+	return l.commands
+}
+
+type fakeLiveResourceLister struct {
+	cloudProvider string
+	manifests     map[string][]provider.Manifest
+}
+
+func (l *fakeLiveResourceLister) ListKubernetesAppLiveResources(cloudProvider, appID string) ([]provider.Manifest, bool) {
+	if cloudProvider != l.cloudProvider {
+		return nil, false
+	}
+	return l.manifests[appID], true
+}
+
+type fakeDeleter struct {
+	cfg      *config.CloudProviderKubernetesConfig
+	existing map[provider.ResourceKey]bool
+	failed   map[provider.ResourceKey]bool
+	deleted  []provider.ResourceKey
+}
+
+func (d *fakeDeleter) Delete(_ context.Context, key provider.ResourceKey) error {
+	if d.failed[key] {
+		return errors.New("forbidden")
+	}
+	if !d.existing[key] {
+		return provider.ErrNotFound
+	}
+	d.deleted = append(d.deleted, key)
+	return nil
+}
+
+func TestPrune(t *testing.T) {
+	var (
+		deployment = provider.ResourceKey{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "default", Name: "simple"}
+		service    = provider.ResourceKey{APIVersion: "v1", Kind: "Service", Namespace: "default", Name: "simple"}
+		configMap  = provider.ResourceKey{APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: "simple"}
+		clusterCfg = &config.CloudProviderKubernetesConfig{MasterURL: "https://cluster.example.com"}
+	)
+	cfg := &config.PipedSpec{
+		CloudProviders: []config.PipedCloudProvider{
+			{
+				Name:             "kubernetes-dev",
+				Type:             model.CloudProviderKubernetes,
+				KubernetesConfig: clusterCfg,
+			},
+			{
+				Name:            "terraform",
+				Type:            model.CloudProviderTerraform,
+				TerraformConfig: &config.CloudProviderTerraformConfig{},
+			},
+		},
+	}
+	lister := &fakeLiveResourceLister{
+		cloudProvider: "kubernetes-dev",
+		manifests: map[string][]provider.Manifest{
+			"app-1": {
+				provider.MakeManifest(deployment, nil),
+				provider.MakeManifest(service, nil),
+				provider.MakeManifest(configMap, nil),
+			},
+		},
+	}
+
+	testcases := []struct {
+		name            string
+		cmd             *model.Command_PruneApplicationResources
+		failed          map[provider.ResourceKey]bool
+		expectedDeleted []provider.ResourceKey
+		expectedNum     int
+		expectedErr     bool
+	}{
+		{
+			name: "delete the remaining resources",
+			cmd: &model.Command_PruneApplicationResources{
+				ApplicationId: "app-1",
+				CloudProvider: "kubernetes-dev",
+			},
+			expectedDeleted: []provider.ResourceKey{deployment, service},
+			expectedNum:     2,
+		},
+		{
+			name: "stop at the first failure",
+			cmd: &model.Command_PruneApplicationResources{
+				ApplicationId: "app-1",
+				CloudProvider: "kubernetes-dev",
+			},
+			failed:          map[provider.ResourceKey]bool{service: true},
+			expectedDeleted: []provider.ResourceKey{deployment},
+			expectedNum:     1,
+			expectedErr:     true,
+		},
+		{
+			name: "not a kubernetes cloud provider",
+			cmd: &model.Command_PruneApplicationResources{
+				ApplicationId: "app-1",
+				CloudProvider: "terraform",
+			},
+			expectedErr: true,
+		},
+		{
+			name: "unknown cloud provider",
+			cmd: &model.Command_PruneApplicationResources{
+				ApplicationId: "app-1",
+				CloudProvider: "unknown",
+			},
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			deleter := &fakeDeleter{
+				existing: map[provider.ResourceKey]bool{deployment: true, service: true},
+				failed:   tc.failed,
+			}
+			p := NewPruner(&fakeCommandLister{}, lister, cfg, zap.NewNop())
+			p.newDeleter = func(cfg *config.CloudProviderKubernetesConfig) resourceDeleter {
+				deleter.cfg = cfg
+				return deleter
+			}
+
+			num, err := p.prune(context.Background(), tc.cmd)
+			assert.Equal(t, tc.expectedErr, err != nil)
+			assert.Equal(t, tc.expectedNum, num)
+			assert.Equal(t, tc.expectedDeleted, deleter.deleted)
+			if tc.expectedNum > 0 {
+				// The resources must be deleted from the cluster of the cloud provider.
+				assert.Same(t, clusterCfg, deleter.cfg)
+			}
+		})
+	}
+}
+
+func TestCheckCommands(t *testing.T) {
+	key := provider.ResourceKey{APIVersion: "v1", Kind: "Service", Namespace: "default", Name: "simple"}
+	cfg := &config.PipedSpec{
+		CloudProviders: []config.PipedCloudProvider{
+			{
+				Name:             "kubernetes-dev",
+				Type:             model.CloudProviderKubernetes,
+				KubernetesConfig: &config.CloudProviderKubernetesConfig{},
+			},
+		},
+	}
+
+	type report struct {
+		status   model.CommandStatus
+		metadata map[string]string
+	}
+	var reports []report
+	newCommand := func(cmd *model.Command) model.ReportableCommand {
+		return model.ReportableCommand{
+			Command: cmd,
+			Report: func(_ context.Context, status model.CommandStatus, metadata map[string]string, _ []byte) error {
+				reports = append(reports, report{status: status, metadata: metadata})
+				return nil
+			},
+		}
+	}
+	cl := &fakeCommandLister{
+		commands: []model.ReportableCommand{
+			newCommand(&model.Command{
+				Id:   "sync",
+				Type: model.Command_SYNC_APPLICATION,
+				SyncApplication: &model.Command_SyncApplication{
+					ApplicationId: "app-1",
+				},
+			}),
+			newCommand(&model.Command{
+				Id:   "prune",
+				Type: model.Command_PRUNE_APPLICATION_RESOURCES,
+				PruneApplicationResources: &model.Command_PruneApplicationResources{
+					ApplicationId: "app-1",
+					CloudProvider: "kubernetes-dev",
+				},
+			}),
+			newCommand(&model.Command{
+				Id:   "prune-unknown",
+				Type: model.Command_PRUNE_APPLICATION_RESOURCES,
+				PruneApplicationResources: &model.Command_PruneApplicationResources{
+					ApplicationId: "app-2",
+					CloudProvider: "unknown",
+				},
+			}),
+		},
+	}
+	lister := &fakeLiveResourceLister{
+		cloudProvider: "kubernetes-dev",
+		manifests: map[string][]provider.Manifest{
+			"app-1": {provider.MakeManifest(key, nil)},
+		},
+	}
+	p := NewPruner(cl, lister, cfg, zap.NewNop())
+	p.newDeleter = func(*config.CloudProviderKubernetesConfig) resourceDeleter {
+		return &fakeDeleter{existing: map[provider.ResourceKey]bool{key: true}}
+	}
+
+	p.checkCommands(context.Background())
+
+	require.Len(t, reports, 2)
+	assert.Equal(t, report{
+		status:   model.CommandStatus_COMMAND_SUCCEEDED,
+		metadata: map[string]string{prunedResourcesKey: "1"},
+	}, reports[0])
+	assert.Equal(t, report{
+		status:   model.CommandStatus_COMMAND_FAILED,
+		metadata: map[string]string{prunedResourcesKey: "0"},
+	}, reports[1])
+}
@@ -0,0 +1,242 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trigger
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/pipe-cd/pipe/pkg/git"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+type syncStateChange struct {
+	applicationID string
+	state         *model.ApplicationSyncState
+}
+
+// autoSyncState keeps track of the automatic syncs
+// made for the running commit of an application.
+type autoSyncState struct {
+	commitHash      string
+	attempts        int
+	lastTriggeredAt time.Time
+	// The OUT_OF_SYNC state which is waiting to be handled.
+	// It is kept while the cooldown is not passed yet.
+	pending *model.ApplicationSyncState
+	// The reason why the pending sync was postponed last time.
+	postponedReason string
+}
+
+// observe resets the counters when the application is running at another commit.
+// Since the counters are not kept over the restarts of piped, they are restored
+// from the recent deployments of the application which were triggered by the automatic sync.
+func (s *autoSyncState) observe(app *model.Application, commitHash string) {
+	if s.commitHash == commitHash {
+		return
+	}
+	s.commitHash = commitHash
+	s.attempts = 0
+	s.lastTriggeredAt = time.Time{}
+
+	for _, d := range []*model.ApplicationDeploymentReference{
+		app.MostRecentlyTriggeredDeployment,
+		app.MostRecentlySuccessfulDeployment,
+	} {
+		trigger := d.GetTrigger()
+		if trigger.GetCommit().GetHash() != commitHash || int(trigger.GetAutoSyncAttempt()) <= s.attempts {
+			continue
+		}
+		s.attempts = int(trigger.AutoSyncAttempt)
+		s.lastTriggeredAt = time.Unix(trigger.Timestamp, 0)
+	}
+}
+
+// postpone logs the reason why the pending sync was postponed.
+// The same reason is logged only once to not repeat it on every check.
+func (s *autoSyncState) postpone(logger *zap.Logger, reason string, fields ...zap.Field) {
+	if s.postponedReason == reason {
+		return
+	}
+	s.postponedReason = reason
+	logger.Info(reason, fields...)
+}
+
+func (s *autoSyncState) inCooldown(cooldown time.Duration, now time.Time) bool {
+	return !s.lastTriggeredAt.IsZero() && now.Sub(s.lastTriggeredAt) < cooldown
+}
+
+// NotifySyncState passes the sync state of an application detected by the drift detector.
+// When the application is OUT_OF_SYNC and its autoSync is enabled,
+// a quick sync at the running commit will be triggered.
+func (t *Trigger) NotifySyncState(ctx context.Context, appID string, state *model.ApplicationSyncState) error {
+	select {
+	case t.syncStateCh <- syncStateChange{applicationID: appID, state: state}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (t *Trigger) handleSyncState(ctx context.Context, c syncStateChange) {
+	switch c.state.Status {
+	case model.ApplicationSyncStatus_SYNCED:
+		if s, ok := t.autoSyncStates[c.applicationID]; ok {
+			s.pending = nil
+			s.postponedReason = ""
+		}
+
+	case model.ApplicationSyncStatus_OUT_OF_SYNC:
+		s, ok := t.autoSyncStates[c.applicationID]
+		if !ok {
+			s = &autoSyncState{}
+			t.autoSyncStates[c.applicationID] = s
+		}
+		s.pending = c.state
+		if err := t.autoSyncApplication(ctx, c.applicationID, s, true); err != nil {
+			t.logger.Error("failed to sync out-of-sync application automatically",
+				zap.String("app-id", c.applicationID),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// checkPendingAutoSyncs retries the automatic syncs those were postponed by the cooldown.
+func (t *Trigger) checkPendingAutoSyncs(ctx context.Context) {
+	for appID, s := range t.autoSyncStates {
+		if s.pending == nil {
+			continue
+		}
+		if err := t.autoSyncApplication(ctx, appID, s, false); err != nil {
+			t.logger.Error("failed to sync out-of-sync application automatically",
+				zap.String("app-id", appID),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// autoSyncApplication triggers a quick sync at the running commit of the given application
+// to fix its configuration drift. detected indicates whether the drift was just reported
+// or it is a retry of the postponed one.
+func (t *Trigger) autoSyncApplication(ctx context.Context, appID string, s *autoSyncState, detected bool) error {
+	app, ok := t.applicationLister.Get(appID)
+	if !ok {
+		delete(t.autoSyncStates, appID)
+		return nil
+	}
+	logger := t.logger.With(
+		zap.String("app", app.Name),
+		zap.String("app-id", app.Id),
+	)
+
+	running := app.MostRecentlySuccessfulDeployment
+	if running == nil || running.Trigger == nil || running.Trigger.Commit == nil {
+		logger.Info("skip automatic sync since the application has no running commit")
+		s.pending = nil
+		return nil
+	}
+
+	repo, ok := t.gitRepos[app.GitPath.Repo.Id]
+	if !ok {
+		s.pending = nil
+		return fmt.Errorf("missing repository")
+	}
+	cfg, err := loadDeploymentConfiguration(repo.GetPath(), app)
+	if err != nil {
+		s.pending = nil
+		return err
+	}
+	if cfg.AutoSync == nil || !cfg.AutoSync.Enabled {
+		s.pending = nil
+		return nil
+	}
+
+	commit := running.Trigger.Commit
+	s.observe(app, commit.Hash)
+	maxAttempts := cfg.AutoSync.GetMaxAttempts()
+
+	// The drift came back even though it was fixed by the previous automatic sync.
+	// That usually means someone keeps editing the live resources manually.
+	if detected && s.attempts > 0 {
+		t.notifyDriftRepeated(app, s.pending, s.attempts, maxAttempts)
+	}
+	if s.attempts >= maxAttempts {
+		logger.Info(fmt.Sprintf("skip automatic sync since it was already done %d times for the running commit", s.attempts),
+			zap.String("running-commit", commit.Hash),
+		)
+		s.pending = nil
+		return nil
+	}
+
+	// Another deployment is being handled or it was failed,
+	// so syncing to the running commit may conflict with it.
+	if triggered := app.MostRecentlyTriggeredDeployment; triggered != nil && triggered.DeploymentId != running.DeploymentId {
+		s.postpone(logger, "postpone automatic sync since the most recently triggered deployment is not the running one",
+			zap.String("deployment-id", triggered.DeploymentId),
+		)
+		return nil
+	}
+
+	now := time.Now()
+	if s.inCooldown(cfg.AutoSync.GetCooldown(), now) {
+		s.postpone(logger, "postpone automatic sync until the cooldown has passed",
+			zap.Time("last-triggered-at", s.lastTriggeredAt),
+		)
+		return nil
+	}
+
+	reason := fmt.Sprintf("Automatic sync at the running commit to fix the configuration drift (attempt %d/%d)", s.attempts+1, maxAttempts)
+	logger.Info("application will be synced because of the configuration drift",
+		zap.String("running-commit", commit.Hash),
+		zap.String("drift-reason", s.pending.GetShortReason()),
+	)
+	c := git.Commit{
+		Hash:      commit.Hash,
+		Message:   commit.Message,
+		Author:    commit.Author,
+		CreatedAt: int(commit.CreatedAt),
+	}
+	if _, err := t.triggerDeployment(ctx, app, repo, commit.Branch, c, "", model.SyncStrategy_QUICK_SYNC, "", 0, reason, s.attempts+1); err != nil {
+		return err
+	}
+
+	s.attempts++
+	s.lastTriggeredAt = now
+	s.pending = nil
+	s.postponedReason = ""
+	return nil
+}
+
+func (t *Trigger) notifyDriftRepeated(app *model.Application, state *model.ApplicationSyncState, attempts, maxAttempts int) {
+	var envName string
+	if env, ok := t.environmentLister.Get(app.EnvId); ok {
+		envName = env.Name
+	}
+	t.notifier.Notify(model.Event{
+		Type: model.EventType_EVENT_APPLICATION_DRIFT_REPEATED,
+		Metadata: &model.EventApplicationDriftRepeated{
+			Application:         app,
+			EnvName:             envName,
+			State:               state,
+			AutoSyncAttempts:    int32(attempts),
+			MaxAutoSyncAttempts: int32(maxAttempts),
+		},
+	})
+}
@@ -0,0 +1,320 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trigger
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+
+	"github.com/pipe-cd/pipe/pkg/app/api/service/pipedservice"
+	"github.com/pipe-cd/pipe/pkg/config"
+	"github.com/pipe-cd/pipe/pkg/git"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+type fakeAPIClient struct {
+	apiClient
+	deployments []*model.Deployment
+}
+
+func (c *fakeAPIClient) CreateDeployment(_ context.Context, req *pipedservice.CreateDeploymentRequest, _ ...grpc.CallOption) (*pipedservice.CreateDeploymentResponse, error) {
+	c.deployments = append(c.deployments, req.Deployment)
+	return &pipedservice.CreateDeploymentResponse{}, nil
+}
+
+func (c *fakeAPIClient) ReportApplicationMostRecentDeployment(_ context.Context, _ *pipedservice.ReportApplicationMostRecentDeploymentRequest, _ ...grpc.CallOption) (*pipedservice.ReportApplicationMostRecentDeploymentResponse, error) {
+	return &pipedservice.ReportApplicationMostRecentDeploymentResponse{}, nil
+}
+
+type fakeRepo struct {
+	git.Repo
+	path string
+}
+
+func (r *fakeRepo) GetPath() string {
+	return r.path
+}
+
+type fakeApplicationLister struct {
+	apps map[string]*model.Application
+}
+
+func (l *fakeApplicationLister) Get(id string) (*model.Application, bool) {
+	app, ok := l.apps[id]
+	return app, ok
+}
+
+func (l *fakeApplicationLister) List() []*model.Application {
+	apps := make([]*model.Application, 0, len(l.apps))
+	for _, app := range l.apps {
+		apps = append(apps, app)
+	}
+	return apps
+}
+
+type fakeEnvironmentLister struct{}
+
+func (fakeEnvironmentLister) Get(id string) (*model.Environment, bool) {
+	return &model.Environment{Id: id, Name: "dev"}, true
+}
+
+type fakeNotifier struct {
+	events []model.Event
+}
+
+func (n *fakeNotifier) Notify(event model.Event) {
+	n.events = append(n.events, event)
+}
+
+const autoSyncTestConfig = `
+apiVersion: pipecd.dev/v1beta1
+kind: KubernetesApp
+spec:
+  autoSync:
+    enabled: true
+    cooldown: 10m
+    maxAttempts: 2
+`
+
+func TestAutoSyncApplication(t *testing.T) {
+	repoDir, err := ioutil.TempDir("", "autosync")
+	require.NoError(t, err)
+	defer os.RemoveAll(repoDir)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(repoDir, "demo"), 0755))
+	err = ioutil.WriteFile(filepath.Join(repoDir, "demo", model.DefaultDeploymentConfigFileName), []byte(autoSyncTestConfig), 0644)
+	require.NoError(t, err)
+
+	running := &model.ApplicationDeploymentReference{
+		DeploymentId: "deployment-1",
+		Trigger: &model.DeploymentTrigger{
+			Commit: &model.Commit{
+				Hash:   "running-commit",
+				Branch: "master",
+			},
+		},
+	}
+	app := &model.Application{
+		Id:   "app-1",
+		Name: "demo",
+		Kind: model.ApplicationKind_KUBERNETES,
+		GitPath: &model.ApplicationGitPath{
+			Repo: &model.ApplicationGitRepository{
+				Id:     "repo-1",
+				Remote: "git@github.com:org/repo.git",
+			},
+			Path: "demo",
+		},
+		MostRecentlySuccessfulDeployment: running,
+		MostRecentlyTriggeredDeployment:  running,
+	}
+
+	var (
+		client   = &fakeAPIClient{}
+		notifier = &fakeNotifier{}
+		tr       = &Trigger{
+			apiClient:         client,
+			applicationLister: &fakeApplicationLister{apps: map[string]*model.Application{app.Id: app}},
+			environmentLister: fakeEnvironmentLister{},
+			notifier:          notifier,
+			config:            &config.PipedSpec{},
+			gitRepos:          map[string]git.Repo{"repo-1": &fakeRepo{path: repoDir}},
+			autoSyncStates:    make(map[string]*autoSyncState),
+			logger:            zap.NewNop(),
+		}
+		ctx       = context.Background()
+		outOfSync = syncStateChange{
+			applicationID: app.Id,
+			state: &model.ApplicationSyncState{
+				Status:      model.ApplicationSyncStatus_OUT_OF_SYNC,
+				ShortReason: "There is a diff in Deployment demo",
+			},
+		}
+	)
+
+	// The first drift triggers a quick sync at the running commit.
+	tr.handleSyncState(ctx, outOfSync)
+	require.Len(t, client.deployments, 1)
+	d := client.deployments[0]
+	assert.Equal(t, "running-commit", d.Trigger.Commit.Hash)
+	assert.Equal(t, "master", d.Trigger.Commit.Branch)
+	assert.Equal(t, model.SyncStrategy_QUICK_SYNC, d.Trigger.SyncStrategy)
+	assert.Contains(t, d.Trigger.Reason, "configuration drift")
+	assert.Equal(t, int32(1), d.Trigger.AutoSyncAttempt)
+	require.Len(t, notifier.events, 1)
+	assert.Equal(t, model.EventType_EVENT_DEPLOYMENT_TRIGGERED, notifier.events[0].Type)
+
+	// The drift came back within the cooldown.
+	tr.handleSyncState(ctx, outOfSync)
+	assert.Len(t, client.deployments, 1)
+	require.Len(t, notifier.events, 2)
+	assert.Equal(t, model.EventType_EVENT_APPLICATION_DRIFT_REPEATED, notifier.events[1].Type)
+	md := notifier.events[1].Metadata.(*model.EventApplicationDriftRepeated)
+	assert.Equal(t, int32(1), md.AutoSyncAttempts)
+	assert.Equal(t, int32(2), md.MaxAutoSyncAttempts)
+	assert.NotNil(t, tr.autoSyncStates[app.Id].pending)
+
+	// The postponed sync is triggered once the cooldown has passed.
+	tr.autoSyncStates[app.Id].lastTriggeredAt = time.Now().Add(-time.Hour)
+	tr.checkPendingAutoSyncs(ctx)
+	require.Len(t, client.deployments, 2)
+	assert.Equal(t, int32(2), client.deployments[1].Trigger.AutoSyncAttempt)
+	assert.Nil(t, tr.autoSyncStates[app.Id].pending)
+
+	// No more sync after reaching the maximum attempts.
+	tr.autoSyncStates[app.Id].lastTriggeredAt = time.Now().Add(-time.Hour)
+	tr.handleSyncState(ctx, outOfSync)
+	assert.Len(t, client.deployments, 2)
+	assert.Equal(t, model.EventType_EVENT_APPLICATION_DRIFT_REPEATED, notifier.events[len(notifier.events)-1].Type)
+	assert.Nil(t, tr.autoSyncStates[app.Id].pending)
+
+	// The attempts are reset once the application was deployed with another commit.
+	app.MostRecentlySuccessfulDeployment = &model.ApplicationDeploymentReference{
+		DeploymentId: "deployment-2",
+		Trigger: &model.DeploymentTrigger{
+			Commit: &model.Commit{Hash: "new-commit"},
+		},
+	}
+	app.MostRecentlyTriggeredDeployment = app.MostRecentlySuccessfulDeployment
+	tr.handleSyncState(ctx, outOfSync)
+	require.Len(t, client.deployments, 3)
+	assert.Equal(t, "new-commit", client.deployments[2].Trigger.Commit.Hash)
+}
+
+func TestAutoSyncApplicationDisabled(t *testing.T) {
+	repoDir, err := ioutil.TempDir("", "autosync")
+	require.NoError(t, err)
+	defer os.RemoveAll(repoDir)
+
+	cfg := "apiVersion: pipecd.dev/v1beta1\nkind: KubernetesApp\nspec: {}\n"
+	err = ioutil.WriteFile(filepath.Join(repoDir, model.DefaultDeploymentConfigFileName), []byte(cfg), 0644)
+	require.NoError(t, err)
+
+	running := &model.ApplicationDeploymentReference{
+		DeploymentId: "deployment-1",
+		Trigger: &model.DeploymentTrigger{
+			Commit: &model.Commit{Hash: "running-commit"},
+		},
+	}
+	app := &model.Application{
+		Id:   "app-1",
+		Kind: model.ApplicationKind_KUBERNETES,
+		GitPath: &model.ApplicationGitPath{
+			Repo: &model.ApplicationGitRepository{
+				Id:     "repo-1",
+				Remote: "git@github.com:org/repo.git",
+			},
+		},
+		MostRecentlySuccessfulDeployment: running,
+	}
+	client := &fakeAPIClient{}
+	tr := &Trigger{
+		apiClient:         client,
+		applicationLister: &fakeApplicationLister{apps: map[string]*model.Application{app.Id: app}},
+		environmentLister: fakeEnvironmentLister{},
+		notifier:          &fakeNotifier{},
+		gitRepos:          map[string]git.Repo{"repo-1": &fakeRepo{path: repoDir}},
+		autoSyncStates:    make(map[string]*autoSyncState),
+		logger:            zap.NewNop(),
+	}
+
+	tr.handleSyncState(context.Background(), syncStateChange{
+		applicationID: app.Id,
+		state:         &model.ApplicationSyncState{Status: model.ApplicationSyncStatus_OUT_OF_SYNC},
+	})
+	assert.Empty(t, client.deployments)
+	assert.Nil(t, tr.autoSyncStates[app.Id].pending)
+}
+
+func TestAutoSyncStateObserve(t *testing.T) {
+	now := time.Now()
+	s := &autoSyncState{
+		commitHash:      "commit-1",
+		attempts:        2,
+		lastTriggeredAt: now,
+	}
+
+	app := &model.Application{}
+
+	s.observe(app, "commit-1")
+	assert.Equal(t, 2, s.attempts)
+	assert.True(t, s.inCooldown(time.Minute, now.Add(30*time.Second)))
+	assert.False(t, s.inCooldown(time.Minute, now.Add(2*time.Minute)))
+
+	s.observe(app, "commit-2")
+	assert.Equal(t, "commit-2", s.commitHash)
+	assert.Equal(t, 0, s.attempts)
+	assert.False(t, s.inCooldown(time.Minute, now))
+}
+
+func TestAutoSyncStateObserveRestore(t *testing.T) {
+	triggeredAt := time.Now().Add(-30 * time.Second).Truncate(time.Second)
+	app := &model.Application{
+		MostRecentlySuccessfulDeployment: &model.ApplicationDeploymentReference{
+			DeploymentId: "deployment-1",
+			Trigger: &model.DeploymentTrigger{
+				Commit:          &model.Commit{Hash: "commit-1"},
+				Timestamp:       triggeredAt.Add(-time.Hour).Unix(),
+				AutoSyncAttempt: 1,
+			},
+		},
+		MostRecentlyTriggeredDeployment: &model.ApplicationDeploymentReference{
+			DeploymentId: "deployment-2",
+			Trigger: &model.DeploymentTrigger{
+				Commit:          &model.Commit{Hash: "commit-1"},
+				Timestamp:       triggeredAt.Unix(),
+				AutoSyncAttempt: 2,
+			},
+		},
+	}
+
+	// The counters are restored from the deployments after restarting.
+	s := &autoSyncState{}
+	s.observe(app, "commit-1")
+	assert.Equal(t, 2, s.attempts)
+	assert.Equal(t, triggeredAt, s.lastTriggeredAt)
+	assert.True(t, s.inCooldown(time.Minute, time.Now()))
+
+	// The deployments at another commit are not counted.
+	s = &autoSyncState{}
+	s.observe(app, "commit-2")
+	assert.Equal(t, 0, s.attempts)
+	assert.True(t, s.lastTriggeredAt.IsZero())
+}
+
+func TestAutoSyncStatePostpone(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	logger := zap.New(core)
+	s := &autoSyncState{}
+
+	s.postpone(logger, "reason-1")
+	s.postpone(logger, "reason-1")
+	s.postpone(logger, "reason-2")
+
+	// The same reason is logged only once.
+	require.Equal(t, 2, logs.Len())
+	assert.Equal(t, "reason-1", logs.All()[0].Message)
+	assert.Equal(t, "reason-2", logs.All()[1].Message)
+}
@@ -21,6 +21,7 @@ import (
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/pipe-cd/pipe/pkg/app/api/service/pipedservice"
 	"github.com/pipe-cd/pipe/pkg/git"
@@ -30,15 +31,26 @@ import (
 func (t *Trigger) triggerDeployment(
 	ctx context.Context,
 	app *model.Application,
+	repo git.Repo,
 	branch string,
 	commit git.Commit,
 	commander string,
 	syncStrategy model.SyncStrategy,
+	chainID string,
+	chainBlockIndex uint32,
+	reason string,
+	autoSyncAttempt int,
 ) (deployment *model.Deployment, err error) {
+	app = t.syncApplicationLabels(ctx, app, repo.GetPath())
+
 	deployment, err = buildDeployment(app, branch, commit, commander, syncStrategy, time.Now())
 	if err != nil {
 		return
 	}
+	deployment.DeploymentChainId = chainID
+	deployment.DeploymentChainBlockIndex = chainBlockIndex
+	deployment.Trigger.Reason = reason
+	deployment.Trigger.AutoSyncAttempt = int32(autoSyncAttempt)
 
 	defer func() {
 		if err != nil {
@@ -77,6 +89,73 @@ func (t *Trigger) triggerDeployment(
 	return
 }
 
+// syncApplicationLabels reports the labels specified in the deployment configuration
+// when they are different from the registered ones or the last reported ones.
+// It gives back the application with the labels which should be used for the deployment.
+func (t *Trigger) syncApplicationLabels(ctx context.Context, app *model.Application, repoPath string) *model.Application {
+	cfg, err := loadDeploymentConfiguration(repoPath, app)
+	if err != nil {
+		t.logger.Warn("unable to load deployment configuration to check the application labels",
+			zap.String("app-id", app.Id),
+			zap.Error(err),
+		)
+		return app
+	}
+	last, reported := t.reportedLabels[app.Id]
+	switch {
+	case reported:
+		// The control-plane does not apply the labels used by the RBAC role bindings
+		// so they are compared with the last reported ones instead of the registered ones.
+		// This also allows clearing the labels by removing them from the configuration.
+		if equalLabels(cfg.Labels, last) {
+			return app
+		}
+	case len(cfg.Labels) == 0:
+		// Keep the labels given from the web console
+		// while no label has been specified in the configuration.
+		return app
+	case equalLabels(cfg.Labels, app.Labels):
+		return app
+	}
+
+	req := &pipedservice.ReportApplicationLabelsRequest{
+		ApplicationId: app.Id,
+		Labels:        cfg.Labels,
+	}
+	resp, err := t.apiClient.ReportApplicationLabels(ctx, req)
+	if err != nil {
+		t.logger.Error("failed to report application labels",
+			zap.String("app-id", app.Id),
+			zap.Error(err),
+		)
+		return app
+	}
+	t.reportedLabels[app.Id] = cfg.Labels
+	if len(resp.IgnoredKeys) > 0 {
+		t.logger.Warn("some labels specified in the deployment configuration were not applied because they are used by the RBAC role bindings",
+			zap.String("app-id", app.Id),
+			zap.Strings("keys", resp.IgnoredKeys),
+		)
+	}
+
+	// The application given by the lister must be treated as read-only.
+	updated := proto.Clone(app).(*model.Application)
+	updated.Labels = resp.Labels
+	return updated
+}
+
+func equalLabels(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if bv, ok := b[k]; !ok || bv != v {
+			return false
+		}
+	}
+	return true
+}
+
 func (t *Trigger) reportMostRecentlyTriggeredDeployment(ctx context.Context, d *model.Deployment) error {
 	var (
 		err error
@@ -144,6 +223,7 @@ func buildDeployment(
 		},
 		GitPath:       app.GitPath,
 		CloudProvider: app.CloudProvider,
+		Labels:        app.Labels,
 		Status:        model.DeploymentStatus_DEPLOYMENT_PENDING,
 		StatusReason:  "The deployment is waiting to be planned",
 		CreatedAt:     now.Unix(),
@@ -0,0 +1,127 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trigger
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	"github.com/pipe-cd/pipe/pkg/app/api/service/pipedservice"
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+// fakeLabelsAPIClient keeps the current values of the bound keys
+// like the control-plane does for the labels used by the RBAC role bindings.
+type fakeLabelsAPIClient struct {
+	apiClient
+	labels    map[string]string
+	boundKeys map[string]struct{}
+	reports   []map[string]string
+}
+
+func (c *fakeLabelsAPIClient) ReportApplicationLabels(_ context.Context, req *pipedservice.ReportApplicationLabelsRequest, _ ...grpc.CallOption) (*pipedservice.ReportApplicationLabelsResponse, error) {
+	c.reports = append(c.reports, req.Labels)
+	labels := make(map[string]string, len(req.Labels))
+	for k, v := range req.Labels {
+		if _, ok := c.boundKeys[k]; !ok {
+			labels[k] = v
+		}
+	}
+	var ignored []string
+	for k := range c.boundKeys {
+		if v, ok := c.labels[k]; ok {
+			labels[k] = v
+		}
+		if req.Labels[k] != c.labels[k] {
+			ignored = append(ignored, k)
+		}
+	}
+	sort.Strings(ignored)
+	c.labels = labels
+	return &pipedservice.ReportApplicationLabelsResponse{
+		Labels:      labels,
+		IgnoredKeys: ignored,
+	}, nil
+}
+
+func TestSyncApplicationLabels(t *testing.T) {
+	repoDir, err := ioutil.TempDir("", "labels")
+	require.NoError(t, err)
+	defer os.RemoveAll(repoDir)
+
+	writeConfig := func(labels string) {
+		cfg := "apiVersion: pipecd.dev/v1beta1\nkind: KubernetesApp\nspec:\n" + labels
+		err := ioutil.WriteFile(filepath.Join(repoDir, model.DefaultDeploymentConfigFileName), []byte(cfg), 0644)
+		require.NoError(t, err)
+	}
+
+	var (
+		ctx    = context.Background()
+		client = &fakeLabelsAPIClient{
+			labels:    map[string]string{"team": "team-a"},
+			boundKeys: map[string]struct{}{"team": {}},
+		}
+		tr = &Trigger{
+			apiClient:      client,
+			reportedLabels: make(map[string]map[string]string),
+			logger:         zap.NewNop(),
+		}
+		app = &model.Application{
+			Id:      "app-1",
+			Kind:    model.ApplicationKind_KUBERNETES,
+			GitPath: &model.ApplicationGitPath{},
+			Labels:  map[string]string{"team": "team-a"},
+		}
+	)
+
+	// The labels given from the web console are kept while the configuration has no label.
+	writeConfig("")
+	got := tr.syncApplicationLabels(ctx, app, repoDir)
+	assert.Equal(t, app, got)
+	assert.Empty(t, client.reports)
+
+	// The bound key is reported but not applied.
+	writeConfig("  labels:\n    env: dev\n    team: team-b\n")
+	got = tr.syncApplicationLabels(ctx, app, repoDir)
+	require.Len(t, client.reports, 1)
+	assert.Equal(t, map[string]string{"env": "dev", "team": "team-a"}, got.Labels)
+
+	// Nothing is reported again until the configuration changes
+	// even though the application labels differ from the configured ones.
+	app.Labels = got.Labels
+	got = tr.syncApplicationLabels(ctx, app, repoDir)
+	assert.Len(t, client.reports, 1)
+	assert.Equal(t, app, got)
+
+	// Removing the labels from the configuration clears them once.
+	writeConfig("")
+	got = tr.syncApplicationLabels(ctx, app, repoDir)
+	require.Len(t, client.reports, 2)
+	assert.Empty(t, client.reports[1])
+	assert.Equal(t, map[string]string{"team": "team-a"}, got.Labels)
+
+	app.Labels = got.Labels
+	tr.syncApplicationLabels(ctx, app, repoDir)
+	assert.Len(t, client.reports, 2)
+}
@@ -42,12 +42,14 @@ var (
 
 const (
 	triggeredDeploymentIDKey = "TriggeredDeploymentID"
+	syncStateChannelSize     = 100
 )
 
 type apiClient interface {
 	GetApplicationMostRecentDeployment(ctx context.Context, req *pipedservice.GetApplicationMostRecentDeploymentRequest, opts ...grpc.CallOption) (*pipedservice.GetApplicationMostRecentDeploymentResponse, error)
 	CreateDeployment(ctx context.Context, in *pipedservice.CreateDeploymentRequest, opts ...grpc.CallOption) (*pipedservice.CreateDeploymentResponse, error)
 	ReportApplicationMostRecentDeployment(ctx context.Context, req *pipedservice.ReportApplicationMostRecentDeploymentRequest, opts ...grpc.CallOption) (*pipedservice.ReportApplicationMostRecentDeploymentResponse, error)
+	ReportApplicationLabels(ctx context.Context, req *pipedservice.ReportApplicationLabelsRequest, opts ...grpc.CallOption) (*pipedservice.ReportApplicationLabelsResponse, error)
 }
 
 type gitClient interface {
@@ -81,6 +83,10 @@ type Trigger struct {
 	config                       *config.PipedSpec
 	mostRecentlyTriggeredCommits map[string]string
 	gitRepos                     map[string]git.Repo
+	configCh                     chan *config.PipedSpec
+	syncStateCh                  chan syncStateChange
+	autoSyncStates               map[string]*autoSyncState
+	reportedLabels               map[string]map[string]string
 	gracePeriod                  time.Duration
 	logger                       *zap.Logger
 }
@@ -108,6 +114,10 @@ func NewTrigger(
 		config:                       cfg,
 		mostRecentlyTriggeredCommits: make(map[string]string),
 		gitRepos:                     make(map[string]git.Repo, len(cfg.Repositories)),
+		configCh:                     make(chan *config.PipedSpec),
+		syncStateCh:                  make(chan syncStateChange, syncStateChannelSize),
+		autoSyncStates:               make(map[string]*autoSyncState),
+		reportedLabels:               make(map[string]map[string]string),
 		gracePeriod:                  gracePeriod,
 		logger:                       logger.Named("trigger"),
 	}
@@ -133,7 +143,9 @@ func (t *Trigger) Run(ctx context.Context) error {
 	}
 
 	commitTicker := time.NewTicker(time.Duration(t.config.SyncInterval))
-	defer commitTicker.Stop()
+	defer func() {
+		commitTicker.Stop()
+	}()
 
 	commandTicker := time.NewTicker(commandCheckInterval)
 	defer commandTicker.Stop()
@@ -147,6 +159,17 @@ L:
 
 		case <-commitTicker.C:
 			t.checkCommit(ctx)
+			t.checkPendingAutoSyncs(ctx)
+
+		case c := <-t.syncStateCh:
+			t.handleSyncState(ctx, c)
+
+		case cfg := <-t.configCh:
+			if cfg.SyncInterval != t.config.SyncInterval {
+				commitTicker.Stop()
+				commitTicker = time.NewTicker(time.Duration(cfg.SyncInterval))
+			}
+			t.applyConfig(ctx, cfg)
 
 		case <-ctx.Done():
 			break L
@@ -157,6 +180,56 @@ L:
 	return nil
 }
 
+// UpdateConfig passes the given piped configuration to the running trigger.
+// The newly added repositories will be cloned and the removed ones will be
+// no longer checked from the next sync.
+func (t *Trigger) UpdateConfig(ctx context.Context, cfg *config.PipedSpec) error {
+	select {
+	case t.configCh <- cfg:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (t *Trigger) applyConfig(ctx context.Context, cfg *config.PipedSpec) {
+	var (
+		repos = make(map[string]git.Repo, len(cfg.Repositories))
+		kept  = make(map[string]struct{}, len(cfg.Repositories))
+	)
+	for _, r := range cfg.Repositories {
+		if old, ok := t.config.GetRepository(r.RepoID); ok && old == r {
+			if repo, ok := t.gitRepos[r.RepoID]; ok {
+				repos[r.RepoID] = repo
+				kept[r.RepoID] = struct{}{}
+				continue
+			}
+		}
+		repo, err := t.gitClient.Clone(ctx, r.RepoID, r.Remote, r.Branch, "")
+		if err != nil {
+			t.logger.Error("failed to clone repository, it will be ignored until the next configuration update",
+				zap.String("repo-id", r.RepoID),
+				zap.Error(err),
+			)
+			continue
+		}
+		t.logger.Info("cloned a repository added by the configuration update", zap.String("repo-id", r.RepoID))
+		repos[r.RepoID] = repo
+	}
+
+	for id, repo := range t.gitRepos {
+		if _, ok := kept[id]; ok {
+			continue
+		}
+		if err := repo.Clean(); err != nil {
+			t.logger.Warn("failed to clean the removed repository", zap.String("repo-id", id), zap.Error(err))
+		}
+	}
+
+	t.gitRepos = repos
+	t.config = cfg
+}
+
 func (t *Trigger) checkCommand(ctx context.Context) error {
 	commands := t.commandLister.ListApplicationCommands()
 	for _, cmd := range commands {
@@ -173,13 +246,13 @@ func (t *Trigger) checkCommand(ctx context.Context) error {
 			)
 			continue
 		}
-		d, err := t.syncApplication(ctx, app, cmd.Commander, syncCmd.SyncStrategy)
+		d, err := t.syncApplication(ctx, app, cmd.Commander, syncCmd)
 		if err != nil {
 			t.logger.Error("failed to sync application",
 				zap.String("app-id", app.Id),
 				zap.Error(err),
 			)
-			if err := cmd.Report(ctx, model.CommandStatus_COMMAND_FAILED, nil); err != nil {
+			if err := cmd.Report(ctx, model.CommandStatus_COMMAND_FAILED, nil, nil); err != nil {
 				t.logger.Error("failed to report command status", zap.Error(err))
 			}
 			continue
@@ -188,15 +261,15 @@ func (t *Trigger) checkCommand(ctx context.Context) error {
 		metadata := map[string]string{
 			triggeredDeploymentIDKey: d.Id,
 		}
-		if err := cmd.Report(ctx, model.CommandStatus_COMMAND_SUCCEEDED, metadata); err != nil {
+		if err := cmd.Report(ctx, model.CommandStatus_COMMAND_SUCCEEDED, metadata, nil); err != nil {
 			t.logger.Error("failed to report command status", zap.Error(err))
 		}
 	}
 	return nil
 }
 
-func (t *Trigger) syncApplication(ctx context.Context, app *model.Application, commander string, syncStrategy model.SyncStrategy) (*model.Deployment, error) {
-	_, branch, headCommit, err := t.updateRepoToLatest(ctx, app.GitPath.Repo.Id)
+func (t *Trigger) syncApplication(ctx context.Context, app *model.Application, commander string, syncCmd *model.Command_SyncApplication) (*model.Deployment, error) {
+	repo, branch, headCommit, err := t.updateRepoToLatest(ctx, app.GitPath.Repo.Id)
 	if err != nil {
 		return nil, err
 	}
@@ -205,7 +278,7 @@ func (t *Trigger) syncApplication(ctx context.Context, app *model.Application, c
 	t.logger.Info(fmt.Sprintf("application %s will be synced because of a sync command", app.Id),
 		zap.String("head-commit", headCommit.Hash),
 	)
-	d, err := t.triggerDeployment(ctx, app, branch, headCommit, commander, syncStrategy)
+	d, err := t.triggerDeployment(ctx, app, repo, branch, headCommit, commander, syncCmd.SyncStrategy, syncCmd.DeploymentChainId, syncCmd.DeploymentChainBlockIndex, "", 0)
 	if err != nil {
 		return nil, err
 	}
@@ -279,7 +352,7 @@ func (t *Trigger) checkApplication(ctx context.Context, app *model.Application,
 		logger.Info("application should be synced because of the new commit",
 			zap.String("most-recently-triggered-commit", preCommitHash),
 		)
-		if _, err := t.triggerDeployment(ctx, app, branch, headCommit, "", model.SyncStrategy_AUTO); err != nil {
+		if _, err := t.triggerDeployment(ctx, app, repo, branch, headCommit, "", model.SyncStrategy_AUTO, "", 0, "", 0); err != nil {
 			return err
 		}
 		t.mostRecentlyTriggeredCommits[app.Id] = headCommit.Hash
@@ -29,6 +29,7 @@ export const dummyDeployment: Deployment = {
       url: "",
     },
     syncStrategy: SyncStrategy.AUTO,
+    reason: "",
   },
   updatedAt: 1,
   version: "0.0.0",
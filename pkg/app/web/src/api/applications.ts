@@ -16,6 +16,10 @@ import {
   EnableApplicationResponse,
   UpdateApplicationRequest,
   UpdateApplicationResponse,
+  RequestKubernetesPodLogsRequest,
+  RequestKubernetesPodLogsResponse,
+  RequestKubernetesResourceEventsRequest,
+  RequestKubernetesResourceEventsResponse,
 } from "pipe/pkg/app/web/api_client/service_pb";
 import { ApplicationGitPath } from "pipe/pkg/app/web/model/common_pb";
 import { ApplicationGitRepository } from "pipe/pkg/app/web/model/common_pb";
@@ -161,3 +165,47 @@ export const updateApplication = async ({
   req.setGitPath(appGitPath);
   return apiRequest(req, apiClient.updateApplication);
 };
+
+export const requestKubernetesPodLogs = ({
+  applicationId,
+  namespace,
+  podName,
+  containerName,
+  tailLines,
+  sinceSeconds,
+  previous,
+  cloudProvider,
+}: RequestKubernetesPodLogsRequest.AsObject): Promise<
+  RequestKubernetesPodLogsResponse.AsObject
+> => {
+  const req = new RequestKubernetesPodLogsRequest();
+  req.setApplicationId(applicationId);
+  req.setNamespace(namespace);
+  req.setPodName(podName);
+  req.setContainerName(containerName);
+  req.setTailLines(tailLines);
+  req.setSinceSeconds(sinceSeconds);
+  req.setPrevious(previous);
+  req.setCloudProvider(cloudProvider);
+  return apiRequest(req, apiClient.requestKubernetesPodLogs);
+};
+
+export const requestKubernetesResourceEvents = ({
+  applicationId,
+  apiVersion,
+  kind,
+  namespace,
+  name,
+  cloudProvider,
+}: RequestKubernetesResourceEventsRequest.AsObject): Promise<
+  RequestKubernetesResourceEventsResponse.AsObject
+> => {
+  const req = new RequestKubernetesResourceEventsRequest();
+  req.setApplicationId(applicationId);
+  req.setApiVersion(apiVersion);
+  req.setKind(kind);
+  req.setNamespace(namespace);
+  req.setName(name);
+  req.setCloudProvider(cloudProvider);
+  return apiRequest(req, apiClient.requestKubernetesResourceEvents);
+};
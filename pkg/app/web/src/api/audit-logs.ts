@@ -0,0 +1,38 @@
+import { apiClient, apiRequest } from "./client";
+import {
+  ListAuditLogsRequest,
+  ListAuditLogsResponse,
+} from "pipe/pkg/app/web/api_client/service_pb";
+import * as google_protobuf_wrappers_pb from "google-protobuf/google/protobuf/wrappers_pb";
+
+export const getAuditLogs = ({
+  options,
+  pageSize,
+  exportFormat,
+}: {
+  options: {
+    actor?: string;
+    method?: string;
+    succeeded?: boolean;
+    minCreatedAt?: number;
+    maxCreatedAt?: number;
+  };
+  pageSize: number;
+  exportFormat?: ListAuditLogsRequest.ExportFormat;
+}): Promise<ListAuditLogsResponse.AsObject> => {
+  const req = new ListAuditLogsRequest();
+  const opts = new ListAuditLogsRequest.Options();
+  opts.setActor(options.actor ?? "");
+  opts.setMethod(options.method ?? "");
+  if (options.succeeded !== undefined) {
+    const succeeded = new google_protobuf_wrappers_pb.BoolValue();
+    succeeded.setValue(options.succeeded);
+    opts.setSucceeded(succeeded);
+  }
+  opts.setMinCreatedAt(options.minCreatedAt ?? 0);
+  opts.setMaxCreatedAt(options.maxCreatedAt ?? 0);
+  req.setOptions(opts);
+  req.setPageSize(pageSize);
+  req.setExportFormat(exportFormat ?? ListAuditLogsRequest.ExportFormat.NONE);
+  return apiRequest(req, apiClient.listAuditLogs);
+};
@@ -2,6 +2,8 @@ import { apiClient, apiRequest } from "./client";
 import {
   GetCommandRequest,
   GetCommandResponse,
+  GetCommandOutputRequest,
+  GetCommandOutputResponse,
 } from "pipe/pkg/app/web/api_client/service_pb";
 
 export const getCommand = ({
@@ -11,3 +13,13 @@ export const getCommand = ({
   req.setCommandId(commandId);
   return apiRequest(req, apiClient.getCommand);
 };
+
+export const getCommandOutput = ({
+  commandId,
+}: GetCommandOutputRequest.AsObject): Promise<
+  GetCommandOutputResponse.AsObject
+> => {
+  const req = new GetCommandOutputRequest();
+  req.setCommandId(commandId);
+  return apiRequest(req, apiClient.getCommandOutput);
+};
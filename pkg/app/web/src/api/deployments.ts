@@ -2,12 +2,16 @@ import { apiClient, apiRequest } from "./client";
 import {
   GetDeploymentRequest,
   GetDeploymentResponse,
+  GetDeploymentDiffRequest,
+  GetDeploymentDiffResponse,
   ListDeploymentsRequest,
   ListDeploymentsResponse,
   CancelDeploymentRequest,
   CancelDeploymentResponse,
   ApproveStageRequest,
   ApproveStageResponse,
+  RejectStageRequest,
+  RejectStageResponse,
 } from "pipe/pkg/app/web/api_client/service_pb";
 
 export const getDeployment = ({
@@ -18,6 +22,16 @@ export const getDeployment = ({
   return apiRequest(req, apiClient.getDeployment);
 };
 
+export const getDeploymentDiff = ({
+  deploymentId,
+}: GetDeploymentDiffRequest.AsObject): Promise<
+  GetDeploymentDiffResponse.AsObject
+> => {
+  const req = new GetDeploymentDiffRequest();
+  req.setDeploymentId(deploymentId);
+  return apiRequest(req, apiClient.getDeploymentDiff);
+};
+
 export const getDeployments = ({
   options,
   pageSize,
@@ -61,3 +75,15 @@ export const approveStage = ({
   req.setStageId(stageId);
   return apiRequest(req, apiClient.approveStage);
 };
+
+export const rejectStage = ({
+  deploymentId,
+  stageId,
+  reason,
+}: RejectStageRequest.AsObject): Promise<RejectStageResponse.AsObject> => {
+  const req = new RejectStageRequest();
+  req.setDeploymentId(deploymentId);
+  req.setStageId(stageId);
+  req.setReason(reason);
+  return apiRequest(req, apiClient.rejectStage);
+};
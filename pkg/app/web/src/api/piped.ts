@@ -14,6 +14,14 @@ import {
   GenerateApplicationSealedSecretResponse,
   UpdatePipedRequest,
   UpdatePipedResponse,
+  GetPipedConfigRequest,
+  GetPipedConfigResponse,
+  UpdatePipedConfigRequest,
+  UpdatePipedConfigResponse,
+  RollbackPipedConfigRequest,
+  RollbackPipedConfigResponse,
+  ListPipedConfigRevisionsRequest,
+  ListPipedConfigRevisionsResponse,
 } from "pipe/pkg/app/web/api_client/service_pb";
 
 export const getPipeds = ({
@@ -89,3 +97,59 @@ export const updatePiped = ({
   req.setEnvIdsList(envIdsList);
   return apiRequest(req, apiClient.updatePiped);
 };
+
+export const getPipedConfig = ({
+  pipedId,
+  version,
+}: GetPipedConfigRequest.AsObject): Promise<
+  GetPipedConfigResponse.AsObject
+> => {
+  const req = new GetPipedConfigRequest();
+  req.setPipedId(pipedId);
+  req.setVersion(version);
+  return apiRequest(req, apiClient.getPipedConfig);
+};
+
+export const updatePipedConfig = ({
+  pipedId,
+  data,
+  desc,
+  baseVersion,
+}: UpdatePipedConfigRequest.AsObject): Promise<
+  UpdatePipedConfigResponse.AsObject
+> => {
+  const req = new UpdatePipedConfigRequest();
+  req.setPipedId(pipedId);
+  req.setData(data);
+  req.setDesc(desc);
+  req.setBaseVersion(baseVersion);
+  return apiRequest(req, apiClient.updatePipedConfig);
+};
+
+export const rollbackPipedConfig = ({
+  pipedId,
+  version,
+  desc,
+  baseVersion,
+}: RollbackPipedConfigRequest.AsObject): Promise<
+  RollbackPipedConfigResponse.AsObject
+> => {
+  const req = new RollbackPipedConfigRequest();
+  req.setPipedId(pipedId);
+  req.setVersion(version);
+  req.setDesc(desc);
+  req.setBaseVersion(baseVersion);
+  return apiRequest(req, apiClient.rollbackPipedConfig);
+};
+
+export const listPipedConfigRevisions = ({
+  pipedId,
+  pageSize,
+}: ListPipedConfigRevisionsRequest.AsObject): Promise<
+  ListPipedConfigRevisionsResponse.AsObject
+> => {
+  const req = new ListPipedConfigRevisionsRequest();
+  req.setPipedId(pipedId);
+  req.setPageSize(pageSize);
+  return apiRequest(req, apiClient.listPipedConfigRevisions);
+};
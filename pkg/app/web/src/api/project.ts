@@ -7,12 +7,16 @@ import {
   GetProjectResponse,
   UpdateProjectRBACConfigRequest,
   UpdateProjectRBACConfigResponse,
+  UpdateProjectDriftDetectionConfigRequest,
+  UpdateProjectDriftDetectionConfigResponse,
   UpdateProjectSSOConfigRequest,
   UpdateProjectSSOConfigResponse,
   UpdateProjectStaticAdminRequest,
   UpdateProjectStaticAdminResponse,
 } from "pipe/pkg/app/web/api_client/service_pb";
 import {
+  KubernetesDriftIgnoreField,
+  ProjectDriftDetectionConfig,
   ProjectRBACConfig,
   ProjectSSOConfig,
 } from "pipe/pkg/app/web/model/project_pb";
@@ -89,3 +93,23 @@ export const updateGitHubSSO = ({
   req.setSso(sso);
   return apiRequest(req, apiClient.updateProjectSSOConfig);
 };
+
+export const updateDriftDetection = ({
+  kubernetesIgnoreFieldsList,
+}: ProjectDriftDetectionConfig.AsObject): Promise<
+  UpdateProjectDriftDetectionConfigResponse.AsObject
+> => {
+  const req = new UpdateProjectDriftDetectionConfigRequest();
+  const config = new ProjectDriftDetectionConfig();
+  config.setKubernetesIgnoreFieldsList(
+    kubernetesIgnoreFieldsList.map((f) => {
+      const field = new KubernetesDriftIgnoreField();
+      field.setKind(f.kind);
+      field.setName(f.name);
+      field.setPathsList(f.pathsList);
+      return field;
+    })
+  );
+  req.setDriftDetection(config);
+  return apiRequest(req, apiClient.updateProjectDriftDetectionConfig);
+};
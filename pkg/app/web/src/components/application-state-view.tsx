@@ -72,7 +72,12 @@ export const ApplicationStateView: FC<Props> = memo(
     switch (liveState.kind) {
       case ApplicationKind.KUBERNETES: {
         const resources = liveState.kubernetes?.resourcesList || [];
-        return <KubernetesStateView resources={resources} />;
+        return (
+          <KubernetesStateView
+            applicationId={applicationId}
+            resources={resources}
+          />
+        );
       }
       default:
     }
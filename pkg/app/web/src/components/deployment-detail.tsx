@@ -214,6 +214,12 @@ export const DeploymentDetail: FC<Props> = memo(function DeploymentDetail({
                     ""
                   }
                 />
+                {deployment.trigger.reason && (
+                  <DetailTableRow
+                    label="Trigger reason"
+                    value={deployment.trigger.reason}
+                  />
+                )}
               </tbody>
             </table>
           </div>
@@ -9,12 +9,14 @@ export default {
 
 export const overview: React.FC = () => (
   <KubernetesResourceDetail
+    applicationId="application-1"
     resource={{
       name: "demo-application-9504e8601a",
       namespace: "default",
       apiVersion: "apps/v1",
       healthDescription: "Unimplemented or unknown resource",
       kind: "Pod",
+      cloudProvider: "kubernetes-default",
     }}
     onClose={action("onClose")}
   />
@@ -1,8 +1,42 @@
-import { IconButton, makeStyles, Paper, Typography } from "@material-ui/core";
+import {
+  Button,
+  CircularProgress,
+  IconButton,
+  makeStyles,
+  Paper,
+  Typography,
+} from "@material-ui/core";
 import CloseIcon from "@material-ui/icons/Close";
-import React, { FC } from "react";
+import React, { FC, useState } from "react";
+import {
+  requestKubernetesPodLogs,
+  requestKubernetesResourceEvents,
+} from "../api/applications";
+import { getCommand, getCommandOutput } from "../api/commands";
+import { CommandStatus } from "../modules/commands";
 
 const DETAIL_WIDTH = 400;
+const POD_LOGS_TAIL_LINES = 500;
+const POLLING_INTERVAL = 2000;
+const MAX_POLLING_COUNT = 30;
+
+// waitForCommandOutput waits until the piped handled the command
+// and returns the output of that command.
+const waitForCommandOutput = async (commandId: string): Promise<string> => {
+  for (let i = 0; i < MAX_POLLING_COUNT; i++) {
+    await new Promise((resolve) => setTimeout(resolve, POLLING_INTERVAL));
+    const { command } = await getCommand({ commandId });
+    if (
+      command === undefined ||
+      command.status === CommandStatus.COMMAND_NOT_HANDLED_YET
+    ) {
+      continue;
+    }
+    const { output } = await getCommandOutput({ commandId });
+    return output;
+  }
+  throw new Error("The request was not handled by piped in time.");
+};
 
 const useStyles = makeStyles((theme) => ({
   root: {
@@ -40,21 +74,84 @@ const useStyles = makeStyles((theme) => ({
   multilineSection: {
     paddingTop: theme.spacing(1),
   },
+  actions: {
+    paddingTop: theme.spacing(2),
+    display: "flex",
+    alignItems: "center",
+    "& > *": {
+      marginRight: theme.spacing(1),
+    },
+  },
+  output: {
+    fontFamily: "Roboto Mono",
+    fontSize: 12,
+    whiteSpace: "pre-wrap",
+    wordBreak: "break-all",
+    backgroundColor: theme.palette.background.default,
+    padding: theme.spacing(1),
+  },
 }));
 
 interface Props {
+  applicationId: string;
   resource: {
     name: string;
     kind: string;
     namespace: string;
     apiVersion: string;
     healthDescription: string;
+    cloudProvider: string;
   };
   onClose: () => void;
 }
 
-export const KubernetesResourceDetail: FC<Props> = ({ resource, onClose }) => {
+export const KubernetesResourceDetail: FC<Props> = ({
+  applicationId,
+  resource,
+  onClose,
+}) => {
   const classes = useStyles();
+  const [isLoading, setIsLoading] = useState(false);
+  const [output, setOutput] = useState<string | null>(null);
+
+  const handleRequest = (request: () => Promise<{ commandId: string }>) => {
+    setIsLoading(true);
+    setOutput(null);
+    request()
+      .then(({ commandId }) => waitForCommandOutput(commandId))
+      .then((out) => setOutput(out || "No output"))
+      .catch((e: Error) => setOutput(e.message))
+      .finally(() => setIsLoading(false));
+  };
+
+  const handleGetLogs = (): void => {
+    handleRequest(() =>
+      requestKubernetesPodLogs({
+        applicationId,
+        namespace: resource.namespace,
+        podName: resource.name,
+        containerName: "",
+        tailLines: POD_LOGS_TAIL_LINES,
+        sinceSeconds: 0,
+        previous: false,
+        cloudProvider: resource.cloudProvider,
+      })
+    );
+  };
+
+  const handleGetEvents = (): void => {
+    handleRequest(() =>
+      requestKubernetesResourceEvents({
+        applicationId,
+        apiVersion: resource.apiVersion,
+        kind: resource.kind,
+        namespace: resource.namespace,
+        name: resource.name,
+        cloudProvider: resource.cloudProvider,
+      })
+    );
+  };
+
   return (
     <Paper className={classes.root} square>
       <IconButton className={classes.closeButton} onClick={onClose}>
@@ -99,6 +196,34 @@ export const KubernetesResourceDetail: FC<Props> = ({ resource, onClose }) => {
           {resource.healthDescription || "Empty"}
         </Typography>
       </div>
+
+      <div className={classes.actions}>
+        {resource.kind === "Pod" && (
+          <Button
+            variant="outlined"
+            size="small"
+            disabled={isLoading}
+            onClick={handleGetLogs}
+          >
+            Logs
+          </Button>
+        )}
+        <Button
+          variant="outlined"
+          size="small"
+          disabled={isLoading}
+          onClick={handleGetEvents}
+        >
+          Events
+        </Button>
+        {isLoading && <CircularProgress size={20} />}
+      </div>
+
+      {output !== null && (
+        <div className={classes.multilineSection}>
+          <pre className={classes.output}>{output}</pre>
+        </div>
+      )}
     </Paper>
   );
 };
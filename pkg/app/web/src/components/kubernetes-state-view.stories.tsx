@@ -8,5 +8,8 @@ export default {
 };
 
 export const overview: React.FC = () => (
-  <KubernetesStateView resources={resourcesList} />
+  <KubernetesStateView
+    applicationId="application-1"
+    resources={resourcesList}
+  />
 );
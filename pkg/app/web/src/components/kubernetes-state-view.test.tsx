@@ -5,13 +5,25 @@ import { resourcesList } from "../__fixtures__/dummy-application-live-state";
 import { KubernetesStateView } from "./kubernetes-state-view";
 
 test("render resources", () => {
-  render(<KubernetesStateView resources={resourcesList} />, {});
+  render(
+    <KubernetesStateView
+      applicationId="application-1"
+      resources={resourcesList}
+    />,
+    {}
+  );
 
   expect(screen.queryAllByTestId("kubernetes-resource")).toHaveLength(3);
 });
 
 test("filter resources", () => {
-  render(<KubernetesStateView resources={resourcesList} />, {});
+  render(
+    <KubernetesStateView
+      applicationId="application-1"
+      resources={resourcesList}
+    />,
+    {}
+  );
 
   userEvent.click(screen.getByRole("button", { name: "FILTER" }));
   userEvent.click(screen.getByRole("checkbox", { name: "ReplicaSet" }));
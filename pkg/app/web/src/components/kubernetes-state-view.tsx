@@ -34,6 +34,7 @@ const useStyles = makeStyles((theme) => ({
 }));
 
 interface Props {
+  applicationId: string;
   resources: KubernetesResourceState[];
 }
 
@@ -85,7 +86,10 @@ function useGraph(
   return graph;
 }
 
-export const KubernetesStateView: FC<Props> = ({ resources }) => {
+export const KubernetesStateView: FC<Props> = ({
+  applicationId,
+  resources,
+}) => {
   const classes = useStyles();
   const [
     selectedResource,
@@ -197,6 +201,7 @@ export const KubernetesStateView: FC<Props> = ({ resources }) => {
 
       {selectedResource && (
         <KubernetesResourceDetail
+          applicationId={applicationId}
           resource={selectedResource}
           onClose={() => setSelectedResource(null)}
         />
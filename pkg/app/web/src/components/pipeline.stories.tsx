@@ -46,6 +46,7 @@ const fakeDeployment: Deployment = {
     commander: "cakecatz",
     timestamp: 1592201366,
     syncStrategy: SyncStrategy.AUTO,
+    reason: "",
   },
   runningCommitHash: "3808585b46f1e90196d7ffe8dd04c807a251febc",
   summary: "This deployment is debug",
@@ -23,6 +23,10 @@ export const COMMAND_TYPE_TEXT: Record<CommandModel.Type, string> = {
   [CommandModel.Type.CANCEL_DEPLOYMENT]: "Cancel Deployment",
   [CommandModel.Type.SYNC_APPLICATION]: "Sync Application",
   [CommandModel.Type.UPDATE_APPLICATION_CONFIG]: "Update Application Config",
+  [CommandModel.Type.REJECT_STAGE]: "Reject Stage",
+  [CommandModel.Type.PRUNE_APPLICATION_RESOURCES]: "Prune Application Resources",
+  [CommandModel.Type.GET_KUBERNETES_POD_LOGS]: "Get Pod Logs",
+  [CommandModel.Type.GET_KUBERNETES_RESOURCE_EVENTS]: "Get Resource Events",
 };
 
 const commandsAdapter = createEntityAdapter<Command>();
@@ -17,6 +17,8 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"net"
+	"strings"
 	"time"
 
 	"github.com/golang/protobuf/jsonpb"
@@ -42,12 +44,81 @@ type ControlPlaneSpec struct {
 	Projects []ControlPlaneProject `json:"projects"`
 	// List of shared SSO configurations that can be used by any projects.
 	SharedSSOConfigs []SharedSSOConfig `json:"sharedSSOConfigs"`
+	// The configuration of the Slack app used to approve or reject
+	// WAIT_APPROVAL stages from the interactive Slack messages.
+	Slack *ControlPlaneSlack `json:"slack"`
+	// List of IP addresses or CIDR ranges of the proxies in front of the control plane
+	// that are trusted to set the X-Forwarded-For header.
+	// The header is ignored while checking the allowed IP ranges of API keys
+	// unless the request came from one of these proxies.
+	TrustedProxies []string `json:"trustedProxies"`
 }
 
 func (s *ControlPlaneSpec) Validate() error {
+	if s.Slack != nil && s.Slack.SigningSecret == "" {
+		return fmt.Errorf("signingSecret must be specified for slack")
+	}
+	if _, err := s.TrustedProxyNetworks(); err != nil {
+		return err
+	}
 	return nil
 }
 
+// TrustedProxyNetworks returns the parsed networks of the trusted proxies.
+func (s *ControlPlaneSpec) TrustedProxyNetworks() ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(s.TrustedProxies))
+	for _, p := range s.TrustedProxies {
+		if strings.Contains(p, "/") {
+			_, n, err := net.ParseCIDR(p)
+			if err != nil {
+				return nil, fmt.Errorf("invalid CIDR %q of trusted proxy: %w", p, err)
+			}
+			nets = append(nets, n)
+			continue
+		}
+		ip := net.ParseIP(p)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP address %q of trusted proxy", p)
+		}
+		bits := 8 * net.IPv6len
+		if v4 := ip.To4(); v4 != nil {
+			ip, bits = v4, 8*net.IPv4len
+		}
+		nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+	}
+	return nets, nil
+}
+
+type ControlPlaneSlack struct {
+	// The signing secret of the Slack app used to verify the requests sent from Slack.
+	SigningSecret string `json:"signingSecret"`
+	// List of Slack users who are allowed to approve or reject from Slack.
+	Users []SlackUserMapping `json:"users"`
+}
+
+// SlackUserMapping maps a Slack user to a PipeCD user of a project.
+type SlackUserMapping struct {
+	// The ID of the Slack user.
+	SlackUserID string `json:"slackUserId"`
+	// The ID of the project the PipeCD user belongs to.
+	ProjectID string `json:"projectId"`
+	// The PipeCD username. It is used as the commander of the commands.
+	Username string `json:"username"`
+	// The SSO team the PipeCD user belongs to.
+	// It is used to check against the approvers list of the stage.
+	Team string `json:"team"`
+}
+
+// FindUser finds the PipeCD user mapped from the given Slack user in the given project.
+func (s *ControlPlaneSlack) FindUser(slackUserID, projectID string) (SlackUserMapping, bool) {
+	for _, u := range s.Users {
+		if u.SlackUserID == slackUserID && u.ProjectID == projectID {
+			return u, true
+		}
+	}
+	return SlackUserMapping{}, false
+}
+
 type ControlPlaneProject struct {
 	// The unique identifier of the project.
 	Id string `json:"id"`
@@ -75,10 +146,10 @@ func (s *SharedSSOConfig) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	provider := m["provider"].(string)
+	provider, _ := m["provider"].(string)
 	v, ok := model.ProjectSSOConfig_Provider_value[provider]
 	if !ok {
-		return fmt.Errorf("unsupported provider %s", provider)
+		return fmt.Errorf("unsupported provider %q", provider)
 	}
 	m["provider"] = v
 
@@ -100,7 +171,10 @@ func (s *SharedSSOConfig) UnmarshalJSON(data []byte) error {
 	if err := jsonpb.UnmarshalString(string(data), &s.ProjectSSOConfig); err != nil {
 		return err
 	}
-	return nil
+	if s.Provider == model.ProjectSSOConfig_OIDC && s.Oidc == nil {
+		return fmt.Errorf("oidc field in SharedSSOConfig %s is required for OIDC provider", s.Name)
+	}
+	return s.ProjectSSOConfig.Validate()
 }
 
 // FindProject finds and returns a specific project in the configured list.
@@ -60,6 +60,19 @@ func TestControlPlaneConfig(t *testing.T) {
 							},
 						},
 					},
+					{
+						Name: "okta",
+						ProjectSSOConfig: model.ProjectSSOConfig{
+							Provider: model.ProjectSSOConfig_OIDC,
+							Oidc: &model.ProjectSSOConfig_OpenIDConnect{
+								ClientId:     "client-id",
+								ClientSecret: "client-secret",
+								Issuer:       "https://example.okta.com/oauth2/default",
+								Scopes:       []string{"groups"},
+								GroupsClaim:  "groups",
+							},
+						},
+					},
 				},
 				Datastore: ControlPlaneDataStore{
 					Type: model.DataStoreFirestore,
@@ -80,6 +93,17 @@ func TestControlPlaneConfig(t *testing.T) {
 				Cache: ControlPlaneCache{
 					TTL: Duration(5 * time.Minute),
 				},
+				Slack: &ControlPlaneSlack{
+					SigningSecret: "signing-secret",
+					Users: []SlackUserMapping{
+						{
+							SlackUserID: "U012AB3CD",
+							ProjectID:   "abc",
+							Username:    "foo",
+							Team:        "pipe-cd/sre",
+						},
+					},
+				},
 			},
 		},
 	}
@@ -90,11 +114,13 @@ func TestControlPlaneConfig(t *testing.T) {
 			if err == nil {
 				assert.Equal(t, tc.expectedKind, cfg.Kind)
 				assert.Equal(t, tc.expectedAPIVersion, cfg.APIVersion)
-				require.Equal(t, 1, len(tc.expectedSpec.SharedSSOConfigs))
-				require.Equal(t, 1, len(cfg.ControlPlaneSpec.SharedSSOConfigs))
-				// Why don't we use assert.Equal to compare?
-				// https://github.com/stretchr/testify/issues/758
-				assert.True(t, proto.Equal(&tc.expectedSpec.SharedSSOConfigs[0].ProjectSSOConfig, &cfg.ControlPlaneSpec.SharedSSOConfigs[0].ProjectSSOConfig))
+				require.Equal(t, len(tc.expectedSpec.SharedSSOConfigs), len(cfg.ControlPlaneSpec.SharedSSOConfigs))
+				for i := range tc.expectedSpec.SharedSSOConfigs {
+					assert.Equal(t, tc.expectedSpec.SharedSSOConfigs[i].Name, cfg.ControlPlaneSpec.SharedSSOConfigs[i].Name)
+					// Why don't we use assert.Equal to compare?
+					// https://github.com/stretchr/testify/issues/758
+					assert.True(t, proto.Equal(&tc.expectedSpec.SharedSSOConfigs[i].ProjectSSOConfig, &cfg.ControlPlaneSpec.SharedSSOConfigs[i].ProjectSSOConfig))
+				}
 
 				tc.expectedSpec.SharedSSOConfigs = nil
 				cfg.ControlPlaneSpec.SharedSSOConfigs = nil
@@ -17,6 +17,7 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/pipe-cd/pipe/pkg/model"
 )
@@ -31,6 +32,38 @@ type GenericDeploymentSpec struct {
 	// List of directories or files where their changes will trigger the deployment.
 	// Regular expression can be used.
 	TriggerPaths []string `json:"triggerPaths,omitempty"`
+	// List of named locks the deployment must acquire before running.
+	// Applications handled by the same piped and sharing a lock name
+	// are never deployed at the same time.
+	DeploymentLocks []string `json:"deploymentLocks,omitempty"`
+	// The downstream applications that should be triggered
+	// once the deployment of this application was completed successfully.
+	Chain *DeploymentChain `json:"chain,omitempty"`
+	// The hooks to be executed automatically before and after syncing the application.
+	Hooks *DeploymentHooks `json:"hooks,omitempty"`
+	// Additional attributes of the application.
+	// When specified, they replace the labels registered for the application.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Configuration for automatically syncing the application
+	// when a configuration drift was detected.
+	AutoSync *AutoSync `json:"autoSync,omitempty"`
+}
+
+func (s GenericDeploymentSpec) Validate() error {
+	if err := model.ValidateLabels(s.Labels); err != nil {
+		return err
+	}
+	if s.Hooks != nil {
+		if err := s.Hooks.Validate(); err != nil {
+			return err
+		}
+	}
+	if s.AutoSync != nil {
+		if err := s.AutoSync.Validate(); err != nil {
+			return fmt.Errorf("invalid autoSync: %w", err)
+		}
+	}
+	return nil
 }
 
 func (s GenericDeploymentSpec) GetStage(index int32) (PipelineStage, bool) {
@@ -64,6 +97,93 @@ type DeploymentCommitMatcher struct {
 	Pipeline string `json:"pipeline"`
 }
 
+// DeploymentChain represents the list of downstream applications
+// which are deployed together after the current one.
+// The downstream applications can also declare their own chain
+// to continue the chain with the next block.
+type DeploymentChain struct {
+	// The list of applications should be triggered in parallel.
+	Applications []ChainApplication `json:"applications"`
+}
+
+// ChainApplication specifies an application to be triggered by a deployment chain.
+type ChainApplication struct {
+	// The name of the application.
+	Name string `json:"name"`
+	// The name of the environment the application belongs to.
+	// Empty means the same environment with the current application.
+	Env string `json:"env,omitempty"`
+}
+
+// DeploymentHooks contains the hooks which are automatically added
+// into the pipeline of every deployment of the application.
+type DeploymentHooks struct {
+	// The hook to be executed before the first stage of the pipeline.
+	PreSync *HookStageOptions `json:"preSync,omitempty"`
+	// The hook to be executed after the last stage of the pipeline.
+	PostSync *HookStageOptions `json:"postSync,omitempty"`
+}
+
+func (h *DeploymentHooks) Validate() error {
+	if h.PreSync != nil {
+		if err := h.PreSync.Validate(); err != nil {
+			return fmt.Errorf("invalid preSync hook: %w", err)
+		}
+	}
+	if h.PostSync != nil {
+		if err := h.PostSync.Validate(); err != nil {
+			return fmt.Errorf("invalid postSync hook: %w", err)
+		}
+	}
+	return nil
+}
+
+const (
+	defaultAutoSyncCooldown    = 5 * time.Minute
+	defaultAutoSyncMaxAttempts = 3
+)
+
+// AutoSync configures the automatic sync of an application
+// that was marked as OUT_OF_SYNC by the drift detector.
+// The application is synced by a quick sync at the running commit.
+type AutoSync struct {
+	// Whether the automatic sync is enabled.
+	Enabled bool `json:"enabled"`
+	// The minimum duration between two automatic syncs of the application.
+	// Default is 5m.
+	Cooldown Duration `json:"cooldown,omitempty"`
+	// The maximum number of automatic syncs for the same running commit.
+	// Once reached, the drift is only notified until the next deployment.
+	// Default is 3.
+	MaxAttempts int `json:"maxAttempts,omitempty"`
+}
+
+func (a *AutoSync) Validate() error {
+	if a.Cooldown < 0 {
+		return fmt.Errorf("cooldown must not be negative")
+	}
+	if a.MaxAttempts < 0 {
+		return fmt.Errorf("maxAttempts must not be negative")
+	}
+	return nil
+}
+
+// GetCooldown returns the cooldown between two automatic syncs, applying the default value.
+func (a *AutoSync) GetCooldown() time.Duration {
+	if a.Cooldown <= 0 {
+		return defaultAutoSyncCooldown
+	}
+	return a.Cooldown.Duration()
+}
+
+// GetMaxAttempts returns the maximum number of automatic syncs, applying the default value.
+func (a *AutoSync) GetMaxAttempts() int {
+	if a.MaxAttempts <= 0 {
+		return defaultAutoSyncMaxAttempts
+	}
+	return a.MaxAttempts
+}
+
 // DeploymentPipeline represents the way to deploy the application.
 // The pipeline is triggered by changes in any of the following objects:
 // - Target PodSpec (Target can be Deployment, DaemonSet, StatefullSet)
@@ -83,6 +203,7 @@ type PipelineStage struct {
 	WaitStageOptions         *WaitStageOptions
 	WaitApprovalStageOptions *WaitApprovalStageOptions
 	AnalysisStageOptions     *AnalysisStageOptions
+	HookStageOptions         *HookStageOptions
 
 	K8sPrimaryRolloutStageOptions  *K8sPrimaryRolloutStageOptions
 	K8sCanaryRolloutStageOptions   *K8sCanaryRolloutStageOptions
@@ -133,11 +254,22 @@ func (s *PipelineStage) UnmarshalJSON(data []byte) error {
 		if len(gs.With) > 0 {
 			err = json.Unmarshal(gs.With, s.WaitApprovalStageOptions)
 		}
+		if err == nil {
+			err = s.WaitApprovalStageOptions.Validate()
+		}
 	case model.StageAnalysis:
 		s.AnalysisStageOptions = &AnalysisStageOptions{}
 		if len(gs.With) > 0 {
 			err = json.Unmarshal(gs.With, s.AnalysisStageOptions)
 		}
+	case model.StageHook:
+		s.HookStageOptions = &HookStageOptions{}
+		if len(gs.With) > 0 {
+			err = json.Unmarshal(gs.With, s.HookStageOptions)
+		}
+		if err == nil {
+			err = s.HookStageOptions.Validate()
+		}
 	case model.StageK8sPrimaryRollout:
 		s.K8sPrimaryRolloutStageOptions = &K8sPrimaryRolloutStageOptions{}
 		if len(gs.With) > 0 {
@@ -225,7 +357,34 @@ type WaitStageOptions struct {
 
 // WaitStageOptions contains all configurable values for a WAIT_APPROVAL stage.
 type WaitApprovalStageOptions struct {
+	// List of usernames or SSO teams configured in the project RBAC
+	// who are allowed to approve or reject the stage.
+	// Empty means every project member who can approve.
 	Approvers []string `json:"approvers"`
+	// The number of approvals from different approvers required to complete the stage.
+	// Default is 1.
+	MinApproverNum int `json:"minApproverNum"`
+	// How long to wait for the approvals before failing the stage.
+	// Zero means no timeout other than the one of the deployment.
+	Timeout Duration `json:"timeout"`
+}
+
+func (o *WaitApprovalStageOptions) Validate() error {
+	if o.MinApproverNum < 0 {
+		return fmt.Errorf("minApproverNum must not be negative")
+	}
+	if o.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative")
+	}
+	return nil
+}
+
+// GetMinApproverNum returns the number of required approvals, applying the default value.
+func (o *WaitApprovalStageOptions) GetMinApproverNum() int {
+	if o.MinApproverNum <= 0 {
+		return 1
+	}
+	return o.MinApproverNum
 }
 
 // AnalysisStageOptions contains all configurable values for a K8S_ANALYSIS stage.
@@ -241,6 +400,27 @@ type AnalysisStageOptions struct {
 	Dynamic          AnalysisDynamic              `json:"dynamic"`
 }
 
+// HookStageOptions contains all configurable values for a HOOK stage.
+// Exactly one of JobManifest or Script must be specified.
+type HookStageOptions struct {
+	// Relative path from the application directory to the file
+	// containing the manifest of the Kubernetes Job to be run.
+	JobManifest string `json:"jobManifest"`
+	// The namespace where the Job should be created.
+	// Empty means the namespace specified in the manifest.
+	Namespace string `json:"namespace"`
+	// The name of the Kubernetes cloud provider where the Job should be created.
+	// Empty means the cloud provider of the application.
+	CloudProvider string `json:"cloudProvider"`
+}
+
+func (o *HookStageOptions) Validate() error {
+	if o.JobManifest == "" {
+		return fmt.Errorf("jobManifest must be specified")
+	}
+	return nil
+}
+
 type AnalysisTemplateRef struct {
 	Name string            `json:"name"`
 	Args map[string]string `json:"args"`
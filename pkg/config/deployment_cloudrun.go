@@ -25,6 +25,9 @@ type CloudRunDeploymentSpec struct {
 
 // Validate returns an error if any wrong configuration value was found.
 func (s *CloudRunDeploymentSpec) Validate() error {
+	if err := s.GenericDeploymentSpec.Validate(); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -14,6 +14,21 @@
 
 package config
 
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+// OCIChartScheme is the prefix of the Helm chart repository stored in an OCI registry.
+const OCIChartScheme = "oci://"
+
+// ociChartVersionRegex matches an exact semantic version.
+// Ranges and floating tags are not allowed because the pulled charts are cached by version.
+var ociChartVersionRegex = regexp.MustCompile(`^v?(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)(-[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*)?(\+[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*)?$`)
+
 // KubernetesDeploymentSpec represents a deployment configuration for Kubernetes application.
 type KubernetesDeploymentSpec struct {
 	GenericDeploymentSpec
@@ -34,10 +49,121 @@ type KubernetesDeploymentSpec struct {
 	Workloads []K8sResourceReference `json:"workloads"`
 	// Which method should be used for traffic routing.
 	TrafficRouting *KubernetesTrafficRouting `json:"trafficRouting"`
+	// List of names of the Kubernetes cloud providers where the application should be deployed.
+	// The quick sync and all stages without their own cloudProviders are executed on all of them.
+	// Empty means only the cloud provider configured for the application.
+	CloudProviders []string `json:"cloudProviders"`
+	// Configuration for detecting the configuration drift.
+	DriftDetection *KubernetesDriftDetection `json:"driftDetection"`
 }
 
 // Validate returns an error if any wrong configuration value was found.
 func (s *KubernetesDeploymentSpec) Validate() error {
+	if err := s.GenericDeploymentSpec.Validate(); err != nil {
+		return err
+	}
+	if c := s.Input.HelmChart; c != nil && c.IsOCI() {
+		if c.Name == "" || c.Version == "" {
+			return fmt.Errorf("name and version of the helm chart stored in OCI registry must be set")
+		}
+		if strings.ContainsAny(c.Name, `/\`) || strings.Contains(c.Name, "..") {
+			return fmt.Errorf("name of the helm chart stored in OCI registry must not contain path separators or \"..\": %s", c.Name)
+		}
+		if !ociChartVersionRegex.MatchString(c.Version) {
+			return fmt.Errorf("version of the helm chart stored in OCI registry must be an exact semantic version: %s", c.Version)
+		}
+	}
+	if o := s.Input.HelmOptions; o != nil && o.HookTimeout < 0 {
+		return fmt.Errorf("hookTimeout of helm options must not be negative")
+	}
+	if s.DriftDetection != nil {
+		if err := s.DriftDetection.Validate(); err != nil {
+			return err
+		}
+	}
+
+	targets := make(map[string]struct{}, len(s.CloudProviders))
+	for _, name := range s.CloudProviders {
+		if name == "" {
+			return fmt.Errorf("cloudProviders must not contain an empty name")
+		}
+		if _, ok := targets[name]; ok {
+			return fmt.Errorf("duplicated cloud provider %s in cloudProviders", name)
+		}
+		targets[name] = struct{}{}
+	}
+
+	if s.Pipeline == nil {
+		return nil
+	}
+	for _, stage := range s.Pipeline.Stages {
+		names := K8sStageCloudProviders(stage)
+		if len(names) == 0 {
+			continue
+		}
+		if len(targets) == 0 {
+			return fmt.Errorf("stage %s: cloudProviders can be used only when cloudProviders of the deployment is configured", stage.Name)
+		}
+		for _, name := range names {
+			if _, ok := targets[name]; !ok {
+				return fmt.Errorf("stage %s: cloud provider %s is not one of the deployment's cloudProviders", stage.Name, name)
+			}
+		}
+	}
+	return nil
+}
+
+// PrimaryServerSideApply reports whether the resources of the PRIMARY variant are applied
+// by using server-side apply, by either the input, the quick sync or a K8S_PRIMARY_ROLLOUT stage.
+// The traffic routing and the rollback use it to apply those resources in the same way.
+func (s *KubernetesDeploymentSpec) PrimaryServerSideApply() bool {
+	if s.Input.ServerSideApply || s.QuickSync.ServerSideApply {
+		return true
+	}
+	if s.Pipeline == nil {
+		return false
+	}
+	for _, stage := range s.Pipeline.Stages {
+		if stage.Name != model.StageK8sPrimaryRollout || stage.K8sPrimaryRolloutStageOptions == nil {
+			continue
+		}
+		if stage.K8sPrimaryRolloutStageOptions.ServerSideApply {
+			return true
+		}
+	}
+	return false
+}
+
+// K8sStageCloudProviders returns the list of cloud providers
+// configured for the given Kubernetes stage.
+// Empty means the stage should be executed on all of the deployment's cloud providers.
+func K8sStageCloudProviders(s PipelineStage) []string {
+	switch s.Name {
+	case model.StageK8sPrimaryRollout:
+		if s.K8sPrimaryRolloutStageOptions != nil {
+			return s.K8sPrimaryRolloutStageOptions.CloudProviders
+		}
+	case model.StageK8sCanaryRollout:
+		if s.K8sCanaryRolloutStageOptions != nil {
+			return s.K8sCanaryRolloutStageOptions.CloudProviders
+		}
+	case model.StageK8sCanaryClean:
+		if s.K8sCanaryCleanStageOptions != nil {
+			return s.K8sCanaryCleanStageOptions.CloudProviders
+		}
+	case model.StageK8sBaselineRollout:
+		if s.K8sBaselineRolloutStageOptions != nil {
+			return s.K8sBaselineRolloutStageOptions.CloudProviders
+		}
+	case model.StageK8sBaselineClean:
+		if s.K8sBaselineCleanStageOptions != nil {
+			return s.K8sBaselineCleanStageOptions.CloudProviders
+		}
+	case model.StageK8sTrafficRouting:
+		if s.K8sTrafficRoutingStageOptions != nil {
+			return s.K8sTrafficRoutingStageOptions.CloudProviders
+		}
+	}
 	return nil
 }
 
@@ -63,6 +189,12 @@ type KubernetesDeploymentInput struct {
 
 	// The namespace where manifests will be applied.
 	Namespace string `json:"namespace"`
+	// Whether to apply manifests by using server-side apply with the "pipecd" field manager.
+	// Default is false, which means client-side apply is used.
+	ServerSideApply bool `json:"serverSideApply"`
+	// Whether to take over the ownership of the fields conflicting with other field managers.
+	// Only valid when server-side apply is used.
+	ForceConflicts bool `json:"forceConflicts"`
 
 	// Automatically reverts all deployment changes on failure.
 	// Default is true.
@@ -78,12 +210,25 @@ type InputHelmChart struct {
 	// Relative path from the repository root directory to the chart directory.
 	Path string `json:"path"`
 
-	// The name of an added Helm Chart Repository.
+	// The name of an added Helm Chart Repository
+	// or the address of an OCI registry prefixed by "oci://".
+	// e.g. oci://harbor.example.com/charts
 	Repository string `json:"repository"`
 	Name       string `json:"name"`
 	Version    string `json:"version"`
 }
 
+// IsOCI returns true if the chart is stored in an OCI registry.
+func (c *InputHelmChart) IsOCI() bool {
+	return strings.HasPrefix(c.Repository, OCIChartScheme)
+}
+
+// OCIReference returns the reference to the chart in OCI registry.
+// e.g. oci://harbor.example.com/charts/helloworld
+func (c *InputHelmChart) OCIReference() string {
+	return strings.TrimSuffix(c.Repository, "/") + "/" + c.Name
+}
+
 type InputHelmOptions struct {
 	// The release name of helm deployment.
 	// By default the release name is equal to the application name.
@@ -92,6 +237,82 @@ type InputHelmOptions struct {
 	ValueFiles []string `json:"valueFiles"`
 	// List of file path for values.
 	SetFiles map[string]string
+	// List of values that should be set by using --set flag.
+	// e.g. image.tag: v1.0.0
+	SetValues map[string]string `json:"setValues"`
+	// Inline values that are used together with the value files.
+	// They take precedence over the ones in the value files.
+	Values map[string]interface{} `json:"values"`
+	// List of Kubernetes api versions used for Capabilities.APIVersions.
+	APIVersions []string `json:"apiVersions"`
+	// Kubernetes version used for Capabilities.KubeVersion.
+	KubeVersion string `json:"kubeVersion"`
+	// Whether the CRDs in the chart should be included in the rendered manifests.
+	IncludeCRDs bool `json:"includeCRDs"`
+	// Whether the Helm hooks should be rendered and executed.
+	// The pre-install and pre-upgrade hooks are run before syncing the resources,
+	// the post-install and post-upgrade hooks are run after that.
+	// This is applied to only K8S_SYNC and K8S_PRIMARY_ROLLOUT stages.
+	EnableHooks bool `json:"enableHooks"`
+	// How long to wait for each Job or Pod hook to complete.
+	// By default the timeout of the stage running the hooks is used.
+	HookTimeout Duration `json:"hookTimeout"`
+}
+
+// KubernetesDriftDetection contains configurable values for detecting the configuration drift.
+type KubernetesDriftDetection struct {
+	// List of fields whose differences between Git and the cluster should be ignored.
+	// They are added to the default ones configured for the project.
+	IgnoreFields []KubernetesDriftIgnoreField `json:"ignoreFields"`
+}
+
+func (d *KubernetesDriftDetection) Validate() error {
+	for _, f := range d.IgnoreFields {
+		if err := f.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// KubernetesDriftIgnoreField represents the fields of some resources
+// whose differences should be ignored.
+type KubernetesDriftIgnoreField struct {
+	// The kind of the resources to apply to. Empty means all kinds.
+	Kind string `json:"kind"`
+	// The name of the resources to apply to. Empty means all names.
+	Name string `json:"name"`
+	// List of regular expressions matching the dot-separated paths of the ignored fields.
+	// e.g. ^spec\.replicas$
+	Paths []string `json:"paths"`
+}
+
+func (f *KubernetesDriftIgnoreField) Validate() error {
+	if len(f.Paths) == 0 {
+		return fmt.Errorf("paths of drift detection ignoreFields must not be empty")
+	}
+	for _, p := range f.Paths {
+		if _, err := regexp.Compile(p); err != nil {
+			return fmt.Errorf("invalid path %q in drift detection ignoreFields: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// ValidateProjectDriftDetection validates the default ignored fields
+// configured for all applications of a project.
+func ValidateProjectDriftDetection(c *model.ProjectDriftDetectionConfig) error {
+	for _, f := range c.GetKubernetesIgnoreFields() {
+		field := KubernetesDriftIgnoreField{
+			Kind:  f.Kind,
+			Name:  f.Name,
+			Paths: f.Paths,
+		}
+		if err := field.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 type KubernetesTrafficRoutingMethod string
@@ -142,6 +363,9 @@ type K8sSyncStageOptions struct {
 	AddVariantLabelToSelector bool `json:"addVariantLabelToSelector"`
 	// Whether the resources that are no longer defined in Git should be removed or not.
 	Prune bool `json:"prune"`
+	// Whether to apply manifests by using server-side apply in this stage
+	// even if it is not enabled in the input.
+	ServerSideApply bool `json:"serverSideApply"`
 }
 
 // K8sPrimaryRolloutStageOptions contains all configurable values for a K8S_PRIMARY_ROLLOUT stage.
@@ -155,6 +379,12 @@ type K8sPrimaryRolloutStageOptions struct {
 	AddVariantLabelToSelector bool `json:"addVariantLabelToSelector"`
 	// Whether the resources that are no longer defined in Git should be removed or not.
 	Prune bool `json:"prune"`
+	// List of names of the cloud providers where this stage should be executed.
+	// Empty means all cloud providers of the deployment.
+	CloudProviders []string `json:"cloudProviders"`
+	// Whether to apply manifests by using server-side apply in this stage
+	// even if it is not enabled in the input.
+	ServerSideApply bool `json:"serverSideApply"`
 }
 
 // K8sCanaryRolloutStageOptions contains all configurable values for a K8S_CANARY_ROLLOUT stage.
@@ -169,10 +399,19 @@ type K8sCanaryRolloutStageOptions struct {
 	Suffix string `json:"suffix"`
 	// Whether the CANARY service should be created.
 	CreateService bool `json:"createService"`
+	// List of names of the cloud providers where this stage should be executed.
+	// Empty means all cloud providers of the deployment.
+	CloudProviders []string `json:"cloudProviders"`
+	// Whether to apply manifests by using server-side apply in this stage
+	// even if it is not enabled in the input.
+	ServerSideApply bool `json:"serverSideApply"`
 }
 
 // K8sCanaryCleanStageOptions contains all configurable values for a K8S_CANARY_CLEAN stage.
 type K8sCanaryCleanStageOptions struct {
+	// List of names of the cloud providers where this stage should be executed.
+	// Empty means all cloud providers of the deployment.
+	CloudProviders []string `json:"cloudProviders"`
 }
 
 // K8sBaselineRolloutStageOptions contains all configurable values for a K8S_BASELINE_ROLLOUT stage.
@@ -187,10 +426,19 @@ type K8sBaselineRolloutStageOptions struct {
 	Suffix string `json:"suffix"`
 	// Whether the BASELINE service should be created.
 	CreateService bool `json:"createService"`
+	// List of names of the cloud providers where this stage should be executed.
+	// Empty means all cloud providers of the deployment.
+	CloudProviders []string `json:"cloudProviders"`
+	// Whether to apply manifests by using server-side apply in this stage
+	// even if it is not enabled in the input.
+	ServerSideApply bool `json:"serverSideApply"`
 }
 
 // K8sBaselineCleanStageOptions contains all configurable values for a K8S_BASELINE_CLEAN stage.
 type K8sBaselineCleanStageOptions struct {
+	// List of names of the cloud providers where this stage should be executed.
+	// Empty means all cloud providers of the deployment.
+	CloudProviders []string `json:"cloudProviders"`
 }
 
 // K8sTrafficRoutingStageOptions contains all configurable values for a K8S_TRAFFIC_ROUTING stage.
@@ -204,6 +452,9 @@ type K8sTrafficRoutingStageOptions struct {
 	Canary int `json:"canary"`
 	// The percentage of traffic should be routed to BASELINE variant.
 	Baseline int `json:"baseline"`
+	// List of names of the cloud providers where this stage should be executed.
+	// Empty means all cloud providers of the deployment.
+	CloudProviders []string `json:"cloudProviders"`
 }
 
 func (opts K8sTrafficRoutingStageOptions) Percentages() (primary, canary, baseline int) {
@@ -16,6 +16,7 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -78,6 +79,61 @@ func TestKubernetesDeploymentConfig(t *testing.T) {
 			},
 			expectedError: nil,
 		},
+		{
+			fileName:           "testdata/application/k8s-app-multi-cluster.yaml",
+			expectedKind:       KindKubernetesApp,
+			expectedAPIVersion: "pipecd.dev/v1beta1",
+			expectedSpec: &KubernetesDeploymentSpec{
+				GenericDeploymentSpec: GenericDeploymentSpec{
+					Pipeline: &DeploymentPipeline{
+						Stages: []PipelineStage{
+							{
+								Name: model.StageK8sCanaryRollout,
+								K8sCanaryRolloutStageOptions: &K8sCanaryRolloutStageOptions{
+									Replicas: Replicas{
+										Number:       10,
+										IsPercentage: true,
+									},
+									CloudProviders: []string{"cluster-1"},
+								},
+							},
+							{
+								Name:                          model.StageK8sPrimaryRollout,
+								K8sPrimaryRolloutStageOptions: &K8sPrimaryRolloutStageOptions{},
+							},
+							{
+								Name: model.StageK8sCanaryClean,
+								K8sCanaryCleanStageOptions: &K8sCanaryCleanStageOptions{
+									CloudProviders: []string{"cluster-1"},
+								},
+							},
+						},
+					},
+				},
+				Input:          KubernetesDeploymentInput{AutoRollback: true},
+				CloudProviders: []string{"cluster-1", "cluster-2"},
+			},
+			expectedError: nil,
+		},
+		{
+			fileName:           "testdata/application/k8s-app-auto-sync.yaml",
+			expectedKind:       KindKubernetesApp,
+			expectedAPIVersion: "pipecd.dev/v1beta1",
+			expectedSpec: &KubernetesDeploymentSpec{
+				GenericDeploymentSpec: GenericDeploymentSpec{
+					AutoSync: &AutoSync{
+						Enabled:     true,
+						Cooldown:    Duration(10 * time.Minute),
+						MaxAttempts: 5,
+					},
+				},
+				Input: KubernetesDeploymentInput{
+					Manifests:    []string{"deployment.yaml"},
+					AutoRollback: true,
+				},
+			},
+			expectedError: nil,
+		},
 		// {
 		// 	fileName:           "testdata/application/k8s-app-canary.yaml",
 		// 	expectedKind:       KindKubernetesApp,
@@ -125,3 +181,321 @@ func TestKubernetesDeploymentConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestKubernetesDeploymentSpecValidate(t *testing.T) {
+	canaryStage := func(cloudProviders ...string) PipelineStage {
+		return PipelineStage{
+			Name: model.StageK8sCanaryRollout,
+			K8sCanaryRolloutStageOptions: &K8sCanaryRolloutStageOptions{
+				CloudProviders: cloudProviders,
+			},
+		}
+	}
+	testcases := []struct {
+		name    string
+		spec    KubernetesDeploymentSpec
+		wantErr bool
+	}{
+		{
+			name: "no cloud providers",
+			spec: KubernetesDeploymentSpec{
+				GenericDeploymentSpec: GenericDeploymentSpec{
+					Pipeline: &DeploymentPipeline{Stages: []PipelineStage{canaryStage()}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "stage targets one of the cloud providers",
+			spec: KubernetesDeploymentSpec{
+				GenericDeploymentSpec: GenericDeploymentSpec{
+					Pipeline: &DeploymentPipeline{Stages: []PipelineStage{canaryStage("cluster-1")}},
+				},
+				CloudProviders: []string{"cluster-1", "cluster-2"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid drift detection ignore fields",
+			spec: KubernetesDeploymentSpec{
+				DriftDetection: &KubernetesDriftDetection{
+					IgnoreFields: []KubernetesDriftIgnoreField{
+						{Kind: "Deployment", Paths: []string{`^spec\.replicas$`}},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "drift detection ignore field without paths",
+			spec: KubernetesDeploymentSpec{
+				DriftDetection: &KubernetesDriftDetection{
+					IgnoreFields: []KubernetesDriftIgnoreField{
+						{Kind: "Deployment"},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "drift detection ignore field with invalid path",
+			spec: KubernetesDeploymentSpec{
+				DriftDetection: &KubernetesDriftDetection{
+					IgnoreFields: []KubernetesDriftIgnoreField{
+						{Paths: []string{`spec.(replicas`}},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "oci chart with version",
+			spec: KubernetesDeploymentSpec{
+				Input: KubernetesDeploymentInput{
+					HelmChart: &InputHelmChart{
+						Repository: "oci://harbor.example.com/charts",
+						Name:       "helloworld",
+						Version:    "v0.1.0",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "oci chart with pre-release version",
+			spec: KubernetesDeploymentSpec{
+				Input: KubernetesDeploymentInput{
+					HelmChart: &InputHelmChart{
+						Repository: "oci://harbor.example.com/charts",
+						Name:       "helloworld",
+						Version:    "1.2.3-rc.1+build.5",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "oci chart with version range",
+			spec: KubernetesDeploymentSpec{
+				Input: KubernetesDeploymentInput{
+					HelmChart: &InputHelmChart{
+						Repository: "oci://harbor.example.com/charts",
+						Name:       "helloworld",
+						Version:    "^1.2.0",
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "oci chart with floating tag",
+			spec: KubernetesDeploymentSpec{
+				Input: KubernetesDeploymentInput{
+					HelmChart: &InputHelmChart{
+						Repository: "oci://harbor.example.com/charts",
+						Name:       "helloworld",
+						Version:    "latest",
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "oci chart with version escaping the cache",
+			spec: KubernetesDeploymentSpec{
+				Input: KubernetesDeploymentInput{
+					HelmChart: &InputHelmChart{
+						Repository: "oci://harbor.example.com/charts",
+						Name:       "helloworld",
+						Version:    "../../1.0.0",
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "oci chart with path separator in name",
+			spec: KubernetesDeploymentSpec{
+				Input: KubernetesDeploymentInput{
+					HelmChart: &InputHelmChart{
+						Repository: "oci://harbor.example.com/charts",
+						Name:       "../../etc",
+						Version:    "v0.1.0",
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "oci chart with dot-dot name",
+			spec: KubernetesDeploymentSpec{
+				Input: KubernetesDeploymentInput{
+					HelmChart: &InputHelmChart{
+						Repository: "oci://harbor.example.com/charts",
+						Name:       "..",
+						Version:    "v0.1.0",
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "oci chart without version",
+			spec: KubernetesDeploymentSpec{
+				Input: KubernetesDeploymentInput{
+					HelmChart: &InputHelmChart{
+						Repository: "oci://harbor.example.com/charts",
+						Name:       "helloworld",
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicated cloud provider",
+			spec: KubernetesDeploymentSpec{
+				CloudProviders: []string{"cluster-1", "cluster-1"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "stage targets an unknown cloud provider",
+			spec: KubernetesDeploymentSpec{
+				GenericDeploymentSpec: GenericDeploymentSpec{
+					Pipeline: &DeploymentPipeline{Stages: []PipelineStage{canaryStage("cluster-3")}},
+				},
+				CloudProviders: []string{"cluster-1", "cluster-2"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "stage cloud providers without deployment cloud providers",
+			spec: KubernetesDeploymentSpec{
+				GenericDeploymentSpec: GenericDeploymentSpec{
+					Pipeline: &DeploymentPipeline{Stages: []PipelineStage{canaryStage("cluster-1")}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative hook timeout",
+			spec: KubernetesDeploymentSpec{
+				Input: KubernetesDeploymentInput{
+					HelmOptions: &InputHelmOptions{
+						EnableHooks: true,
+						HookTimeout: Duration(-time.Minute),
+					},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.spec.Validate()
+			assert.Equal(t, tc.wantErr, err != nil)
+		})
+	}
+}
+
+func TestKubernetesDeploymentSpecPrimaryServerSideApply(t *testing.T) {
+	primaryStage := func(serverSideApply bool) PipelineStage {
+		return PipelineStage{
+			Name: model.StageK8sPrimaryRollout,
+			K8sPrimaryRolloutStageOptions: &K8sPrimaryRolloutStageOptions{
+				ServerSideApply: serverSideApply,
+			},
+		}
+	}
+	testcases := []struct {
+		name     string
+		spec     KubernetesDeploymentSpec
+		expected bool
+	}{
+		{
+			name: "not enabled",
+			spec: KubernetesDeploymentSpec{
+				GenericDeploymentSpec: GenericDeploymentSpec{
+					Pipeline: &DeploymentPipeline{Stages: []PipelineStage{primaryStage(false)}},
+				},
+			},
+			expected: false,
+		},
+		{
+			name: "enabled in the input",
+			spec: KubernetesDeploymentSpec{
+				Input: KubernetesDeploymentInput{ServerSideApply: true},
+			},
+			expected: true,
+		},
+		{
+			name: "enabled in the quick sync",
+			spec: KubernetesDeploymentSpec{
+				QuickSync: K8sSyncStageOptions{ServerSideApply: true},
+			},
+			expected: true,
+		},
+		{
+			name: "enabled in the primary rollout stage",
+			spec: KubernetesDeploymentSpec{
+				GenericDeploymentSpec: GenericDeploymentSpec{
+					Pipeline: &DeploymentPipeline{Stages: []PipelineStage{primaryStage(true)}},
+				},
+			},
+			expected: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.spec.PrimaryServerSideApply())
+		})
+	}
+}
+
+func TestValidateProjectDriftDetection(t *testing.T) {
+	testcases := []struct {
+		name    string
+		cfg     *model.ProjectDriftDetectionConfig
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			cfg: &model.ProjectDriftDetectionConfig{
+				KubernetesIgnoreFields: []*model.KubernetesDriftIgnoreField{
+					{Kind: "Deployment", Paths: []string{`^spec\.replicas$`}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "empty",
+			cfg:     &model.ProjectDriftDetectionConfig{},
+			wantErr: false,
+		},
+		{
+			name: "ignore field without paths",
+			cfg: &model.ProjectDriftDetectionConfig{
+				KubernetesIgnoreFields: []*model.KubernetesDriftIgnoreField{
+					{Kind: "Deployment"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid regular expression",
+			cfg: &model.ProjectDriftDetectionConfig{
+				KubernetesIgnoreFields: []*model.KubernetesDriftIgnoreField{
+					{Paths: []string{`spec.(replicas`}},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateProjectDriftDetection(tc.cfg)
+			assert.Equal(t, tc.wantErr, err != nil)
+		})
+	}
+}
@@ -16,6 +16,7 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -89,7 +90,73 @@ func TestTerraformDeploymentConfig(t *testing.T) {
 							{
 								Name: model.StageWaitApproval,
 								WaitApprovalStageOptions: &WaitApprovalStageOptions{
-									Approvers: []string{"foo", "bar"},
+									Approvers:      []string{"foo", "pipe-cd/sre"},
+									MinApproverNum: 2,
+									Timeout:        Duration(2 * time.Hour),
+								},
+							},
+							{
+								Name:                       model.StageTerraformApply,
+								TerraformApplyStageOptions: &TerraformApplyStageOptions{},
+							},
+						},
+					},
+				},
+				Input: TerraformDeploymentInput{
+					Workspace:        "dev",
+					TerraformVersion: "0.12.23",
+				},
+			},
+			expectedError: nil,
+		},
+		{
+			fileName:           "testdata/application/terraform-app-with-chain.yaml",
+			expectedKind:       KindTerraformApp,
+			expectedAPIVersion: "pipecd.dev/v1beta1",
+			expectedSpec: &TerraformDeploymentSpec{
+				GenericDeploymentSpec: GenericDeploymentSpec{
+					Chain: &DeploymentChain{
+						Applications: []ChainApplication{
+							{Name: "backend"},
+							{Name: "frontend", Env: "staging"},
+						},
+					},
+				},
+				Input: TerraformDeploymentInput{
+					Workspace:        "dev",
+					TerraformVersion: "0.12.23",
+				},
+			},
+			expectedError: nil,
+		},
+		{
+			fileName:           "testdata/application/terraform-app-with-hooks.yaml",
+			expectedKind:       KindTerraformApp,
+			expectedAPIVersion: "pipecd.dev/v1beta1",
+			expectedSpec: &TerraformDeploymentSpec{
+				GenericDeploymentSpec: GenericDeploymentSpec{
+					Hooks: &DeploymentHooks{
+						PreSync: &HookStageOptions{
+							JobManifest:   "hooks/check-job.yaml",
+							CloudProvider: "kubernetes-dev",
+						},
+						PostSync: &HookStageOptions{
+							JobManifest:   "hooks/smoke-test-job.yaml",
+							Namespace:     "hooks",
+							CloudProvider: "kubernetes-dev",
+						},
+					},
+					Pipeline: &DeploymentPipeline{
+						Stages: []PipelineStage{
+							{
+								Name:                      model.StageTerraformPlan,
+								TerraformPlanStageOptions: &TerraformPlanStageOptions{},
+							},
+							{
+								Name: model.StageHook,
+								HookStageOptions: &HookStageOptions{
+									JobManifest:   "hooks/notify-job.yaml",
+									CloudProvider: "kubernetes-dev",
 								},
 							},
 							{
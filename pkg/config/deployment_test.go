@@ -16,6 +16,7 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -71,3 +72,49 @@ func TestHasStage(t *testing.T) {
 		})
 	}
 }
+
+func TestAutoSync(t *testing.T) {
+	testcases := []struct {
+		name                string
+		autoSync            AutoSync
+		expectedCooldown    time.Duration
+		expectedMaxAttempts int
+		wantErr             bool
+	}{
+		{
+			name:                "default values",
+			autoSync:            AutoSync{Enabled: true},
+			expectedCooldown:    5 * time.Minute,
+			expectedMaxAttempts: 3,
+		},
+		{
+			name: "specified values",
+			autoSync: AutoSync{
+				Enabled:     true,
+				Cooldown:    Duration(time.Minute),
+				MaxAttempts: 10,
+			},
+			expectedCooldown:    time.Minute,
+			expectedMaxAttempts: 10,
+		},
+		{
+			name: "negative max attempts",
+			autoSync: AutoSync{
+				Enabled:     true,
+				MaxAttempts: -1,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.autoSync.Validate()
+			assert.Equal(t, tc.wantErr, err != nil)
+			if tc.wantErr {
+				return
+			}
+			assert.Equal(t, tc.expectedCooldown, tc.autoSync.GetCooldown())
+			assert.Equal(t, tc.expectedMaxAttempts, tc.autoSync.GetMaxAttempts())
+		})
+	}
+}
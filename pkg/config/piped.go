@@ -17,8 +17,11 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
+	"k8s.io/client-go/util/jsonpath"
+
 	"github.com/pipe-cd/pipe/pkg/model"
 )
 
@@ -47,12 +50,18 @@ type PipedSpec struct {
 	// How often to check whether an application should be synced.
 	// Default is 1m.
 	SyncInterval Duration `json:"syncInterval"`
+	// The maximum number of deployments this piped can run at the same time.
+	// The PLANNED deployments exceeding this limit will be queued.
+	// Default is 0, which means no limit.
+	MaxConcurrentDeployments int `json:"maxConcurrentDeployments"`
 	// Git configuration needed for git commands.
 	Git PipedGit `json:"git"`
 	// List of git repositories this piped will handle.
 	Repositories []PipedRepository `json:"repositories"`
 	// List of helm chart repositories that should be added while starting up.
 	ChartRepositories []HelmChartRepository `json:"chartRepositories"`
+	// List of OCI registries storing Helm charts that need to be logged in.
+	ChartRegistries []HelmChartRegistry `json:"chartRegistries"`
 	// List of cloud providers can be used by this piped.
 	CloudProviders []PipedCloudProvider `json:"cloudProviders"`
 	// List of analysis providers can be used by this piped.
@@ -65,6 +74,10 @@ type PipedSpec struct {
 	SealedSecretManagement *SealedSecretManagement `json:"sealedSecretManagement"`
 	// Optional settings for image watcher.
 	ImageWatcher PipedImageWatcher `json:"imageWatcher"`
+
+	// The version of the centrally managed configuration this spec was loaded from.
+	// Zero means it was loaded from the local configuration file.
+	ConfigVersion int64 `json:"-"`
 }
 
 // Validate validates configured data of all fields.
@@ -87,6 +100,9 @@ func (s *PipedSpec) Validate() error {
 	if s.SyncInterval < 0 {
 		s.SyncInterval = Duration(time.Minute)
 	}
+	if s.MaxConcurrentDeployments < 0 {
+		return fmt.Errorf("maxConcurrentDeployments must not be negative")
+	}
 	if s.SealedSecretManagement != nil {
 		if err := s.SealedSecretManagement.Validate(); err != nil {
 			return err
@@ -95,6 +111,24 @@ func (s *PipedSpec) Validate() error {
 	if err := s.ImageWatcher.Validate(); err != nil {
 		return err
 	}
+	if err := s.Notifications.Validate(); err != nil {
+		return err
+	}
+	for _, r := range s.ChartRegistries {
+		if err := r.Validate(s.ImageProviders); err != nil {
+			return err
+		}
+	}
+	for _, cp := range s.CloudProviders {
+		if cp.KubernetesConfig == nil {
+			continue
+		}
+		for _, r := range cp.KubernetesConfig.HealthRules {
+			if err := r.Validate(); err != nil {
+				return fmt.Errorf("invalid health rule of cloud provider %s: %w", cp.Name, err)
+			}
+		}
+	}
 	return nil
 }
 
@@ -212,6 +246,41 @@ type HelmChartRepository struct {
 	Password string `json:"password"`
 }
 
+// HelmChartRegistry represents an OCI registry storing Helm charts.
+// The credentials can be given directly or taken from one of the configured image providers.
+type HelmChartRegistry struct {
+	// The address of the OCI registry.
+	// e.g. harbor.example.com
+	Address string `json:"address"`
+	// Username used to login to the registry.
+	Username string `json:"username"`
+	// Password used to login to the registry.
+	Password string `json:"password"`
+	// The name of the image provider whose credentials should be used to login.
+	ImageProvider string `json:"imageProvider"`
+}
+
+func (r *HelmChartRegistry) Validate(imageProviders []PipedImageProvider) error {
+	if r.Address == "" {
+		return fmt.Errorf("address of chart registry must be set")
+	}
+	if strings.Contains(r.Address, "://") {
+		return fmt.Errorf("address of chart registry %s must not contain the scheme", r.Address)
+	}
+	if r.ImageProvider == "" {
+		return nil
+	}
+	if r.Username != "" || r.Password != "" {
+		return fmt.Errorf("chart registry %s must not have both username/password and imageProvider", r.Address)
+	}
+	for _, p := range imageProviders {
+		if p.Name == r.ImageProvider {
+			return nil
+		}
+	}
+	return fmt.Errorf("missing image provider %s that is used by chart registry %s", r.ImageProvider, r.Address)
+}
+
 type PipedCloudProvider struct {
 	Name string
 	Type model.CloudProviderType
@@ -273,6 +342,127 @@ type CloudProviderKubernetesConfig struct {
 	KubeConfigPath string `json:"kubeConfigPath"`
 	// Configuration for application resource informer.
 	AppStateInformer KubernetesAppStateInformer `json:"appStateInformer"`
+	// List of rules to determine the health status of the resources
+	// whose kinds are not known by piped, such as custom resources.
+	HealthRules []KubernetesHealthRule `json:"healthRules"`
+}
+
+type KubernetesHealthStatus string
+
+const (
+	KubernetesHealthStatusHealthy     KubernetesHealthStatus = "HEALTHY"
+	KubernetesHealthStatusProgressing KubernetesHealthStatus = "PROGRESSING"
+	KubernetesHealthStatusUnhealthy   KubernetesHealthStatus = "UNHEALTHY"
+)
+
+func (s KubernetesHealthStatus) Validate() error {
+	switch s {
+	case KubernetesHealthStatusHealthy, KubernetesHealthStatusProgressing, KubernetesHealthStatusUnhealthy:
+		return nil
+	default:
+		return fmt.Errorf("unsupported health status %q", s)
+	}
+}
+
+// KubernetesHealthRule determines the health status of the resources of a kind.
+// The checks are evaluated in order and the first matched one decides the status.
+type KubernetesHealthRule struct {
+	// The APIVersion of the resources.
+	APIVersion string `json:"apiVersion"`
+	// The kind of the resources.
+	Kind string `json:"kind"`
+	// List of checks evaluated in order.
+	Checks []KubernetesHealthCheck `json:"checks"`
+	// The health status used when no check was matched.
+	// Default is PROGRESSING.
+	DefaultStatus KubernetesHealthStatus `json:"defaultStatus"`
+}
+
+func (r *KubernetesHealthRule) Validate() error {
+	if r.APIVersion == "" {
+		return fmt.Errorf("apiVersion must be set")
+	}
+	if r.Kind == "" {
+		return fmt.Errorf("kind must be set")
+	}
+	if len(r.Checks) == 0 {
+		return fmt.Errorf("at least one check must be set for %s/%s", r.APIVersion, r.Kind)
+	}
+	for _, c := range r.Checks {
+		if err := c.Validate(); err != nil {
+			return fmt.Errorf("invalid check for %s/%s: %w", r.APIVersion, r.Kind, err)
+		}
+	}
+	if r.DefaultStatus != "" {
+		if err := r.DefaultStatus.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetDefaultStatus returns the health status used when no check was matched.
+func (r *KubernetesHealthRule) GetDefaultStatus() KubernetesHealthStatus {
+	if r.DefaultStatus == "" {
+		return KubernetesHealthStatusProgressing
+	}
+	return r.DefaultStatus
+}
+
+// KubernetesHealthCheck matches a resource either by one of its status conditions
+// or by the result of a JSONPath expression.
+// Exactly one of condition and jsonPath must be set.
+type KubernetesHealthCheck struct {
+	// Matches when the resource has a status condition of this type
+	// with the given status and reason.
+	Condition *KubernetesHealthCondition `json:"condition"`
+	// The JSONPath expression evaluated against the resource, e.g. "{.status.phase}".
+	JSONPath string `json:"jsonPath"`
+	// The value the result of the JSONPath expression must be equal to.
+	// Empty means matching any non-empty result.
+	Value string `json:"value"`
+	// The health status of the resource when matched.
+	Status KubernetesHealthStatus `json:"status"`
+	// The description of the health status.
+	// Empty means using the message of the matched condition.
+	Description string `json:"description"`
+}
+
+func (c *KubernetesHealthCheck) Validate() error {
+	if (c.Condition == nil) == (c.JSONPath == "") {
+		return fmt.Errorf("exactly one of condition and jsonPath must be set")
+	}
+	if c.Condition != nil && c.Condition.Type == "" {
+		return fmt.Errorf("condition.type must be set")
+	}
+	if c.Value != "" && c.JSONPath == "" {
+		return fmt.Errorf("value can be used only with jsonPath")
+	}
+	if c.JSONPath != "" {
+		if err := jsonpath.New("health").Parse(c.JSONPath); err != nil {
+			return fmt.Errorf("invalid jsonPath %q: %w", c.JSONPath, err)
+		}
+	}
+	return c.Status.Validate()
+}
+
+type KubernetesHealthCondition struct {
+	// The type of the condition, e.g. Ready.
+	Type string `json:"type"`
+	// The status of the condition.
+	// Default is True.
+	Status string `json:"status"`
+	// The reason of the condition.
+	// Empty means any reason.
+	Reason string `json:"reason"`
+}
+
+// GetStatus returns the condition status the check expects.
+func (c *KubernetesHealthCondition) GetStatus() string {
+	if c.Status == "" {
+		return "True"
+	}
+	return c.Status
 }
 
 type KubernetesAppStateInformer struct {
@@ -468,6 +658,22 @@ type Notifications struct {
 	Receivers []NotificationReceiver `json:"receivers"`
 }
 
+func (n *Notifications) Validate() error {
+	receivers := make(map[string]struct{}, len(n.Receivers))
+	for _, r := range n.Receivers {
+		receivers[r.Name] = struct{}{}
+	}
+	for _, r := range n.Routes {
+		if _, ok := receivers[r.Receiver]; !ok {
+			return fmt.Errorf("missing receiver %s that is used in route %s", r.Receiver, r.Name)
+		}
+		if _, err := model.ParseLabelSelector(r.LabelSelector); err != nil {
+			return fmt.Errorf("invalid labelSelector in notification route %s: %w", r.Name, err)
+		}
+	}
+	return nil
+}
+
 type NotificationRoute struct {
 	Name         string   `json:"name"`
 	Receiver     string   `json:"receiver"`
@@ -479,6 +685,9 @@ type NotificationRoute struct {
 	IgnoreApps   []string `json:"ignoreApps"`
 	Envs         []string `json:"envs"`
 	IgnoreEnvs   []string `json:"ignoreEnvs"`
+	// Kubernetes-style label selector to filter the events by the labels of their application.
+	// e.g. "team=pipe-cd,tier in (backend)"
+	LabelSelector string `json:"labelSelector"`
 }
 
 type NotificationReceiver struct {
@@ -489,6 +698,10 @@ type NotificationReceiver struct {
 
 type NotificationReceiverSlack struct {
 	HookURL string `json:"hookURL"`
+	// Whether to add Approve and Reject buttons to the messages of WAIT_APPROVAL stages.
+	// This requires the hookURL to be an incoming webhook of a Slack app
+	// whose interactivity request URL is pointing to the control plane.
+	InteractiveApproval bool `json:"interactiveApproval"`
 }
 
 type NotificationReceiverWebhook struct {
@@ -0,0 +1,136 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"sigs.k8s.io/yaml"
+)
+
+// secretReferenceRegex matches the secret references such as ${secret:slack-hook-url}
+// those are used in the centrally managed piped configuration instead of the secret values.
+var secretReferenceRegex = regexp.MustCompile(`\$\{secret:([A-Za-z0-9_.-]+)\}`)
+
+// SecretResolver returns the value of the secret referenced by the given name.
+type SecretResolver func(name string) (string, error)
+
+// DecodeCentralPipedSpec decodes the piped configuration managed by the control plane.
+// The fields used to connect to the control plane must not be specified in that configuration
+// because they are always taken from the given local configuration.
+// All secret references are replaced by the values returned from the given resolver.
+func DecodeCentralPipedSpec(data []byte, local *PipedSpec, resolve SecretResolver) (*PipedSpec, error) {
+	js, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the configuration before resolving to ensure that
+	// no secret value was inlined.
+	c := &Config{}
+	if err := json.Unmarshal(js, c); err != nil {
+		return nil, err
+	}
+	if c.Kind != KindPiped {
+		return nil, fmt.Errorf("wrong configuration kind for piped: %v", c.Kind)
+	}
+	if err := c.PipedSpec.validateCentralFields(); err != nil {
+		return nil, err
+	}
+
+	var resolveErr error
+	js = secretReferenceRegex.ReplaceAllFunc(js, func(ref []byte) []byte {
+		name := string(secretReferenceRegex.FindSubmatch(ref)[1])
+		value, err := resolve(name)
+		if err != nil {
+			if resolveErr == nil {
+				resolveErr = fmt.Errorf("failed to resolve secret %s: %w", name, err)
+			}
+			return ref
+		}
+		// The reference is always placed inside a JSON string
+		// so the value must be escaped and unquoted.
+		escaped, _ := json.Marshal(value)
+		return escaped[1 : len(escaped)-1]
+	})
+	if resolveErr != nil {
+		return nil, resolveErr
+	}
+
+	c = &Config{}
+	if err := json.Unmarshal(js, c); err != nil {
+		return nil, err
+	}
+	spec := c.PipedSpec
+	spec.ProjectID = local.ProjectID
+	spec.PipedID = local.PipedID
+	spec.PipedKeyFile = local.PipedKeyFile
+	spec.APIAddress = local.APIAddress
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// ValidateCentralPipedConfig validates the given data as a piped configuration
+// managed by the control plane without resolving its secret references.
+func ValidateCentralPipedConfig(data []byte) error {
+	local := &PipedSpec{
+		ProjectID:    "project-id",
+		PipedID:      "piped-id",
+		PipedKeyFile: "piped-key-file",
+		APIAddress:   "api-address",
+	}
+	_, err := DecodeCentralPipedSpec(data, local, func(name string) (string, error) {
+		return name, nil
+	})
+	return err
+}
+
+func (s *PipedSpec) validateCentralFields() error {
+	if s.ProjectID != "" || s.PipedID != "" || s.PipedKeyFile != "" || s.APIAddress != "" {
+		return fmt.Errorf("projectID, pipedID, pipedKeyFile and apiAddress must be specified only in the local configuration file")
+	}
+	for _, r := range s.ChartRepositories {
+		if !isSecretReference(r.Password) {
+			return fmt.Errorf("password of chart repository %s must be a secret reference", r.Name)
+		}
+	}
+	for _, r := range s.ChartRegistries {
+		if !isSecretReference(r.Password) {
+			return fmt.Errorf("password of chart registry %s must be a secret reference", r.Address)
+		}
+	}
+	for _, r := range s.Notifications.Receivers {
+		if r.Slack != nil && !isSecretReference(r.Slack.HookURL) {
+			return fmt.Errorf("hookURL of notification receiver %s must be a secret reference", r.Name)
+		}
+		if r.Webhook != nil && !isSecretReference(r.Webhook.URL) {
+			return fmt.Errorf("url of notification receiver %s must be a secret reference", r.Name)
+		}
+	}
+	return nil
+}
+
+// isSecretReference checks whether the given value is empty or a single secret reference.
+func isSecretReference(value string) bool {
+	if value == "" {
+		return true
+	}
+	loc := secretReferenceRegex.FindStringIndex(value)
+	return loc != nil && loc[0] == 0 && loc[1] == len(value)
+}
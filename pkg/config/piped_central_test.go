@@ -0,0 +1,153 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeCentralPipedSpec(t *testing.T) {
+	local := &PipedSpec{
+		ProjectID:    "test-project",
+		PipedID:      "test-piped",
+		PipedKeyFile: "/etc/piped-secret/piped-key",
+		APIAddress:   "pipecd.dev:443",
+	}
+	secrets := map[string]string{
+		"slack-hook-url": "https://slack.com/\"hook\"",
+	}
+	resolve := func(name string) (string, error) {
+		if v, ok := secrets[name]; ok {
+			return v, nil
+		}
+		return "", fmt.Errorf("not found")
+	}
+
+	testcases := []struct {
+		name        string
+		data        string
+		expected    *PipedSpec
+		expectedErr bool
+	}{
+		{
+			name: "valid config",
+			data: `
+apiVersion: pipecd.dev/v1beta1
+kind: Piped
+spec:
+  webAddress: https://pipecd.dev
+  syncInterval: 1m
+  notifications:
+    routes:
+      - name: all
+        receiver: slack
+    receivers:
+      - name: slack
+        slack:
+          hookURL: ${secret:slack-hook-url}
+`,
+			expected: &PipedSpec{
+				ProjectID:    "test-project",
+				PipedID:      "test-piped",
+				PipedKeyFile: "/etc/piped-secret/piped-key",
+				APIAddress:   "pipecd.dev:443",
+				WebAddress:   "https://pipecd.dev",
+				SyncInterval: Duration(time.Minute),
+				Notifications: Notifications{
+					Routes: []NotificationRoute{
+						{Name: "all", Receiver: "slack"},
+					},
+					Receivers: []NotificationReceiver{
+						{Name: "slack", Slack: &NotificationReceiverSlack{HookURL: "https://slack.com/\"hook\""}},
+					},
+				},
+			},
+		},
+		{
+			name: "local-only field was specified",
+			data: `
+apiVersion: pipecd.dev/v1beta1
+kind: Piped
+spec:
+  pipedID: another-piped
+  webAddress: https://pipecd.dev
+`,
+			expectedErr: true,
+		},
+		{
+			name: "secret value was inlined",
+			data: `
+apiVersion: pipecd.dev/v1beta1
+kind: Piped
+spec:
+  webAddress: https://pipecd.dev
+  notifications:
+    receivers:
+      - name: slack
+        slack:
+          hookURL: https://slack.com/hook
+`,
+			expectedErr: true,
+		},
+		{
+			name: "unknown secret was referenced",
+			data: `
+apiVersion: pipecd.dev/v1beta1
+kind: Piped
+spec:
+  webAddress: https://pipecd.dev
+  notifications:
+    receivers:
+      - name: slack
+        slack:
+          hookURL: ${secret:unknown}
+`,
+			expectedErr: true,
+		},
+		{
+			name: "wrong kind",
+			data: `
+apiVersion: pipecd.dev/v1beta1
+kind: KubernetesApp
+spec:
+`,
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			spec, err := DecodeCentralPipedSpec([]byte(tc.data), local, resolve)
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, spec)
+		})
+	}
+}
+
+func TestIsSecretReference(t *testing.T) {
+	assert.True(t, isSecretReference(""))
+	assert.True(t, isSecretReference("${secret:hook-url}"))
+	assert.False(t, isSecretReference("https://${secret:host}/hook"))
+	assert.False(t, isSecretReference("https://slack.com/hook"))
+}
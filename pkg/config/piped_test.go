@@ -37,12 +37,13 @@ func TestPipedConfig(t *testing.T) {
 			expectedKind:       KindPiped,
 			expectedAPIVersion: "pipecd.dev/v1beta1",
 			expectedSpec: &PipedSpec{
-				ProjectID:    "test-project",
-				PipedID:      "test-piped",
-				PipedKeyFile: "etc/piped/key",
-				APIAddress:   "your-pipecd.domain",
-				WebAddress:   "https://your-pipecd.domain",
-				SyncInterval: Duration(time.Minute),
+				ProjectID:                "test-project",
+				PipedID:                  "test-piped",
+				PipedKeyFile:             "etc/piped/key",
+				APIAddress:               "your-pipecd.domain",
+				WebAddress:               "https://your-pipecd.domain",
+				SyncInterval:             Duration(time.Minute),
+				MaxConcurrentDeployments: 5,
 				Git: PipedGit{
 					Username:   "username",
 					Email:      "username@email.com",
@@ -319,3 +320,165 @@ func TestPipedImageWatcherValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestHelmChartRegistryValidate(t *testing.T) {
+	imageProviders := []PipedImageProvider{
+		{
+			Name: "my-ecr",
+			Type: model.ImageProviderTypeECR,
+		},
+	}
+	testcases := []struct {
+		name     string
+		registry HelmChartRegistry
+		wantErr  bool
+	}{
+		{
+			name: "valid with credentials",
+			registry: HelmChartRegistry{
+				Address:  "harbor.example.com",
+				Username: "user",
+				Password: "pass",
+			},
+		},
+		{
+			name: "valid with image provider",
+			registry: HelmChartRegistry{
+				Address:       "123456789012.dkr.ecr.us-west-2.amazonaws.com",
+				ImageProvider: "my-ecr",
+			},
+		},
+		{
+			name:     "missing address",
+			registry: HelmChartRegistry{},
+			wantErr:  true,
+		},
+		{
+			name: "address with scheme",
+			registry: HelmChartRegistry{
+				Address: "oci://harbor.example.com",
+			},
+			wantErr: true,
+		},
+		{
+			name: "both credentials and image provider",
+			registry: HelmChartRegistry{
+				Address:       "harbor.example.com",
+				Username:      "user",
+				ImageProvider: "my-ecr",
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing image provider",
+			registry: HelmChartRegistry{
+				Address:       "harbor.example.com",
+				ImageProvider: "unknown",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.registry.Validate(imageProviders)
+			assert.Equal(t, tc.wantErr, err != nil)
+		})
+	}
+}
+
+func TestKubernetesHealthRuleValidate(t *testing.T) {
+	testcases := []struct {
+		name    string
+		rule    KubernetesHealthRule
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			rule: KubernetesHealthRule{
+				APIVersion: "cert-manager.io/v1",
+				Kind:       "Certificate",
+				Checks: []KubernetesHealthCheck{
+					{
+						Condition: &KubernetesHealthCondition{Type: "Ready"},
+						Status:    KubernetesHealthStatusHealthy,
+					},
+					{
+						JSONPath: "{.status.phase}",
+						Value:    "Failed",
+						Status:   KubernetesHealthStatusUnhealthy,
+					},
+				},
+			},
+		},
+		{
+			name: "missing kind",
+			rule: KubernetesHealthRule{
+				APIVersion: "cert-manager.io/v1",
+				Checks: []KubernetesHealthCheck{
+					{
+						Condition: &KubernetesHealthCondition{Type: "Ready"},
+						Status:    KubernetesHealthStatusHealthy,
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "no check",
+			rule: KubernetesHealthRule{
+				APIVersion: "cert-manager.io/v1",
+				Kind:       "Certificate",
+			},
+			wantErr: true,
+		},
+		{
+			name: "both condition and jsonPath",
+			rule: KubernetesHealthRule{
+				APIVersion: "cert-manager.io/v1",
+				Kind:       "Certificate",
+				Checks: []KubernetesHealthCheck{
+					{
+						Condition: &KubernetesHealthCondition{Type: "Ready"},
+						JSONPath:  "{.status.phase}",
+						Status:    KubernetesHealthStatusHealthy,
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "malformed jsonPath",
+			rule: KubernetesHealthRule{
+				APIVersion: "cert-manager.io/v1",
+				Kind:       "Certificate",
+				Checks: []KubernetesHealthCheck{
+					{
+						JSONPath: "{.status.phase",
+						Status:   KubernetesHealthStatusHealthy,
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unsupported status",
+			rule: KubernetesHealthRule{
+				APIVersion: "cert-manager.io/v1",
+				Kind:       "Certificate",
+				Checks: []KubernetesHealthCheck{
+					{
+						Condition: &KubernetesHealthCondition{Type: "Ready"},
+						Status:    "DEGRADED",
+					},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.rule.Validate()
+			assert.Equal(t, tc.wantErr, err != nil)
+		})
+	}
+}
@@ -35,6 +35,7 @@ type APIKeyStore interface {
 	GetAPIKey(ctx context.Context, id string) (*model.APIKey, error)
 	DisableAPIKey(ctx context.Context, id, projectID string) error
 	ListAPIKeys(ctx context.Context, opts ListOptions) ([]*model.APIKey, error)
+	UpdateAPIKeyLastUsedAt(ctx context.Context, id string, usedAt int64) error
 }
 
 type apiKeyStore struct {
@@ -106,3 +107,15 @@ func (s *apiKeyStore) DisableAPIKey(ctx context.Context, id, projectID string) e
 		return k.Validate()
 	})
 }
+
+// UpdateAPIKeyLastUsedAt records the time when the key was used.
+// The UpdatedAt is left as is since using the key does not change it.
+func (s *apiKeyStore) UpdateAPIKeyLastUsedAt(ctx context.Context, id string, usedAt int64) error {
+	return s.ds.Update(ctx, apiKeyModelKind, id, apiKeyFactory, func(e interface{}) error {
+		k := e.(*model.APIKey)
+		if usedAt > k.LastUsedAt {
+			k.LastUsedAt = usedAt
+		}
+		return nil
+	})
+}
@@ -31,6 +31,7 @@ type ApplicationStore interface {
 	AddApplication(ctx context.Context, app *model.Application) error
 	EnableApplication(ctx context.Context, id string) error
 	DisableApplication(ctx context.Context, id string) error
+	DeleteApplication(ctx context.Context, id string) error
 	GetApplication(ctx context.Context, id string) (*model.Application, error)
 	ListApplications(ctx context.Context, opts ListOptions) ([]*model.Application, error)
 	UpdateApplication(ctx context.Context, id string, updater func(*model.Application) error) error
@@ -69,6 +70,9 @@ func (s *applicationStore) AddApplication(ctx context.Context, app *model.Applic
 func (s *applicationStore) EnableApplication(ctx context.Context, id string) error {
 	return s.ds.Update(ctx, applicationModelKind, id, applicationFactory, func(e interface{}) error {
 		app := e.(*model.Application)
+		if app.Deleted {
+			return ErrInvalidArgument
+		}
 		app.Disabled = false
 		app.UpdatedAt = time.Now().Unix()
 		return nil
@@ -84,6 +88,20 @@ func (s *applicationStore) DisableApplication(ctx context.Context, id string) er
 	})
 }
 
+// DeleteApplication marks the application as deleted.
+// The application entity is kept to show the history of its deployments.
+func (s *applicationStore) DeleteApplication(ctx context.Context, id string) error {
+	now := s.nowFunc().Unix()
+	return s.ds.Update(ctx, applicationModelKind, id, applicationFactory, func(e interface{}) error {
+		app := e.(*model.Application)
+		app.Disabled = true
+		app.Deleted = true
+		app.DeletedAt = now
+		app.UpdatedAt = now
+		return nil
+	})
+}
+
 func (s *applicationStore) GetApplication(ctx context.Context, id string) (*model.Application, error) {
 	var entity model.Application
 	if err := s.ds.Get(ctx, applicationModelKind, id, &entity); err != nil {
@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -176,3 +177,30 @@ func TestListApplications(t *testing.T) {
 		})
 	}
 }
+
+func TestDeleteApplication(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ds := NewMockDataStore(ctrl)
+	ds.EXPECT().
+		Update(gomock.Any(), "Application", "id", gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _ string, factory Factory, updater Updater) error {
+			app := factory().(*model.Application)
+			if err := updater(app); err != nil {
+				return err
+			}
+			assert.True(t, app.Disabled)
+			assert.True(t, app.Deleted)
+			assert.Equal(t, int64(100), app.DeletedAt)
+			assert.Equal(t, int64(100), app.UpdatedAt)
+			return nil
+		})
+
+	s := &applicationStore{
+		backend: backend{ds: ds},
+		nowFunc: func() time.Time { return time.Unix(100, 0) },
+	}
+	err := s.DeleteApplication(context.Background(), "id")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,77 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"context"
+	"time"
+
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+const auditLogModelKind = "AuditLog"
+
+type AuditLogStore interface {
+	AddAuditLog(ctx context.Context, l *model.AuditLog) error
+	ListAuditLogs(ctx context.Context, opts ListOptions) ([]*model.AuditLog, error)
+}
+
+type auditLogStore struct {
+	backend
+	nowFunc func() time.Time
+}
+
+func NewAuditLogStore(ds DataStore) AuditLogStore {
+	return &auditLogStore{
+		backend: backend{
+			ds: ds,
+		},
+		nowFunc: time.Now,
+	}
+}
+
+func (s *auditLogStore) AddAuditLog(ctx context.Context, l *model.AuditLog) error {
+	now := s.nowFunc().Unix()
+	if l.CreatedAt == 0 {
+		l.CreatedAt = now
+	}
+	if l.UpdatedAt == 0 {
+		l.UpdatedAt = now
+	}
+	if err := l.Validate(); err != nil {
+		return err
+	}
+	return s.ds.Create(ctx, auditLogModelKind, l.Id, l)
+}
+
+func (s *auditLogStore) ListAuditLogs(ctx context.Context, opts ListOptions) ([]*model.AuditLog, error) {
+	it, err := s.ds.Find(ctx, auditLogModelKind, opts)
+	if err != nil {
+		return nil, err
+	}
+	ls := make([]*model.AuditLog, 0)
+	for {
+		var l model.AuditLog
+		err := it.Next(&l)
+		if err == ErrIteratorDone {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		ls = append(ls, &l)
+	}
+	return ls, nil
+}
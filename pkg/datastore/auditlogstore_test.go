@@ -0,0 +1,125 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+func TestAddAuditLog(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testcases := []struct {
+		name      string
+		log       *model.AuditLog
+		dsFactory func(*model.AuditLog) DataStore
+		wantErr   bool
+	}{
+		{
+			name:      "Invalid audit log",
+			log:       &model.AuditLog{},
+			dsFactory: func(l *model.AuditLog) DataStore { return nil },
+			wantErr:   true,
+		},
+		{
+			name: "Valid audit log",
+			log: &model.AuditLog{
+				Id:         "id",
+				ProjectId:  "project-id",
+				Actor:      "user",
+				Method:     "/pipe.api.service.webservice.WebService/DisablePiped",
+				StatusCode: "OK",
+				Succeeded:  true,
+			},
+			dsFactory: func(l *model.AuditLog) DataStore {
+				ds := NewMockDataStore(ctrl)
+				ds.EXPECT().Create(gomock.Any(), "AuditLog", l.Id, l)
+				return ds
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewAuditLogStore(tc.dsFactory(tc.log))
+			err := s.AddAuditLog(context.Background(), tc.log)
+			assert.Equal(t, tc.wantErr, err != nil)
+		})
+	}
+}
+
+func TestListAuditLogs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testcases := []struct {
+		name    string
+		opts    ListOptions
+		ds      DataStore
+		wantErr bool
+	}{
+		{
+			name: "iterator done",
+			opts: ListOptions{Page: 1},
+			ds: func() DataStore {
+				it := NewMockIterator(ctrl)
+				it.EXPECT().
+					Next(&model.AuditLog{}).
+					Return(ErrIteratorDone)
+
+				ds := NewMockDataStore(ctrl)
+				ds.EXPECT().
+					Find(gomock.Any(), "AuditLog", ListOptions{Page: 1}).
+					Return(it, nil)
+				return ds
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "unexpected error occurred",
+			opts: ListOptions{Page: 1},
+			ds: func() DataStore {
+				it := NewMockIterator(ctrl)
+				it.EXPECT().
+					Next(&model.AuditLog{}).
+					Return(fmt.Errorf("err"))
+
+				ds := NewMockDataStore(ctrl)
+				ds.EXPECT().
+					Find(gomock.Any(), "AuditLog", ListOptions{Page: 1}).
+					Return(it, nil)
+				return ds
+			}(),
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewAuditLogStore(tc.ds)
+			_, err := s.ListAuditLogs(context.Background(), tc.opts)
+			assert.Equal(t, tc.wantErr, err != nil)
+		})
+	}
+}
@@ -43,6 +43,7 @@ type CommandStore interface {
 	UpdateCommand(ctx context.Context, id string, updater func(piped *model.Command) error) error
 	ListCommands(ctx context.Context, opts ListOptions) ([]*model.Command, error)
 	GetCommand(ctx context.Context, id string) (*model.Command, error)
+	DeleteCommand(ctx context.Context, id string) error
 }
 
 type commandStore struct {
@@ -112,3 +113,7 @@ func (s *commandStore) GetCommand(ctx context.Context, id string) (*model.Comman
 	}
 	return &entity, nil
 }
+
+func (s *commandStore) DeleteCommand(ctx context.Context, id string) error {
+	return s.ds.Delete(ctx, commandModelKind, id)
+}
@@ -56,6 +56,9 @@ type DataStore interface {
 	// Update updates an existing entity in the datastore.
 	// If updating entity was not found in the datastore, ErrNotFound will be returned.
 	Update(ctx context.Context, kind, id string, factory Factory, updater Updater) error
+	// Delete deletes the entity specified with ID from the datastore.
+	// Deleting a non-existent entity does not return any error.
+	Delete(ctx context.Context, kind, id string) error
 	// Close closes datastore resources held by the client.
 	Close() error
 }
@@ -0,0 +1,118 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"context"
+	"time"
+
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+const deploymentChainModelKind = "DeploymentChain"
+
+var deploymentChainFactory = func() interface{} {
+	return &model.DeploymentChain{}
+}
+
+var (
+	DeploymentChainNodeUpdater = func(blockIndex uint32, appID, deploymentID string, status model.DeploymentStatus, now int64) func(*model.DeploymentChain) error {
+		return func(c *model.DeploymentChain) error {
+			node, ok := c.FindNode(blockIndex, appID)
+			if !ok {
+				return ErrInvalidArgument
+			}
+			node.DeploymentId = deploymentID
+			node.DeploymentStatus = status
+			c.UpdateStatus(now)
+			return nil
+		}
+	}
+)
+
+type DeploymentChainStore interface {
+	AddDeploymentChain(ctx context.Context, c *model.DeploymentChain) error
+	UpdateDeploymentChain(ctx context.Context, id string, updater func(*model.DeploymentChain) error) error
+	ListDeploymentChains(ctx context.Context, opts ListOptions) ([]*model.DeploymentChain, error)
+	GetDeploymentChain(ctx context.Context, id string) (*model.DeploymentChain, error)
+}
+
+type deploymentChainStore struct {
+	backend
+	nowFunc func() time.Time
+}
+
+func NewDeploymentChainStore(ds DataStore) DeploymentChainStore {
+	return &deploymentChainStore{
+		backend: backend{
+			ds: ds,
+		},
+		nowFunc: time.Now,
+	}
+}
+
+func (s *deploymentChainStore) AddDeploymentChain(ctx context.Context, c *model.DeploymentChain) error {
+	now := s.nowFunc().Unix()
+	if c.CreatedAt == 0 {
+		c.CreatedAt = now
+	}
+	if c.UpdatedAt == 0 {
+		c.UpdatedAt = now
+	}
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	return s.ds.Create(ctx, deploymentChainModelKind, c.Id, c)
+}
+
+func (s *deploymentChainStore) UpdateDeploymentChain(ctx context.Context, id string, updater func(*model.DeploymentChain) error) error {
+	now := s.nowFunc().Unix()
+	return s.ds.Update(ctx, deploymentChainModelKind, id, deploymentChainFactory, func(e interface{}) error {
+		c := e.(*model.DeploymentChain)
+		if err := updater(c); err != nil {
+			return err
+		}
+		c.UpdatedAt = now
+		return c.Validate()
+	})
+}
+
+func (s *deploymentChainStore) ListDeploymentChains(ctx context.Context, opts ListOptions) ([]*model.DeploymentChain, error) {
+	it, err := s.ds.Find(ctx, deploymentChainModelKind, opts)
+	if err != nil {
+		return nil, err
+	}
+	cs := make([]*model.DeploymentChain, 0)
+	for {
+		var c model.DeploymentChain
+		err := it.Next(&c)
+		if err == ErrIteratorDone {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		cs = append(cs, &c)
+	}
+	return cs, nil
+}
+
+func (s *deploymentChainStore) GetDeploymentChain(ctx context.Context, id string) (*model.DeploymentChain, error) {
+	var entity model.DeploymentChain
+	if err := s.ds.Get(ctx, deploymentChainModelKind, id, &entity); err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
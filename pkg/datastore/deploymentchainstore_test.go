@@ -0,0 +1,170 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+func TestAddDeploymentChain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testcases := []struct {
+		name      string
+		chain     *model.DeploymentChain
+		dsFactory func(*model.DeploymentChain) DataStore
+		wantErr   bool
+	}{
+		{
+			name:      "Invalid chain",
+			chain:     &model.DeploymentChain{},
+			dsFactory: func(d *model.DeploymentChain) DataStore { return nil },
+			wantErr:   true,
+		},
+		{
+			name: "Valid chain",
+			chain: &model.DeploymentChain{
+				Id:                  "id",
+				ProjectId:           "project-id",
+				TriggerDeploymentId: "deployment-id",
+
+				CreatedAt: 1,
+				UpdatedAt: 1,
+			},
+			dsFactory: func(d *model.DeploymentChain) DataStore {
+				ds := NewMockDataStore(ctrl)
+				ds.EXPECT().Create(gomock.Any(), "DeploymentChain", d.Id, d)
+				return ds
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewDeploymentChainStore(tc.dsFactory(tc.chain))
+			err := s.AddDeploymentChain(context.Background(), tc.chain)
+			assert.Equal(t, tc.wantErr, err != nil)
+		})
+	}
+}
+
+func TestGetDeploymentChain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testcases := []struct {
+		name    string
+		id      string
+		ds      DataStore
+		wantErr bool
+	}{
+		{
+			name: "successful fetch from datastore",
+			id:   "id",
+			ds: func() DataStore {
+				ds := NewMockDataStore(ctrl)
+				ds.EXPECT().
+					Get(gomock.Any(), "DeploymentChain", "id", &model.DeploymentChain{}).
+					Return(nil)
+				return ds
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "failed fetch from datastore",
+			id:   "id",
+			ds: func() DataStore {
+				ds := NewMockDataStore(ctrl)
+				ds.EXPECT().
+					Get(gomock.Any(), "DeploymentChain", "id", &model.DeploymentChain{}).
+					Return(fmt.Errorf("err"))
+				return ds
+			}(),
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewDeploymentChainStore(tc.ds)
+			_, err := s.GetDeploymentChain(context.Background(), tc.id)
+			assert.Equal(t, tc.wantErr, err != nil)
+		})
+	}
+}
+
+func TestListDeploymentChains(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testcases := []struct {
+		name    string
+		opts    ListOptions
+		ds      DataStore
+		wantErr bool
+	}{
+		{
+			name: "iterator done",
+			opts: ListOptions{Page: 1},
+			ds: func() DataStore {
+				it := NewMockIterator(ctrl)
+				it.EXPECT().
+					Next(&model.DeploymentChain{}).
+					Return(ErrIteratorDone)
+
+				ds := NewMockDataStore(ctrl)
+				ds.EXPECT().
+					Find(gomock.Any(), "DeploymentChain", ListOptions{Page: 1}).
+					Return(it, nil)
+				return ds
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "unexpected error occurred",
+			opts: ListOptions{Page: 1},
+			ds: func() DataStore {
+				it := NewMockIterator(ctrl)
+				it.EXPECT().
+					Next(&model.DeploymentChain{}).
+					Return(fmt.Errorf("err"))
+
+				ds := NewMockDataStore(ctrl)
+				ds.EXPECT().
+					Find(gomock.Any(), "DeploymentChain", ListOptions{Page: 1}).
+					Return(it, nil)
+				return ds
+			}(),
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewDeploymentChainStore(tc.ds)
+			_, err := s.ListDeploymentChains(context.Background(), tc.opts)
+			assert.Equal(t, tc.wantErr, err != nil)
+		})
+	}
+}
@@ -31,6 +31,7 @@ type EnvironmentStore interface {
 	AddEnvironment(ctx context.Context, env *model.Environment) error
 	GetEnvironment(ctx context.Context, id string) (*model.Environment, error)
 	ListEnvironments(ctx context.Context, opts ListOptions) ([]*model.Environment, error)
+	DeleteEnvironment(ctx context.Context, id string) error
 }
 
 type environmentStore struct {
@@ -88,3 +89,15 @@ func (s *environmentStore) ListEnvironments(ctx context.Context, opts ListOption
 	}
 	return envs, nil
 }
+
+// DeleteEnvironment marks the environment as deleted.
+func (s *environmentStore) DeleteEnvironment(ctx context.Context, id string) error {
+	now := s.nowFunc().Unix()
+	return s.ds.Update(ctx, environmentModelKind, id, environmentFactory, func(e interface{}) error {
+		env := e.(*model.Environment)
+		env.Deleted = true
+		env.DeletedAt = now
+		env.UpdatedAt = now
+		return nil
+	})
+}
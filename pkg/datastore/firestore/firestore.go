@@ -182,6 +182,19 @@ func (s *FireStore) Put(ctx context.Context, kind, id string, entity interface{}
 	return nil
 }
 
+func (s *FireStore) Delete(ctx context.Context, kind, id string) error {
+	col := s.client.Collection(s.namespace).Doc(s.environment).Collection(kind)
+	if _, err := col.Doc(id).Delete(ctx); err != nil {
+		s.logger.Error("failed to delete entity",
+			zap.String("id", id),
+			zap.String("kind", kind),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
 func (s *FireStore) Update(ctx context.Context, kind, id string, factory datastore.Factory, updater datastore.Updater) error {
 	ref := s.client.Collection(s.namespace).Doc(s.environment).Collection(kind).Doc(id)
 	err := s.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
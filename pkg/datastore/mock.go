@@ -104,6 +104,20 @@ func (mr *MockDataStoreMockRecorder) Update(ctx, kind, id, factory, updater inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockDataStore)(nil).Update), ctx, kind, id, factory, updater)
 }
 
+// Delete mocks base method
+func (m *MockDataStore) Delete(ctx context.Context, kind, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, kind, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockDataStoreMockRecorder) Delete(ctx, kind, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockDataStore)(nil).Delete), ctx, kind, id)
+}
+
 // Close mocks base method
 func (m *MockDataStore) Close() error {
 	m.ctrl.T.Helper()
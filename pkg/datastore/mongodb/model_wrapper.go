@@ -32,6 +32,14 @@ func wrapModel(entity interface{}) (interface{}, error) {
 			ID:          e.GetId(),
 			Application: *e,
 		}, nil
+	case *model.AuditLog:
+		if e == nil {
+			return nil, fmt.Errorf("nil entity given")
+		}
+		return &auditLog{
+			ID:       e.GetId(),
+			AuditLog: *e,
+		}, nil
 	case *model.Command:
 		if e == nil {
 			return nil, fmt.Errorf("nil entity given")
@@ -48,6 +56,14 @@ func wrapModel(entity interface{}) (interface{}, error) {
 			ID:         e.GetId(),
 			Deployment: *e,
 		}, nil
+	case *model.DeploymentChain:
+		if e == nil {
+			return nil, fmt.Errorf("nil entity given")
+		}
+		return &deploymentChain{
+			ID:              e.GetId(),
+			DeploymentChain: *e,
+		}, nil
 	case *model.Environment:
 		if e == nil {
 			return nil, fmt.Errorf("nil entity given")
@@ -64,6 +80,14 @@ func wrapModel(entity interface{}) (interface{}, error) {
 			ID:    e.GetId(),
 			Piped: *e,
 		}, nil
+	case *model.PipedConfigRevision:
+		if e == nil {
+			return nil, fmt.Errorf("nil entity given")
+		}
+		return &pipedConfigRevision{
+			ID:                  e.GetId(),
+			PipedConfigRevision: *e,
+		}, nil
 	case *model.Project:
 		if e == nil {
 			return nil, fmt.Errorf("nil entity given")
@@ -88,6 +112,12 @@ func extractModel(wrapper interface{}, e interface{}) error {
 			return fmt.Errorf(msg, w)
 		}
 		*e = w.Application
+	case *auditLog:
+		e, ok := e.(*model.AuditLog)
+		if !ok {
+			return fmt.Errorf(msg, w)
+		}
+		*e = w.AuditLog
 	case *command:
 		e, ok := e.(*model.Command)
 		if !ok {
@@ -100,6 +130,12 @@ func extractModel(wrapper interface{}, e interface{}) error {
 			return fmt.Errorf(msg, w)
 		}
 		*e = w.Deployment
+	case *deploymentChain:
+		e, ok := e.(*model.DeploymentChain)
+		if !ok {
+			return fmt.Errorf(msg, w)
+		}
+		*e = w.DeploymentChain
 	case *environment:
 		e, ok := e.(*model.Environment)
 		if !ok {
@@ -112,6 +148,12 @@ func extractModel(wrapper interface{}, e interface{}) error {
 			return fmt.Errorf(msg, w)
 		}
 		*e = w.Piped
+	case *pipedConfigRevision:
+		e, ok := e.(*model.PipedConfigRevision)
+		if !ok {
+			return fmt.Errorf(msg, w)
+		}
+		*e = w.PipedConfigRevision
 	case *project:
 		e, ok := e.(*model.Project)
 		if !ok {
@@ -129,6 +171,11 @@ type application struct {
 	ID                string `bson:"_id"`
 }
 
+type auditLog struct {
+	model.AuditLog `bson:",inline"`
+	ID             string `bson:"_id"`
+}
+
 type command struct {
 	model.Command `bson:",inline"`
 	ID            string `bson:"_id"`
@@ -139,6 +186,11 @@ type deployment struct {
 	ID               string `bson:"_id"`
 }
 
+type deploymentChain struct {
+	model.DeploymentChain `bson:",inline"`
+	ID                    string `bson:"_id"`
+}
+
 type environment struct {
 	model.Environment `bson:",inline"`
 	ID                string `bson:"_id"`
@@ -149,6 +201,11 @@ type piped struct {
 	ID          string `bson:"_id"`
 }
 
+type pipedConfigRevision struct {
+	model.PipedConfigRevision `bson:",inline"`
+	ID                        string `bson:"_id"`
+}
+
 type project struct {
 	model.Project `bson:",inline"`
 	ID            string `bson:"_id"`
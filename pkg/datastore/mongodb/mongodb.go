@@ -99,9 +99,7 @@ func (m *MongoDB) Find(ctx context.Context, kind string, opts datastore.ListOpti
 			return nil, err
 		}
 		ops[i] = bson.M{
-			// Note: The field name of protobuf is saved in lower case by default in mongodb.
-			// e.g. Name => name, ProjectId => projectid, CreatedAt => createdat
-			strings.ToLower(f.Field): bson.M{
+			convertToMongoDBField(f.Field): bson.M{
 				op: f.Value,
 			},
 		}
@@ -245,6 +243,19 @@ func (m *MongoDB) Update(ctx context.Context, kind, id string, factory datastore
 	return nil
 }
 
+func (m *MongoDB) Delete(ctx context.Context, kind, id string) error {
+	col := m.client.Database(m.database).Collection(kind)
+	if _, err := col.DeleteOne(ctx, makePrimaryKeyFilter(id)); err != nil {
+		m.logger.Error("failed to delete entity",
+			zap.String("id", id),
+			zap.String("kind", kind),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
 func (m *MongoDB) Close() error {
 	return m.client.Disconnect(m.ctx)
 }
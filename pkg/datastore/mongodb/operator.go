@@ -16,8 +16,29 @@ package mongodb
 
 import (
 	"fmt"
+	"strings"
 )
 
+// The fields whose type is map.
+// The keys of these fields are case-sensitive so they must not be converted.
+var mapFields = map[string]struct{}{
+	"labels": {},
+}
+
+// convertToMongoDBField converts the given field path into the one saved in mongodb.
+// Note: The field name of protobuf is saved in lower case by default in mongodb.
+// e.g. Name => name, ProjectId => projectid, SyncState.Status => syncstate.status, Labels.Team => labels.Team
+func convertToMongoDBField(field string) string {
+	parts := strings.Split(field, ".")
+	for i := range parts {
+		parts[i] = strings.ToLower(parts[i])
+		if _, ok := mapFields[parts[i]]; ok {
+			break
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
 func convertToMongoDBOperator(op string) (string, error) {
 	switch op {
 	case "==":
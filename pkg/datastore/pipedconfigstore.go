@@ -0,0 +1,88 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"context"
+	"time"
+
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+const pipedConfigRevisionModelKind = "PipedConfigRevision"
+
+type PipedConfigRevisionStore interface {
+	AddPipedConfigRevision(ctx context.Context, r *model.PipedConfigRevision) error
+	GetPipedConfigRevision(ctx context.Context, id string) (*model.PipedConfigRevision, error)
+	ListPipedConfigRevisions(ctx context.Context, opts ListOptions) ([]*model.PipedConfigRevision, error)
+}
+
+type pipedConfigRevisionStore struct {
+	backend
+	nowFunc func() time.Time
+}
+
+func NewPipedConfigRevisionStore(ds DataStore) PipedConfigRevisionStore {
+	return &pipedConfigRevisionStore{
+		backend: backend{
+			ds: ds,
+		},
+		nowFunc: time.Now,
+	}
+}
+
+// AddPipedConfigRevision adds a new revision.
+// ErrAlreadyExists is returned when the same version of the piped was already added.
+func (s *pipedConfigRevisionStore) AddPipedConfigRevision(ctx context.Context, r *model.PipedConfigRevision) error {
+	now := s.nowFunc().Unix()
+	if r.CreatedAt == 0 {
+		r.CreatedAt = now
+	}
+	if r.UpdatedAt == 0 {
+		r.UpdatedAt = now
+	}
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	return s.ds.Create(ctx, pipedConfigRevisionModelKind, r.Id, r)
+}
+
+func (s *pipedConfigRevisionStore) GetPipedConfigRevision(ctx context.Context, id string) (*model.PipedConfigRevision, error) {
+	var entity model.PipedConfigRevision
+	if err := s.ds.Get(ctx, pipedConfigRevisionModelKind, id, &entity); err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+func (s *pipedConfigRevisionStore) ListPipedConfigRevisions(ctx context.Context, opts ListOptions) ([]*model.PipedConfigRevision, error) {
+	it, err := s.ds.Find(ctx, pipedConfigRevisionModelKind, opts)
+	if err != nil {
+		return nil, err
+	}
+	rs := make([]*model.PipedConfigRevision, 0)
+	for {
+		var r model.PipedConfigRevision
+		err := it.Next(&r)
+		if err == ErrIteratorDone {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		rs = append(rs, &r)
+	}
+	return rs, nil
+}
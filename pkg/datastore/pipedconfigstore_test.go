@@ -0,0 +1,169 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+func TestAddPipedConfigRevision(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testcases := []struct {
+		name      string
+		revision  *model.PipedConfigRevision
+		dsFactory func(*model.PipedConfigRevision) DataStore
+		wantErr   bool
+	}{
+		{
+			name:      "Invalid revision",
+			revision:  &model.PipedConfigRevision{},
+			dsFactory: func(r *model.PipedConfigRevision) DataStore { return nil },
+			wantErr:   true,
+		},
+		{
+			name: "Valid revision",
+			revision: &model.PipedConfigRevision{
+				Id:        "piped-id:1",
+				PipedId:   "piped-id",
+				ProjectId: "project-id",
+				Version:   1,
+				Data:      "apiVersion: pipecd.dev/v1beta1",
+			},
+			dsFactory: func(r *model.PipedConfigRevision) DataStore {
+				ds := NewMockDataStore(ctrl)
+				ds.EXPECT().Create(gomock.Any(), "PipedConfigRevision", r.Id, r)
+				return ds
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewPipedConfigRevisionStore(tc.dsFactory(tc.revision))
+			err := s.AddPipedConfigRevision(context.Background(), tc.revision)
+			assert.Equal(t, tc.wantErr, err != nil)
+		})
+	}
+}
+
+func TestGetPipedConfigRevision(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testcases := []struct {
+		name    string
+		id      string
+		ds      DataStore
+		wantErr bool
+	}{
+		{
+			name: "successful fetch from datastore",
+			id:   "piped-id:1",
+			ds: func() DataStore {
+				ds := NewMockDataStore(ctrl)
+				ds.EXPECT().
+					Get(gomock.Any(), "PipedConfigRevision", "piped-id:1", &model.PipedConfigRevision{}).
+					Return(nil)
+				return ds
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "failed fetch from datastore",
+			id:   "piped-id:1",
+			ds: func() DataStore {
+				ds := NewMockDataStore(ctrl)
+				ds.EXPECT().
+					Get(gomock.Any(), "PipedConfigRevision", "piped-id:1", &model.PipedConfigRevision{}).
+					Return(fmt.Errorf("err"))
+				return ds
+			}(),
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewPipedConfigRevisionStore(tc.ds)
+			_, err := s.GetPipedConfigRevision(context.Background(), tc.id)
+			assert.Equal(t, tc.wantErr, err != nil)
+		})
+	}
+}
+
+func TestListPipedConfigRevisions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testcases := []struct {
+		name    string
+		opts    ListOptions
+		ds      DataStore
+		wantErr bool
+	}{
+		{
+			name: "iterator done",
+			opts: ListOptions{Page: 1},
+			ds: func() DataStore {
+				it := NewMockIterator(ctrl)
+				it.EXPECT().
+					Next(&model.PipedConfigRevision{}).
+					Return(ErrIteratorDone)
+
+				ds := NewMockDataStore(ctrl)
+				ds.EXPECT().
+					Find(gomock.Any(), "PipedConfigRevision", ListOptions{Page: 1}).
+					Return(it, nil)
+				return ds
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "unexpected error occurred",
+			opts: ListOptions{Page: 1},
+			ds: func() DataStore {
+				it := NewMockIterator(ctrl)
+				it.EXPECT().
+					Next(&model.PipedConfigRevision{}).
+					Return(fmt.Errorf("err"))
+
+				ds := NewMockDataStore(ctrl)
+				ds.EXPECT().
+					Find(gomock.Any(), "PipedConfigRevision", ListOptions{Page: 1}).
+					Return(it, nil)
+				return ds
+			}(),
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewPipedConfigRevisionStore(tc.ds)
+			_, err := s.ListPipedConfigRevisions(context.Background(), tc.opts)
+			assert.Equal(t, tc.wantErr, err != nil)
+		})
+	}
+}
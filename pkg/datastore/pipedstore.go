@@ -33,6 +33,7 @@ var (
 		status model.Piped_ConnectionStatus,
 		sse *model.Piped_SealedSecretEncryption,
 		version string,
+		configVersion int64,
 		startedAt int64,
 	) func(piped *model.Piped) error {
 
@@ -44,6 +45,7 @@ var (
 				piped.SealedSecretEncryption = sse
 			}
 			piped.Version = version
+			piped.AppliedConfigVersion = configVersion
 			piped.StartedAt = startedAt
 			return nil
 		}
@@ -57,6 +59,7 @@ type PipedStore interface {
 	UpdatePiped(ctx context.Context, id string, updater func(piped *model.Piped) error) error
 	EnablePiped(ctx context.Context, id string) error
 	DisablePiped(ctx context.Context, id string) error
+	DeletePiped(ctx context.Context, id string) error
 	AddKey(ctx context.Context, id, keyHash, creator string, createdAt time.Time) error
 }
 
@@ -130,6 +133,9 @@ func (s *pipedStore) UpdatePiped(ctx context.Context, id string, updater func(pi
 
 func (s *pipedStore) EnablePiped(ctx context.Context, id string) error {
 	return s.UpdatePiped(ctx, id, func(piped *model.Piped) error {
+		if piped.Deleted {
+			return ErrInvalidArgument
+		}
 		piped.Disabled = false
 		piped.UpdatedAt = time.Now().Unix()
 		return nil
@@ -144,6 +150,17 @@ func (s *pipedStore) DisablePiped(ctx context.Context, id string) error {
 	})
 }
 
+// DeletePiped marks the piped as deleted.
+func (s *pipedStore) DeletePiped(ctx context.Context, id string) error {
+	now := s.nowFunc().Unix()
+	return s.UpdatePiped(ctx, id, func(piped *model.Piped) error {
+		piped.Disabled = true
+		piped.Deleted = true
+		piped.DeletedAt = now
+		return nil
+	})
+}
+
 func (s *pipedStore) AddKey(ctx context.Context, id, keyHash, creator string, createdAt time.Time) error {
 	return s.UpdatePiped(ctx, id, func(piped *model.Piped) error {
 		piped.AddKey(keyHash, creator, createdAt)
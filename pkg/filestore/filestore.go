@@ -46,6 +46,12 @@ type Lister interface {
 	ListObjects(ctx context.Context, prefix string) ([]Object, error)
 }
 
+type Deleter interface {
+	// DeleteObject deletes the object at path.
+	// Deleting a non-existent object does not return any error.
+	DeleteObject(ctx context.Context, path string) error
+}
+
 type Closer interface {
 	Close() error
 }
@@ -54,6 +60,7 @@ type Store interface {
 	Getter
 	Putter
 	Lister
+	Deleter
 	Closer
 	NewReader(ctx context.Context, path string) (io.ReadCloser, error)
 }
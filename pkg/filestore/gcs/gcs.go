@@ -156,6 +156,14 @@ func (s *Store) ListObjects(ctx context.Context, prefix string) ([]filestore.Obj
 	return objects, nil
 }
 
+func (s *Store) DeleteObject(ctx context.Context, path string) error {
+	err := s.client.Bucket(s.bucket).Object(path).Delete(ctx)
+	if err == storage.ErrObjectNotExist {
+		return nil
+	}
+	return err
+}
+
 func (s *Store) Close() error {
 	return s.client.Close()
 }
@@ -151,6 +151,11 @@ func (s *Store) ListObjects(ctx context.Context, prefix string) ([]filestore.Obj
 	return objects, nil
 }
 
+func (s *Store) DeleteObject(ctx context.Context, path string) error {
+	// RemoveObject does not return any error for a non-existent object.
+	return s.client.RemoveObject(ctx, s.bucket, path, minio.RemoveObjectOptions{})
+}
+
 func (s *Store) Close() error {
 	// No need to close the connection. Minio server automatically cleans
 	// idle connections and properly gives back resources to kernel.
@@ -17,7 +17,9 @@ package model
 import (
 	"errors"
 	"fmt"
+	"net"
 	"strings"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
@@ -67,3 +69,84 @@ func (k *APIKey) CompareKey(key string) error {
 func (k *APIKey) RedactSensitiveData() {
 	k.KeyHash = redactedMessage
 }
+
+// IsExpired reports whether the key has expired at the given time.
+func (k *APIKey) IsExpired(now time.Time) bool {
+	return k.ExpiresAt != 0 && now.Unix() >= k.ExpiresAt
+}
+
+// IsUnused reports whether the key has not been used for the given duration.
+// The keys that have never been used are measured from their creation time.
+func (k *APIKey) IsUnused(now time.Time, d time.Duration) bool {
+	last := k.LastUsedAt
+	if last == 0 {
+		last = k.CreatedAt
+	}
+	return now.Add(-d).Unix() >= last
+}
+
+// ValidateAllowedIPRanges checks that all allowed IP ranges are valid IP addresses or CIDRs.
+func (k *APIKey) ValidateAllowedIPRanges() error {
+	for _, r := range k.AllowedIpRanges {
+		if _, err := parseIPRange(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AllowsIP reports whether the key can be used from the given IP address.
+func (k *APIKey) AllowsIP(ip net.IP) bool {
+	if len(k.AllowedIpRanges) == 0 {
+		return true
+	}
+	if ip == nil {
+		return false
+	}
+	for _, r := range k.AllowedIpRanges {
+		n, err := parseIPRange(r)
+		if err != nil {
+			continue
+		}
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsApplication reports whether the key can be used for the application
+// of the given ID in the given environment.
+func (k *APIKey) AllowsApplication(envID, appID string) bool {
+	if len(k.EnvIds) > 0 && !containsString(k.EnvIds, envID) {
+		return false
+	}
+	if len(k.ApplicationIds) > 0 && !containsString(k.ApplicationIds, appID) {
+		return false
+	}
+	return true
+}
+
+// IsScoped reports whether the key is restricted to some environments or applications.
+func (k *APIKey) IsScoped() bool {
+	return len(k.EnvIds) > 0 || len(k.ApplicationIds) > 0
+}
+
+// parseIPRange parses the given string as a CIDR or a single IP address.
+func parseIPRange(r string) (*net.IPNet, error) {
+	if strings.Contains(r, "/") {
+		_, n, err := net.ParseCIDR(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IP range %q: %w", r, err)
+		}
+		return n, nil
+	}
+	ip := net.ParseIP(r)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address %q", r)
+	}
+	if v4 := ip.To4(); v4 != nil {
+		return &net.IPNet{IP: v4, Mask: net.CIDRMask(8*net.IPv4len, 8*net.IPv4len)}, nil
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(8*net.IPv6len, 8*net.IPv6len)}, nil
+}
@@ -15,7 +15,9 @@
 package model
 
 import (
+	"net"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -49,3 +51,80 @@ func TestAPIKeyRedactSensitiveData(t *testing.T) {
 	apiKey.RedactSensitiveData()
 	assert.Equal(t, apiKey.KeyHash, "redacted")
 }
+
+func TestAPIKeyIsExpired(t *testing.T) {
+	now := time.Unix(1000, 0)
+	assert.False(t, (&APIKey{}).IsExpired(now))
+	assert.False(t, (&APIKey{ExpiresAt: 1001}).IsExpired(now))
+	assert.True(t, (&APIKey{ExpiresAt: 1000}).IsExpired(now))
+}
+
+func TestAPIKeyIsUnused(t *testing.T) {
+	now := time.Unix(10000, 0)
+	assert.True(t, (&APIKey{CreatedAt: 100}).IsUnused(now, time.Hour))
+	assert.False(t, (&APIKey{CreatedAt: 9000}).IsUnused(now, time.Hour))
+	assert.True(t, (&APIKey{CreatedAt: 100, LastUsedAt: 6000}).IsUnused(now, time.Hour))
+	assert.False(t, (&APIKey{CreatedAt: 100, LastUsedAt: 9000}).IsUnused(now, time.Hour))
+}
+
+func TestAPIKeyAllowsIP(t *testing.T) {
+	testcases := []struct {
+		name     string
+		ranges   []string
+		ip       string
+		expected bool
+	}{
+		{
+			name:     "no restriction",
+			ip:       "1.2.3.4",
+			expected: true,
+		},
+		{
+			name:     "in CIDR",
+			ranges:   []string{"10.0.0.0/8"},
+			ip:       "10.1.2.3",
+			expected: true,
+		},
+		{
+			name:     "not in CIDR",
+			ranges:   []string{"10.0.0.0/8"},
+			ip:       "192.168.0.1",
+			expected: false,
+		},
+		{
+			name:     "single address",
+			ranges:   []string{"192.168.0.1"},
+			ip:       "192.168.0.1",
+			expected: true,
+		},
+		{
+			name:     "IPv6",
+			ranges:   []string{"2001:db8::/32"},
+			ip:       "2001:db8::1",
+			expected: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			k := &APIKey{AllowedIpRanges: tc.ranges}
+			require.NoError(t, k.ValidateAllowedIPRanges())
+			assert.Equal(t, tc.expected, k.AllowsIP(net.ParseIP(tc.ip)))
+		})
+	}
+
+	k := &APIKey{AllowedIpRanges: []string{"10.0.0.0/33"}}
+	assert.Error(t, k.ValidateAllowedIPRanges())
+}
+
+func TestAPIKeyAllowsApplication(t *testing.T) {
+	k := &APIKey{}
+	assert.True(t, k.AllowsApplication("env-1", "app-1"))
+
+	k = &APIKey{EnvIds: []string{"env-1"}}
+	assert.True(t, k.AllowsApplication("env-1", "app-1"))
+	assert.False(t, k.AllowsApplication("env-2", "app-1"))
+
+	k = &APIKey{EnvIds: []string{"env-1"}, ApplicationIds: []string{"app-1"}}
+	assert.True(t, k.AllowsApplication("env-1", "app-1"))
+	assert.False(t, k.AllowsApplication("env-1", "app-2"))
+}
@@ -14,6 +14,10 @@
 
 package model
 
+import (
+	"sort"
+)
+
 func (v ApplicationLiveStateVersion) IsBefore(a ApplicationLiveStateVersion) bool {
 	if v.Timestamp < a.Timestamp {
 		return true
@@ -37,6 +41,9 @@ func (s KubernetesResourceState) HasDiff(a KubernetesResourceState) bool {
 	if s.HealthDescription != a.HealthDescription {
 		return true
 	}
+	if s.CloudProvider != a.CloudProvider {
+		return true
+	}
 	if len(s.OwnerIds) != len(a.OwnerIds) {
 		return true
 	}
@@ -67,14 +74,38 @@ func (s *ApplicationLiveStateSnapshot) DetermineAppHealthStatus() {
 		if k == nil {
 			return
 		}
-		status := ApplicationLiveStateSnapshot_HEALTHY
+		var (
+			status   = ApplicationLiveStateSnapshot_HEALTHY
+			clusters = make(map[string]ApplicationLiveStateSnapshot_Status)
+		)
 		for _, r := range k.Resources {
+			cs, ok := clusters[r.CloudProvider]
+			if !ok {
+				cs = ApplicationLiveStateSnapshot_HEALTHY
+			}
 			if r.HealthStatus == KubernetesResourceState_OTHER {
 				status = ApplicationLiveStateSnapshot_OTHER
-				break
+				cs = ApplicationLiveStateSnapshot_OTHER
 			}
+			clusters[r.CloudProvider] = cs
 		}
 		s.HealthStatus = status
+
+		// The per-cluster health is only meaningful when the resources
+		// were reported together with their cloud providers.
+		k.Clusters = make([]*KubernetesClusterLiveState, 0, len(clusters))
+		for name, cs := range clusters {
+			if name == "" {
+				continue
+			}
+			k.Clusters = append(k.Clusters, &KubernetesClusterLiveState{
+				CloudProvider: name,
+				HealthStatus:  cs,
+			})
+		}
+		sort.Slice(k.Clusters, func(i, j int) bool {
+			return k.Clusters[i].CloudProvider < k.Clusters[j].CloudProvider
+		})
 	default:
 		// TODO: Determine health state of other than k8s app
 		return
@@ -0,0 +1,41 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetermineAppHealthStatus(t *testing.T) {
+	snapshot := &ApplicationLiveStateSnapshot{
+		Kind: ApplicationKind_KUBERNETES,
+		Kubernetes: &KubernetesApplicationLiveState{
+			Resources: []*KubernetesResourceState{
+				{CloudProvider: "cluster-2", HealthStatus: KubernetesResourceState_HEALTHY},
+				{CloudProvider: "cluster-1", HealthStatus: KubernetesResourceState_HEALTHY},
+				{CloudProvider: "cluster-2", HealthStatus: KubernetesResourceState_OTHER},
+			},
+		},
+	}
+	snapshot.DetermineAppHealthStatus()
+
+	assert.Equal(t, ApplicationLiveStateSnapshot_OTHER, snapshot.HealthStatus)
+	assert.Equal(t, []*KubernetesClusterLiveState{
+		{CloudProvider: "cluster-1", HealthStatus: ApplicationLiveStateSnapshot_HEALTHY},
+		{CloudProvider: "cluster-2", HealthStatus: ApplicationLiveStateSnapshot_OTHER},
+	}, snapshot.Kubernetes.Clusters)
+}
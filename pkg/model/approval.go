@@ -0,0 +1,73 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import "strings"
+
+const (
+	// ApproversStageMetadataKey is the stage metadata key for the comma-separated list
+	// of users or SSO teams who are allowed to approve a WAIT_APPROVAL stage.
+	ApproversStageMetadataKey = "Approvers"
+	// MinApproverNumStageMetadataKey is the stage metadata key for the number of
+	// approvals required to complete a WAIT_APPROVAL stage.
+	MinApproverNumStageMetadataKey = "MinApproverNum"
+
+	// SlackApprovalCallbackID is the callback ID of the interactive Slack messages
+	// sent by piped to approve or reject WAIT_APPROVAL stages.
+	SlackApprovalCallbackID = "wait_approval"
+	// SlackApproveActionName is the name of the action to approve the stage.
+	SlackApproveActionName = "approve"
+	// SlackRejectActionName is the name of the action to reject the stage.
+	SlackRejectActionName = "reject"
+)
+
+// IsStageApprover reports whether the given user is allowed to approve or reject
+// a WAIT_APPROVAL stage having the given approvers.
+// Each approver is either a username or an SSO team configured in the project RBAC.
+// An empty list of approvers means everyone is allowed.
+func IsStageApprover(approvers []string, user, team string) bool {
+	if len(approvers) == 0 {
+		return true
+	}
+	for _, a := range approvers {
+		if a == user || (team != "" && a == team) {
+			return true
+		}
+	}
+	return false
+}
+
+// MakeSlackApprovalValue makes the value of the Slack approval actions for the given stage.
+func MakeSlackApprovalValue(deploymentID, stageID string) string {
+	return deploymentID + "/" + stageID
+}
+
+// ParseSlackApprovalValue returns the deployment ID and stage ID from the value of a Slack approval action.
+func ParseSlackApprovalValue(value string) (deploymentID, stageID string, ok bool) {
+	parts := strings.SplitN(value, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// StageApprovers returns the list of approvers recorded in the metadata of the given stage.
+func StageApprovers(stage *PipelineStage) []string {
+	v := stage.Metadata[ApproversStageMetadataKey]
+	if v == "" {
+		return nil
+	}
+	return strings.Split(v, ",")
+}
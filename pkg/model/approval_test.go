@@ -0,0 +1,89 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsStageApprover(t *testing.T) {
+	testcases := []struct {
+		name      string
+		approvers []string
+		user      string
+		team      string
+		expected  bool
+	}{
+		{
+			name:     "no approvers",
+			user:     "foo",
+			expected: true,
+		},
+		{
+			name:      "listed user",
+			approvers: []string{"foo", "bar"},
+			user:      "bar",
+			expected:  true,
+		},
+		{
+			name:      "listed team",
+			approvers: []string{"foo", "pipe-cd/sre"},
+			user:      "baz",
+			team:      "pipe-cd/sre",
+			expected:  true,
+		},
+		{
+			name:      "not listed",
+			approvers: []string{"foo", "pipe-cd/sre"},
+			user:      "baz",
+			team:      "pipe-cd/dev",
+			expected:  false,
+		},
+		{
+			name:      "empty team never matches",
+			approvers: []string{""},
+			user:      "baz",
+			expected:  false,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := IsStageApprover(tc.approvers, tc.user, tc.team)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestStageApprovers(t *testing.T) {
+	assert.Nil(t, StageApprovers(&PipelineStage{}))
+	assert.Equal(t, []string{"foo", "pipe-cd/sre"}, StageApprovers(&PipelineStage{
+		Metadata: map[string]string{
+			ApproversStageMetadataKey: "foo,pipe-cd/sre",
+		},
+	}))
+}
+
+func TestSlackApprovalValue(t *testing.T) {
+	value := MakeSlackApprovalValue("deployment-1", "stage/with-slash")
+	deploymentID, stageID, ok := ParseSlackApprovalValue(value)
+	assert.True(t, ok)
+	assert.Equal(t, "deployment-1", deploymentID)
+	assert.Equal(t, "stage/with-slash", stageID)
+
+	_, _, ok = ParseSlackApprovalValue("invalid")
+	assert.False(t, ok)
+}
@@ -18,5 +18,10 @@ import "context"
 
 type ReportableCommand struct {
 	*Command
-	Report func(ctx context.Context, status CommandStatus, metadata map[string]string) error
+	Report func(ctx context.Context, status CommandStatus, metadata map[string]string, output []byte) error
+}
+
+// IsHandled reports whether the command has been handled by piped.
+func (c *Command) IsHandled() bool {
+	return c.Status != CommandStatus_COMMAND_NOT_HANDLED_YET
 }
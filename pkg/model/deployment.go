@@ -20,6 +20,12 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
+const (
+	// DeploymentManifestsDiffArtifactName is the name of the deployment artifact
+	// containing the diff between the running and target manifests.
+	DeploymentManifestsDiffArtifactName = "manifests.diff"
+)
+
 var notCompletedDeploymentStatuses = []DeploymentStatus{
 	DeploymentStatus_DEPLOYMENT_PENDING,
 	DeploymentStatus_DEPLOYMENT_PLANNED,
@@ -0,0 +1,217 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// ChainNodeStartTimeout is the maximum duration for the piped of a triggered node
+// to create the deployment. The node is considered failed after that.
+const ChainNodeStartTimeout = time.Hour
+
+// IsCompletedChain checks whether the deployment chain is at a completion state.
+func IsCompletedChain(status ChainStatus) bool {
+	switch status {
+	case ChainStatus_DEPLOYMENT_CHAIN_SUCCESS:
+		return true
+	case ChainStatus_DEPLOYMENT_CHAIN_FAILURE:
+		return true
+	case ChainStatus_DEPLOYMENT_CHAIN_CANCELLED:
+		return true
+	}
+	return false
+}
+
+// FindNode returns the node of the given application at the specified block.
+func (c *DeploymentChain) FindNode(blockIndex uint32, appID string) (*ChainNode, bool) {
+	if int(blockIndex) >= len(c.Blocks) {
+		return nil, false
+	}
+	for _, n := range c.Blocks[blockIndex].Nodes {
+		if n.ApplicationId == appID {
+			return n, true
+		}
+	}
+	return nil, false
+}
+
+// AddNodes adds the given nodes into the block at the specified index.
+// A new block will be appended when the index is equal to the number of blocks.
+// The nodes whose application is already in that block are ignored.
+// Returns the actually added nodes.
+func (c *DeploymentChain) AddNodes(blockIndex uint32, nodes []*ChainNode, now int64) []*ChainNode {
+	if int(blockIndex) > len(c.Blocks) {
+		return nil
+	}
+	if int(blockIndex) == len(c.Blocks) {
+		c.Blocks = append(c.Blocks, &ChainBlock{
+			Status:    ChainStatus_DEPLOYMENT_CHAIN_PENDING,
+			StartedAt: now,
+		})
+	}
+
+	added := make([]*ChainNode, 0, len(nodes))
+	for _, n := range nodes {
+		if _, ok := c.FindNode(blockIndex, n.ApplicationId); ok {
+			continue
+		}
+		c.Blocks[blockIndex].Nodes = append(c.Blocks[blockIndex].Nodes, n)
+		added = append(added, n)
+	}
+	c.UpdateStatus(now)
+	return added
+}
+
+// UpdateStatus re-calculates the status of all blocks and the chain itself
+// based on the statuses of their deployments.
+// The status of an already completed chain is kept as is,
+// and a cancelled chain is not updated at all.
+func (c *DeploymentChain) UpdateStatus(now int64) {
+	if c.Status == ChainStatus_DEPLOYMENT_CHAIN_CANCELLED {
+		return
+	}
+
+	var timedOut string
+	for _, b := range c.Blocks {
+		if IsCompletedChain(b.Status) {
+			continue
+		}
+		for _, n := range b.Nodes {
+			if n.DeploymentId != "" || n.TriggeredAt == 0 {
+				continue
+			}
+			if now-n.TriggeredAt >= int64(ChainNodeStartTimeout.Seconds()) {
+				n.DeploymentStatus = DeploymentStatus_DEPLOYMENT_FAILURE
+				timedOut = n.ApplicationName
+			}
+		}
+		b.Status = blockStatus(b)
+		if IsCompletedChain(b.Status) {
+			b.CompletedAt = now
+		}
+	}
+	if IsCompletedChain(c.Status) {
+		return
+	}
+
+	// A chain which has no downstream block yet is still pending.
+	if len(c.Blocks) <= 1 {
+		c.Status = ChainStatus_DEPLOYMENT_CHAIN_PENDING
+		return
+	}
+
+	status := ChainStatus_DEPLOYMENT_CHAIN_SUCCESS
+	for _, b := range c.Blocks {
+		switch b.Status {
+		case ChainStatus_DEPLOYMENT_CHAIN_FAILURE:
+			c.Status = b.Status
+			c.StatusReason = "One of the deployments in the chain was failed"
+			if timedOut != "" {
+				c.StatusReason = fmt.Sprintf("The deployment of application %s was not started within %v", timedOut, ChainNodeStartTimeout)
+			}
+			c.CompletedAt = now
+			return
+		case ChainStatus_DEPLOYMENT_CHAIN_CANCELLED:
+			c.Status = b.Status
+			c.StatusReason = "One of the deployments in the chain was cancelled"
+			if c.CancelledBy != "" {
+				c.StatusReason = fmt.Sprintf("The chain was cancelled by %s", c.CancelledBy)
+			}
+			c.CompletedAt = now
+			return
+		case ChainStatus_DEPLOYMENT_CHAIN_SUCCESS:
+		default:
+			status = ChainStatus_DEPLOYMENT_CHAIN_RUNNING
+		}
+	}
+
+	c.Status = status
+	switch status {
+	case ChainStatus_DEPLOYMENT_CHAIN_SUCCESS:
+		c.StatusReason = "All deployments in the chain were completed successfully"
+		c.CompletedAt = now
+	case ChainStatus_DEPLOYMENT_CHAIN_RUNNING:
+		c.StatusReason = "The deployments in the chain are running"
+	}
+}
+
+// Cancel marks the chain as cancelled by the given commander.
+// The cancellation is terminal, the chain will not be updated anymore.
+func (c *DeploymentChain) Cancel(commander string, now int64) {
+	c.CancelledBy = commander
+	c.Status = ChainStatus_DEPLOYMENT_CHAIN_CANCELLED
+	c.StatusReason = fmt.Sprintf("The chain was cancelled by %s", commander)
+	c.CompletedAt = now
+}
+
+// NodesToTrigger returns the nodes of the first block whose previous block
+// has been completed successfully, and which are waiting for their deployments.
+// The trigger time of those nodes is set to now if they have not been triggered yet.
+// Nothing is returned for a completed chain.
+func (c *DeploymentChain) NodesToTrigger(now int64) (uint32, []*ChainNode) {
+	if IsCompletedChain(c.Status) {
+		return 0, nil
+	}
+	for i := 1; i < len(c.Blocks); i++ {
+		if c.Blocks[i-1].Status != ChainStatus_DEPLOYMENT_CHAIN_SUCCESS {
+			return 0, nil
+		}
+		b := c.Blocks[i]
+		if IsCompletedChain(b.Status) {
+			continue
+		}
+		nodes := make([]*ChainNode, 0, len(b.Nodes))
+		for _, n := range b.Nodes {
+			if n.DeploymentId != "" {
+				continue
+			}
+			if n.TriggeredAt == 0 {
+				n.TriggeredAt = now
+			}
+			nodes = append(nodes, n)
+		}
+		return uint32(i), nodes
+	}
+	return 0, nil
+}
+
+func blockStatus(b *ChainBlock) ChainStatus {
+	var (
+		succeeded int
+		started   bool
+	)
+	for _, n := range b.Nodes {
+		switch n.DeploymentStatus {
+		case DeploymentStatus_DEPLOYMENT_FAILURE:
+			return ChainStatus_DEPLOYMENT_CHAIN_FAILURE
+		case DeploymentStatus_DEPLOYMENT_CANCELLED:
+			return ChainStatus_DEPLOYMENT_CHAIN_CANCELLED
+		case DeploymentStatus_DEPLOYMENT_SUCCESS:
+			succeeded++
+		}
+		if n.DeploymentId != "" {
+			started = true
+		}
+	}
+	if len(b.Nodes) > 0 && succeeded == len(b.Nodes) {
+		return ChainStatus_DEPLOYMENT_CHAIN_SUCCESS
+	}
+	if started {
+		return ChainStatus_DEPLOYMENT_CHAIN_RUNNING
+	}
+	return ChainStatus_DEPLOYMENT_CHAIN_PENDING
+}
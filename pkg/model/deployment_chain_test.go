@@ -0,0 +1,180 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeploymentChainUpdateStatus(t *testing.T) {
+	newChain := func(statuses ...DeploymentStatus) *DeploymentChain {
+		c := &DeploymentChain{
+			Blocks: []*ChainBlock{
+				{
+					Nodes: []*ChainNode{
+						{ApplicationId: "head", DeploymentId: "d-head", DeploymentStatus: DeploymentStatus_DEPLOYMENT_SUCCESS},
+					},
+				},
+			},
+		}
+		nodes := make([]*ChainNode, 0, len(statuses))
+		for i, s := range statuses {
+			nodes = append(nodes, &ChainNode{
+				ApplicationId:    string(rune('a' + i)),
+				DeploymentId:     string(rune('a' + i)),
+				DeploymentStatus: s,
+			})
+		}
+		c.AddNodes(1, nodes, 1)
+		return c
+	}
+
+	testcases := []struct {
+		name     string
+		chain    *DeploymentChain
+		expected ChainStatus
+	}{
+		{
+			name:     "no downstream block",
+			chain:    &DeploymentChain{Blocks: []*ChainBlock{{}}},
+			expected: ChainStatus_DEPLOYMENT_CHAIN_PENDING,
+		},
+		{
+			name:     "downstream deployments are running",
+			chain:    newChain(DeploymentStatus_DEPLOYMENT_SUCCESS, DeploymentStatus_DEPLOYMENT_RUNNING),
+			expected: ChainStatus_DEPLOYMENT_CHAIN_RUNNING,
+		},
+		{
+			name:     "all deployments succeeded",
+			chain:    newChain(DeploymentStatus_DEPLOYMENT_SUCCESS, DeploymentStatus_DEPLOYMENT_SUCCESS),
+			expected: ChainStatus_DEPLOYMENT_CHAIN_SUCCESS,
+		},
+		{
+			name:     "one deployment failed",
+			chain:    newChain(DeploymentStatus_DEPLOYMENT_RUNNING, DeploymentStatus_DEPLOYMENT_FAILURE),
+			expected: ChainStatus_DEPLOYMENT_CHAIN_FAILURE,
+		},
+		{
+			name:     "one deployment was cancelled",
+			chain:    newChain(DeploymentStatus_DEPLOYMENT_CANCELLED, DeploymentStatus_DEPLOYMENT_SUCCESS),
+			expected: ChainStatus_DEPLOYMENT_CHAIN_CANCELLED,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.chain.UpdateStatus(1)
+			assert.Equal(t, tc.expected, tc.chain.Status)
+		})
+	}
+}
+
+func TestDeploymentChainAddNodes(t *testing.T) {
+	c := &DeploymentChain{
+		Blocks: []*ChainBlock{
+			{Nodes: []*ChainNode{{ApplicationId: "head"}}},
+		},
+	}
+
+	added := c.AddNodes(1, []*ChainNode{{ApplicationId: "a"}, {ApplicationId: "b"}}, 1)
+	assert.Equal(t, 2, len(added))
+	assert.Equal(t, 2, len(c.Blocks))
+
+	// Applications already in the block are ignored.
+	added = c.AddNodes(1, []*ChainNode{{ApplicationId: "b"}, {ApplicationId: "c"}}, 1)
+	assert.Equal(t, []*ChainNode{{ApplicationId: "c"}}, added)
+	assert.Equal(t, 3, len(c.Blocks[1].Nodes))
+
+	// Skipping a block is not allowed.
+	added = c.AddNodes(3, []*ChainNode{{ApplicationId: "d"}}, 1)
+	assert.Equal(t, 0, len(added))
+	assert.Equal(t, 2, len(c.Blocks))
+}
+
+func TestDeploymentChainCancel(t *testing.T) {
+	c := &DeploymentChain{
+		Blocks: []*ChainBlock{
+			{Nodes: []*ChainNode{{ApplicationId: "head", DeploymentId: "d-head", DeploymentStatus: DeploymentStatus_DEPLOYMENT_SUCCESS}}},
+			{Nodes: []*ChainNode{{ApplicationId: "a", DeploymentId: "d-a", DeploymentStatus: DeploymentStatus_DEPLOYMENT_RUNNING}}},
+		},
+	}
+	c.UpdateStatus(1)
+	assert.Equal(t, ChainStatus_DEPLOYMENT_CHAIN_RUNNING, c.Status)
+
+	c.Cancel("user", 2)
+	assert.Equal(t, ChainStatus_DEPLOYMENT_CHAIN_CANCELLED, c.Status)
+
+	// The running deployment was completed successfully after the cancellation.
+	c.Blocks[1].Nodes[0].DeploymentStatus = DeploymentStatus_DEPLOYMENT_SUCCESS
+	c.UpdateStatus(3)
+	assert.Equal(t, ChainStatus_DEPLOYMENT_CHAIN_CANCELLED, c.Status)
+	assert.Equal(t, int64(2), c.CompletedAt)
+}
+
+func TestDeploymentChainNodesToTrigger(t *testing.T) {
+	c := &DeploymentChain{
+		Blocks: []*ChainBlock{
+			{Nodes: []*ChainNode{{ApplicationId: "head", DeploymentId: "d-head", DeploymentStatus: DeploymentStatus_DEPLOYMENT_RUNNING}}},
+		},
+	}
+	c.AddNodes(1, []*ChainNode{{ApplicationId: "a"}, {ApplicationId: "b"}}, 1)
+
+	// The previous block is still running.
+	_, nodes := c.NodesToTrigger(1)
+	assert.Equal(t, 0, len(nodes))
+
+	c.Blocks[0].Nodes[0].DeploymentStatus = DeploymentStatus_DEPLOYMENT_SUCCESS
+	c.UpdateStatus(2)
+	block, nodes := c.NodesToTrigger(2)
+	assert.Equal(t, uint32(1), block)
+	assert.Equal(t, 2, len(nodes))
+	assert.Equal(t, int64(2), nodes[0].TriggeredAt)
+
+	// A node of the next block must wait for all nodes of the current block.
+	c.Blocks[1].Nodes[0].DeploymentId = "d-a"
+	c.Blocks[1].Nodes[0].DeploymentStatus = DeploymentStatus_DEPLOYMENT_SUCCESS
+	c.AddNodes(2, []*ChainNode{{ApplicationId: "c"}}, 3)
+	block, nodes = c.NodesToTrigger(3)
+	assert.Equal(t, uint32(1), block)
+	assert.Equal(t, []string{"b"}, []string{nodes[0].ApplicationId})
+	// The trigger time is kept for the already triggered node.
+	assert.Equal(t, int64(2), nodes[0].TriggeredAt)
+
+	c.Blocks[1].Nodes[1].DeploymentId = "d-b"
+	c.Blocks[1].Nodes[1].DeploymentStatus = DeploymentStatus_DEPLOYMENT_SUCCESS
+	c.UpdateStatus(4)
+	block, nodes = c.NodesToTrigger(4)
+	assert.Equal(t, uint32(2), block)
+	assert.Equal(t, "c", nodes[0].ApplicationId)
+}
+
+func TestDeploymentChainNodeStartTimeout(t *testing.T) {
+	c := &DeploymentChain{
+		Blocks: []*ChainBlock{
+			{Nodes: []*ChainNode{{ApplicationId: "head", DeploymentId: "d-head", DeploymentStatus: DeploymentStatus_DEPLOYMENT_SUCCESS}}},
+			{Nodes: []*ChainNode{{ApplicationId: "a", ApplicationName: "app-a", TriggeredAt: 100}}},
+		},
+	}
+	timeout := int64(ChainNodeStartTimeout.Seconds())
+
+	c.UpdateStatus(100 + timeout - 1)
+	assert.Equal(t, ChainStatus_DEPLOYMENT_CHAIN_RUNNING, c.Status)
+
+	c.UpdateStatus(100 + timeout)
+	assert.Equal(t, ChainStatus_DEPLOYMENT_CHAIN_FAILURE, c.Status)
+	assert.Contains(t, c.StatusReason, "app-a")
+}
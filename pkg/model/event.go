@@ -29,6 +29,8 @@ func (e Event) Group() EventGroup {
 		return EventGroup_EVENT_APPLICATION_HEALTH
 	case e.Type < 400:
 		return EventGroup_EVENT_PIPED
+	case e.Type < 500:
+		return EventGroup_EVENT_DEPLOYMENT_CHAIN
 	default:
 		return EventGroup_EVENT_NONE
 	}
@@ -58,6 +60,10 @@ func (e *EventDeploymentFailed) GetAppName() string {
 	return e.Deployment.ApplicationName
 }
 
+func (e *EventDeploymentWaitApproval) GetAppName() string {
+	return e.Deployment.ApplicationName
+}
+
 func (e *EventApplicationSynced) GetAppName() string {
 	return e.Application.Id
 }
@@ -65,3 +71,55 @@ func (e *EventApplicationSynced) GetAppName() string {
 func (e *EventApplicationOutOfSync) GetAppName() string {
 	return e.Application.Id
 }
+
+func (e *EventApplicationDriftRepeated) GetAppName() string {
+	return e.Application.Id
+}
+
+func (e *EventDeploymentChainTriggered) GetAppName() string {
+	return e.Deployment.ApplicationName
+}
+
+func (e *EventDeploymentTriggered) GetAppLabels() map[string]string {
+	return e.Deployment.Labels
+}
+
+func (e *EventDeploymentPlanned) GetAppLabels() map[string]string {
+	return e.Deployment.Labels
+}
+
+func (e *EventDeploymentApproved) GetAppLabels() map[string]string {
+	return e.Deployment.Labels
+}
+
+func (e *EventDeploymentRollingBack) GetAppLabels() map[string]string {
+	return e.Deployment.Labels
+}
+
+func (e *EventDeploymentSucceeded) GetAppLabels() map[string]string {
+	return e.Deployment.Labels
+}
+
+func (e *EventDeploymentFailed) GetAppLabels() map[string]string {
+	return e.Deployment.Labels
+}
+
+func (e *EventDeploymentWaitApproval) GetAppLabels() map[string]string {
+	return e.Deployment.Labels
+}
+
+func (e *EventApplicationSynced) GetAppLabels() map[string]string {
+	return e.Application.Labels
+}
+
+func (e *EventApplicationOutOfSync) GetAppLabels() map[string]string {
+	return e.Application.Labels
+}
+
+func (e *EventApplicationDriftRepeated) GetAppLabels() map[string]string {
+	return e.Application.Labels
+}
+
+func (e *EventDeploymentChainTriggered) GetAppLabels() map[string]string {
+	return e.Deployment.Labels
+}
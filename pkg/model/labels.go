@@ -0,0 +1,212 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	labelKeyRegex   = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9_-]{0,61}[A-Za-z0-9])?$`)
+	labelValueRegex = regexp.MustCompile(`^([A-Za-z0-9]([A-Za-z0-9_.-]{0,61}[A-Za-z0-9])?)?$`)
+	setBasedRegex   = regexp.MustCompile(`^(\S+)\s+(in|notin)\s+\((.*)\)$`)
+)
+
+// ValidateLabels checks whether all keys and values of the given labels are valid.
+// A key must consist of alphanumeric characters, '-' and '_' and be at most 63 characters.
+// It is more restrictive than Kubernetes to be usable as a field path in all datastores.
+// A value can be empty or consist of alphanumeric characters, '-', '_' and '.'.
+func ValidateLabels(labels map[string]string) error {
+	for k, v := range labels {
+		if err := validateLabelKey(k); err != nil {
+			return err
+		}
+		if err := validateLabelValue(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateLabelKey(key string) error {
+	if !labelKeyRegex.MatchString(key) {
+		return fmt.Errorf("invalid label key %q", key)
+	}
+	return nil
+}
+
+func validateLabelValue(value string) error {
+	if !labelValueRegex.MatchString(value) {
+		return fmt.Errorf("invalid label value %q", value)
+	}
+	return nil
+}
+
+type labelOperator string
+
+const (
+	labelOperatorEquals       labelOperator = "="
+	labelOperatorNotEquals    labelOperator = "!="
+	labelOperatorIn           labelOperator = "in"
+	labelOperatorNotIn        labelOperator = "notin"
+	labelOperatorExists       labelOperator = "exists"
+	labelOperatorDoesNotExist labelOperator = "!"
+)
+
+type labelRequirement struct {
+	key      string
+	operator labelOperator
+	values   []string
+}
+
+func (r labelRequirement) matches(labels map[string]string) bool {
+	v, ok := labels[r.key]
+	switch r.operator {
+	case labelOperatorEquals:
+		return ok && v == r.values[0]
+	case labelOperatorNotEquals:
+		return !ok || v != r.values[0]
+	case labelOperatorIn:
+		return ok && containsString(r.values, v)
+	case labelOperatorNotIn:
+		return !ok || !containsString(r.values, v)
+	case labelOperatorExists:
+		return ok
+	case labelOperatorDoesNotExist:
+		return !ok
+	}
+	return false
+}
+
+// LabelSelector filters objects by their labels in the same way as Kubernetes.
+// It is a comma-separated list of requirements which must all be satisfied.
+// Supported requirements are:
+//   - key=value, key==value, key!=value
+//   - key in (value1,value2), key notin (value1,value2)
+//   - key, !key
+type LabelSelector struct {
+	requirements []labelRequirement
+}
+
+// ParseLabelSelector parses the given string into a LabelSelector.
+// An empty string gives back a selector matching everything.
+func ParseLabelSelector(s string) (LabelSelector, error) {
+	var sel LabelSelector
+	for _, term := range splitLabelSelector(s) {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		r, err := parseLabelRequirement(term)
+		if err != nil {
+			return LabelSelector{}, err
+		}
+		sel.requirements = append(sel.requirements, r)
+	}
+	return sel, nil
+}
+
+// splitLabelSelector splits the given selector by the commas outside of parentheses.
+func splitLabelSelector(s string) []string {
+	var (
+		terms []string
+		depth int
+		start int
+	)
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				terms = append(terms, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(terms, s[start:])
+}
+
+func parseLabelRequirement(term string) (labelRequirement, error) {
+	r := labelRequirement{}
+
+	switch {
+	case setBasedRegex.MatchString(term):
+		m := setBasedRegex.FindStringSubmatch(term)
+		r.key, r.operator = m[1], labelOperator(m[2])
+		for _, v := range strings.Split(m[3], ",") {
+			r.values = append(r.values, strings.TrimSpace(v))
+		}
+
+	case strings.HasPrefix(term, "!"):
+		r.key, r.operator = strings.TrimSpace(term[1:]), labelOperatorDoesNotExist
+
+	case strings.Contains(term, "!="):
+		parts := strings.SplitN(term, "!=", 2)
+		r.key, r.operator, r.values = strings.TrimSpace(parts[0]), labelOperatorNotEquals, []string{strings.TrimSpace(parts[1])}
+
+	case strings.Contains(term, "=="):
+		parts := strings.SplitN(term, "==", 2)
+		r.key, r.operator, r.values = strings.TrimSpace(parts[0]), labelOperatorEquals, []string{strings.TrimSpace(parts[1])}
+
+	case strings.Contains(term, "="):
+		parts := strings.SplitN(term, "=", 2)
+		r.key, r.operator, r.values = strings.TrimSpace(parts[0]), labelOperatorEquals, []string{strings.TrimSpace(parts[1])}
+
+	default:
+		r.key, r.operator = term, labelOperatorExists
+	}
+
+	if err := validateLabelKey(r.key); err != nil {
+		return labelRequirement{}, fmt.Errorf("invalid label selector %q: %w", term, err)
+	}
+	for _, v := range r.values {
+		if err := validateLabelValue(v); err != nil {
+			return labelRequirement{}, fmt.Errorf("invalid label selector %q: %w", term, err)
+		}
+	}
+	return r, nil
+}
+
+// Empty reports whether the selector has no requirement.
+func (s LabelSelector) Empty() bool {
+	return len(s.requirements) == 0
+}
+
+// Matches reports whether the given labels satisfy all requirements of the selector.
+func (s LabelSelector) Matches(labels map[string]string) bool {
+	for _, r := range s.requirements {
+		if !r.matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// EqualityRequirements returns the key-value pairs the labels must exactly have.
+// They can be used to narrow down the objects before calling Matches.
+func (s LabelSelector) EqualityRequirements() map[string]string {
+	eqs := make(map[string]string)
+	for _, r := range s.requirements {
+		if r.operator == labelOperatorEquals {
+			eqs[r.key] = r.values[0]
+		}
+	}
+	return eqs
+}
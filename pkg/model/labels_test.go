@@ -0,0 +1,140 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateLabels(t *testing.T) {
+	testcases := []struct {
+		name    string
+		labels  map[string]string
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			labels: map[string]string{
+				"team":     "pipe-cd",
+				"tier":     "backend_v1.2",
+				"optional": "",
+			},
+		},
+		{
+			name:    "key contains slash",
+			labels:  map[string]string{"app.kubernetes.io/name": "foo"},
+			wantErr: true,
+		},
+		{
+			name:    "value contains space",
+			labels:  map[string]string{"team": "pipe cd"},
+			wantErr: true,
+		},
+		{
+			name:    "empty key",
+			labels:  map[string]string{"": "foo"},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateLabels(tc.labels)
+			assert.Equal(t, tc.wantErr, err != nil)
+		})
+	}
+}
+
+func TestLabelSelector(t *testing.T) {
+	labels := map[string]string{
+		"env":  "prod",
+		"team": "pipe-cd",
+		"tier": "backend",
+	}
+	testcases := []struct {
+		selector string
+		matches  bool
+		eqs      map[string]string
+	}{
+		{
+			selector: "",
+			matches:  true,
+			eqs:      map[string]string{},
+		},
+		{
+			selector: "env=prod",
+			matches:  true,
+			eqs:      map[string]string{"env": "prod"},
+		},
+		{
+			selector: "env==prod, team=pipe-cd",
+			matches:  true,
+			eqs:      map[string]string{"env": "prod", "team": "pipe-cd"},
+		},
+		{
+			selector: "env=dev",
+			matches:  false,
+			eqs:      map[string]string{"env": "dev"},
+		},
+		{
+			selector: "env!=dev,region!=us",
+			matches:  true,
+			eqs:      map[string]string{},
+		},
+		{
+			selector: "tier in (frontend, backend),env=prod",
+			matches:  true,
+			eqs:      map[string]string{"env": "prod"},
+		},
+		{
+			selector: "tier notin (frontend,backend)",
+			matches:  false,
+			eqs:      map[string]string{},
+		},
+		{
+			selector: "team,!deprecated",
+			matches:  true,
+			eqs:      map[string]string{},
+		},
+		{
+			selector: "!team",
+			matches:  false,
+			eqs:      map[string]string{},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.selector, func(t *testing.T) {
+			sel, err := ParseLabelSelector(tc.selector)
+			require.NoError(t, err)
+			assert.Equal(t, tc.matches, sel.Matches(labels))
+			assert.Equal(t, tc.eqs, sel.EqualityRequirements())
+		})
+	}
+}
+
+func TestParseLabelSelectorError(t *testing.T) {
+	selectors := []string{
+		"env=prod value",
+		"=prod",
+		"tier in (front end)",
+		"app.kubernetes.io/name=foo",
+	}
+	for _, s := range selectors {
+		_, err := ParseLabelSelector(s)
+		assert.Error(t, err, s)
+	}
+}
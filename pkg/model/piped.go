@@ -15,6 +15,7 @@
 package model
 
 import (
+	"fmt"
 	"sort"
 	"time"
 
@@ -104,3 +105,9 @@ func (p *Piped) AddKey(hash, creator string, createdAt time.Time) {
 func (p *Piped) RedactSensitiveData() {
 	p.KeyHash = redactedMessage
 }
+
+// MakePipedConfigRevisionID returns the identifier of the given version
+// of the centrally managed configuration of the piped.
+func MakePipedConfigRevisionID(pipedID string, version int64) string {
+	return fmt.Sprintf("%s:%d", pipedID, version)
+}
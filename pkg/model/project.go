@@ -59,6 +59,148 @@ func (p *Project) RedactSensitiveData() {
 	}
 }
 
+// TeamForRole returns the SSO team through which the given role is granted.
+func (p *ProjectRBACConfig) TeamForRole(role Role_ProjectRole) string {
+	switch role {
+	case Role_ADMIN:
+		return p.Admin
+	case Role_EDITOR:
+		return p.Editor
+	case Role_VIEWER:
+		return p.Viewer
+	default:
+		return ""
+	}
+}
+
+// ValidateRoleBindings checks that the role names are unique
+// and every binding refers to one of the defined roles.
+func (p *ProjectRBACConfig) ValidateRoleBindings() error {
+	roles := make(map[string]struct{}, len(p.Roles))
+	for _, r := range p.Roles {
+		if _, ok := roles[r.Name]; ok {
+			return fmt.Errorf("duplicated role name %q", r.Name)
+		}
+		roles[r.Name] = struct{}{}
+	}
+	for _, b := range p.Bindings {
+		if _, ok := roles[b.Role]; !ok {
+			return fmt.Errorf("binding for team %q refers to an undefined role %q", b.Team, b.Role)
+		}
+	}
+	return nil
+}
+
+// BoundTeams returns the teams from the given list that are referred by at least one role binding.
+func (p *ProjectRBACConfig) BoundTeams(teams []string) []string {
+	var bound []string
+	for _, t := range teams {
+		for _, b := range p.GetBindings() {
+			if b.Team == t {
+				bound = append(bound, t)
+				break
+			}
+		}
+	}
+	return bound
+}
+
+// HasScopedPermission reports whether any of the given teams is granted the permission
+// through a role binding matching the given environment and application labels.
+func (p *ProjectRBACConfig) HasScopedPermission(teams []string, envID string, appLabels map[string]string, perm ProjectRBACRole_Permission) bool {
+	roles := make(map[string]*ProjectRBACRole, len(p.GetRoles()))
+	for _, r := range p.GetRoles() {
+		roles[r.Name] = r
+	}
+	for _, b := range p.GetBindings() {
+		if !containsString(teams, b.Team) || !b.matches(envID, appLabels) {
+			continue
+		}
+		r, ok := roles[b.Role]
+		if !ok {
+			continue
+		}
+		for _, rp := range r.Permissions {
+			if rp == perm {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// BoundLabelKeys returns the application label keys used by the role bindings to scope their permissions.
+func (p *ProjectRBACConfig) BoundLabelKeys() map[string]struct{} {
+	keys := make(map[string]struct{})
+	for _, b := range p.GetBindings() {
+		for k := range b.ApplicationLabels {
+			keys[k] = struct{}{}
+		}
+	}
+	return keys
+}
+
+// HasProjectRolePermission reports whether the project-wide role alone grants the given permission.
+func HasProjectRolePermission(role Role_ProjectRole, perm ProjectRBACRole_Permission) bool {
+	switch perm {
+	case ProjectRBACRole_EDIT_CONFIG:
+		return role == Role_ADMIN
+	default:
+		return role == Role_ADMIN || role == Role_EDITOR
+	}
+}
+
+// Authorize reports whether the given role is allowed to perform the action
+// on an application in the given environment and with the given labels.
+// The users having the required project role are always allowed,
+// the others must be granted the permission through a scoped role binding.
+func (p *Project) Authorize(role *Role, envID string, appLabels map[string]string, perm ProjectRBACRole_Permission) bool {
+	if HasProjectRolePermission(role.ProjectRole, perm) {
+		return true
+	}
+	return len(role.Teams) > 0 && p.Rbac.HasScopedPermission(role.Teams, envID, appLabels, perm)
+}
+
+// RoleForTeam returns the project role granted to the members of the given SSO team.
+// The highest role is returned when the team is bound to multiple roles.
+func (p *ProjectRBACConfig) RoleForTeam(team string) (Role_ProjectRole, bool) {
+	switch {
+	case team == "":
+		return Role_VIEWER, false
+	case team == p.Admin:
+		return Role_ADMIN, true
+	case team == p.Editor:
+		return Role_EDITOR, true
+	case team == p.Viewer:
+		return Role_VIEWER, true
+	default:
+		return Role_VIEWER, false
+	}
+}
+
+// matches reports whether the binding applies to an application
+// in the given environment and with the given labels.
+func (b *ProjectRBACBinding) matches(envID string, appLabels map[string]string) bool {
+	if len(b.EnvIds) > 0 && !containsString(b.EnvIds, envID) {
+		return false
+	}
+	for k, v := range b.ApplicationLabels {
+		if appLabels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
 // RedactSensitiveData redacts sensitive data.
 func (p *ProjectStaticUser) RedactSensitiveData() {
 	p.PasswordHash = redactedMessage
@@ -103,6 +245,9 @@ func (p *ProjectSSOConfig) RedactSensitiveData() {
 	}
 	if p.Google != nil {
 	}
+	if p.Oidc != nil {
+		p.Oidc.RedactSensitiveData()
+	}
 }
 
 // Update updates ProjectSSOConfig with given data.
@@ -118,6 +263,14 @@ func (p *ProjectSSOConfig) Update(sso *ProjectSSOConfig) error {
 	}
 	if sso.Google != nil {
 	}
+	if sso.Oidc != nil {
+		if p.Oidc == nil {
+			p.Oidc = &ProjectSSOConfig_OpenIDConnect{}
+		}
+		if err := p.Oidc.Update(sso.Oidc); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -130,6 +283,11 @@ func (p *ProjectSSOConfig) Encrypt(encrypter encrypter) error {
 	}
 	if p.Google != nil {
 	}
+	if p.Oidc != nil {
+		if err := p.Oidc.Encrypt(encrypter); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -142,6 +300,11 @@ func (p *ProjectSSOConfig) Decrypt(decrypter decrypter) error {
 	}
 	if p.Google != nil {
 	}
+	if p.Oidc != nil {
+		if err := p.Oidc.Decrypt(decrypter); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -154,6 +317,11 @@ func (p *ProjectSSOConfig) GenerateAuthCodeURL(project, callbackURL, state strin
 		}
 		return p.Github.GenerateAuthCodeURL(project, callbackURL, state)
 
+	case ProjectSSOConfig_OIDC:
+		// The auth URL of an OIDC provider can be known only after fetching its discovery document,
+		// so it must be generated by using the oauth/oidc package instead.
+		return "", fmt.Errorf("auth URL for OIDC provider must be generated from its discovery document")
+
 	default:
 		return "", fmt.Errorf("not implemented")
 	}
@@ -239,3 +407,73 @@ func (p *ProjectSSOConfig_GitHub) GenerateAuthCodeURL(project, callbackURL, stat
 
 	return authURL, nil
 }
+
+// RedactSensitiveData redacts sensitive data.
+func (p *ProjectSSOConfig_OpenIDConnect) RedactSensitiveData() {
+	p.ClientId = redactedMessage
+	p.ClientSecret = redactedMessage
+}
+
+// Update updates ProjectSSOConfig with given data.
+func (p *ProjectSSOConfig_OpenIDConnect) Update(input *ProjectSSOConfig_OpenIDConnect) error {
+	if input.ClientId != "" {
+		p.ClientId = input.ClientId
+	}
+	if input.ClientSecret != "" {
+		p.ClientSecret = input.ClientSecret
+	}
+	if input.Issuer != "" {
+		p.Issuer = input.Issuer
+	}
+	if len(input.Scopes) > 0 {
+		p.Scopes = input.Scopes
+	}
+	if input.UsernameClaim != "" {
+		p.UsernameClaim = input.UsernameClaim
+	}
+	if input.GroupsClaim != "" {
+		p.GroupsClaim = input.GroupsClaim
+	}
+	if input.ProxyUrl != "" {
+		p.ProxyUrl = input.ProxyUrl
+	}
+	return nil
+}
+
+// Encrypt encrypts sensitive data in ProjectSSOConfig.
+func (p *ProjectSSOConfig_OpenIDConnect) Encrypt(encrypter encrypter) error {
+	if p.ClientId != "" {
+		encrypedClientID, err := encrypter.Encrypt(p.ClientId)
+		if err != nil {
+			return err
+		}
+		p.ClientId = encrypedClientID
+	}
+	if p.ClientSecret != "" {
+		encryptedClientSecret, err := encrypter.Encrypt(p.ClientSecret)
+		if err != nil {
+			return err
+		}
+		p.ClientSecret = encryptedClientSecret
+	}
+	return nil
+}
+
+// Decrypt decrypts ProjectSSOConfig.
+func (p *ProjectSSOConfig_OpenIDConnect) Decrypt(decrypter decrypter) error {
+	if p.ClientId != "" {
+		decrypedClientID, err := decrypter.Decrypt(p.ClientId)
+		if err != nil {
+			return err
+		}
+		p.ClientId = decrypedClientID
+	}
+	if p.ClientSecret != "" {
+		decryptedClientSecret, err := decrypter.Decrypt(p.ClientSecret)
+		if err != nil {
+			return err
+		}
+		p.ClientSecret = decryptedClientSecret
+	}
+	return nil
+}
@@ -225,3 +225,137 @@ func TestDecrypt(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateRoleBindings(t *testing.T) {
+	cases := []struct {
+		name    string
+		rbac    *ProjectRBACConfig
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			rbac: &ProjectRBACConfig{
+				Admin:    "admin",
+				Roles:    []*ProjectRBACRole{{Name: "deployer"}},
+				Bindings: []*ProjectRBACBinding{{Team: "team-x", Role: "deployer"}},
+			},
+		},
+		{
+			name: "duplicated role",
+			rbac: &ProjectRBACConfig{
+				Admin: "admin",
+				Roles: []*ProjectRBACRole{{Name: "deployer"}, {Name: "deployer"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "undefined role",
+			rbac: &ProjectRBACConfig{
+				Admin:    "admin",
+				Bindings: []*ProjectRBACBinding{{Team: "team-x", Role: "deployer"}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.rbac.ValidateRoleBindings()
+			assert.Equal(t, tc.wantErr, err != nil)
+		})
+	}
+}
+
+func TestHasScopedPermission(t *testing.T) {
+	rbac := &ProjectRBACConfig{
+		Admin: "admin",
+		Roles: []*ProjectRBACRole{
+			{
+				Name: "deployer",
+				Permissions: []ProjectRBACRole_Permission{
+					ProjectRBACRole_SYNC,
+					ProjectRBACRole_APPROVE,
+				},
+			},
+			{
+				Name: "maintainer",
+				Permissions: []ProjectRBACRole_Permission{
+					ProjectRBACRole_EDIT_CONFIG,
+				},
+			},
+		},
+		Bindings: []*ProjectRBACBinding{
+			{
+				Team:   "team-x",
+				Role:   "deployer",
+				EnvIds: []string{"staging"},
+			},
+			{
+				Team:              "team-y",
+				Role:              "maintainer",
+				ApplicationLabels: map[string]string{"team": "y", "tier": "web"},
+			},
+		},
+	}
+
+	cases := []struct {
+		name      string
+		teams     []string
+		envID     string
+		appLabels map[string]string
+		perm      ProjectRBACRole_Permission
+		expected  bool
+	}{
+		{
+			name:     "granted in the bound environment",
+			teams:    []string{"team-x"},
+			envID:    "staging",
+			perm:     ProjectRBACRole_SYNC,
+			expected: true,
+		},
+		{
+			name:     "not granted in another environment",
+			teams:    []string{"team-x"},
+			envID:    "production",
+			perm:     ProjectRBACRole_SYNC,
+			expected: false,
+		},
+		{
+			name:     "permission not in the role",
+			teams:    []string{"team-x"},
+			envID:    "staging",
+			perm:     ProjectRBACRole_CANCEL,
+			expected: false,
+		},
+		{
+			name:      "all labels matched",
+			teams:     []string{"team-y"},
+			envID:     "production",
+			appLabels: map[string]string{"team": "y", "tier": "web", "foo": "bar"},
+			perm:      ProjectRBACRole_EDIT_CONFIG,
+			expected:  true,
+		},
+		{
+			name:      "a label is not matched",
+			teams:     []string{"team-y"},
+			envID:     "production",
+			appLabels: map[string]string{"team": "y"},
+			perm:      ProjectRBACRole_EDIT_CONFIG,
+			expected:  false,
+		},
+		{
+			name:     "team not bound",
+			teams:    []string{"team-z"},
+			envID:    "staging",
+			perm:     ProjectRBACRole_SYNC,
+			expected: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := rbac.HasScopedPermission(tc.teams, tc.envID, tc.appLabels, tc.perm)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
@@ -27,6 +27,9 @@ const (
 	// StageAnalysis represents the waiting state for analysing
 	// the application status based on metrics, log, http request...
 	StageAnalysis Stage = "ANALYSIS"
+	// StageHook represents the state where a Kubernetes Job
+	// specified by user is being executed and waited until it completes.
+	StageHook Stage = "HOOK"
 
 	// StageK8sSync represents the state where
 	// all resources should be synced with the Git state.
@@ -32,6 +32,7 @@ type OAuthClient struct {
 	*github.Client
 
 	projectID  string
+	rbac       *model.ProjectRBACConfig
 	adminTeam  string
 	editorTeam string
 	viewerTeam string
@@ -45,6 +46,7 @@ func NewOAuthClient(ctx context.Context,
 ) (*OAuthClient, error) {
 	c := &OAuthClient{
 		projectID:  projectID,
+		rbac:       rbac,
 		adminTeam:  rbac.Admin,
 		editorTeam: rbac.Editor,
 		viewerTeam: rbac.Viewer,
@@ -106,9 +108,15 @@ func (c *OAuthClient) GetUser(ctx context.Context) (*model.User, error) {
 	if err != nil {
 		return nil, err
 	}
+	boundTeams := c.rbac.BoundTeams(teamNames(teams))
 	role, err := c.decideRole(user.GetLogin(), teams)
 	if err != nil {
-		return nil, err
+		// Members of only the teams having scoped role bindings
+		// can view everything and act on the bound applications.
+		if len(boundTeams) == 0 {
+			return nil, err
+		}
+		role = model.Role_VIEWER
 	}
 
 	return &model.User{
@@ -117,10 +125,25 @@ func (c *OAuthClient) GetUser(ctx context.Context) (*model.User, error) {
 		Role: &model.Role{
 			ProjectId:   c.projectID,
 			ProjectRole: role,
+			Teams:       boundTeams,
 		},
 	}, nil
 }
 
+// teamNames returns the names of the given teams in the "org/team-slug" format.
+func teamNames(teams []*github.Team) []string {
+	names := make([]string, 0, len(teams))
+	for _, team := range teams {
+		slug := team.GetSlug()
+		org := team.Organization.GetLogin()
+		if org == "" || slug == "" {
+			continue
+		}
+		names = append(names, fmt.Sprintf("%s/%s", org, slug))
+	}
+	return names
+}
+
 func (c *OAuthClient) decideRole(user string, teams []*github.Team) (role model.Role_ProjectRole, err error) {
 	var found bool
 
@@ -0,0 +1,429 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dgrijalva/jwt-go"
+	"golang.org/x/oauth2"
+
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+const (
+	discoveryPath = "/.well-known/openid-configuration"
+
+	defaultUsernameClaim = "preferred_username"
+	defaultGroupsClaim   = "groups"
+	avatarClaim          = "picture"
+)
+
+var (
+	defaultScopes     = []string{"openid", "profile", "email"}
+	fallbackUsernames = []string{"email", "sub"}
+	signingMethods    = []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}
+)
+
+// providerMetadata is the part of the discovery document used by PipeCD.
+type providerMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// OAuthClient is a oauth client for OpenID Connect providers.
+type OAuthClient struct {
+	claims jwt.MapClaims
+
+	projectID     string
+	rbac          *model.ProjectRBACConfig
+	adminTeam     string
+	editorTeam    string
+	viewerTeam    string
+	usernameClaim string
+	groupsClaim   string
+}
+
+// GenerateAuthCodeURL generates an auth URL by using the endpoint written in the discovery document of the provider.
+func GenerateAuthCodeURL(ctx context.Context, sso *model.ProjectSSOConfig_OpenIDConnect, projectID, callbackURL, state string) (string, error) {
+	ctx, err := withProxy(ctx, sso.ProxyUrl)
+	if err != nil {
+		return "", err
+	}
+	md, err := discover(ctx, sso.Issuer)
+	if err != nil {
+		return "", err
+	}
+	cfg := makeOAuthConfig(sso, md, projectID, callbackURL)
+	return cfg.AuthCodeURL(state, oauth2.AccessTypeOnline), nil
+}
+
+// NewOAuthClient creates a new oauth client for an OpenID Connect provider.
+// The given code is exchanged for an ID token whose signature, issuer and audience are verified.
+func NewOAuthClient(ctx context.Context,
+	sso *model.ProjectSSOConfig_OpenIDConnect,
+	rbac *model.ProjectRBACConfig,
+	projectID, callbackURL, code string,
+) (*OAuthClient, error) {
+	c := &OAuthClient{
+		projectID:     projectID,
+		rbac:          rbac,
+		adminTeam:     rbac.Admin,
+		editorTeam:    rbac.Editor,
+		viewerTeam:    rbac.Viewer,
+		usernameClaim: sso.UsernameClaim,
+		groupsClaim:   sso.GroupsClaim,
+	}
+	if c.groupsClaim == "" {
+		c.groupsClaim = defaultGroupsClaim
+	}
+
+	ctx, err := withProxy(ctx, sso.ProxyUrl)
+	if err != nil {
+		return nil, err
+	}
+	md, err := discover(ctx, sso.Issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := makeOAuthConfig(sso, md, projectID, callbackURL)
+	token, err := cfg.Exchange(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, fmt.Errorf("missing id_token in the token response")
+	}
+
+	keys, err := fetchKeys(ctx, md.JWKSURI)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := verifyIDToken(rawIDToken, keys, md.Issuer, sso.ClientId)
+	if err != nil {
+		return nil, err
+	}
+	c.claims = claims
+	return c, nil
+}
+
+// GetUser returns a user model.
+func (c *OAuthClient) GetUser(ctx context.Context) (*model.User, error) {
+	username := c.username()
+	if username == "" {
+		return nil, fmt.Errorf("unable to find username in the ID token claims")
+	}
+	groups := c.groups()
+	boundTeams := c.rbac.BoundTeams(groups)
+	role, err := c.decideRole(username, groups)
+	if err != nil {
+		// Members of only the teams having scoped role bindings
+		// can view everything and act on the bound applications.
+		if len(boundTeams) == 0 {
+			return nil, err
+		}
+		role = model.Role_VIEWER
+	}
+	avatar, _ := c.claims[avatarClaim].(string)
+
+	return &model.User{
+		Username:  username,
+		AvatarUrl: avatar,
+		Role: &model.Role{
+			ProjectId:   c.projectID,
+			ProjectRole: role,
+			Teams:       boundTeams,
+		},
+	}, nil
+}
+
+func (c *OAuthClient) username() string {
+	if c.usernameClaim != "" {
+		v, _ := c.claims[c.usernameClaim].(string)
+		return v
+	}
+	for _, name := range append([]string{defaultUsernameClaim}, fallbackUsernames...) {
+		if v, _ := c.claims[name].(string); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// groups returns the groups of the user.
+// Some providers return a single group as a string instead of a list.
+func (c *OAuthClient) groups() []string {
+	switch v := c.claims[c.groupsClaim].(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		groups := make([]string, 0, len(v))
+		for _, g := range v {
+			if s, ok := g.(string); ok {
+				groups = append(groups, s)
+			}
+		}
+		return groups
+	default:
+		return nil
+	}
+}
+
+func (c *OAuthClient) decideRole(user string, groups []string) (role model.Role_ProjectRole, err error) {
+	var found bool
+
+	for _, g := range groups {
+		if g == "" {
+			continue
+		}
+		switch g {
+		case c.adminTeam:
+			role = model.Role_ADMIN
+			return
+		case c.editorTeam:
+			role = model.Role_EDITOR
+			found = true
+		case c.viewerTeam:
+			if role != model.Role_EDITOR {
+				role = model.Role_VIEWER
+				found = true
+			}
+		}
+	}
+
+	if found {
+		return
+	}
+
+	err = fmt.Errorf("user (%s) not found in any of the %d project teams", user, len(groups))
+	return
+}
+
+func makeOAuthConfig(sso *model.ProjectSSOConfig_OpenIDConnect, md *providerMetadata, projectID, callbackURL string) oauth2.Config {
+	scopes := append([]string{}, defaultScopes...)
+	for _, s := range sso.Scopes {
+		if !containsString(scopes, s) {
+			scopes = append(scopes, s)
+		}
+	}
+	return oauth2.Config{
+		ClientID:     sso.ClientId,
+		ClientSecret: sso.ClientSecret,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  md.AuthorizationEndpoint,
+			TokenURL: md.TokenEndpoint,
+		},
+		// Unlike GitHub, OIDC providers require the same redirect URL while exchanging the code.
+		RedirectURL: fmt.Sprintf("%s?project=%s", callbackURL, url.QueryEscape(projectID)),
+		Scopes:      scopes,
+	}
+}
+
+func withProxy(ctx context.Context, proxy string) (context.Context, error) {
+	if proxy == "" {
+		return ctx, nil
+	}
+	proxyURL, err := url.Parse(proxy)
+	if err != nil {
+		return nil, err
+	}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = http.ProxyURL(proxyURL)
+	return context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: t}), nil
+}
+
+func discover(ctx context.Context, issuer string) (*providerMetadata, error) {
+	var md providerMetadata
+	u := strings.TrimSuffix(issuer, "/") + discoveryPath
+	if err := getJSON(ctx, u, &md); err != nil {
+		return nil, fmt.Errorf("failed to fetch discovery document: %w", err)
+	}
+	// https://openid.net/specs/openid-connect-discovery-1_0.html#ProviderConfigurationValidation
+	if strings.TrimSuffix(md.Issuer, "/") != strings.TrimSuffix(issuer, "/") {
+		return nil, fmt.Errorf("issuer %q in the discovery document does not match the configured one %q", md.Issuer, issuer)
+	}
+	if md.AuthorizationEndpoint == "" || md.TokenEndpoint == "" || md.JWKSURI == "" {
+		return nil, fmt.Errorf("discovery document of %s is missing required endpoints", issuer)
+	}
+	return &md, nil
+}
+
+func getJSON(ctx context.Context, u string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	client := http.DefaultClient
+	if c, ok := ctx.Value(oauth2.HTTPClient).(*http.Client); ok {
+		client = c
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, 1024*1024))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s from %s: %s", resp.Status, u, strings.TrimSpace(string(body)))
+	}
+	return json.Unmarshal(body, v)
+}
+
+// jsonWebKey represents a public key in JWK format.
+// https://tools.ietf.org/html/rfc7517
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func fetchKeys(ctx context.Context, jwksURI string) ([]jsonWebKey, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := getJSON(ctx, jwksURI, &set); err != nil {
+		return nil, fmt.Errorf("failed to fetch JSON web keys: %w", err)
+	}
+	return set.Keys, nil
+}
+
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	decode := func(s string) (*big.Int, error) {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil {
+			return nil, err
+		}
+		return new(big.Int).SetBytes(b), nil
+	}
+
+	switch k.Kty {
+	case "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+// verifyIDToken verifies the signature, the expiration, the issuer and the audience of the given ID token.
+// https://openid.net/specs/openid-connect-core-1_0.html#IDTokenValidation
+func verifyIDToken(raw string, keys []jsonWebKey, issuer, clientID string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	parser := &jwt.Parser{ValidMethods: signingMethods}
+	_, err := parser.ParseWithClaims(raw, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		for _, k := range keys {
+			if k.Use != "" && k.Use != "sig" {
+				continue
+			}
+			if kid == "" || k.Kid == kid {
+				return k.publicKey()
+			}
+		}
+		return nil, fmt.Errorf("no key was found for kid %q", kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID token: %w", err)
+	}
+
+	if iss, _ := claims["iss"].(string); strings.TrimSuffix(iss, "/") != strings.TrimSuffix(issuer, "/") {
+		return nil, fmt.Errorf("invalid ID token: unexpected issuer %q", iss)
+	}
+	if !verifyAudience(claims["aud"], clientID) {
+		return nil, fmt.Errorf("invalid ID token: the client %q is not in the audience", clientID)
+	}
+	return claims, nil
+}
+
+// verifyAudience checks the aud claim which can be either a string or a list of strings.
+func verifyAudience(aud interface{}, clientID string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == clientID
+	case []interface{}:
+		for _, a := range v {
+			if s, ok := a.(string); ok && s == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,295 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pipe-cd/pipe/pkg/model"
+)
+
+// stubProvider is a minimal OpenID Connect provider used for testing.
+type stubProvider struct {
+	*httptest.Server
+	key    *rsa.PrivateKey
+	claims jwt.MapClaims
+}
+
+func newStubProvider(t *testing.T) *stubProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	p := &stubProvider{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc(discoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(providerMetadata{
+			Issuer:                p.URL,
+			AuthorizationEndpoint: p.URL + "/authorize",
+			TokenEndpoint:         p.URL + "/token",
+			JWKSURI:               p.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []jsonWebKey{{
+				Kty: "RSA",
+				Kid: "key-1",
+				Use: "sig",
+				N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("code") != "valid-code" {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, p.claims)
+		token.Header["kid"] = "key-1"
+		idToken, err := token.SignedString(key)
+		require.NoError(t, err)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "access-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     idToken,
+		})
+	})
+	p.Server = httptest.NewServer(mux)
+	return p
+}
+
+func TestGenerateAuthCodeURL(t *testing.T) {
+	p := newStubProvider(t)
+	defer p.Close()
+
+	sso := &model.ProjectSSOConfig_OpenIDConnect{
+		ClientId:     "client-id",
+		ClientSecret: "client-secret",
+		Issuer:       p.URL,
+		Scopes:       []string{"groups", "openid"},
+	}
+	got, err := GenerateAuthCodeURL(context.Background(), sso, "project-1", "https://pipecd.dev/auth/callback", "state")
+	require.NoError(t, err)
+
+	u, err := url.Parse(got)
+	require.NoError(t, err)
+	assert.Equal(t, p.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+	assert.Equal(t, "client-id", u.Query().Get("client_id"))
+	assert.Equal(t, "openid profile email groups", u.Query().Get("scope"))
+	assert.Equal(t, "https://pipecd.dev/auth/callback?project=project-1", u.Query().Get("redirect_uri"))
+	assert.Equal(t, "state", u.Query().Get("state"))
+}
+
+func TestGetUser(t *testing.T) {
+	p := newStubProvider(t)
+	defer p.Close()
+
+	rbac := &model.ProjectRBACConfig{
+		Admin:  "admins",
+		Editor: "editors",
+		Viewer: "viewers",
+	}
+	now := time.Now()
+	testcases := []struct {
+		name     string
+		sso      *model.ProjectSSOConfig_OpenIDConnect
+		code     string
+		claims   jwt.MapClaims
+		expected *model.User
+		wantErr  bool
+	}{
+		{
+			name: "editor with default claims",
+			sso:  &model.ProjectSSOConfig_OpenIDConnect{ClientId: "client-id", ClientSecret: "secret"},
+			code: "valid-code",
+			claims: jwt.MapClaims{
+				"iss":                "",
+				"aud":                "client-id",
+				"exp":                now.Add(time.Hour).Unix(),
+				"sub":                "123",
+				"preferred_username": "foo",
+				"picture":            "https://avatar",
+				"groups":             []string{"viewers", "editors"},
+			},
+			expected: &model.User{
+				Username:  "foo",
+				AvatarUrl: "https://avatar",
+				Role:      &model.Role{ProjectId: "project-1", ProjectRole: model.Role_EDITOR},
+			},
+		},
+		{
+			name: "admin with custom claims and audience list",
+			sso: &model.ProjectSSOConfig_OpenIDConnect{
+				ClientId:      "client-id",
+				ClientSecret:  "secret",
+				UsernameClaim: "email",
+				GroupsClaim:   "roles",
+			},
+			code: "valid-code",
+			claims: jwt.MapClaims{
+				"iss":   "",
+				"aud":   []string{"another", "client-id"},
+				"exp":   now.Add(time.Hour).Unix(),
+				"email": "foo@pipecd.dev",
+				"roles": "admins",
+			},
+			expected: &model.User{
+				Username: "foo@pipecd.dev",
+				Role:     &model.Role{ProjectId: "project-1", ProjectRole: model.Role_ADMIN},
+			},
+		},
+		{
+			name: "not in any team",
+			sso:  &model.ProjectSSOConfig_OpenIDConnect{ClientId: "client-id", ClientSecret: "secret"},
+			code: "valid-code",
+			claims: jwt.MapClaims{
+				"iss":    "",
+				"aud":    "client-id",
+				"exp":    now.Add(time.Hour).Unix(),
+				"sub":    "123",
+				"groups": []string{"others"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "wrong audience",
+			sso:  &model.ProjectSSOConfig_OpenIDConnect{ClientId: "client-id", ClientSecret: "secret"},
+			code: "valid-code",
+			claims: jwt.MapClaims{
+				"iss":    "",
+				"aud":    "another",
+				"exp":    now.Add(time.Hour).Unix(),
+				"sub":    "123",
+				"groups": []string{"admins"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "expired token",
+			sso:  &model.ProjectSSOConfig_OpenIDConnect{ClientId: "client-id", ClientSecret: "secret"},
+			code: "valid-code",
+			claims: jwt.MapClaims{
+				"iss":    "",
+				"aud":    "client-id",
+				"exp":    now.Add(-time.Hour).Unix(),
+				"sub":    "123",
+				"groups": []string{"admins"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "wrong issuer",
+			sso:  &model.ProjectSSOConfig_OpenIDConnect{ClientId: "client-id", ClientSecret: "secret"},
+			code: "valid-code",
+			claims: jwt.MapClaims{
+				"iss":    "https://another",
+				"aud":    "client-id",
+				"exp":    now.Add(time.Hour).Unix(),
+				"sub":    "123",
+				"groups": []string{"admins"},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "invalid code",
+			sso:     &model.ProjectSSOConfig_OpenIDConnect{ClientId: "client-id", ClientSecret: "secret"},
+			code:    "invalid-code",
+			wantErr: true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.sso.Issuer = p.URL
+			if tc.claims != nil && tc.claims["iss"] == "" {
+				tc.claims["iss"] = p.URL
+			}
+			p.claims = tc.claims
+
+			ctx := context.Background()
+			c, err := NewOAuthClient(ctx, tc.sso, rbac, "project-1", "https://pipecd.dev/auth/callback", tc.code)
+			if err == nil {
+				var user *model.User
+				user, err = c.GetUser(ctx)
+				if err == nil {
+					assert.Equal(t, tc.expected, user)
+				}
+			}
+			assert.Equal(t, tc.wantErr, err != nil, "%v", err)
+		})
+	}
+}
+
+func TestDecideRole(t *testing.T) {
+	cases := []struct {
+		name    string
+		groups  []string
+		role    model.Role_ProjectRole
+		wantErr bool
+	}{
+		{
+			name:    "nothing",
+			groups:  []string{"team1"},
+			wantErr: true,
+		},
+		{
+			name:   "admin",
+			groups: []string{"team-viewer", "team-editor", "team-admin"},
+			role:   model.Role_ADMIN,
+		},
+		{
+			name:   "editor",
+			groups: []string{"team1", "team-editor", "team-viewer"},
+			role:   model.Role_EDITOR,
+		},
+		{
+			name:   "viewer",
+			groups: []string{"team1", "team-viewer"},
+			role:   model.Role_VIEWER,
+		},
+	}
+
+	c := &OAuthClient{
+		adminTeam:  "team-admin",
+		editorTeam: "team-editor",
+		viewerTeam: "team-viewer",
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			role, err := c.decideRole("foo", tc.groups)
+			assert.Equal(t, tc.wantErr, err != nil)
+			if err == nil {
+				assert.Equal(t, tc.role, role)
+			}
+		})
+	}
+}
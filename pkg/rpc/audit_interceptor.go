@@ -0,0 +1,130 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/pipe-cd/pipe/pkg/model"
+	"github.com/pipe-cd/pipe/pkg/rpc/rpcauth"
+)
+
+// AuditLogRecorder stores the given audit log.
+type AuditLogRecorder interface {
+	AddAuditLog(ctx context.Context, l *model.AuditLog) error
+}
+
+// AuditLogUnaryServerInterceptor records an audit log for every mutating request.
+// A method whose name starts with "Get" or "List" is considered as a read-only one.
+// This must be placed before the authentication interceptors so that the requests
+// denied by them are recorded as well. The actor is taken from the credentials verified
+// by those interceptors, and the requests without any verified credentials are only logged
+// since they cannot be attributed to any project.
+func AuditLogUnaryServerInterceptor(recorder AuditLogRecorder, logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !isMutatingMethod(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		ctx, id := rpcauth.ContextWithIdentity(ctx)
+
+		resp, err := handler(ctx, req)
+
+		st := status.Convert(err)
+		l, ok := newAuditLog(id, info.FullMethod)
+		if !ok {
+			logger.Warn("rejected a mutating request without valid credentials",
+				zap.String("method", info.FullMethod),
+				zap.String("code", st.Code().String()),
+			)
+			return resp, err
+		}
+		l.Succeeded = err == nil
+		l.StatusCode = st.Code().String()
+		l.StatusMessage = st.Message()
+		l.Targets = make(map[string]string)
+		addTargetIDs(l.Targets, req)
+		if err == nil {
+			addTargetIDs(l.Targets, resp)
+		}
+
+		// The action was already done, so failing to record it must not change the result.
+		if e := recorder.AddAuditLog(ctx, l); e != nil {
+			logger.Error("failed to record audit log",
+				zap.String("method", info.FullMethod),
+				zap.String("actor", l.Actor),
+				zap.Error(e),
+			)
+		}
+		return resp, err
+	}
+}
+
+func isMutatingMethod(fullMethod string) bool {
+	name := fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+	return !strings.HasPrefix(name, "Get") && !strings.HasPrefix(name, "List")
+}
+
+// newAuditLog builds an audit log whose actor is taken from the verified credentials.
+func newAuditLog(id *rpcauth.Identity, method string) (*model.AuditLog, bool) {
+	l := &model.AuditLog{
+		Id:     uuid.New().String(),
+		Method: method,
+	}
+	if claims := id.Claims; claims != nil {
+		l.ProjectId = claims.Role.ProjectId
+		l.Actor = claims.Subject
+		l.ActorType = model.AuditActorType_AUDIT_ACTOR_USER
+		l.Role = claims.Role.ProjectRole.String()
+		return l, true
+	}
+	if key := id.APIKey; key != nil {
+		l.ProjectId = key.ProjectId
+		l.Actor = key.Id
+		l.ActorType = model.AuditActorType_AUDIT_ACTOR_API_KEY
+		l.Role = key.Role.String()
+		return l, true
+	}
+	return nil, false
+}
+
+// addTargetIDs adds the non-empty top-level ID fields of the given message
+// such as "id", "piped_id" into the targets map.
+func addTargetIDs(targets map[string]string, msg interface{}) {
+	m, ok := msg.(proto.Message)
+	if !ok {
+		return
+	}
+	m.ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.Kind() != protoreflect.StringKind || fd.IsList() || fd.IsMap() {
+			return true
+		}
+		name := string(fd.Name())
+		if name != "id" && !strings.HasSuffix(name, "_id") {
+			return true
+		}
+		if _, ok := targets[name]; !ok {
+			targets[name] = v.String()
+		}
+		return true
+	})
+}
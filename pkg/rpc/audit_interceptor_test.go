@@ -0,0 +1,182 @@
+// Copyright 2020 The PipeCD Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	jwtgo "github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/pipe-cd/pipe/pkg/app/api/service/webservice"
+	"github.com/pipe-cd/pipe/pkg/jwt"
+	"github.com/pipe-cd/pipe/pkg/model"
+	"github.com/pipe-cd/pipe/pkg/rpc/rpcauth"
+)
+
+type fakeAuditLogRecorder struct {
+	logs []*model.AuditLog
+}
+
+func (r *fakeAuditLogRecorder) AddAuditLog(_ context.Context, l *model.AuditLog) error {
+	r.logs = append(r.logs, l)
+	return nil
+}
+
+type fakeAPIKeyVerifier struct {
+	key *model.APIKey
+}
+
+func (v fakeAPIKeyVerifier) Verify(_ context.Context, key string) (*model.APIKey, error) {
+	if key != "valid-key" {
+		return nil, fmt.Errorf("invalid key")
+	}
+	return v.key, nil
+}
+
+type fakeJWTVerifier struct {
+	claims *jwt.Claims
+}
+
+func (v fakeJWTVerifier) Verify(token string) (*jwt.Claims, error) {
+	if token != "valid-token" {
+		return nil, fmt.Errorf("invalid token")
+	}
+	return v.claims, nil
+}
+
+func TestAuditLogUnaryServerInterceptor(t *testing.T) {
+	apiKey := &model.APIKey{
+		Id:        "key-id",
+		ProjectId: "project-id",
+		Role:      model.APIKey_READ_WRITE,
+	}
+	apiKeyContext := func(key string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "API-KEY "+key))
+	}
+	testcases := []struct {
+		name       string
+		ctx        context.Context
+		method     string
+		jwtAuth    bool
+		claims     *jwt.Claims
+		handlerErr error
+		expectErr  error
+		expected   *model.AuditLog
+	}{
+		{
+			name:   "read-only method is not recorded",
+			ctx:    apiKeyContext("valid-key"),
+			method: "/pipe.api.service.apiservice.APIService/GetDeployment",
+		},
+		{
+			name:      "unauthenticated request is not recorded",
+			ctx:       apiKeyContext("invalid-key"),
+			method:    "/pipe.api.service.apiservice.APIService/SyncApplication",
+			expectErr: status.Error(codes.Unauthenticated, "Unauthenticated"),
+		},
+		{
+			name:   "succeeded action",
+			ctx:    apiKeyContext("valid-key"),
+			method: "/pipe.api.service.apiservice.APIService/SyncApplication",
+			expected: &model.AuditLog{
+				ProjectId:  "project-id",
+				Actor:      "key-id",
+				ActorType:  model.AuditActorType_AUDIT_ACTOR_API_KEY,
+				Role:       "READ_WRITE",
+				Method:     "/pipe.api.service.apiservice.APIService/SyncApplication",
+				Targets:    map[string]string{"application_id": "app-id", "id": "command-id"},
+				Succeeded:  true,
+				StatusCode: "OK",
+			},
+		},
+		{
+			name:       "failed action",
+			ctx:        apiKeyContext("valid-key"),
+			method:     "/pipe.api.service.apiservice.APIService/SyncApplication",
+			handlerErr: status.Error(codes.NotFound, "Application is not found"),
+			expectErr:  status.Error(codes.NotFound, "Application is not found"),
+			expected: &model.AuditLog{
+				ProjectId:     "project-id",
+				Actor:         "key-id",
+				ActorType:     model.AuditActorType_AUDIT_ACTOR_API_KEY,
+				Role:          "READ_WRITE",
+				Method:        "/pipe.api.service.apiservice.APIService/SyncApplication",
+				Targets:       map[string]string{"application_id": "app-id"},
+				StatusCode:    "NotFound",
+				StatusMessage: "Application is not found",
+			},
+		},
+		{
+			name:    "action denied by the RBAC authorizer",
+			ctx:     metadata.NewIncomingContext(context.Background(), metadata.Pairs("cookie", jwt.SignedTokenKey+"=valid-token")),
+			method:  "/pipe.api.service.webservice.WebService/DisablePiped",
+			jwtAuth: true,
+			claims: &jwt.Claims{
+				StandardClaims: jwtgo.StandardClaims{Subject: "user"},
+				Role:           model.Role{ProjectId: "project-id", ProjectRole: model.Role_VIEWER},
+			},
+			expectErr: status.Error(codes.PermissionDenied, "Permission Denied"),
+			expected: &model.AuditLog{
+				ProjectId:     "project-id",
+				Actor:         "user",
+				ActorType:     model.AuditActorType_AUDIT_ACTOR_USER,
+				Role:          "VIEWER",
+				Method:        "/pipe.api.service.webservice.WebService/DisablePiped",
+				Targets:       map[string]string{"application_id": "app-id"},
+				StatusCode:    "PermissionDenied",
+				StatusMessage: "Permission Denied",
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := &fakeAuditLogRecorder{}
+			auth := rpcauth.APIKeyUnaryServerInterceptor(fakeAPIKeyVerifier{key: apiKey}, zap.NewNop())
+			if tc.jwtAuth {
+				auth = rpcauth.JWTUnaryServerInterceptor(fakeJWTVerifier{claims: tc.claims}, webservice.NewRBACAuthorizer(), zap.NewNop())
+			}
+			in := ChainUnaryServerInterceptors(AuditLogUnaryServerInterceptor(recorder, zap.NewNop()), auth)
+			req := &model.Command{ApplicationId: "app-id"}
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				if tc.handlerErr != nil {
+					return nil, tc.handlerErr
+				}
+				return &model.Command{Id: "command-id"}, nil
+			}
+
+			_, err := in(tc.ctx, req, &grpc.UnaryServerInfo{FullMethod: tc.method}, handler)
+			assert.Equal(t, status.Convert(tc.expectErr).Proto(), status.Convert(err).Proto())
+
+			if tc.expected == nil {
+				assert.Empty(t, recorder.logs)
+				return
+			}
+			require.Len(t, recorder.logs, 1)
+			l := recorder.logs[0]
+			assert.NotEmpty(t, l.Id)
+			l.Id = ""
+			assert.Equal(t, tc.expected, l)
+		})
+	}
+}
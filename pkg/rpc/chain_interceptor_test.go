@@ -58,3 +58,28 @@ func TestChainUnaryServerInterceptors(t *testing.T) {
 	assert.True(t, secondRun)
 	assert.True(t, handlerRun)
 }
+
+func TestChainStreamServerInterceptors(t *testing.T) {
+	serverInfo := &grpc.StreamServerInfo{
+		FullMethod: "service.test",
+	}
+	var order []string
+	first := func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		require.Equal(t, serverInfo, info)
+		order = append(order, "first")
+		return handler(srv, stream)
+	}
+	second := func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		require.Equal(t, serverInfo, info)
+		order = append(order, "second")
+		return handler(srv, stream)
+	}
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		order = append(order, "handler")
+		return nil
+	}
+	interceptors := ChainStreamServerInterceptors(first, second)
+	err := interceptors("srv", nil, serverInfo, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"first", "second", "handler"}, order)
+}
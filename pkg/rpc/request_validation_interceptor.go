@@ -41,3 +41,27 @@ func RequestValidationUnaryServerInterceptor() grpc.UnaryServerInterceptor {
 		return handler(ctx, req)
 	}
 }
+
+// RequestValidationStreamServerInterceptor is the stream version of RequestValidationUnaryServerInterceptor.
+// Every message received from client is validated.
+func RequestValidationStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &validatingServerStream{stream})
+	}
+}
+
+type validatingServerStream struct {
+	grpc.ServerStream
+}
+
+func (s *validatingServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if v, ok := m.(requestValidator); ok {
+		if err := v.Validate(); err != nil {
+			return status.Error(codes.InvalidArgument, fmt.Sprintf("invalid request: %v", err))
+		}
+	}
+	return nil
+}
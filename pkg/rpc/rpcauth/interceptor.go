@@ -55,15 +55,45 @@ type (
 		PipedID   string
 		PipedKey  string
 	}
-	apiKeyContextKey struct{}
+	apiKeyContextKey   struct{}
+	identityContextKey struct{}
 )
 
 var (
 	claimsKey     = claimsContextKey{}
 	pipedTokenKey = pipedTokenContextKey{}
 	apiKeyKey     = apiKeyContextKey{}
+	identityKey   = identityContextKey{}
 )
 
+// Identity holds the credentials verified by the authentication interceptors.
+// It is filled as soon as the credentials were verified, even when the request
+// is then denied by the RBAC authorizer, so that the denied requests can be audited.
+type Identity struct {
+	Claims *jwt.Claims
+	APIKey *model.APIKey
+}
+
+// ContextWithIdentity returns a new context into which the authentication interceptors
+// placed after will record the verified credentials, and the Identity to read them.
+func ContextWithIdentity(ctx context.Context) (context.Context, *Identity) {
+	id := &Identity{}
+	return context.WithValue(ctx, identityKey, id), id
+}
+
+func recordIdentity(ctx context.Context, claims *jwt.Claims, apiKey *model.APIKey) {
+	id, ok := ctx.Value(identityKey).(*Identity)
+	if !ok {
+		return
+	}
+	if claims != nil {
+		id.Claims = claims
+	}
+	if apiKey != nil {
+		id.APIKey = apiKey
+	}
+}
+
 // PipedTokenUnaryServerInterceptor extracts credentials from gRPC metadata
 // and validates it by the specified Verifier.
 // If the token was valid the parsed ProjectID, PipedID, PipedKey will be set to the context.
@@ -150,24 +180,49 @@ func ExtractPipedToken(ctx context.Context) (projectID, pipedID, pipedKey string
 // The valid API key will be set to the context.
 func APIKeyUnaryServerInterceptor(verifier APIKeyVerifier, logger *zap.Logger) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		creds, err := extractCredentials(ctx)
+		apiKey, err := verifyAPIKey(ctx, verifier, logger)
 		if err != nil {
 			return nil, err
 		}
-		if creds.Type != APIKeyCredentials {
-			logger.Warn("wrong credentials type for APIKeyCredentials", zap.Any("credentials", creds))
-			return nil, errUnauthenticated
-		}
-		apiKey, err := verifier.Verify(ctx, creds.Data)
-		if err != nil {
-			logger.Warn("unable to verify api key", zap.Error(err))
-			return nil, errUnauthenticated
-		}
 		ctx = ContextWithAPIKey(ctx, apiKey)
 		return handler(ctx, req)
 	}
 }
 
+// APIKeyStreamServerInterceptor is the stream version of APIKeyUnaryServerInterceptor.
+func APIKeyStreamServerInterceptor(verifier APIKeyVerifier, logger *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := stream.Context()
+		apiKey, err := verifyAPIKey(ctx, verifier, logger)
+		if err != nil {
+			return err
+		}
+		wrappedStream := &wrappedServerStream{
+			ServerStream: stream,
+			ctx:          ContextWithAPIKey(ctx, apiKey),
+		}
+		return handler(srv, wrappedStream)
+	}
+}
+
+func verifyAPIKey(ctx context.Context, verifier APIKeyVerifier, logger *zap.Logger) (*model.APIKey, error) {
+	creds, err := extractCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if creds.Type != APIKeyCredentials {
+		logger.Warn("wrong credentials type for APIKeyCredentials", zap.Any("credentials", creds))
+		return nil, errUnauthenticated
+	}
+	apiKey, err := verifier.Verify(ctx, creds.Data)
+	if err != nil {
+		logger.Warn("unable to verify api key", zap.Error(err))
+		return nil, errUnauthenticated
+	}
+	recordIdentity(ctx, nil, apiKey)
+	return apiKey, nil
+}
+
 // ContextWithAPIKey returns a new context in which the given API key was attached.
 func ContextWithAPIKey(ctx context.Context, k *model.APIKey) context.Context {
 	return context.WithValue(ctx, apiKeyKey, k)
@@ -186,30 +241,55 @@ func ExtractAPIKey(ctx context.Context) (*model.APIKey, error) {
 // must be verified by verifier.
 func JWTUnaryServerInterceptor(verifier jwt.Verifier, authorizer RBACAuthorizer, logger *zap.Logger) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		cookie, err := extractCookie(ctx)
+		claims, err := verifyJWT(ctx, info.FullMethod, verifier, authorizer, logger)
 		if err != nil {
-			logger.Warn("failed to extract cookie", zap.Error(err))
-			return nil, errUnauthenticated
-		}
-		token, ok := cookie[jwt.SignedTokenKey]
-		if !ok {
-			logger.Warn("token does not exist in cookie")
-			return nil, errUnauthenticated
+			return nil, err
 		}
-		claims, err := verifier.Verify(token)
+		ctx = context.WithValue(ctx, claimsKey, *claims)
+		return handler(ctx, req)
+	}
+}
+
+// JWTStreamServerInterceptor is the stream version of JWTUnaryServerInterceptor.
+func JWTStreamServerInterceptor(verifier jwt.Verifier, authorizer RBACAuthorizer, logger *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := stream.Context()
+		claims, err := verifyJWT(ctx, info.FullMethod, verifier, authorizer, logger)
 		if err != nil {
-			logger.Warn("unable to verify token", zap.Error(err))
-			return nil, errUnauthenticated
+			return err
 		}
-		if !authorizer.Authorize(info.FullMethod, claims.Role) {
-			logger.Warn(fmt.Sprintf("unsufficient permission for method: %s", info.FullMethod),
-				zap.Any("claims", claims),
-			)
-			return nil, errPermissionDenied
+		wrappedStream := &wrappedServerStream{
+			ServerStream: stream,
+			ctx:          context.WithValue(ctx, claimsKey, *claims),
 		}
-		ctx = context.WithValue(ctx, claimsKey, *claims)
-		return handler(ctx, req)
+		return handler(srv, wrappedStream)
+	}
+}
+
+func verifyJWT(ctx context.Context, method string, verifier jwt.Verifier, authorizer RBACAuthorizer, logger *zap.Logger) (*jwt.Claims, error) {
+	cookie, err := extractCookie(ctx)
+	if err != nil {
+		logger.Warn("failed to extract cookie", zap.Error(err))
+		return nil, errUnauthenticated
+	}
+	token, ok := cookie[jwt.SignedTokenKey]
+	if !ok {
+		logger.Warn("token does not exist in cookie")
+		return nil, errUnauthenticated
 	}
+	claims, err := verifier.Verify(token)
+	if err != nil {
+		logger.Warn("unable to verify token", zap.Error(err))
+		return nil, errUnauthenticated
+	}
+	recordIdentity(ctx, claims, nil)
+	if !authorizer.Authorize(method, claims.Role) {
+		logger.Warn(fmt.Sprintf("unsufficient permission for method: %s", method),
+			zap.Any("claims", claims),
+		)
+		return nil, errPermissionDenied
+	}
+	return claims, nil
 }
 
 // ExtractClaims returns the claims inside a given context.
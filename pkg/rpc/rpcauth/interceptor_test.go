@@ -237,3 +237,60 @@ func TestAPIKeyUnaryServerInterceptor(t *testing.T) {
 		})
 	}
 }
+
+func TestAPIKeyStreamServerInterceptor(t *testing.T) {
+	verifier := testAPIKeyVerifier{
+		keyString: "test-api-key",
+		key: &model.APIKey{
+			Id: "test-api-key",
+		},
+	}
+	in := APIKeyStreamServerInterceptor(verifier, zap.NewNop())
+	testcases := []struct {
+		name      string
+		ctx       context.Context
+		errString string
+	}{
+		{
+			name:      "missing credentials",
+			ctx:       context.TODO(),
+			errString: "rpc error: code = Unauthenticated desc = missing credentials",
+		},
+		{
+			name: "wrong credentials type",
+			ctx: metadata.NewIncomingContext(context.Background(), metadata.MD{
+				"authorization": []string{"PIPED-TOKEN test-project-id,test-piped-id,test-piped-key"},
+			}),
+			errString: "rpc error: code = Unauthenticated desc = Unauthenticated",
+		},
+		{
+			name: "ok",
+			ctx: metadata.NewIncomingContext(context.Background(), metadata.MD{
+				"authorization": []string{"API-KEY test-api-key"},
+			}),
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			stream := &fakeServerStream{
+				ctx: tc.ctx,
+			}
+			err := in(nil, stream, nil, func(srv interface{}, stream grpc.ServerStream) error {
+				apiKey, err := ExtractAPIKey(stream.Context())
+				if err != nil {
+					return err
+				}
+				if apiKey.Id != "test-api-key" {
+					return errors.New("invalid api key")
+				}
+				return nil
+			})
+			if tc.errString != "" {
+				require.NotNil(t, err)
+				assert.Equal(t, tc.errString, err.Error())
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
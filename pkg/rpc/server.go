@@ -46,12 +46,16 @@ type Server struct {
 	enabelGRPCReflection bool
 	logger               *zap.Logger
 
-	pipedKeyAuthUnaryInterceptor      grpc.UnaryServerInterceptor
-	pipedKeyAuthStreamInterceptor     grpc.StreamServerInterceptor
-	apiKeyAuthUnaryInterceptor        grpc.UnaryServerInterceptor
-	jwtAuthUnaryInterceptor           grpc.UnaryServerInterceptor
-	requestValidationUnaryInterceptor grpc.UnaryServerInterceptor
-	logUnaryInterceptor               grpc.UnaryServerInterceptor
+	pipedKeyAuthUnaryInterceptor       grpc.UnaryServerInterceptor
+	pipedKeyAuthStreamInterceptor      grpc.StreamServerInterceptor
+	apiKeyAuthUnaryInterceptor         grpc.UnaryServerInterceptor
+	apiKeyAuthStreamInterceptor        grpc.StreamServerInterceptor
+	jwtAuthUnaryInterceptor            grpc.UnaryServerInterceptor
+	jwtAuthStreamInterceptor           grpc.StreamServerInterceptor
+	auditLogUnaryInterceptor           grpc.UnaryServerInterceptor
+	requestValidationUnaryInterceptor  grpc.UnaryServerInterceptor
+	requestValidationStreamInterceptor grpc.StreamServerInterceptor
+	logUnaryInterceptor                grpc.UnaryServerInterceptor
 }
 
 // Option defines a function to set configurable field of Server.
@@ -85,6 +89,13 @@ func WithAPIKeyAuthUnaryInterceptor(verifier rpcauth.APIKeyVerifier, logger *zap
 	}
 }
 
+// WithAPIKeyAuthStreamInterceptor sets an interceptor for validating API key.
+func WithAPIKeyAuthStreamInterceptor(verifier rpcauth.APIKeyVerifier, logger *zap.Logger) Option {
+	return func(s *Server) {
+		s.apiKeyAuthStreamInterceptor = rpcauth.APIKeyStreamServerInterceptor(verifier, logger)
+	}
+}
+
 // WithJWTAuthUnaryInterceptor sets an interceprot for checking JWT token.
 func WithJWTAuthUnaryInterceptor(verifier jwt.Verifier, authorizer rpcauth.RBACAuthorizer, logger *zap.Logger) Option {
 	return func(s *Server) {
@@ -92,6 +103,20 @@ func WithJWTAuthUnaryInterceptor(verifier jwt.Verifier, authorizer rpcauth.RBACA
 	}
 }
 
+// WithJWTAuthStreamInterceptor sets an interceprot for checking JWT token.
+func WithJWTAuthStreamInterceptor(verifier jwt.Verifier, authorizer rpcauth.RBACAuthorizer, logger *zap.Logger) Option {
+	return func(s *Server) {
+		s.jwtAuthStreamInterceptor = rpcauth.JWTStreamServerInterceptor(verifier, authorizer, logger)
+	}
+}
+
+// WithAuditLogUnaryInterceptor sets an interceptor for recording mutating requests.
+func WithAuditLogUnaryInterceptor(recorder AuditLogRecorder, logger *zap.Logger) Option {
+	return func(s *Server) {
+		s.auditLogUnaryInterceptor = AuditLogUnaryServerInterceptor(recorder, logger.Named("audit-log"))
+	}
+}
+
 // WithRequestValidationUnaryInterceptor sets an interceptor for validating request payload.
 func WithRequestValidationUnaryInterceptor() Option {
 	return func(s *Server) {
@@ -99,6 +124,13 @@ func WithRequestValidationUnaryInterceptor() Option {
 	}
 }
 
+// WithRequestValidationStreamInterceptor sets an interceptor for validating request payload.
+func WithRequestValidationStreamInterceptor() Option {
+	return func(s *Server) {
+		s.requestValidationStreamInterceptor = RequestValidationStreamServerInterceptor()
+	}
+}
+
 // WithLogUnaryInterceptor sets an interceptor for logging handled request.
 func WithLogUnaryInterceptor(logger *zap.Logger) Option {
 	return func(s *Server) {
@@ -196,6 +228,10 @@ func (s *Server) init() error {
 	if s.logUnaryInterceptor != nil {
 		unaryInterceptors = append(unaryInterceptors, s.logUnaryInterceptor)
 	}
+	// Placed before the authentication ones to record the denied requests as well.
+	if s.auditLogUnaryInterceptor != nil {
+		unaryInterceptors = append(unaryInterceptors, s.auditLogUnaryInterceptor)
+	}
 	if s.pipedKeyAuthUnaryInterceptor != nil {
 		unaryInterceptors = append(unaryInterceptors, s.pipedKeyAuthUnaryInterceptor)
 	}
@@ -212,8 +248,22 @@ func (s *Server) init() error {
 		c := ChainUnaryServerInterceptors(unaryInterceptors...)
 		opts = append(opts, grpc.UnaryInterceptor(c))
 	}
+	var streamInterceptors []grpc.StreamServerInterceptor
 	if s.pipedKeyAuthStreamInterceptor != nil {
-		opts = append(opts, grpc.StreamInterceptor(s.pipedKeyAuthStreamInterceptor))
+		streamInterceptors = append(streamInterceptors, s.pipedKeyAuthStreamInterceptor)
+	}
+	if s.apiKeyAuthStreamInterceptor != nil {
+		streamInterceptors = append(streamInterceptors, s.apiKeyAuthStreamInterceptor)
+	}
+	if s.jwtAuthStreamInterceptor != nil {
+		streamInterceptors = append(streamInterceptors, s.jwtAuthStreamInterceptor)
+	}
+	if s.requestValidationStreamInterceptor != nil {
+		streamInterceptors = append(streamInterceptors, s.requestValidationStreamInterceptor)
+	}
+	if len(streamInterceptors) > 0 {
+		c := ChainStreamServerInterceptors(streamInterceptors...)
+		opts = append(opts, grpc.StreamInterceptor(c))
 	}
 	s.grpcServer = grpc.NewServer(opts...)
 